@@ -82,7 +82,8 @@ func (BigQueryDialect) KindForDataType(rawBqType string) (typing.KindDetails, er
 	case "decimal", "float", "float64", "bigdecimal":
 		return typing.Float, nil
 	case "int", "integer", "int64":
-		return typing.Integer, nil
+		// BigQuery only has INT64, the other names are aliases.
+		return typing.BuildIntegerKind(typing.BigIntegerKind), nil
 	case "varchar", "string":
 		return typing.String, nil
 	case "bool", "boolean":
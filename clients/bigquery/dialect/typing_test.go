@@ -146,7 +146,7 @@ func TestBigQueryDialect_KindForDataType(t *testing.T) {
 		for _, intKind := range []string{"int", "integer", "inT64"} {
 			kd, err := dialect.KindForDataType(intKind)
 			assert.NoError(t, err)
-			assert.Equal(t, typing.Integer, kd, intKind)
+			assert.Equal(t, typing.BuildIntegerKind(typing.BigIntegerKind), kd, intKind)
 		}
 	}
 	{
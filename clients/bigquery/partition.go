@@ -2,6 +2,7 @@ package bigquery
 
 import (
 	"fmt"
+	"iter"
 	"maps"
 	"slices"
 	"time"
@@ -12,10 +13,14 @@ import (
 )
 
 // [buildDistinctDates] - Builds a list of distinct dates from the rows for BigQuery date partitioning.
-func buildDistinctDates(colName string, rows []optimization.Row, reservedColumnNames map[string]bool) ([]string, error) {
+func buildDistinctDates(colName string, rows iter.Seq2[optimization.Row, error], reservedColumnNames map[string]bool) ([]string, error) {
 	dateMap := make(map[string]bool)
 	colName = columns.EscapeName(colName, reservedColumnNames)
-	for _, row := range rows {
+	for row, err := range rows {
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+
 		val, ok := row.GetValue(colName)
 		if !ok || val == nil {
 			// Skip distinct date filtering if there are nil or missing values. They will all end up in the `__NULL__` partition.
@@ -1,6 +1,7 @@
 package bigquery
 
 import (
+	"iter"
 	"slices"
 	"testing"
 	"time"
@@ -10,12 +11,14 @@ import (
 	"github.com/artie-labs/transfer/lib/optimization"
 )
 
-func buildRows(data []map[string]any) []optimization.Row {
-	var rows []optimization.Row
-	for _, row := range data {
-		rows = append(rows, optimization.NewRow(row))
+func buildRows(data []map[string]any) iter.Seq2[optimization.Row, error] {
+	return func(yield func(optimization.Row, error) bool) {
+		for _, row := range data {
+			if !yield(optimization.NewRow(row), nil) {
+				return
+			}
+		}
 	}
-	return rows
 }
 
 func TestDistinctDates(t *testing.T) {
@@ -38,7 +41,7 @@ func TestDistinctDates(t *testing.T) {
 	}
 	{
 		// No dates
-		dates, err := buildDistinctDates("", nil, nil)
+		dates, err := buildDistinctDates("", buildRows(nil), nil)
 		assert.NoError(t, err)
 		assert.Empty(t, dates)
 	}
@@ -123,7 +123,26 @@ func (s Store) Merge(ctx context.Context, tableData *optimization.TableData, whC
 	return true, nil
 }
 
-func (s Store) Dedupe(ctx context.Context, tableID sql.TableIdentifier, _ kafkalib.DatabaseAndSchemaPair, primaryKeys []string, includeArtieUpdatedAt bool) error {
+func (s Store) Dedupe(ctx context.Context, tableID sql.TableIdentifier, pair kafkalib.DatabaseAndSchemaPair, primaryKeys []string, includeArtieUpdatedAt bool) error {
+	query, args := dialect.ClickhouseDialect{}.BuildTableEngineQuery(tableID)
+	var engine dialect.TableEngine
+	if err := s.QueryRowContext(ctx, query, args...).Scan(&engine.Table, &engine.Database, &engine.SortingKey); err != nil {
+		return fmt.Errorf("failed to get the engine for %q: %w", tableID.FullyQualifiedName(), err)
+	}
+
+	stagingTableID := shared.BuildStagingTableID(s, pair, tableID)
+	dedupeQueries, err := dialect.ClickhouseDialect{}.BuildDedupeQueriesForEngine(tableID, stagingTableID, primaryKeys, includeArtieUpdatedAt, engine)
+	if err != nil {
+		return err
+	}
+
+	// ClickHouse does not support multi-statement transactions, so each statement runs on its own.
+	for _, query := range dedupeQueries {
+		if _, err := s.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to dedupe: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -1,7 +1,9 @@
 package dialect
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/artie-labs/transfer/lib/config"
@@ -47,8 +49,83 @@ func (ClickhouseDialect) BuildIsNotToastValueExpression(tableAlias constants.Tab
 	panic("not implemented")
 }
 
-func (ClickhouseDialect) BuildDedupeQueries(tableID, stagingTableID sql.TableIdentifier, primaryKeys []string, includeArtieUpdatedAt bool) []string {
-	panic("not implemented")
+// TableEngine is what [ClickhouseDialect.BuildTableEngineQuery] returns for a table.
+type TableEngine struct {
+	Table    string
+	Database string
+	// SortingKey is the table's ORDER BY expression, e.g. "id, name".
+	SortingKey string
+}
+
+// BuildTableEngineQuery returns the engine and sorting key of [tableID] and the engine of the database it lives in.
+func (ClickhouseDialect) BuildTableEngineQuery(tableID sql.TableIdentifier) (string, []any) {
+	return "SELECT t.engine, d.engine, t.sorting_key FROM system.tables AS t INNER JOIN system.databases AS d ON t.database = d.name WHERE t.database = ? AND t.name = ?", []any{tableID.Schema(), tableID.Table()}
+}
+
+// BuildDedupeQueriesForEngine picks how to dedupe [tableID] based on its table and database engines:
+//   - ReplacingMergeTree tables are deduped in place with OPTIMIZE ... FINAL DEDUPLICATE, as long as their sorting key is [primaryKeys].
+//   - Other Replicated* tables are not supported. A clone would share its ZooKeeper path, so they can't be rewritten through a
+//     staging table, and OPTIMIZE ... DEDUPLICATE only removes rows that are fully identical.
+//   - Other MergeTree tables are rewritten with [ClickhouseDialect.BuildDedupeQueries], which needs an Atomic database for EXCHANGE TABLES.
+func (cd ClickhouseDialect) BuildDedupeQueriesForEngine(tableID, stagingTableID sql.TableIdentifier, primaryKeys []string, includeArtieUpdatedAt bool, engine TableEngine) ([]string, error) {
+	if strings.HasSuffix(engine.Table, "ReplacingMergeTree") {
+		// Rows are only collapsed by the sorting key, so a different sorting key would keep duplicates or drop distinct rows.
+		if !sortingKeyMatches(engine.SortingKey, primaryKeys) {
+			return nil, fmt.Errorf("dedupe is not supported for %q tables whose sorting key %q does not match the primary keys %v: %w", engine.Table, engine.SortingKey, primaryKeys, errors.ErrUnsupported)
+		}
+
+		return []string{cd.BuildOptimizeDedupeQuery(tableID)}, nil
+	}
+
+	if strings.HasPrefix(engine.Table, "Replicated") || !strings.HasSuffix(engine.Table, "MergeTree") {
+		return nil, fmt.Errorf("dedupe is not supported for tables with the %q engine: %w", engine.Table, errors.ErrUnsupported)
+	}
+
+	if engine.Database != "Atomic" {
+		return nil, fmt.Errorf("dedupe is not supported for tables in a database with the %q engine: %w", engine.Database, errors.ErrUnsupported)
+	}
+
+	return cd.BuildDedupeQueries(tableID, stagingTableID, primaryKeys, includeArtieUpdatedAt), nil
+}
+
+// sortingKeyMatches returns true if [sortingKey] is made up of exactly the columns in [primaryKeys], in any order.
+func sortingKeyMatches(sortingKey string, primaryKeys []string) bool {
+	var columns []string
+	for _, part := range strings.Split(sortingKey, ",") {
+		columns = append(columns, strings.Trim(strings.TrimSpace(part), "`"))
+	}
+
+	return len(primaryKeys) > 0 && slices.Equal(slices.Sorted(slices.Values(columns)), slices.Sorted(slices.Values(primaryKeys)))
+}
+
+// BuildOptimizeDedupeQuery forces a merge of every part of [tableID]. ReplacingMergeTree tables keep the row with the
+// highest version (or the last inserted row if there is no version column) per sorting key and DEDUPLICATE removes any rows that are fully identical.
+func (cd ClickhouseDialect) BuildOptimizeDedupeQuery(tableID sql.TableIdentifier) string {
+	return fmt.Sprintf("OPTIMIZE TABLE %s FINAL DEDUPLICATE", tableID.FullyQualifiedName())
+}
+
+// BuildDedupeQueries rewrites the table into a clone that keeps the latest row per primary key and then swaps the two.
+// OPTIMIZE ... FINAL only collapses rows by the table's sorting key (which may not match [primaryKeys]) and does
+// nothing for plain MergeTree tables, which is why those are rewritten instead.
+func (cd ClickhouseDialect) BuildDedupeQueries(tableID, stagingTableID sql.TableIdentifier, primaryKeys []string, includeArtieUpdatedAt bool) []string {
+	primaryKeysEscaped := sql.QuoteIdentifiers(primaryKeys, cd)
+	orderByCols := append([]string{}, primaryKeysEscaped...)
+	if includeArtieUpdatedAt {
+		orderByCols = append(orderByCols, fmt.Sprintf("%s DESC", cd.QuoteIdentifier(constants.UpdateColumnMarker)))
+	}
+
+	return []string{
+		fmt.Sprintf("CREATE TABLE %s AS %s", stagingTableID.FullyQualifiedName(), tableID.FullyQualifiedName()),
+		fmt.Sprintf("INSERT INTO %s SELECT * FROM %s ORDER BY %s LIMIT 1 BY %s",
+			stagingTableID.FullyQualifiedName(),
+			tableID.FullyQualifiedName(),
+			strings.Join(orderByCols, ", "),
+			strings.Join(primaryKeysEscaped, ", "),
+		),
+		// EXCHANGE TABLES is atomic, so readers never observe a partially deduped table.
+		fmt.Sprintf("EXCHANGE TABLES %s AND %s", tableID.FullyQualifiedName(), stagingTableID.FullyQualifiedName()),
+		fmt.Sprintf("DROP TABLE %s", stagingTableID.FullyQualifiedName()),
+	}
 }
 
 func (ClickhouseDialect) BuildMergeQueries(
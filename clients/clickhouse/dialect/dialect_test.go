@@ -0,0 +1,88 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClickhouseDialect_BuildDedupeQueries(t *testing.T) {
+	tableID := NewTableIdentifier("shop", "customers")
+	stagingTableID := NewTableIdentifier("shop", "customers__artie_dedupe")
+	{
+		// One primary key + no `__artie_updated_at` flag.
+		assert.Equal(t, []string{
+			"CREATE TABLE `shop`.`customers__artie_dedupe` AS `shop`.`customers`",
+			"INSERT INTO `shop`.`customers__artie_dedupe` SELECT * FROM `shop`.`customers` ORDER BY `id` LIMIT 1 BY `id`",
+			"EXCHANGE TABLES `shop`.`customers` AND `shop`.`customers__artie_dedupe`",
+			"DROP TABLE `shop`.`customers__artie_dedupe`",
+		}, ClickhouseDialect{}.BuildDedupeQueries(tableID, stagingTableID, []string{"id"}, false))
+	}
+	{
+		// Composite keys + `__artie_updated_at` flag.
+		parts := ClickhouseDialect{}.BuildDedupeQueries(tableID, stagingTableID, []string{"user_id", "settings"}, true)
+		assert.Len(t, parts, 4)
+		assert.Equal(t, "INSERT INTO `shop`.`customers__artie_dedupe` SELECT * FROM `shop`.`customers` ORDER BY `user_id`, `settings`, `__artie_updated_at` DESC LIMIT 1 BY `user_id`, `settings`", parts[1])
+	}
+}
+
+func TestClickhouseDialect_BuildDedupeQueriesForEngine(t *testing.T) {
+	tableID := NewTableIdentifier("shop", "customers")
+	stagingTableID := NewTableIdentifier("shop", "customers__artie_dedupe")
+	{
+		// ReplacingMergeTree is deduped in place.
+		for _, engine := range []string{"ReplacingMergeTree", "ReplicatedReplacingMergeTree", "SharedReplacingMergeTree"} {
+			queries, err := ClickhouseDialect{}.BuildDedupeQueriesForEngine(tableID, stagingTableID, []string{"id"}, true, TableEngine{Table: engine, Database: "Ordinary", SortingKey: "id"})
+			assert.NoError(t, err, engine)
+			assert.Equal(t, []string{"OPTIMIZE TABLE `shop`.`customers` FINAL DEDUPLICATE"}, queries, engine)
+		}
+	}
+	{
+		// The sorting key can list the primary keys in any order.
+		queries, err := ClickhouseDialect{}.BuildDedupeQueriesForEngine(tableID, stagingTableID, []string{"id", "store id"}, true, TableEngine{Table: "ReplacingMergeTree", Database: "Atomic", SortingKey: "`store id`, id"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"OPTIMIZE TABLE `shop`.`customers` FINAL DEDUPLICATE"}, queries)
+	}
+	{
+		// ReplacingMergeTree with a sorting key that doesn't match the primary keys.
+		for _, sortingKey := range []string{"id, created_at", "store_id", "toDate(created_at), id", ""} {
+			_, err := ClickhouseDialect{}.BuildDedupeQueriesForEngine(tableID, stagingTableID, []string{"id"}, true, TableEngine{Table: "ReplacingMergeTree", Database: "Atomic", SortingKey: sortingKey})
+			assert.ErrorIs(t, err, errors.ErrUnsupported, sortingKey)
+			assert.ErrorContains(t, err, fmt.Sprintf(`sorting key %q does not match the primary keys [id]`, sortingKey))
+		}
+	}
+	{
+		// Other replicated tables can't be cloned and OPTIMIZE would only remove identical rows.
+		for _, engine := range []string{"ReplicatedMergeTree", "ReplicatedCollapsingMergeTree"} {
+			_, err := ClickhouseDialect{}.BuildDedupeQueriesForEngine(tableID, stagingTableID, []string{"id"}, true, TableEngine{Table: engine, Database: "Atomic", SortingKey: "id"})
+			assert.ErrorIs(t, err, errors.ErrUnsupported, engine)
+			assert.ErrorContains(t, err, fmt.Sprintf(`tables with the %q engine`, engine))
+		}
+	}
+	{
+		// MergeTree in an Atomic database is rewritten.
+		queries, err := ClickhouseDialect{}.BuildDedupeQueriesForEngine(tableID, stagingTableID, []string{"id"}, false, TableEngine{Table: "MergeTree", Database: "Atomic", SortingKey: "id"})
+		assert.NoError(t, err)
+		assert.Equal(t, ClickhouseDialect{}.BuildDedupeQueries(tableID, stagingTableID, []string{"id"}, false), queries)
+	}
+	{
+		// MergeTree outside of an Atomic database doesn't support EXCHANGE TABLES.
+		_, err := ClickhouseDialect{}.BuildDedupeQueriesForEngine(tableID, stagingTableID, []string{"id"}, false, TableEngine{Table: "MergeTree", Database: "Ordinary"})
+		assert.ErrorIs(t, err, errors.ErrUnsupported)
+		assert.ErrorContains(t, err, `database with the "Ordinary" engine`)
+	}
+	{
+		// Non-MergeTree engines are not supported.
+		_, err := ClickhouseDialect{}.BuildDedupeQueriesForEngine(tableID, stagingTableID, []string{"id"}, false, TableEngine{Table: "Log", Database: "Atomic"})
+		assert.ErrorIs(t, err, errors.ErrUnsupported)
+		assert.ErrorContains(t, err, `tables with the "Log" engine`)
+	}
+}
+
+func TestClickhouseDialect_BuildTableEngineQuery(t *testing.T) {
+	query, args := ClickhouseDialect{}.BuildTableEngineQuery(NewTableIdentifier("shop", "customers"))
+	assert.Equal(t, "SELECT t.engine, d.engine, t.sorting_key FROM system.tables AS t INNER JOIN system.databases AS d ON t.database = d.name WHERE t.database = ? AND t.name = ?", query)
+	assert.Equal(t, []any{"shop", "customers"}, args)
+}
@@ -71,7 +71,11 @@ func (s Store) LoadDataIntoTable(ctx context.Context, tableData *optimization.Ta
 		defer stmt.Close()
 
 		// Insert each row using the prepared statement
-		for _, row := range tableData.Rows() {
+		for row, err := range tableData.AllRows() {
+			if err != nil {
+				return fmt.Errorf("failed to read row: %w", err)
+			}
+
 			values := []any{}
 			for _, col := range cols {
 				if dontWriteArtieColumns[col.Name()] {
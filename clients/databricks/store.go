@@ -97,6 +97,21 @@ func (s Store) Dedupe(ctx context.Context, tableID sql.TableIdentifier, pair kaf
 	return nil
 }
 
+// ValidateColumnRename returns an error if column mapping is not enabled, Delta tables cannot rename or drop columns without it.
+func (s Store) ValidateColumnRename(ctx context.Context, tableID sql.TableIdentifier) error {
+	var key, value string
+	query := fmt.Sprintf("SHOW TBLPROPERTIES %s ('delta.columnMapping.mode')", tableID.FullyQualifiedName())
+	if err := s.QueryRowContext(ctx, query).Scan(&key, &value); err != nil {
+		return fmt.Errorf("failed to get column mapping mode: %w", err)
+	}
+
+	if value != "name" && value != "id" {
+		return fmt.Errorf("renaming columns is not supported on %s since column mapping is not enabled, set 'delta.columnMapping.mode' to 'name' to allow it", tableID.FullyQualifiedName())
+	}
+
+	return nil
+}
+
 func (s Store) GetTableConfig(ctx context.Context, tableID sql.TableIdentifier, dropDeletedColumns bool) (*types.DestinationTableConfig, error) {
 	return shared.GetTableCfgArgs{
 		Destination:           s,
@@ -0,0 +1,22 @@
+//go:build !duckdb || !cgo
+
+package duckdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+)
+
+// LoadStore returns an error since DuckDB is opened in-process through cgo, which the release binary is built without.
+// Build with `CGO_ENABLED=1 go build -tags duckdb` to use DuckDB as a destination.
+func LoadStore(_ context.Context, _ config.Config) (destination.SQLDestination, error) {
+	return nil, fmt.Errorf("transfer was built without DuckDB support, rebuild it with cgo enabled and the %q build tag", "duckdb")
+}
+
+// LoadReadOnlyStore returns an error for the same reason as [LoadStore].
+func LoadReadOnlyStore(ctx context.Context, cfg config.Config) (destination.SQLDestination, error) {
+	return LoadStore(ctx, cfg)
+}
@@ -0,0 +1,100 @@
+//go:build duckdb && cgo
+
+package duckdb
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/artie-labs/transfer/clients/motherduck/dialect"
+	"github.com/artie-labs/transfer/clients/shared"
+	"github.com/artie-labs/transfer/lib/batch"
+	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+// batchSize is the number of rows to insert per INSERT statement.
+const batchSize = 1000
+
+func (s *Store) LoadDataIntoTable(ctx context.Context, tableData *optimization.TableData, dwh *types.DestinationTableConfig, tableID, _ sql.TableIdentifier, opts types.AdditionalSettings, createTempTable bool) error {
+	if createTempTable {
+		if err := shared.CreateTempTable(ctx, s, tableData, dwh, opts.ColumnSettings, tableID); err != nil {
+			return fmt.Errorf("failed to create temp table: %w", err)
+		}
+	}
+
+	if tableData.NumberOfRows() == 0 {
+		return nil
+	}
+
+	cols := tableData.ReadOnlyInMemoryCols().ValidColumns()
+	if len(cols) == 0 {
+		return fmt.Errorf("no valid columns to insert")
+	}
+
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	return db.CommitOrRollback(tx, func(tx *gosql.Tx) error {
+		var rowsLoaded int64
+		err := batch.ByCount(tableData.AllRows(), batchSize, func(rows []optimization.Row) error {
+			affected, err := s.executeBatchInsert(ctx, tx, tableID, cols, rows)
+			if err != nil {
+				return err
+			}
+
+			rowsLoaded += affected
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to load rows: %w", err)
+		}
+
+		if expectedRows := int64(tableData.NumberOfRows()); rowsLoaded != expectedRows {
+			return fmt.Errorf("expected %d rows to be loaded, but got %d", expectedRows, rowsLoaded)
+		}
+
+		return nil
+	})
+}
+
+// executeBatchInsert inserts [rows] with a single INSERT statement, unlike the appender this will cast the values into the column's type.
+func (s *Store) executeBatchInsert(ctx context.Context, tx *gosql.Tx, tableID sql.TableIdentifier, cols []columns.Column, rows []optimization.Row) (int64, error) {
+	placeholders := make([]string, len(rows))
+	values := make([]any, 0, len(rows)*len(cols))
+	for i, row := range rows {
+		rowPlaceholders := make([]string, len(cols))
+		for j, col := range cols {
+			value, _ := row.GetValue(col.Name())
+			convertedValue, err := dialect.ConvertValue(value, col.KindDetails)
+			if err != nil {
+				return 0, fmt.Errorf("failed to convert value for column %q: %w", col.Name(), err)
+			}
+
+			values = append(values, convertedValue)
+			rowPlaceholders[j] = fmt.Sprintf("$%d", len(values))
+		}
+
+		placeholders[i] = "(" + strings.Join(rowPlaceholders, ", ") + ")"
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		tableID.FullyQualifiedName(),
+		strings.Join(sql.QuoteColumns(cols, s.dialect()), ", "),
+		strings.Join(placeholders, ", "),
+	)
+
+	result, err := tx.ExecContext(ctx, query, values...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute batch insert: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,183 @@
+//go:build duckdb && cgo
+
+package duckdb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+
+	"github.com/artie-labs/transfer/clients/motherduck/dialect"
+	"github.com/artie-labs/transfer/clients/shared"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/webhooks"
+)
+
+// Store writes to a local DuckDB database that is opened in-process, it shares the dialect with MotherDuck.
+type Store struct {
+	// catalog is the name DuckDB gave to the database file, every table is created in here regardless of the topic's database.
+	catalog   string
+	configMap *types.DestinationTableConfigMap
+	config    config.Config
+
+	db.Store
+}
+
+func LoadStore(ctx context.Context, cfg config.Config) (*Store, error) {
+	if err := cfg.DuckDB.Validate(); err != nil {
+		return nil, err
+	}
+
+	store, err := db.Open("duckdb", cfg.DuckDB.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		configMap: &types.DestinationTableConfigMap{},
+		config:    cfg,
+		Store:     store,
+	}
+
+	if err = s.setUp(ctx); err != nil {
+		if closeErr := store.Close(); closeErr != nil {
+			slog.Warn("Failed to close database after error", slog.Any("error", closeErr))
+		}
+
+		return nil, err
+	}
+
+	slog.Info("Loaded DuckDB as a destination", slog.String("path", cfg.DuckDB.Path), slog.String("catalog", s.catalog))
+	return s, nil
+}
+
+// LoadReadOnlyStore opens an existing database file in read-only mode, unlike [LoadStore] it won't create the file or any schemas.
+func LoadReadOnlyStore(ctx context.Context, cfg config.Config) (*Store, error) {
+	if err := cfg.DuckDB.Validate(); err != nil {
+		return nil, err
+	}
+
+	store, err := db.Open("duckdb", cfg.DuckDB.Path+"?access_mode=read_only")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		configMap: &types.DestinationTableConfigMap{},
+		config:    cfg,
+		Store:     store,
+	}
+
+	if err = s.loadCatalog(ctx); err != nil {
+		if closeErr := store.Close(); closeErr != nil {
+			slog.Warn("Failed to close database after error", slog.Any("error", closeErr))
+		}
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Store) loadCatalog(ctx context.Context) error {
+	if err := s.QueryRowContext(ctx, "SELECT current_database()").Scan(&s.catalog); err != nil {
+		return fmt.Errorf("failed to retrieve the current database: %w", err)
+	}
+
+	return nil
+}
+
+// setUp looks up the catalog name and creates the schemas for every topic, MotherDuck users create these ahead of time but a local file starts out empty.
+func (s *Store) setUp(ctx context.Context) error {
+	if err := s.loadCatalog(ctx); err != nil {
+		return err
+	}
+
+	for _, schema := range kafkalib.GetAllUniqueSchemas(s.config.TopicConfigs()) {
+		if _, err := s.ExecContext(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s.%s", s.dialect().QuoteIdentifier(s.catalog), s.dialect().QuoteIdentifier(schema))); err != nil {
+			return fmt.Errorf("failed to create schema %q: %w", schema, err)
+		}
+	}
+
+	return nil
+}
+
+func (s Store) dialect() dialect.DuckDBDialect {
+	return dialect.DuckDBDialect{}
+}
+
+func (s Store) Dialect() sql.Dialect {
+	return s.dialect()
+}
+
+func (s Store) Label() constants.DestinationKind {
+	return s.config.Output
+}
+
+func (s Store) GetConfig() config.Config {
+	return s.config
+}
+
+func (s Store) IsOLTP() bool {
+	return false
+}
+
+func (s *Store) Dedupe(ctx context.Context, tableID sql.TableIdentifier, pair kafkalib.DatabaseAndSchemaPair, primaryKeys []string, includeArtieUpdatedAt bool) error {
+	stagingTableID := shared.BuildStagingTableID(s, pair, tableID)
+	if _, err := destination.ExecContextStatements(ctx, s, s.dialect().BuildDedupeQueries(tableID, stagingTableID, primaryKeys, includeArtieUpdatedAt)); err != nil {
+		return fmt.Errorf("failed to dedupe: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) SweepTemporaryTables(ctx context.Context) error {
+	return shared.Sweep(ctx, s, s.config.TopicConfigs(), func(_, schemaName string) (string, []any) {
+		return s.dialect().BuildSweepQuery(s.catalog, schemaName)
+	})
+}
+
+func (s *Store) DropTable(ctx context.Context, tableID sql.TableIdentifier) error {
+	return shared.DropTemporaryTable(ctx, s, tableID, s.configMap)
+}
+
+func (s *Store) Merge(ctx context.Context, tableData *optimization.TableData, whClient *webhooks.Client) (bool, error) {
+	if tableData.MultiStepMergeSettings().Enabled {
+		return shared.MultiStepMerge(ctx, s, tableData, types.MergeOpts{}, whClient)
+	}
+
+	if err := shared.Merge(ctx, s, tableData, types.MergeOpts{}, whClient); err != nil {
+		return false, fmt.Errorf("failed to merge: %w", err)
+	}
+
+	return true, nil
+}
+
+func (s *Store) Append(ctx context.Context, tableData *optimization.TableData, whClient *webhooks.Client, _ bool) error {
+	return shared.Append(ctx, s, tableData, whClient, types.AdditionalSettings{})
+}
+
+// IdentifierFor returns a generic [sql.TableIdentifier] interface for a [TopicConfig] + table name.
+func (s *Store) IdentifierFor(databaseAndSchema kafkalib.DatabaseAndSchemaPair, table string) sql.TableIdentifier {
+	return dialect.NewTableIdentifier(s.catalog, databaseAndSchema.Schema, table)
+}
+
+func (s *Store) GetTableConfig(ctx context.Context, tableID sql.TableIdentifier, dropDeletedColumns bool) (*types.DestinationTableConfig, error) {
+	return shared.GetTableCfgArgs{
+		Destination:           s,
+		TableID:               tableID,
+		ConfigMap:             s.configMap,
+		ColumnNameForName:     "column_name",
+		ColumnNameForDataType: "data_type",
+		DropDeletedColumns:    dropDeletedColumns,
+	}.GetTableConfig(ctx)
+}
@@ -0,0 +1,200 @@
+//go:build duckdb && cgo
+
+package duckdb
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/clients/motherduck/dialect"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+var topicConfig = kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders", DropDeletedColumns: true}
+
+func newStore(t *testing.T, topicConfigs ...*kafkalib.TopicConfig) *Store {
+	store, err := LoadStore(t.Context(), config.Config{
+		Output: constants.DuckDB,
+		DuckDB: &config.DuckDBSettings{Path: filepath.Join(t.TempDir(), "artie.duckdb")},
+		Kafka:  &kafkalib.Kafka{TopicConfigs: topicConfigs},
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, store.Close()) })
+	return store
+}
+
+func newTableData(t *testing.T, topicConfig kafkalib.TopicConfig, colNames []string, rows ...map[string]any) *optimization.TableData {
+	cols := columns.NewColumns(nil)
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	for _, colName := range colNames {
+		col := columns.NewColumn(colName, typing.String)
+		col.ToastColumn = true
+		cols.AddColumn(col)
+	}
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+	cols.AddColumn(columns.NewColumn(constants.OnlySetDeleteColumnMarker, typing.Boolean))
+
+	tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, topicConfig, "orders")
+	tableData.SetLatestTimestamp(time.Now())
+	for _, row := range rows {
+		deleted, _ := row[constants.DeleteColumnMarker].(bool)
+		assert.NoError(t, tableData.InsertRow(fmt.Sprint(row["id"]), row, deleted))
+	}
+
+	return tableData
+}
+
+func merge(t *testing.T, store *Store, tableData *optimization.TableData) {
+	commit, err := store.Merge(t.Context(), tableData, nil)
+	assert.NoError(t, err)
+	assert.True(t, commit)
+}
+
+func queryRows(t *testing.T, store *Store, query string) [][]any {
+	rows, err := store.QueryContext(t.Context(), query)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	assert.NoError(t, err)
+
+	var out [][]any
+	for rows.Next() {
+		values := make([]any, len(cols))
+		pointers := make([]any, len(cols))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		assert.NoError(t, rows.Scan(pointers...))
+		out = append(out, values)
+	}
+
+	assert.NoError(t, rows.Err())
+	return out
+}
+
+func TestLoadStore(t *testing.T) {
+	{
+		// Invalid settings
+		_, err := LoadStore(t.Context(), config.Config{Output: constants.DuckDB, DuckDB: &config.DuckDBSettings{}})
+		assert.ErrorContains(t, err, "duckdb path is required")
+	}
+	{
+		// The catalog is named after the file and the schemas are created upfront
+		store := newStore(t, &topicConfig)
+		assert.Equal(t, "artie", store.catalog)
+		assert.Equal(t, [][]any{{"public"}}, queryRows(t, store, `SELECT schema_name FROM information_schema.schemata WHERE schema_name = 'public'`))
+	}
+}
+
+func TestLoadReadOnlyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "artie.duckdb")
+	cfg := config.Config{
+		Output: constants.DuckDB,
+		DuckDB: &config.DuckDBSettings{Path: path},
+		Kafka:  &kafkalib.Kafka{TopicConfigs: []*kafkalib.TopicConfig{&topicConfig}},
+	}
+	{
+		// The database file has to exist already
+		_, err := LoadReadOnlyStore(t.Context(), cfg)
+		assert.Error(t, err)
+		assert.NoFileExists(t, path)
+	}
+	{
+		// Schemas aren't created and nothing can be written
+		store, err := LoadStore(t.Context(), config.Config{Output: constants.DuckDB, DuckDB: &config.DuckDBSettings{Path: path}})
+		assert.NoError(t, err)
+		assert.NoError(t, store.Close())
+
+		readOnlyStore, err := LoadReadOnlyStore(t.Context(), cfg)
+		assert.NoError(t, err)
+		t.Cleanup(func() { assert.NoError(t, readOnlyStore.Close()) })
+		assert.Equal(t, "artie", readOnlyStore.catalog)
+		assert.Empty(t, queryRows(t, readOnlyStore, `SELECT schema_name FROM information_schema.schemata WHERE schema_name = 'public'`))
+
+		_, err = readOnlyStore.ExecContext(t.Context(), "CREATE SCHEMA public")
+		assert.ErrorContains(t, err, "read-only")
+	}
+}
+
+func TestStore_Merge(t *testing.T) {
+	store := newStore(t, &topicConfig)
+	tableID := store.IdentifierFor(topicConfig.BuildDatabaseAndSchemaPair(), "orders")
+	{
+		// Initial load creates the table
+		merge(t, store, newTableData(t, topicConfig, []string{"name"},
+			map[string]any{"id": int64(1), "name": "foo", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(2), "name": "bar", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(3), "name": "baz", constants.DeleteColumnMarker: false},
+		))
+		assert.Equal(t, [][]any{{int64(1), "foo"}, {int64(2), "bar"}, {int64(3), "baz"}}, queryRows(t, store, fmt.Sprintf(`SELECT "id", "name" FROM %s ORDER BY "id"`, tableID.FullyQualifiedName())))
+	}
+	{
+		// Update, delete, TOAST and a new column
+		merge(t, store, newTableData(t, topicConfig, []string{"name", "email"},
+			map[string]any{"id": int64(1), "name": "updated", "email": "foo@artie.com", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(2), constants.DeleteColumnMarker: true},
+			map[string]any{"id": int64(3), "name": constants.ToastUnavailableValuePlaceholder, "email": "baz@artie.com", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(4), "name": "new", constants.DeleteColumnMarker: false},
+		))
+		assert.Equal(t, [][]any{
+			{int64(1), "updated", "foo@artie.com"},
+			{int64(3), "baz", "baz@artie.com"},
+			{int64(4), "new", nil},
+		}, queryRows(t, store, fmt.Sprintf(`SELECT "id", "name", "email" FROM %s ORDER BY "id"`, tableID.FullyQualifiedName())))
+	}
+	{
+		// The table config is read back through information_schema
+		store.configMap.RemoveTable(tableID)
+		tableConfig, err := store.GetTableConfig(t.Context(), tableID, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"id", "name", "email"}, columns.ColumnNames(tableConfig.GetColumns()))
+	}
+	{
+		// Temporary tables are dropped after every merge
+		assert.Empty(t, queryRows(t, store, fmt.Sprintf(`SELECT table_name FROM information_schema.tables WHERE table_name LIKE '%%%s%%'`, constants.ArtiePrefix)))
+	}
+}
+
+func TestStore_Merge_SoftDelete(t *testing.T) {
+	softDelete := kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders", SoftDelete: true}
+	store := newStore(t, &softDelete)
+	merge(t, store, newTableData(t, softDelete, []string{"name"},
+		map[string]any{"id": int64(1), "name": "foo", constants.DeleteColumnMarker: false},
+		map[string]any{"id": int64(2), "name": "bar", constants.DeleteColumnMarker: false},
+	))
+	merge(t, store, newTableData(t, softDelete, []string{"name"},
+		map[string]any{"id": int64(1), constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true},
+		map[string]any{"id": int64(3), "name": "baz", constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true},
+	))
+
+	tableID := store.IdentifierFor(softDelete.BuildDatabaseAndSchemaPair(), "orders")
+	assert.Equal(t, [][]any{
+		{int64(1), "foo", true},
+		{int64(2), "bar", false},
+		{int64(3), "baz", true},
+	}, queryRows(t, store, fmt.Sprintf(`SELECT "id", "name", "__artie_delete" FROM %s ORDER BY "id"`, tableID.FullyQualifiedName())))
+}
+
+func TestStore_SweepTemporaryTables(t *testing.T) {
+	store := newStore(t, &topicConfig)
+	expired := fmt.Sprintf("orders_%s_abcde_%d", constants.ArtiePrefix, time.Now().Add(-time.Hour).Unix())
+	active := fmt.Sprintf("orders_%s_fghij_%d", constants.ArtiePrefix, time.Now().Add(time.Hour).Unix())
+	for _, tableName := range []string{"orders", expired, active} {
+		_, err := store.ExecContext(t.Context(), fmt.Sprintf("CREATE TABLE %s (id BIGINT)", dialect.NewTableIdentifier(store.catalog, "public", tableName).FullyQualifiedName()))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, store.SweepTemporaryTables(t.Context()))
+	assert.Equal(t, [][]any{{"orders"}, {active}}, queryRows(t, store, `SELECT table_name FROM information_schema.tables WHERE table_schema = 'public' ORDER BY table_name`))
+}
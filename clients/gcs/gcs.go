@@ -18,7 +18,6 @@ import (
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/optimization"
 	"github.com/artie-labs/transfer/lib/sql"
-	"github.com/artie-labs/transfer/lib/stringutil"
 	"github.com/artie-labs/transfer/lib/webhooks"
 )
 
@@ -49,6 +48,10 @@ func (s Store) Validate() error {
 	return nil
 }
 
+func (s Store) CheckConnectivity(ctx context.Context) error {
+	return s.gcsClient.BucketAttrs(ctx, s.config.GCS.Bucket)
+}
+
 func (s *Store) IdentifierFor(topicConfig kafkalib.DatabaseAndSchemaPair, table string) sql.TableIdentifier {
 	return NewTableIdentifier(topicConfig.Database, topicConfig.Schema, table, s.config.GCS.TableNameSeparator)
 }
@@ -77,41 +80,43 @@ func (s *Store) Append(ctx context.Context, tableData *optimization.TableData, w
 	return nil
 }
 
-func buildTemporaryFilePath(tableData *optimization.TableData) string {
-	return fmt.Sprintf("/tmp/%d_%s.parquet", tableData.GetLatestTimestamp().UnixMilli(), stringutil.Random(4))
-}
-
 // Merge - will take tableData, write it into a particular file in the specified format, in these steps:
-// 1. Load a ParquetWriter from a JSON schema (auto-generated)
-// 2. Load the temporary file, under this format: gs://bucket/folderName/fullyQualifiedTableName/YYYY-MM-DD/{{unix_timestamp}}.parquet
-// 3. It will then upload this to GCS
+// 1. Group the rows by their object prefix, see [s3.GroupRowsByPrefix]
+// 2. Write each group into a temporary file using the configured output format (parquet, csv or jsonl)
+// 3. It will then upload this to GCS, under this format: gs://bucket/{{prefix}}/{{unix_timestamp}}.{{extension}}
 // 4. Delete the temporary file
 func (s *Store) Merge(ctx context.Context, tableData *optimization.TableData, whClient *webhooks.Client) (bool, error) {
 	if tableData.ShouldSkipUpdate() {
 		return false, nil
 	}
 
-	fp := buildTemporaryFilePath(tableData)
-	if err := s3.WriteParquetFiles(tableData, fp); err != nil {
+	settings := s.config.GCS
+	tableID := s.IdentifierFor(tableData.TopicConfig().BuildDatabaseAndSchemaPair(), tableData.Name())
+	groups, err := s3.GroupRowsByPrefix(tableData, settings.FolderName, tableID.FullyQualifiedName(), s.ObjectPrefix(tableData))
+	if err != nil {
 		return false, err
 	}
 
-	defer func() {
-		// Delete the file regardless of outcome to avoid fs build up.
-		if removeErr := os.RemoveAll(fp); removeErr != nil {
-			slog.Warn("Failed to delete temp file", slog.Any("err", removeErr), slog.String("filePath", fp))
+	if err = s3.WriteObjects(tableData, groups, settings.OutputFormat, settings.FormatOptions, s.config.SharedDestinationSettings, func(prefix string, filePath string) error {
+		gcsPath, err := s.gcsClient.UploadLocalFileToGCS(ctx, settings.Bucket, prefix, filePath)
+		if err != nil {
+			return fmt.Errorf("failed to upload file to GCS: %w", err)
 		}
-	}()
 
-	gcsPath, err := s.gcsClient.UploadLocalFileToGCS(ctx, s.config.GCS.Bucket, s.ObjectPrefix(tableData), fp)
-	if err != nil {
-		return false, fmt.Errorf("failed to upload file to GCS: %w", err)
+		slog.Info("Successfully wrote and uploaded file to GCS", slog.String("format", string(settings.OutputFormat)), slog.String("filePath", filePath), slog.String("gcsPath", gcsPath))
+		return nil
+	}); err != nil {
+		return false, err
 	}
 
-	slog.Info("Successfully wrote and uploaded Parquet file to GCS", slog.String("filePath", fp), slog.String("gcsPath", gcsPath))
 	return true, nil
 }
 
+// ShouldDeferFlush implements [destination.FlushDeferrer] so that small flushes can be rolled up into larger files.
+func (s *Store) ShouldDeferFlush(tableData *optimization.TableData, lastFlushTime time.Time) bool {
+	return s.config.GCS.Rollup.ShouldDefer(tableData.ApproxSize(), lastFlushTime)
+}
+
 func (s *Store) IsRetryableError(_ error) bool {
 	return false // not supported for GCS
 }
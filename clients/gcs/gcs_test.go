@@ -2,7 +2,6 @@ package gcs
 
 import (
 	"fmt"
-	"strings"
 	"testing"
 	"time"
 
@@ -14,16 +13,6 @@ import (
 	"github.com/artie-labs/transfer/lib/optimization"
 )
 
-func TestBuildTemporaryFilePath(t *testing.T) {
-	ts := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
-	td := &optimization.TableData{}
-	td.SetLatestTimestamp(ts)
-
-	fp := buildTemporaryFilePath(td)
-	assert.True(t, strings.HasPrefix(fp, "/tmp/1577836800000_"), fp)
-	assert.True(t, strings.HasSuffix(fp, ".parquet"), fp)
-}
-
 func TestObjectPrefix(t *testing.T) {
 	td := optimization.NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{Database: "db", TableName: "table", Schema: "public"}, "table")
 	{
@@ -0,0 +1,248 @@
+package httpsink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/retry"
+	sqllib "github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/webhooks"
+)
+
+const (
+	HeaderTimestamp = "X-Artie-Timestamp"
+	HeaderRowCount  = "X-Artie-Row-Count"
+
+	// maxErrorBodySize is how much of the response body is kept when the request fails.
+	maxErrorBodySize = 1024
+)
+
+// StatusError is returned when the endpoint responds with a non-2xx status code.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (s StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", s.StatusCode, s.Body)
+}
+
+type Store struct {
+	config     config.Config
+	httpClient *http.Client
+	retryCfg   retry.RetryConfig
+}
+
+func (s *Store) Label() constants.DestinationKind {
+	return s.config.Output
+}
+
+func (s *Store) GetConfig() config.Config {
+	return s.config
+}
+
+func (s *Store) IsOLTP() bool {
+	return false
+}
+
+func (s *Store) Validate() error {
+	return s.config.HTTPSink.Validate()
+}
+
+func (s *Store) IdentifierFor(topicConfig kafkalib.DatabaseAndSchemaPair, table string) sqllib.TableIdentifier {
+	return NewTableIdentifier(topicConfig.Database, topicConfig.Schema, table)
+}
+
+func (s *Store) Append(ctx context.Context, tableData *optimization.TableData, whClient *webhooks.Client, _ bool) error {
+	// For HTTP, append and merge are identical - we always send new requests
+	if _, err := s.Merge(ctx, tableData, whClient); err != nil {
+		return fmt.Errorf("failed to append: %w", err)
+	}
+	return nil
+}
+
+// buildBatches splits [rows] into request bodies that respect the row and byte limits, each batch is passed to [yield] once it's full.
+func buildBatches(rows iter.Seq2[optimization.Row, error], settings config.HTTPSinkSettings, yield func([][]byte) error) error {
+	var batch [][]byte
+	var batchSize int
+	for row, err := range rows {
+		if err != nil {
+			return fmt.Errorf("failed to read row: %w", err)
+		}
+
+		jsonData, err := json.Marshal(row.GetData())
+		if err != nil {
+			return fmt.Errorf("failed to marshal row data: %w", err)
+		}
+
+		// The separators between rows are counted towards the size as well.
+		rowSize := len(jsonData) + 1
+		if rowSize > settings.GetMaxBytesPerRequest() {
+			return fmt.Errorf("row is %d bytes, which exceeds maxBytesPerRequest (%d)", len(jsonData), settings.GetMaxBytesPerRequest())
+		}
+
+		if len(batch) == settings.GetMaxRowsPerRequest() || batchSize+rowSize > settings.GetMaxBytesPerRequest() {
+			if err = yield(batch); err != nil {
+				return err
+			}
+
+			batch = nil
+			batchSize = 0
+		}
+
+		batch = append(batch, jsonData)
+		batchSize += rowSize
+	}
+
+	if len(batch) > 0 {
+		return yield(batch)
+	}
+
+	return nil
+}
+
+func encodeBatch(batch [][]byte, format config.HTTPSinkFormat) ([]byte, string) {
+	if format == config.HTTPSinkFormatNDJSON {
+		return append(bytes.Join(batch, []byte("\n")), '\n'), "application/x-ndjson"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	buf.Write(bytes.Join(batch, []byte(",")))
+	buf.WriteByte(']')
+	return buf.Bytes(), "application/json"
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of "<timestamp>.<body>", receivers should reject requests with a stale timestamp.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Store) send(ctx context.Context, url string, body []byte, contentType string, rowCount int) error {
+	settings := s.config.HTTPSink
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for key, value := range settings.Headers {
+		req.Header.Set(key, value)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(HeaderRowCount, strconv.Itoa(rowCount))
+	if settings.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+settings.BearerToken)
+	}
+
+	if settings.SigningSecret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(settings.GetSignatureHeader(), "sha256="+Sign(settings.SigningSecret, timestamp, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Merge POSTs all rows from TableData as JSON to the configured URL
+func (s *Store) Merge(ctx context.Context, tableData *optimization.TableData, _ *webhooks.Client) (bool, error) {
+	if tableData.ShouldSkipUpdate() {
+		return false, nil
+	}
+
+	settings := s.config.HTTPSink
+	topicConfig := tableData.TopicConfig()
+	url := settings.URL(topicConfig.Database, topicConfig.Schema, tableData.Name(), topicConfig.Topic)
+
+	var totalSent, requests int
+	err := buildBatches(tableData.AllRows(), *settings, func(batch [][]byte) error {
+		body, contentType := encodeBatch(batch, settings.GetFormat())
+		if err := retry.WithRetries(s.retryCfg, func(_ int, _ error) error {
+			return s.send(ctx, url, body, contentType, len(batch))
+		}); err != nil {
+			return fmt.Errorf("failed to send %d rows: %w", len(batch), err)
+		}
+
+		totalSent += len(batch)
+		requests++
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	slog.Info("Successfully sent rows", slog.String("table", tableData.Name()), slog.Int("rowCount", totalSent), slog.Int("requests", requests))
+	return true, nil
+}
+
+func (s *Store) IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var statusErr StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	// Timeouts and connection errors
+	return db.IsRetryableError(err)
+}
+
+func (s *Store) DropTable(_ context.Context, _ sqllib.TableIdentifier) error {
+	// There's nothing to drop for a HTTP endpoint.
+	return nil
+}
+
+func LoadStore(_ context.Context, cfg config.Config) (*Store, error) {
+	if err := cfg.HTTPSink.Validate(); err != nil {
+		return nil, err
+	}
+
+	store := &Store{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: cfg.HTTPSink.GetTimeout()},
+	}
+
+	retryCfg, err := retry.NewJitterRetryConfig(1_000, 30_000, cfg.HTTPSink.GetMaxAttempts(), store.IsRetryableError)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retry config: %w", err)
+	}
+
+	store.retryCfg = retryCfg
+	return store, nil
+}
@@ -0,0 +1,178 @@
+package httpsink
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/retry"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func newTestTableData(t *testing.T, numRows int) *optimization.TableData {
+	cols := columns.NewColumns(nil)
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+
+	topicConfig := kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders"}
+	tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, topicConfig, "orders")
+	for i := range numRows {
+		assert.NoError(t, tableData.InsertRow(fmt.Sprint(i), map[string]any{"id": i, "name": fmt.Sprintf("order %d", i)}, false))
+	}
+
+	return tableData
+}
+
+func newTestStore(t *testing.T, settings config.HTTPSinkSettings) *Store {
+	store := &Store{
+		config:     config.Config{Output: constants.HTTPSink, HTTPSink: &settings},
+		httpClient: &http.Client{Timeout: settings.GetTimeout()},
+	}
+
+	retryCfg, err := retry.NewJitterRetryConfig(1, 1, 3, store.IsRetryableError)
+	assert.NoError(t, err)
+	store.retryCfg = retryCfg
+	return store
+}
+
+func TestStore_Merge(t *testing.T) {
+	{
+		// JSON, split by the row limit, with auth and a signature
+		var rows []map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/ingest/public/orders", r.URL.Path)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			assert.Equal(t, "key", r.Header.Get("X-Api-Key"))
+
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, "sha256="+Sign("secret", r.Header.Get(HeaderTimestamp), body), r.Header.Get("X-Signature"))
+
+			var batch []map[string]any
+			assert.NoError(t, json.Unmarshal(body, &batch))
+			assert.LessOrEqual(t, len(batch), 2)
+			assert.Equal(t, fmt.Sprint(len(batch)), r.Header.Get(HeaderRowCount))
+			rows = append(rows, batch...)
+		}))
+		defer server.Close()
+
+		store := newTestStore(t, config.HTTPSinkSettings{
+			URLTemplate:       server.URL + "/ingest/{schema}/{table}",
+			BearerToken:       "token",
+			Headers:           map[string]string{"X-Api-Key": "key"},
+			SigningSecret:     "secret",
+			SignatureHeader:   "X-Signature",
+			MaxRowsPerRequest: 2,
+		})
+
+		commit, err := store.Merge(t.Context(), newTestTableData(t, 5), nil)
+		assert.NoError(t, err)
+		assert.True(t, commit)
+		assert.Len(t, rows, 5)
+	}
+	{
+		// NDJSON
+		var rows int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+			assert.Empty(t, r.Header.Get(config.DefaultHTTPSinkSignatureHeader))
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				var row map[string]any
+				assert.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+				rows++
+			}
+		}))
+		defer server.Close()
+
+		store := newTestStore(t, config.HTTPSinkSettings{URLTemplate: server.URL, Format: config.HTTPSinkFormatNDJSON})
+		_, err := store.Merge(t.Context(), newTestTableData(t, 3), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, rows)
+	}
+	{
+		// 503s are retried
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		store := newTestStore(t, config.HTTPSinkSettings{URLTemplate: server.URL})
+		_, err := store.Merge(t.Context(), newTestTableData(t, 1), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), requests.Load())
+	}
+	{
+		// 400s are not retried
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("bad row"))
+		}))
+		defer server.Close()
+
+		store := newTestStore(t, config.HTTPSinkSettings{URLTemplate: server.URL})
+		_, err := store.Merge(t.Context(), newTestTableData(t, 1), nil)
+		assert.ErrorContains(t, err, "failed to send 1 rows: unexpected status code 400: bad row")
+		assert.Equal(t, int32(1), requests.Load())
+	}
+}
+
+func TestBuildBatches(t *testing.T) {
+	tableData := newTestTableData(t, 4)
+	collectBatches := func(settings config.HTTPSinkSettings) ([][][]byte, error) {
+		var batches [][][]byte
+		err := buildBatches(tableData.AllRows(), settings, func(batch [][]byte) error {
+			batches = append(batches, batch)
+			return nil
+		})
+		return batches, err
+	}
+	{
+		// Byte limit
+		batches, err := collectBatches(config.HTTPSinkSettings{MaxBytesPerRequest: 60})
+		assert.NoError(t, err)
+		assert.Len(t, batches, 2)
+		for _, batch := range batches {
+			body, _ := encodeBatch(batch, config.HTTPSinkFormatJSON)
+			assert.LessOrEqual(t, len(body), 60+1)
+		}
+	}
+	{
+		// Row is larger than the byte limit
+		_, err := collectBatches(config.HTTPSinkSettings{MaxBytesPerRequest: 10})
+		assert.ErrorContains(t, err, "exceeds maxBytesPerRequest (10)")
+	}
+	{
+		// NDJSON encoding
+		body, contentType := encodeBatch([][]byte{[]byte(`{"a":1}`), []byte(`{"a":2}`)}, config.HTTPSinkFormatNDJSON)
+		assert.Equal(t, "application/x-ndjson", contentType)
+		assert.True(t, bytes.Equal([]byte("{\"a\":1}\n{\"a\":2}\n"), body))
+	}
+}
+
+func TestStore_IsRetryableError(t *testing.T) {
+	store := &Store{}
+	assert.False(t, store.IsRetryableError(nil))
+	assert.True(t, store.IsRetryableError(fmt.Errorf("failed: %w", StatusError{StatusCode: http.StatusTooManyRequests})))
+	assert.True(t, store.IsRetryableError(StatusError{StatusCode: http.StatusBadGateway}))
+	assert.False(t, store.IsRetryableError(StatusError{StatusCode: http.StatusUnauthorized}))
+}
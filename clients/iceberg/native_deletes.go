@@ -0,0 +1,428 @@
+package iceberg
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	iceberggo "github.com/apache/iceberg-go"
+	iceio "github.com/apache/iceberg-go/io"
+	"github.com/apache/iceberg-go/table"
+	"github.com/google/uuid"
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/ocf"
+)
+
+const (
+	filePathFieldID = 2147483546
+	posFieldID      = 2147483545
+)
+
+// positionDeleteSchema is the schema of a position delete file, we don't use [iceberggo.PositionalDeleteSchema] since it declares pos as an int instead of a long.
+var positionDeleteSchema = iceberggo.NewSchema(0,
+	iceberggo.NestedField{ID: filePathFieldID, Type: iceberggo.PrimitiveTypes.String, Name: "file_path", Required: true},
+	iceberggo.NestedField{ID: posFieldID, Type: iceberggo.PrimitiveTypes.Int64, Name: "pos", Required: true},
+)
+
+// positionDeletes are the rows of [dataFile] that are replaced or deleted by a merge.
+type positionDeletes struct {
+	dataFile  iceberggo.DataFile
+	positions []int64
+}
+
+// readDeletedPositions returns the positions of [task]'s data file that were already removed by earlier merges.
+func readDeletedPositions(ctx context.Context, mem memory.Allocator, fs iceio.IO, task table.FileScanTask) (map[int64]bool, error) {
+	deleted := make(map[int64]bool)
+	for _, deleteFile := range task.DeleteFiles {
+		if deleteFile.ContentType() != iceberggo.EntryContentPosDeletes {
+			return nil, fmt.Errorf("delete file %q is not a position delete file: %w", deleteFile.FilePath(), errors.ErrUnsupported)
+		}
+
+		if err := readPositionDeleteFile(ctx, mem, fs, deleteFile.FilePath(), task.File.FilePath(), deleted); err != nil {
+			return nil, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// readPositionDeleteFile adds the positions in the delete file at [path] that belong to [dataFilePath] to [deleted].
+func readPositionDeleteFile(ctx context.Context, mem memory.Allocator, fs iceio.IO, path, dataFilePath string, deleted map[int64]bool) error {
+	file, err := fs.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open delete file: %w", err)
+	}
+	defer file.Close()
+
+	tbl, err := pqarrow.ReadTable(ctx, file, parquet.NewReaderProperties(mem), pqarrow.ArrowReadProperties{}, mem)
+	if err != nil {
+		return fmt.Errorf("failed to read delete file: %w", err)
+	}
+	defer tbl.Release()
+
+	filePathIdxs, posIdxs := tbl.Schema().FieldIndices("file_path"), tbl.Schema().FieldIndices("pos")
+	if len(filePathIdxs) == 0 || len(posIdxs) == 0 {
+		return fmt.Errorf("delete file %q is missing the file_path or pos column", path)
+	}
+
+	reader := array.NewTableReader(tbl, -1)
+	defer reader.Release()
+	for reader.Next() {
+		record := reader.RecordBatch()
+		filePaths, ok := record.Column(filePathIdxs[0]).(*array.String)
+		if !ok {
+			return fmt.Errorf("expected file_path to be a string, got %s", record.Column(filePathIdxs[0]).DataType())
+		}
+		positions, ok := record.Column(posIdxs[0]).(*array.Int64)
+		if !ok {
+			return fmt.Errorf("expected pos to be a long, got %s", record.Column(posIdxs[0]).DataType())
+		}
+
+		for i := range int(record.NumRows()) {
+			if filePaths.Value(i) == dataFilePath {
+				deleted[positions.Value(i)] = true
+			}
+		}
+	}
+
+	return reader.Err()
+}
+
+// writePositionDeletes writes a position delete file for each partition of the data files in [deletes].
+func writePositionDeletes(tbl *table.Table, fs iceio.IO, deletes []positionDeletes) ([]iceberggo.DataFile, error) {
+	groups := make(map[string][]positionDeletes)
+	for _, _deletes := range deletes {
+		// Delete files are scoped to a single partition, so they need to be grouped the same way as the data files that they apply to.
+		key := fmt.Sprint(_deletes.dataFile.SpecID(), _deletes.dataFile.Partition())
+		groups[key] = append(groups[key], _deletes)
+	}
+
+	var deleteFiles []iceberggo.DataFile
+	for _, key := range slices.Sorted(maps.Keys(groups)) {
+		deleteFile, err := writePositionDeleteFile(tbl, fs, groups[key])
+		if err != nil {
+			return nil, err
+		}
+		deleteFiles = append(deleteFiles, deleteFile)
+	}
+
+	return deleteFiles, nil
+}
+
+func writePositionDeleteFile(tbl *table.Table, fs iceio.IO, deletes []positionDeletes) (iceberggo.DataFile, error) {
+	arrowSchema, err := table.SchemaToArrowSchema(positionDeleteSchema, nil, true, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build arrow schema: %w", err)
+	}
+
+	// The spec requires the rows to be sorted by file_path and then pos.
+	slices.SortFunc(deletes, func(a, b positionDeletes) int {
+		return cmp.Compare(a.dataFile.FilePath(), b.dataFile.FilePath())
+	})
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema)
+	defer builder.Release()
+
+	filePaths := builder.Field(0).(*array.StringBuilder)
+	positions := builder.Field(1).(*array.Int64Builder)
+	for _, _deletes := range deletes {
+		slices.Sort(_deletes.positions)
+		for _, pos := range _deletes.positions {
+			filePaths.Append(_deletes.dataFile.FilePath())
+			positions.Append(pos)
+		}
+	}
+
+	record := builder.NewRecordBatch()
+	defer record.Release()
+
+	var buf bytes.Buffer
+	writer, err := pqarrow.NewFileWriter(arrowSchema, &buf, parquet.NewWriterProperties(parquet.WithStats(true)), pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+	if err = writer.Write(record); err != nil {
+		return nil, fmt.Errorf("failed to write delete file: %w", err)
+	}
+	if err = writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write delete file: %w", err)
+	}
+
+	locations, err := tbl.LocationProvider()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load location provider: %w", err)
+	}
+
+	path := locations.NewDataLocation(fmt.Sprintf("%s-deletes.parquet", uuid.NewString()))
+	if err = writeFile(fs, path, buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	spec, err := partitionSpec(tbl.Metadata(), int(deletes[0].dataFile.SpecID()))
+	if err != nil {
+		return nil, err
+	}
+
+	numRows := record.NumRows()
+	fileBuilder, err := iceberggo.NewDataFileBuilder(spec, iceberggo.EntryContentPosDeletes, path, iceberggo.ParquetFile,
+		deletes[0].dataFile.Partition(), nil, nil, numRows, int64(buf.Len()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build delete file: %w", err)
+	}
+
+	// The file_path bounds are used by readers to figure out which data files a delete file applies to.
+	return fileBuilder.
+		ValueCounts(map[int]int64{filePathFieldID: numRows, posFieldID: numRows}).
+		NullValueCounts(map[int]int64{filePathFieldID: 0, posFieldID: 0}).
+		LowerBoundValues(map[int][]byte{filePathFieldID: []byte(deletes[0].dataFile.FilePath())}).
+		UpperBoundValues(map[int][]byte{filePathFieldID: []byte(deletes[len(deletes)-1].dataFile.FilePath())}).
+		Build(), nil
+}
+
+// writeDeleteManifests writes a delete manifest for each partition spec of [deleteFiles].
+func writeDeleteManifests(tbl *table.Table, fs iceio.IO, snapshotID int64, deleteFiles []iceberggo.DataFile) ([]iceberggo.ManifestFile, error) {
+	specs := make(map[int32][]iceberggo.DataFile)
+	for _, deleteFile := range deleteFiles {
+		specs[deleteFile.SpecID()] = append(specs[deleteFile.SpecID()], deleteFile)
+	}
+
+	locations, err := tbl.LocationProvider()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load location provider: %w", err)
+	}
+
+	var manifests []iceberggo.ManifestFile
+	for _, specID := range slices.Sorted(maps.Keys(specs)) {
+		spec, err := partitionSpec(tbl.Metadata(), int(specID))
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		writer, err := iceberggo.NewManifestWriter(tbl.Metadata().Version(), &buf, spec, tbl.Schema(), snapshotID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create manifest writer: %w", err)
+		}
+
+		for _, deleteFile := range specs[specID] {
+			if err = writer.Add(iceberggo.NewManifestEntry(iceberggo.EntryStatusADDED, &snapshotID, nil, nil, deleteFile)); err != nil {
+				return nil, fmt.Errorf("failed to add delete file to manifest: %w", err)
+			}
+		}
+
+		if err = writer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write manifest: %w", err)
+		}
+
+		data, err := setManifestContent(buf.Bytes(), iceberggo.ManifestContentDeletes)
+		if err != nil {
+			return nil, err
+		}
+
+		path := locations.NewMetadataLocation(fmt.Sprintf("%s-m0.avro", uuid.NewString()))
+		if err = writeFile(fs, path, data); err != nil {
+			return nil, err
+		}
+
+		// [iceberggo.ManifestWriter] only builds data manifests, so we copy its stats onto a delete manifest.
+		written, err := writer.ToManifestFile(path, int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build manifest: %w", err)
+		}
+
+		manifests = append(manifests, iceberggo.NewManifestFile(written.Version(), path, written.Length(), specID, snapshotID).
+			Content(iceberggo.ManifestContentDeletes).
+			AddedFiles(written.AddedDataFiles()).
+			AddedRows(written.AddedRows()).
+			Partitions(written.Partitions()).
+			Build())
+	}
+
+	return manifests, nil
+}
+
+// setManifestContent replaces the content type in the header of an Avro manifest file, [iceberggo.ManifestWriter] always marks its manifests as data manifests.
+func setManifestContent(data []byte, content iceberggo.ManifestContent) ([]byte, error) {
+	var header ocf.Header
+	if err := avro.NewDecoderForSchema(ocf.HeaderSchema, bytes.NewReader(data)).Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to read manifest header: %w", err)
+	}
+
+	// The blocks that follow the header don't depend on its length, so only the header has to be rewritten.
+	original, err := avro.Marshal(ocf.HeaderSchema, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest header: %w", err)
+	}
+
+	header.Meta["content"] = []byte(content.String())
+	updated, err := avro.Marshal(ocf.HeaderSchema, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest header: %w", err)
+	}
+
+	return append(updated, data[len(original):]...), nil
+}
+
+// commitMerge appends [record] and adds position delete files for [deletes] in a single snapshot.
+//
+// iceberg-go transactions can't add delete files yet, so the data files are staged with a transaction and its snapshot is
+// rewritten to also reference a manifest for the delete files before it is committed through the catalog.
+func commitMerge(ctx context.Context, cat nativeCatalog, tbl *table.Table, record arrow.RecordBatch, deletes []positionDeletes) error {
+	if len(deletes) == 0 {
+		return commitRecord(ctx, tbl, record)
+	}
+
+	fs, err := tbl.FS(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load file IO: %w", err)
+	}
+
+	var records []arrow.RecordBatch
+	if record.NumRows() > 0 {
+		// iceberg-go would write out an empty data file otherwise.
+		records = append(records, record)
+	}
+
+	reader, err := array.NewRecordReader(record.Schema(), records)
+	if err != nil {
+		return fmt.Errorf("failed to create record reader: %w", err)
+	}
+	defer reader.Release()
+
+	tx := tbl.NewTransaction()
+	if err = tx.Append(ctx, reader, nil); err != nil {
+		return fmt.Errorf("failed to write data files: %w", err)
+	}
+
+	staged, err := tx.StagedTable()
+	if err != nil {
+		return fmt.Errorf("failed to stage snapshot: %w", err)
+	}
+
+	snapshot := *staged.CurrentSnapshot()
+	manifests, err := snapshot.Manifests(fs)
+	if err != nil {
+		return fmt.Errorf("failed to read manifests: %w", err)
+	}
+
+	deleteFiles, err := writePositionDeletes(tbl, fs, deletes)
+	if err != nil {
+		return err
+	}
+
+	deleteManifests, err := writeDeleteManifests(tbl, fs, snapshot.SnapshotID, deleteFiles)
+	if err != nil {
+		return err
+	}
+
+	locations, err := tbl.LocationProvider()
+	if err != nil {
+		return fmt.Errorf("failed to load location provider: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err = iceberggo.WriteManifestList(tbl.Metadata().Version(), &buf, snapshot.SnapshotID, snapshot.ParentSnapshotID,
+		&snapshot.SequenceNumber, 0, append(manifests, deleteManifests...)); err != nil {
+		return fmt.Errorf("failed to write manifest list: %w", err)
+	}
+
+	stagedManifestList := snapshot.ManifestList
+	snapshot.ManifestList = locations.NewMetadataLocation(fmt.Sprintf("snap-%d-1-%s.avro", snapshot.SnapshotID, uuid.NewString()))
+	if err = writeFile(fs, snapshot.ManifestList, buf.Bytes()); err != nil {
+		return err
+	}
+
+	// The manifest list from the transaction is never committed.
+	if err = fs.Remove(stagedManifestList); err != nil {
+		slog.Warn("Failed to delete staged manifest list", slog.Any("err", err), slog.String("filePath", stagedManifestList))
+	}
+
+	snapshot.Summary = deleteSummary(snapshot.Summary, deleteFiles)
+
+	var parentSnapshotID *int64
+	if current := tbl.CurrentSnapshot(); current != nil {
+		parentSnapshotID = &current.SnapshotID
+	}
+
+	if _, _, err = cat.CommitTable(ctx, tbl.Identifier(),
+		[]table.Requirement{table.AssertTableUUID(tbl.Metadata().TableUUID()), table.AssertRefSnapshotID("main", parentSnapshotID)},
+		[]table.Update{table.NewAddSnapshotUpdate(&snapshot), table.NewSetSnapshotRefUpdate("main", snapshot.SnapshotID, table.BranchRef, -1, -1, -1)},
+	); err != nil {
+		return fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// deleteSummary adds the delete files to the summary of the staged append snapshot.
+func deleteSummary(summary *table.Summary, deleteFiles []iceberggo.DataFile) *table.Summary {
+	var numRows, numBytes int64
+	for _, deleteFile := range deleteFiles {
+		numRows += deleteFile.Count()
+		numBytes += deleteFile.FileSizeBytes()
+	}
+
+	properties := iceberggo.Properties{}
+	if summary != nil {
+		maps.Copy(properties, summary.Properties)
+	}
+
+	for key, value := range map[string]int64{
+		"added-delete-files":          int64(len(deleteFiles)),
+		"added-position-delete-files": int64(len(deleteFiles)),
+		"added-position-deletes":      numRows,
+		"added-files-size":            numBytes,
+		"total-delete-files":          int64(len(deleteFiles)),
+		"total-position-deletes":      numRows,
+		"total-files-size":            numBytes,
+	} {
+		current, _ := strconv.ParseInt(properties[key], 10, 64)
+		properties[key] = strconv.FormatInt(current+value, 10)
+	}
+
+	return &table.Summary{Operation: table.OpOverwrite, Properties: properties}
+}
+
+func partitionSpec(metadata table.Metadata, specID int) (iceberggo.PartitionSpec, error) {
+	for _, spec := range metadata.PartitionSpecs() {
+		if spec.ID() == specID {
+			return spec, nil
+		}
+	}
+
+	return iceberggo.PartitionSpec{}, fmt.Errorf("partition spec %d does not exist", specID)
+}
+
+func writeFile(fs iceio.IO, path string, data []byte) error {
+	writeFS, ok := fs.(iceio.WriteFileIO)
+	if !ok {
+		return fmt.Errorf("file IO %T does not support writes", fs)
+	}
+
+	file, err := writeFS.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", path, err)
+	}
+
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+
+	return nil
+}
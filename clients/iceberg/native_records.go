@@ -0,0 +1,577 @@
+package iceberg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	iceberggo "github.com/apache/iceberg-go"
+	iceio "github.com/apache/iceberg-go/io"
+	"github.com/apache/iceberg-go/table"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/converters"
+	"github.com/artie-labs/transfer/lib/typing/converters/primitives"
+	"github.com/artie-labs/transfer/lib/typing/values"
+)
+
+const (
+	parquetFieldIDKey = "PARQUET:field_id"
+	// readBatchSize is the number of rows that are read at a time when looking for existing rows.
+	readBatchSize = 64 * 1024
+)
+
+// appendValue converts [value] into the type of [builder], [kd] is the in-memory kind of the column.
+func appendValue(builder array.Builder, value any, kd typing.KindDetails, cfg config.SharedDestinationSettings) error {
+	if value == nil {
+		builder.AppendNull()
+		return nil
+	}
+
+	switch castedBuilder := builder.(type) {
+	case *array.StringBuilder:
+		castedValue, err := values.ToStringOpts(value, kd, converters.GetStringConverterOpts{UseNewStringMethod: cfg.UseNewStringMethod})
+		if err != nil {
+			return err
+		}
+		castedBuilder.Append(castedValue)
+	case *array.BinaryBuilder:
+		castedValue, err := values.ToStringOpts(value, kd, converters.GetStringConverterOpts{UseNewStringMethod: cfg.UseNewStringMethod})
+		if err != nil {
+			return err
+		}
+		castedBuilder.AppendString(castedValue)
+	case *array.BooleanBuilder:
+		castedValue, err := primitives.BooleanConverter{}.Convert(value)
+		if err != nil {
+			return err
+		}
+		castedBuilder.Append(castedValue)
+	case *array.Int32Builder:
+		castedValue, err := primitives.Int64Converter{}.Convert(value)
+		if err != nil {
+			return err
+		}
+		if castedValue > math.MaxInt32 || castedValue < math.MinInt32 {
+			return fmt.Errorf("value %d overflows int32", castedValue)
+		}
+		castedBuilder.Append(int32(castedValue))
+	case *array.Int64Builder:
+		castedValue, err := primitives.Int64Converter{}.Convert(value)
+		if err != nil {
+			return err
+		}
+		castedBuilder.Append(castedValue)
+	case *array.Float32Builder:
+		castedValue, err := primitives.Float32Converter{}.Convert(value)
+		if err != nil {
+			return err
+		}
+		castedBuilder.Append(castedValue)
+	case *array.Float64Builder:
+		castedValue, err := primitives.Float64Converter{}.Convert(value)
+		if err != nil {
+			return err
+		}
+		castedBuilder.Append(castedValue)
+	case *array.Decimal128Builder:
+		dataType, ok := castedBuilder.Type().(*arrow.Decimal128Type)
+		if !ok {
+			return fmt.Errorf("expected *arrow.Decimal128Type, got %T", castedBuilder.Type())
+		}
+
+		castedValue, err := values.ToString(value, kd)
+		if err != nil {
+			return err
+		}
+
+		num, err := decimal128.FromString(castedValue, dataType.Precision, dataType.Scale)
+		if err != nil {
+			return fmt.Errorf("failed to parse decimal %q: %w", castedValue, err)
+		}
+		castedBuilder.Append(num)
+	case *array.Date32Builder:
+		_time, err := typing.ParseDateFromAny(value)
+		if err != nil {
+			return err
+		}
+		castedBuilder.Append(arrow.Date32FromTime(_time))
+	case *array.Time64Builder:
+		_time, err := typing.ParseTimeFromAny(value)
+		if err != nil {
+			return err
+		}
+
+		year, month, day := _time.Date()
+		sinceMidnight := _time.Sub(time.Date(year, month, day, 0, 0, 0, 0, _time.Location()))
+		castedBuilder.Append(arrow.Time64(sinceMidnight.Microseconds()))
+	case *array.TimestampBuilder:
+		dataType, ok := castedBuilder.Type().(*arrow.TimestampType)
+		if !ok {
+			return fmt.Errorf("expected *arrow.TimestampType, got %T", castedBuilder.Type())
+		}
+
+		var _time time.Time
+		var err error
+		if dataType.TimeZone == "" {
+			_time, err = typing.ParseTimestampNTZFromAny(value)
+		} else {
+			_time, err = typing.ParseTimestampTZFromAny(value)
+		}
+		if err != nil {
+			return err
+		}
+
+		ts, err := arrow.TimestampFromTime(_time, dataType.Unit)
+		if err != nil {
+			return err
+		}
+		castedBuilder.Append(ts)
+	default:
+		return fmt.Errorf("unsupported builder type: %T", builder)
+	}
+
+	return nil
+}
+
+// copyValue appends the value at [i] of [src] to [builder], a nil [src] is treated as a column of nulls.
+func copyValue(builder array.Builder, src arrow.Array, i int) error {
+	if src == nil || src.IsNull(i) {
+		builder.AppendNull()
+		return nil
+	}
+
+	switch castedBuilder := builder.(type) {
+	case *array.StringBuilder:
+		castedSrc, ok := src.(interface{ Value(int) string })
+		if !ok {
+			return fmt.Errorf("cannot copy %s into %s", src.DataType(), builder.Type())
+		}
+		castedBuilder.Append(castedSrc.Value(i))
+	case *array.BinaryBuilder:
+		castedSrc, ok := src.(interface{ Value(int) []byte })
+		if !ok {
+			return fmt.Errorf("cannot copy %s into %s", src.DataType(), builder.Type())
+		}
+		castedBuilder.Append(castedSrc.Value(i))
+	case *array.BooleanBuilder:
+		return copyTypedValue(castedBuilder, src, i)
+	case *array.Int32Builder:
+		return copyTypedValue(castedBuilder, src, i)
+	case *array.Int64Builder:
+		return copyTypedValue(castedBuilder, src, i)
+	case *array.Float32Builder:
+		return copyTypedValue(castedBuilder, src, i)
+	case *array.Float64Builder:
+		return copyTypedValue(castedBuilder, src, i)
+	case *array.Date32Builder:
+		return copyTypedValue(castedBuilder, src, i)
+	case *array.Time64Builder:
+		return copyTypedValue(castedBuilder, src, i)
+	case *array.TimestampBuilder:
+		return copyTypedValue(castedBuilder, src, i)
+	case *array.Decimal128Builder:
+		if !arrow.TypeEqual(src.DataType(), builder.Type()) {
+			return fmt.Errorf("cannot copy %s into %s", src.DataType(), builder.Type())
+		}
+		return copyTypedValue(castedBuilder, src, i)
+	default:
+		return fmt.Errorf("unsupported builder type: %T", builder)
+	}
+
+	return nil
+}
+
+func copyTypedValue[T any](builder interface{ Append(T) }, src arrow.Array, i int) error {
+	castedSrc, ok := src.(interface{ Value(int) T })
+	if !ok {
+		return fmt.Errorf("cannot copy %s into %T", src.DataType(), builder)
+	}
+
+	builder.Append(castedSrc.Value(i))
+	return nil
+}
+
+// rowKey builds a key out of the primary key columns so that rows from the batch can be matched against rows that were read back from the table.
+func rowKey(cols []arrow.Array, primaryKeyIdxs []int, i int) string {
+	parts := make([]string, len(primaryKeyIdxs))
+	for j, idx := range primaryKeyIdxs {
+		if cols[idx] == nil || cols[idx].IsNull(i) {
+			parts[j] = "<null>"
+		} else {
+			parts[j] = strconv.Quote(cols[idx].ValueStr(i))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+type batchRow struct {
+	// inherit contains the column indexes that should keep the value that is already in the table (e.g. TOAST columns).
+	inherit map[int]bool
+	// deleted is set for hard deletes, these rows are only used to remove the existing row.
+	deleted bool
+}
+
+// nativeBatch is the buffered [optimization.TableData] rows converted into the table's Arrow schema.
+type nativeBatch struct {
+	record         arrow.RecordBatch
+	rows           []batchRow
+	primaryKeyIdxs []int
+	// keys maps the primary key to the row index in [record].
+	keys map[string]int
+}
+
+func (n nativeBatch) columns() []arrow.Array {
+	return n.record.Columns()
+}
+
+func (n nativeBatch) release() {
+	n.record.Release()
+}
+
+type buildBatchArgs struct {
+	schema      *arrow.Schema
+	rows        []map[string]any
+	cols        []columns.Column
+	primaryKeys []string
+	softDelete  bool
+	// merge is set when we are upserting, otherwise every row is appended as is.
+	merge bool
+	cfg   config.SharedDestinationSettings
+}
+
+func buildBatch(mem memory.Allocator, args buildBatchArgs) (nativeBatch, error) {
+	inMemoryCols := make(map[string]columns.Column, len(args.cols))
+	for _, col := range args.cols {
+		inMemoryCols[col.Name()] = col
+	}
+
+	var primaryKeyIdxs []int
+	for _, primaryKey := range args.primaryKeys {
+		idxs := args.schema.FieldIndices(primaryKey)
+		if len(idxs) == 0 {
+			return nativeBatch{}, fmt.Errorf("primary key %q does not exist in the table", primaryKey)
+		}
+		primaryKeyIdxs = append(primaryKeyIdxs, idxs[0])
+	}
+
+	builder := array.NewRecordBuilder(mem, args.schema)
+	defer builder.Release()
+
+	rows := make([]batchRow, len(args.rows))
+	for i, row := range args.rows {
+		rows[i].inherit = make(map[int]bool)
+		isDeleted, _ := row[constants.DeleteColumnMarker].(bool)
+		onlySetDelete, _ := row[constants.OnlySetDeleteColumnMarker].(bool)
+		rows[i].deleted = args.merge && isDeleted && !args.softDelete
+
+		for j, field := range args.schema.Fields() {
+			fieldBuilder := builder.Field(j)
+			col, ok := inMemoryCols[field.Name]
+			if !ok {
+				// Columns that we don't have in memory are left untouched in the table.
+				rows[i].inherit[j] = args.merge
+				fieldBuilder.AppendNull()
+				continue
+			}
+
+			if args.merge && onlySetDelete && field.Name != constants.DeleteColumnMarker {
+				// We only have the primary keys for this delete, so we'll only flip the [constants.DeleteColumnMarker] on an existing row.
+				rows[i].inherit[j] = true
+			}
+
+			value := row[col.Name()]
+			if value == constants.ToastUnavailableValuePlaceholder {
+				rows[i].inherit[j] = args.merge
+				if _, ok := fieldBuilder.(*array.StringBuilder); !ok {
+					fieldBuilder.AppendNull()
+					continue
+				}
+			}
+
+			if err := appendValue(fieldBuilder, value, col.KindDetails, args.cfg); err != nil {
+				return nativeBatch{}, fmt.Errorf("failed to convert value for column %q: %w", col.Name(), err)
+			}
+		}
+	}
+
+	batch := nativeBatch{
+		record:         builder.NewRecordBatch(),
+		rows:           rows,
+		primaryKeyIdxs: primaryKeyIdxs,
+		keys:           make(map[string]int, len(rows)),
+	}
+
+	if len(primaryKeyIdxs) > 0 {
+		cols := batch.columns()
+		for i := range rows {
+			batch.keys[rowKey(cols, primaryKeyIdxs, i)] = i
+		}
+	}
+
+	return batch, nil
+}
+
+// rowFilter is used to prune the data files that cannot contain any of the rows in this batch.
+func (n nativeBatch) rowFilter() iceberggo.BooleanExpression {
+	var exprs []iceberggo.BooleanExpression
+	for _, idx := range n.primaryKeyIdxs {
+		ref := iceberggo.Reference(n.record.Schema().Field(idx).Name)
+		switch castedCol := n.record.Column(idx).(type) {
+		case *array.Int32:
+			if vals := nonNullValues(castedCol, castedCol.Value); len(vals) > 0 {
+				exprs = append(exprs, iceberggo.IsIn(ref, vals...))
+			}
+		case *array.Int64:
+			if vals := nonNullValues(castedCol, castedCol.Value); len(vals) > 0 {
+				exprs = append(exprs, iceberggo.IsIn(ref, vals...))
+			}
+		case *array.String:
+			if vals := nonNullValues(castedCol, castedCol.Value); len(vals) > 0 {
+				exprs = append(exprs, iceberggo.IsIn(ref, vals...))
+			}
+		}
+	}
+
+	switch len(exprs) {
+	case 0:
+		return iceberggo.AlwaysTrue{}
+	case 1:
+		return exprs[0]
+	default:
+		return iceberggo.NewAnd(exprs[0], exprs[1], exprs[2:]...)
+	}
+}
+
+func nonNullValues[T any](arr arrow.Array, value func(int) T) []T {
+	var out []T
+	for i := range arr.Len() {
+		if !arr.IsNull(i) {
+			out = append(out, value(i))
+		}
+	}
+	return out
+}
+
+// readColumns returns the indexes of the columns that have to be read back from the table, these are the primary keys and the columns that some row inherits.
+func (n nativeBatch) readColumns() []int {
+	idxs := slices.Clone(n.primaryKeyIdxs)
+	for j := range n.record.Schema().Fields() {
+		if slices.Contains(idxs, j) {
+			continue
+		}
+
+		for _, row := range n.rows {
+			if row.inherit[j] && !row.deleted {
+				idxs = append(idxs, j)
+				break
+			}
+		}
+	}
+
+	return idxs
+}
+
+// existingRows are the rows in the table that the batch replaces, the columns are in the same order as the table schema.
+// Only the primary keys and the inherited columns are read, every other column is null.
+type existingRows struct {
+	record arrow.RecordBatch
+	// keys maps the primary key to the row index in [record].
+	keys map[string]int
+}
+
+func (e existingRows) release() {
+	if e.record != nil {
+		e.record.Release()
+	}
+}
+
+// findExistingRows scans the data files that may contain one of the batch's primary keys and returns the rows that the batch replaces,
+// along with their positions so that they can be removed with position delete files.
+func findExistingRows(ctx context.Context, mem memory.Allocator, tbl *table.Table, arrowSchema *arrow.Schema, batch nativeBatch) (existingRows, []positionDeletes, error) {
+	builder := array.NewRecordBuilder(mem, arrowSchema)
+	defer builder.Release()
+
+	rows := existingRows{keys: make(map[string]int)}
+	var deletes []positionDeletes
+	if tbl.CurrentSnapshot() != nil {
+		tasks, err := tbl.Scan(table.WithRowFilter(batch.rowFilter())).PlanFiles(ctx)
+		if err != nil {
+			return existingRows{}, nil, fmt.Errorf("failed to plan files: %w", err)
+		}
+
+		fs, err := tbl.FS(ctx)
+		if err != nil {
+			return existingRows{}, nil, fmt.Errorf("failed to load file IO: %w", err)
+		}
+
+		readColumns := batch.readColumns()
+		seen := make(map[string]bool)
+		for _, task := range tasks {
+			path := task.File.FilePath()
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+
+			deleted, err := readDeletedPositions(ctx, mem, fs, task)
+			if err != nil {
+				return existingRows{}, nil, err
+			}
+
+			positions, err := matchDataFile(ctx, mem, fs, path, tbl.Schema(), readColumns, batch, deleted, builder, rows.keys)
+			if err != nil {
+				return existingRows{}, nil, err
+			}
+
+			if len(positions) > 0 {
+				deletes = append(deletes, positionDeletes{dataFile: task.File, positions: positions})
+			}
+		}
+	}
+
+	rows.record = builder.NewRecordBatch()
+	return rows, deletes, nil
+}
+
+// matchDataFile streams [readColumns] of the data file at [path] and returns the positions of the rows that are in [batch].
+// The matched rows are copied into [matched] and [keys] maps their primary key to the row index in [matched].
+func matchDataFile(
+	ctx context.Context,
+	mem memory.Allocator,
+	fs iceio.IO,
+	path string,
+	schema *iceberggo.Schema,
+	readColumns []int,
+	batch nativeBatch,
+	deleted map[int64]bool,
+	matched *array.RecordBuilder,
+	keys map[string]int,
+) ([]int64, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data file: %w", err)
+	}
+	defer file.Close()
+
+	parquetReader, err := pqfile.NewParquetReader(file, pqfile.WithReadProps(parquet.NewReaderProperties(mem)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+	defer parquetReader.Close()
+
+	fileReader, err := pqarrow.NewFileReader(parquetReader, pqarrow.ArrowReadProperties{BatchSize: readBatchSize}, mem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	fileSchema, err := fileReader.Schema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file schema: %w", err)
+	}
+
+	var fieldIdxs []int
+	for fileIdx, idx := range schemaIndexes(fileSchema, schema) {
+		if slices.Contains(readColumns, idx) {
+			fieldIdxs = append(fieldIdxs, fileIdx)
+		}
+	}
+
+	if len(fieldIdxs) == 0 {
+		// None of the primary keys are in this file, so none of its rows can match.
+		return nil, nil
+	}
+
+	columnIdxs, err := fileReader.Manifest.GetFieldIndices(fieldIdxs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up columns: %w", err)
+	}
+
+	records, err := fileReader.GetRecordReader(ctx, columnIdxs, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+	defer records.Release()
+
+	recordIdxs := schemaIndexes(records.Schema(), schema)
+	cols := make([]arrow.Array, len(schema.Fields()))
+	var positions []int64
+	var offset int64
+	for records.Next() {
+		record := records.RecordBatch()
+		for recordIdx, idx := range recordIdxs {
+			cols[idx] = record.Column(recordIdx)
+		}
+
+		for i := range int(record.NumRows()) {
+			pos := offset + int64(i)
+			if deleted[pos] {
+				continue
+			}
+
+			key := rowKey(cols, batch.primaryKeyIdxs, i)
+			if _, ok := batch.keys[key]; !ok {
+				continue
+			}
+
+			positions = append(positions, pos)
+			keys[key] = matched.Field(0).Len()
+			for j, col := range cols {
+				if err = copyValue(matched.Field(j), col, i); err != nil {
+					return nil, fmt.Errorf("failed to copy column %q from %q: %w", schema.Field(j).Name, path, err)
+				}
+			}
+		}
+
+		offset += record.NumRows()
+	}
+
+	if err = records.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	return positions, nil
+}
+
+// schemaIndexes maps the fields of [arrowSchema] to their index in [schema], fields are matched by field ID and fall back to the column name for files that were written without field IDs.
+func schemaIndexes(arrowSchema *arrow.Schema, schema *iceberggo.Schema) map[int]int {
+	byFieldID := make(map[int]int)
+	byName := make(map[string]int)
+	for i, field := range arrowSchema.Fields() {
+		byName[field.Name] = i
+		if idx := field.Metadata.FindKey(parquetFieldIDKey); idx >= 0 {
+			if fieldID, err := strconv.Atoi(field.Metadata.Values()[idx]); err == nil {
+				byFieldID[fieldID] = i
+			}
+		}
+	}
+
+	idxs := make(map[int]int)
+	for j, field := range schema.Fields() {
+		i, ok := byFieldID[field.ID]
+		if !ok && len(byFieldID) == 0 {
+			i, ok = byName[field.Name]
+		}
+
+		if ok {
+			idxs[i] = j
+		}
+	}
+
+	return idxs
+}
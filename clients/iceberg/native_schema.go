@@ -0,0 +1,137 @@
+package iceberg
+
+import (
+	"fmt"
+	"slices"
+
+	iceberggo "github.com/apache/iceberg-go"
+
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+// icebergTypeForKind mirrors [dialect.IcebergDialect.DataTypeForKind] so that a table looks the same regardless of which writer created it.
+func icebergTypeForKind(kd typing.KindDetails) (iceberggo.Type, error) {
+	switch kd.Kind {
+	case typing.Boolean.Kind:
+		return iceberggo.PrimitiveTypes.Bool, nil
+	case
+		typing.Array.Kind,
+		typing.Struct.Kind,
+		typing.String.Kind,
+		typing.Interval.Kind,
+		typing.TimeKindDetails.Kind:
+		return iceberggo.PrimitiveTypes.String, nil
+	case typing.Float.Kind:
+		return iceberggo.PrimitiveTypes.Float64, nil
+	case typing.EDecimal.Kind:
+		if kd.ExtendedDecimalDetails == nil {
+			return nil, fmt.Errorf("extended decimal details are not set")
+		}
+
+		precision := kd.ExtendedDecimalDetails.Precision()
+		if precision == decimal.PrecisionNotSpecified || precision > decimal.MaxPrecisionBeforeString {
+			return iceberggo.PrimitiveTypes.String, nil
+		}
+
+		return iceberggo.DecimalTypeOf(int(precision), int(kd.ExtendedDecimalDetails.Scale())), nil
+	case typing.Integer.Kind:
+		if kd.OptionalIntegerKind != nil {
+			switch *kd.OptionalIntegerKind {
+			case typing.SmallIntegerKind, typing.IntegerKind:
+				return iceberggo.PrimitiveTypes.Int32, nil
+			}
+		}
+		return iceberggo.PrimitiveTypes.Int64, nil
+	case typing.Date.Kind:
+		return iceberggo.PrimitiveTypes.Date, nil
+	case typing.TimestampNTZ.Kind:
+		return iceberggo.PrimitiveTypes.Timestamp, nil
+	case typing.TimestampTZ.Kind:
+		return iceberggo.PrimitiveTypes.TimestampTz, nil
+	default:
+		return nil, fmt.Errorf("unsupported kind: %q", kd.Kind)
+	}
+}
+
+func kindForIcebergType(icebergType iceberggo.Type) (typing.KindDetails, error) {
+	switch castedType := icebergType.(type) {
+	case iceberggo.BooleanType:
+		return typing.Boolean, nil
+	case iceberggo.Int32Type:
+		return typing.BuildIntegerKind(typing.IntegerKind), nil
+	case iceberggo.Int64Type:
+		return typing.BuildIntegerKind(typing.BigIntegerKind), nil
+	case iceberggo.Float32Type, iceberggo.Float64Type:
+		return typing.Float, nil
+	case iceberggo.DecimalType:
+		return typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(int32(castedType.Precision()), int32(castedType.Scale()))), nil
+	case iceberggo.StringType, iceberggo.BinaryType, iceberggo.UUIDType, iceberggo.FixedType:
+		return typing.String, nil
+	case iceberggo.DateType:
+		return typing.Date, nil
+	case iceberggo.TimeType:
+		return typing.TimeKindDetails, nil
+	case iceberggo.TimestampType:
+		return typing.TimestampNTZ, nil
+	case iceberggo.TimestampTzType:
+		return typing.TimestampTZ, nil
+	default:
+		return typing.Invalid, fmt.Errorf("unsupported data type: %q", icebergType)
+	}
+}
+
+func columnsFromSchema(schema *iceberggo.Schema) ([]columns.Column, error) {
+	fields := schema.Fields()
+	cols := make([]columns.Column, len(fields))
+	for i, field := range fields {
+		kind, err := kindForIcebergType(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kind for column %q: %w", field.Name, err)
+		}
+
+		cols[i] = columns.NewColumn(field.Name, kind)
+	}
+
+	return cols, nil
+}
+
+// buildSchemaWithColumns returns a copy of [schema] with [cols] appended, field IDs are assigned starting after [lastColumnID].
+func buildSchemaWithColumns(schema *iceberggo.Schema, lastColumnID int, cols []columns.Column) (*iceberggo.Schema, error) {
+	fields := schema.Fields()
+	for _, col := range cols {
+		icebergType, err := icebergTypeForKind(col.KindDetails)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get data type for column %q: %w", col.Name(), err)
+		}
+
+		lastColumnID++
+		fields = append(fields, iceberggo.NestedField{ID: lastColumnID, Name: col.Name(), Type: icebergType})
+	}
+
+	return iceberggo.NewSchemaWithIdentifiers(schema.ID+1, schema.IdentifierFieldIDs, fields...), nil
+}
+
+// buildSchemaWithoutColumns returns a copy of [schema] without [cols].
+func buildSchemaWithoutColumns(schema *iceberggo.Schema, cols []columns.Column) *iceberggo.Schema {
+	toDrop := make(map[string]bool, len(cols))
+	for _, col := range cols {
+		toDrop[col.Name()] = true
+	}
+
+	var fields []iceberggo.NestedField
+	var identifierFieldIDs []int
+	for _, field := range schema.Fields() {
+		if toDrop[field.Name] {
+			continue
+		}
+
+		fields = append(fields, field)
+		if slices.Contains(schema.IdentifierFieldIDs, field.ID) {
+			identifierFieldIDs = append(identifierFieldIDs, field.ID)
+		}
+	}
+
+	return iceberggo.NewSchemaWithIdentifiers(schema.ID+1, identifierFieldIDs, fields...)
+}
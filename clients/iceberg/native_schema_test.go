@@ -0,0 +1,135 @@
+package iceberg
+
+import (
+	"testing"
+
+	iceberggo "github.com/apache/iceberg-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+func TestIcebergTypeForKind(t *testing.T) {
+	expected := map[string]iceberggo.Type{
+		"boolean":        iceberggo.PrimitiveTypes.Bool,
+		"string":         iceberggo.PrimitiveTypes.String,
+		"struct":         iceberggo.PrimitiveTypes.String,
+		"array":          iceberggo.PrimitiveTypes.String,
+		"time":           iceberggo.PrimitiveTypes.String,
+		"float":          iceberggo.PrimitiveTypes.Float64,
+		"integer":        iceberggo.PrimitiveTypes.Int32,
+		"bigint":         iceberggo.PrimitiveTypes.Int64,
+		"unspecified":    iceberggo.PrimitiveTypes.Int64,
+		"date":           iceberggo.PrimitiveTypes.Date,
+		"timestamp_ntz":  iceberggo.PrimitiveTypes.Timestamp,
+		"timestamp_tz":   iceberggo.PrimitiveTypes.TimestampTz,
+		"decimal":        iceberggo.DecimalTypeOf(10, 2),
+		"large decimal":  iceberggo.PrimitiveTypes.String,
+		"variable scale": iceberggo.PrimitiveTypes.String,
+	}
+
+	kinds := map[string]typing.KindDetails{
+		"boolean":        typing.Boolean,
+		"string":         typing.String,
+		"struct":         typing.Struct,
+		"array":          typing.Array,
+		"time":           typing.TimeKindDetails,
+		"float":          typing.Float,
+		"integer":        typing.BuildIntegerKind(typing.IntegerKind),
+		"bigint":         typing.BuildIntegerKind(typing.BigIntegerKind),
+		"unspecified":    typing.Integer,
+		"date":           typing.Date,
+		"timestamp_ntz":  typing.TimestampNTZ,
+		"timestamp_tz":   typing.TimestampTZ,
+		"decimal":        typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(10, 2)),
+		"large decimal":  typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(40, 2)),
+		"variable scale": typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(decimal.PrecisionNotSpecified, decimal.DefaultScale)),
+	}
+
+	for name, kd := range kinds {
+		actual, err := icebergTypeForKind(kd)
+		assert.NoError(t, err, name)
+		assert.True(t, expected[name].Equals(actual), name)
+	}
+
+	{
+		// Invalid
+		_, err := icebergTypeForKind(typing.Invalid)
+		assert.ErrorContains(t, err, "unsupported kind")
+	}
+}
+
+func TestKindForIcebergType(t *testing.T) {
+	{
+		// Round trip
+		for _, kd := range []typing.KindDetails{
+			typing.Boolean,
+			typing.String,
+			typing.Float,
+			typing.BuildIntegerKind(typing.IntegerKind),
+			typing.BuildIntegerKind(typing.BigIntegerKind),
+			typing.Date,
+			typing.TimestampNTZ,
+			typing.TimestampTZ,
+			typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(10, 2)),
+		} {
+			icebergType, err := icebergTypeForKind(kd)
+			assert.NoError(t, err)
+
+			actual, err := kindForIcebergType(icebergType)
+			assert.NoError(t, err)
+			assert.Equal(t, kd, actual)
+		}
+	}
+	{
+		// Types that we don't create
+		kd, err := kindForIcebergType(iceberggo.PrimitiveTypes.UUID)
+		assert.NoError(t, err)
+		assert.Equal(t, typing.String, kd)
+
+		kd, err = kindForIcebergType(iceberggo.PrimitiveTypes.Time)
+		assert.NoError(t, err)
+		assert.Equal(t, typing.TimeKindDetails, kd)
+	}
+	{
+		// Nested types are not supported
+		_, err := kindForIcebergType(&iceberggo.ListType{ElementID: 1, Element: iceberggo.PrimitiveTypes.String})
+		assert.ErrorContains(t, err, "unsupported data type")
+	}
+}
+
+func TestBuildSchema(t *testing.T) {
+	schema, err := buildSchemaWithColumns(iceberggo.NewSchema(0), 0, []columns.Column{
+		columns.NewColumn("id", typing.Integer),
+		columns.NewColumn("name", typing.String),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, []int{schema.Fields()[0].ID, schema.Fields()[1].ID})
+
+	{
+		// Adding columns should not reuse the field IDs of dropped columns
+		updated, err := buildSchemaWithColumns(schema, 5, []columns.Column{columns.NewColumn("email", typing.String)})
+		assert.NoError(t, err)
+		assert.Len(t, updated.Fields(), 3)
+		assert.Equal(t, "email", updated.Fields()[2].Name)
+		assert.Equal(t, 6, updated.Fields()[2].ID)
+		assert.Equal(t, schema.ID+1, updated.ID)
+	}
+	{
+		// Dropping columns
+		updated := buildSchemaWithoutColumns(schema, []columns.Column{columns.NewColumn("name", typing.String)})
+		assert.Len(t, updated.Fields(), 1)
+		assert.Equal(t, "id", updated.Fields()[0].Name)
+	}
+	{
+		// Reading the columns back
+		cols, err := columnsFromSchema(schema)
+		assert.NoError(t, err)
+		assert.Equal(t, []columns.Column{
+			columns.NewColumn("id", typing.BuildIntegerKind(typing.BigIntegerKind)),
+			columns.NewColumn("name", typing.String),
+		}, cols)
+	}
+}
@@ -0,0 +1,419 @@
+package iceberg
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	iceberggo "github.com/apache/iceberg-go"
+	"github.com/apache/iceberg-go/catalog"
+	iceio "github.com/apache/iceberg-go/io"
+	"github.com/apache/iceberg-go/table"
+
+	"github.com/artie-labs/transfer/clients/iceberg/dialect"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/iceberg"
+	icebergcatalog "github.com/artie-labs/transfer/lib/iceberg/catalog"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/webhooks"
+)
+
+// nativeCatalog is the subset of [icebergcatalog.RestCatalog] that the native writer needs.
+type nativeCatalog interface {
+	iceberg.IcebergCatalog
+	LoadTable(ctx context.Context, namespace, name string) (*table.Table, error)
+	CreateTable(ctx context.Context, namespace, name string, schema *iceberggo.Schema) (*table.Table, error)
+	CommitTable(ctx context.Context, identifier table.Identifier, requirements []table.Requirement, updates []table.Update) (table.Metadata, string, error)
+}
+
+// NativeStore writes to Iceberg without Spark, Parquet data files are written with iceberg-go and snapshots are committed through the REST catalog.
+//
+// Merges are merge-on-read: the existing rows that are updated or deleted are removed with position delete files and the upserted rows are appended
+// to new data files in the same snapshot, so existing data files are never rewritten.
+type NativeStore struct {
+	catalogName string
+	catalog     nativeCatalog
+	config      config.Config
+	cm          *types.DestinationTableConfigMap
+}
+
+func (s NativeStore) Label() constants.DestinationKind {
+	return s.config.Output
+}
+
+func (s NativeStore) GetConfig() config.Config {
+	return s.config
+}
+
+func (s NativeStore) IsOLTP() bool {
+	return false
+}
+
+func (s NativeStore) IsRetryableError(_ error) bool {
+	return false
+}
+
+func (s NativeStore) Dialect() dialect.IcebergDialect {
+	return dialect.IcebergDialect{}
+}
+
+func (s NativeStore) IdentifierFor(databaseAndSchema kafkalib.DatabaseAndSchemaPair, table string) sql.TableIdentifier {
+	return dialect.NewTableIdentifier(s.catalogName, databaseAndSchema.Schema, table)
+}
+
+func (s NativeStore) DropTable(ctx context.Context, tableID sql.TableIdentifier) error {
+	castedTableID, ok := tableID.(dialect.TableIdentifier)
+	if !ok {
+		return fmt.Errorf("failed to cast table ID to dialect.TableIdentifier")
+	}
+
+	if err := s.catalog.DropTable(ctx, castedTableID.Namespace(), castedTableID.Table()); err != nil {
+		return fmt.Errorf("failed to delete table: %w", err)
+	}
+
+	s.cm.RemoveTable(tableID)
+	return nil
+}
+
+func (s NativeStore) Append(ctx context.Context, tableData *optimization.TableData, _ *webhooks.Client, _ bool) error {
+	if tableData.ShouldSkipUpdate() {
+		return nil
+	}
+
+	tableID := s.IdentifierFor(tableData.TopicConfig().BuildDatabaseAndSchemaPair(), tableData.Name()).(dialect.TableIdentifier)
+	tbl, err := s.prepareTable(ctx, tableID, tableData, false)
+	if err != nil {
+		return err
+	}
+
+	arrowSchema, err := table.SchemaToArrowSchema(tbl.Schema(), nil, false, false)
+	if err != nil {
+		return fmt.Errorf("failed to build arrow schema: %w", err)
+	}
+
+	batch, err := s.buildBatch(tableData, arrowSchema, false)
+	if err != nil {
+		return err
+	}
+	defer batch.release()
+
+	return commitRecord(ctx, tbl, batch.record)
+}
+
+func (s NativeStore) Merge(ctx context.Context, tableData *optimization.TableData, _ *webhooks.Client) (bool, error) {
+	if tableData.ShouldSkipUpdate() {
+		return false, nil
+	}
+
+	tableID := s.IdentifierFor(tableData.TopicConfig().BuildDatabaseAndSchemaPair(), tableData.Name()).(dialect.TableIdentifier)
+	tbl, err := s.prepareTable(ctx, tableID, tableData, true)
+	if err != nil {
+		return false, err
+	}
+
+	arrowSchema, err := table.SchemaToArrowSchema(tbl.Schema(), nil, false, false)
+	if err != nil {
+		return false, fmt.Errorf("failed to build arrow schema: %w", err)
+	}
+
+	batch, err := s.buildBatch(tableData, arrowSchema, true)
+	if err != nil {
+		return false, err
+	}
+	defer batch.release()
+
+	if len(batch.primaryKeyIdxs) == 0 {
+		return false, fmt.Errorf("primary keys cannot be empty")
+	}
+
+	// Position delete files were replaced by deletion vectors in v3.
+	if version := tbl.Metadata().Version(); version != 2 {
+		return false, fmt.Errorf("merging into a v%d table is not supported by the native writer: %w", version, errors.ErrUnsupported)
+	}
+
+	record, deletes, err := mergeBatch(ctx, memory.DefaultAllocator, tbl, arrowSchema, batch)
+	if err != nil {
+		return false, err
+	}
+	defer record.Release()
+
+	if err = commitMerge(ctx, s.catalog, tbl, record, deletes); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s NativeStore) buildBatch(tableData *optimization.TableData, arrowSchema *arrow.Schema, merge bool) (nativeBatch, error) {
+	var rows []map[string]any
+	for row, err := range tableData.AllRows() {
+		if err != nil {
+			return nativeBatch{}, fmt.Errorf("failed to read row: %w", err)
+		}
+		rows = append(rows, row.GetData())
+	}
+
+	var primaryKeys []string
+	if merge {
+		primaryKeys = tableData.PrimaryKeys()
+	}
+
+	batch, err := buildBatch(memory.DefaultAllocator, buildBatchArgs{
+		schema:      arrowSchema,
+		rows:        rows,
+		cols:        tableData.ReadOnlyInMemoryCols().ValidColumns(),
+		primaryKeys: primaryKeys,
+		softDelete:  tableData.TopicConfig().SoftDelete,
+		merge:       merge,
+		cfg:         s.config.SharedDestinationSettings,
+	})
+	if err != nil {
+		return nativeBatch{}, fmt.Errorf("failed to build arrow record: %w", err)
+	}
+
+	return batch, nil
+}
+
+// mergeBatch returns the upserted rows from [batch] and the positions of the existing rows that they replace.
+// Inherited columns (e.g. TOAST values) are filled in from the existing rows.
+func mergeBatch(ctx context.Context, mem memory.Allocator, tbl *table.Table, arrowSchema *arrow.Schema, batch nativeBatch) (arrow.RecordBatch, []positionDeletes, error) {
+	existing, deletes, err := findExistingRows(ctx, mem, tbl, arrowSchema, batch)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer existing.release()
+
+	builder := array.NewRecordBuilder(mem, arrowSchema)
+	defer builder.Release()
+
+	batchCols := batch.columns()
+	for i, row := range batch.rows {
+		if row.deleted {
+			continue
+		}
+
+		existingIdx, hasExisting := existing.keys[rowKey(batchCols, batch.primaryKeyIdxs, i)]
+		for j, col := range batchCols {
+			var err error
+			if row.inherit[j] && hasExisting {
+				err = copyValue(builder.Field(j), existing.record.Column(j), existingIdx)
+			} else {
+				err = copyValue(builder.Field(j), col, i)
+			}
+
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to copy column %q: %w", arrowSchema.Field(j).Name, err)
+			}
+		}
+	}
+
+	return builder.NewRecordBatch(), deletes, nil
+}
+
+// commitRecord appends [record] to the table in a new snapshot.
+func commitRecord(ctx context.Context, tbl *table.Table, record arrow.RecordBatch) error {
+	if record.NumRows() == 0 {
+		return nil
+	}
+
+	reader, err := array.NewRecordReader(record.Schema(), []arrow.RecordBatch{record})
+	if err != nil {
+		return fmt.Errorf("failed to create record reader: %w", err)
+	}
+	defer reader.Release()
+
+	tx := tbl.NewTransaction()
+	if err = tx.Append(ctx, reader, nil); err != nil {
+		return fmt.Errorf("failed to write data files: %w", err)
+	}
+
+	if _, err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func (s NativeStore) getTableConfig(ctx context.Context, tableID dialect.TableIdentifier, dropDeletedColumns bool) (*types.DestinationTableConfig, error) {
+	if tableCfg := s.cm.GetTableConfig(tableID); tableCfg != nil {
+		return tableCfg, nil
+	}
+
+	var cols []columns.Column
+	tbl, err := s.catalog.LoadTable(ctx, tableID.Namespace(), tableID.Table())
+	if err != nil {
+		if !errors.Is(err, catalog.ErrNoSuchTable) {
+			return nil, err
+		}
+	} else {
+		if cols, err = columnsFromSchema(tbl.Schema()); err != nil {
+			return nil, err
+		}
+	}
+
+	tableCfg := types.NewDestinationTableConfig(cols, dropDeletedColumns)
+	s.cm.AddTable(tableID, tableCfg)
+	return tableCfg, nil
+}
+
+// prepareTable creates or evolves the table so that it has all of the columns in [tableData] and returns the latest version of it.
+func (s NativeStore) prepareTable(ctx context.Context, tableID dialect.TableIdentifier, tableData *optimization.TableData, dropColumns bool) (*table.Table, error) {
+	tableConfig, err := s.getTableConfig(ctx, tableID, tableData.TopicConfig().DropDeletedColumns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table config: %w", err)
+	}
+
+	srcKeysMissing, targetKeysMissing := columns.DiffAndFilter(
+		tableData.ReadOnlyInMemoryCols().GetColumns(),
+		tableConfig.GetColumns(),
+		tableData.BuildColumnsToKeep(),
+	)
+
+	var tbl *table.Table
+	if tableConfig.CreateTable() {
+		schema, err := buildSchemaWithColumns(iceberggo.NewSchema(0), 0, targetKeysMissing)
+		if err != nil {
+			return nil, err
+		}
+
+		if tbl, err = s.catalog.CreateTable(ctx, tableID.Namespace(), tableID.Table(), schema); err != nil {
+			return nil, err
+		}
+
+		tableConfig.MutateInMemoryColumns(constants.AddColumn, targetKeysMissing)
+	} else {
+		if tbl, err = s.catalog.LoadTable(ctx, tableID.Namespace(), tableID.Table()); err != nil {
+			if errors.Is(err, catalog.ErrNoSuchTable) {
+				// The table was dropped from underneath us, so we'll need to recreate it.
+				s.cm.RemoveTable(tableID)
+				return s.prepareTable(ctx, tableID, tableData, dropColumns)
+			}
+			return nil, err
+		}
+
+		if len(targetKeysMissing) > 0 {
+			schema, err := buildSchemaWithColumns(tbl.Schema(), tbl.Metadata().LastColumnID(), targetKeysMissing)
+			if err != nil {
+				return nil, err
+			}
+
+			if tbl, err = s.updateSchema(ctx, tableID, tbl, schema); err != nil {
+				return nil, fmt.Errorf("failed to add columns: %w", err)
+			}
+
+			tableConfig.MutateInMemoryColumns(constants.AddColumn, targetKeysMissing)
+		}
+
+		if dropColumns {
+			var colsToDrop []columns.Column
+			for _, col := range srcKeysMissing {
+				if tableConfig.ShouldDeleteColumn(col.Name(), tableData.GetLatestTimestamp(), tableData.ContainsOtherOperations()) {
+					colsToDrop = append(colsToDrop, col)
+				}
+			}
+
+			if len(colsToDrop) > 0 {
+				if tbl, err = s.updateSchema(ctx, tableID, tbl, buildSchemaWithoutColumns(tbl.Schema(), colsToDrop)); err != nil {
+					return nil, fmt.Errorf("failed to drop columns: %w", err)
+				}
+
+				tableConfig.MutateInMemoryColumns(constants.DropColumn, colsToDrop)
+			}
+		}
+	}
+
+	if err = tableData.MergeColumnsFromDestination(tableConfig.GetColumns()...); err != nil {
+		return nil, fmt.Errorf("failed to merge columns from destination: %w for table %q", err, tableData.Name())
+	}
+
+	return tbl, nil
+}
+
+// updateSchema sets [schema] as the table's current schema, iceberg-go transactions do not support schema changes yet so we commit the metadata updates directly.
+func (s NativeStore) updateSchema(ctx context.Context, tableID dialect.TableIdentifier, tbl *table.Table, schema *iceberggo.Schema) (*table.Table, error) {
+	if _, _, err := s.catalog.CommitTable(ctx, tbl.Identifier(),
+		[]table.Requirement{table.AssertCurrentSchemaID(tbl.Schema().ID)},
+		[]table.Update{table.NewAddSchemaUpdate(schema), table.NewSetCurrentSchemaUpdate(-1)},
+	); err != nil {
+		return nil, err
+	}
+
+	return s.catalog.LoadTable(ctx, tableID.Namespace(), tableID.Table())
+}
+
+func LoadNativeStore(ctx context.Context, cfg config.Config) (NativeStore, error) {
+	store, err := LoadReadOnlyNativeStore(ctx, cfg)
+	if err != nil {
+		return NativeStore{}, err
+	}
+
+	// There are no temporary tables to sweep since we don't stage data in the catalog.
+	for _, schema := range kafkalib.GetAllUniqueSchemas(cfg.TopicConfigs()) {
+		if err := ensureNamespaceExists(ctx, store.catalog, store.Dialect().BuildIdentifier(schema)); err != nil {
+			return NativeStore{}, fmt.Errorf("failed to ensure namespace exists: %w", err)
+		}
+	}
+
+	return store, nil
+}
+
+// LoadReadOnlyNativeStore is like [LoadNativeStore], but it doesn't create namespaces.
+func LoadReadOnlyNativeStore(ctx context.Context, cfg config.Config) (NativeStore, error) {
+	restCfg := cfg.Iceberg.RestCatalog
+	if restCfg == nil {
+		return NativeStore{}, fmt.Errorf("the native writer requires a rest catalog")
+	}
+
+	if err := restCfg.ValidateNativeWriter(); err != nil {
+		return NativeStore{}, fmt.Errorf("invalid rest catalog configuration: %w", err)
+	}
+
+	// Data files are written by us, so the FileIO needs credentials unless the catalog vends them.
+	properties := map[string]string{}
+	if restCfg.AwsAccessKeyID != "" {
+		properties[iceio.S3AccessKeyID] = restCfg.AwsAccessKeyID
+		properties[iceio.S3SecretAccessKey] = restCfg.AwsSecretAccessKey
+	}
+	if region := cmp.Or(restCfg.Region, os.Getenv("AWS_REGION")); region != "" {
+		properties[iceio.S3Region] = region
+	}
+
+	cat, err := icebergcatalog.NewRESTCatalog(ctx, icebergcatalog.Config{
+		URI:        restCfg.URI,
+		Token:      restCfg.Token,
+		AuthURI:    restCfg.AuthURI,
+		Scope:      restCfg.Scope,
+		Credential: restCfg.Credential,
+		Warehouse:  restCfg.Warehouse,
+		Prefix:     restCfg.Prefix,
+		Properties: properties,
+	})
+	if err != nil {
+		return NativeStore{}, fmt.Errorf("failed to create REST catalog: %w", err)
+	}
+
+	return NativeStore{
+		catalogName: restCfg.CatalogName(),
+		catalog:     cat,
+		config:      cfg,
+		cm:          &types.DestinationTableConfigMap{},
+	}, nil
+}
+
+func (s NativeStore) CheckConnectivity(ctx context.Context) error {
+	if _, err := s.catalog.ListNamespaces(ctx); err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,437 @@
+package iceberg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	iceberggo "github.com/apache/iceberg-go"
+	"github.com/apache/iceberg-go/catalog"
+	"github.com/apache/iceberg-go/table"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/clients/iceberg/dialect"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/numbers"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+// restCatalog is a local Iceberg REST catalog, table metadata is kept in memory and written to the filesystem warehouse along with the data and manifest files.
+type restCatalog struct {
+	warehouse  string
+	mu         sync.Mutex
+	namespaces map[string]bool
+	tables     map[string]restCatalogTable
+}
+
+type restCatalogTable struct {
+	metadataLocation string
+	metadata         table.Metadata
+}
+
+func newRESTCatalogServer(t *testing.T) *httptest.Server {
+	cat := &restCatalog{
+		warehouse:  t.TempDir(),
+		namespaces: make(map[string]bool),
+		tables:     make(map[string]restCatalogTable),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/config", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"defaults": map[string]string{}, "overrides": map[string]string{}})
+	})
+	mux.HandleFunc("POST /v1/namespaces", cat.createNamespace)
+	mux.HandleFunc("GET /v1/namespaces/{namespace}", cat.loadNamespace)
+	mux.HandleFunc("POST /v1/namespaces/{namespace}/tables", cat.createTable)
+	mux.HandleFunc("GET /v1/namespaces/{namespace}/tables/{table}", cat.loadTable)
+	mux.HandleFunc("POST /v1/namespaces/{namespace}/tables/{table}", cat.commitTable)
+	mux.HandleFunc("DELETE /v1/namespaces/{namespace}/tables/{table}", cat.dropTable)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, code int, _type string, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": err.Error(), "type": _type, "code": code}})
+}
+
+func (c *restCatalog) createNamespace(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Namespace []string `json:"namespace"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.namespaces[strings.Join(body.Namespace, "\x1f")] = true
+	writeJSON(w, map[string]any{"namespace": body.Namespace, "properties": map[string]string{}})
+}
+
+func (c *restCatalog) loadNamespace(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.namespaces[r.PathValue("namespace")] {
+		writeError(w, http.StatusNotFound, "NoSuchNamespaceException", fmt.Errorf("namespace does not exist"))
+		return
+	}
+
+	writeJSON(w, map[string]any{"namespace": strings.Split(r.PathValue("namespace"), "\x1f"), "properties": map[string]string{}})
+}
+
+func (c *restCatalog) createTable(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name       string               `json:"name"`
+		Schema     *iceberggo.Schema    `json:"schema"`
+		Properties iceberggo.Properties `json:"properties"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	namespace := r.PathValue("namespace")
+	if !c.namespaces[namespace] {
+		writeError(w, http.StatusNotFound, "NoSuchNamespaceException", fmt.Errorf("namespace does not exist"))
+		return
+	}
+
+	key := namespace + "." + body.Name
+	if _, ok := c.tables[key]; ok {
+		writeError(w, http.StatusConflict, "AlreadyExistsException", fmt.Errorf("table already exists"))
+		return
+	}
+
+	location := "file://" + filepath.Join(c.warehouse, namespace, body.Name)
+	metadata, err := table.NewMetadata(body.Schema, iceberggo.UnpartitionedSpec, table.UnsortedSortOrder, location, body.Properties)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", err)
+		return
+	}
+
+	c.writeTable(w, key, metadata)
+}
+
+func (c *restCatalog) loadTable(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tbl, ok := c.tables[r.PathValue("namespace")+"."+r.PathValue("table")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchTableException", fmt.Errorf("table does not exist"))
+		return
+	}
+
+	writeJSON(w, map[string]any{"metadata-location": tbl.metadataLocation, "metadata": tbl.metadata, "config": map[string]string{}})
+}
+
+func (c *restCatalog) commitTable(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Requirements table.Requirements `json:"requirements"`
+		Updates      table.Updates      `json:"updates"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := r.PathValue("namespace") + "." + r.PathValue("table")
+	tbl, ok := c.tables[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchTableException", fmt.Errorf("table does not exist"))
+		return
+	}
+
+	for _, requirement := range body.Requirements {
+		if err := requirement.Validate(tbl.metadata); err != nil {
+			writeError(w, http.StatusConflict, "CommitFailedException", err)
+			return
+		}
+	}
+
+	metadata, err := table.UpdateTableMetadata(tbl.metadata, body.Updates, tbl.metadataLocation)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", err)
+		return
+	}
+
+	c.writeTable(w, key, metadata)
+}
+
+func (c *restCatalog) dropTable(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := r.PathValue("namespace") + "." + r.PathValue("table")
+	if _, ok := c.tables[key]; !ok {
+		writeError(w, http.StatusNotFound, "NoSuchTableException", fmt.Errorf("table does not exist"))
+		return
+	}
+
+	delete(c.tables, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTable writes a new metadata file to the warehouse and makes it the table's current metadata.
+func (c *restCatalog) writeTable(w http.ResponseWriter, key string, metadata table.Metadata) {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "ServerErrorException", err)
+		return
+	}
+
+	dir := filepath.Join(strings.TrimPrefix(metadata.Location(), "file://"), "metadata")
+	path := filepath.Join(dir, fmt.Sprintf("%s.metadata.json", uuid.NewString()))
+	if err = os.MkdirAll(dir, 0o755); err == nil {
+		err = os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "ServerErrorException", err)
+		return
+	}
+
+	c.tables[key] = restCatalogTable{metadataLocation: "file://" + path, metadata: metadata}
+	writeJSON(w, map[string]any{"metadata-location": "file://" + path, "metadata": json.RawMessage(data), "config": map[string]string{}})
+}
+
+func newTestNativeStore(t *testing.T, topicConfigs ...*kafkalib.TopicConfig) NativeStore {
+	server := newRESTCatalogServer(t)
+	store, err := LoadNativeStore(t.Context(), config.Config{
+		Output: constants.Iceberg,
+		Kafka:  &kafkalib.Kafka{TopicConfigs: topicConfigs},
+		Iceberg: &config.Iceberg{
+			Writer:      config.IcebergWriterNative,
+			RestCatalog: &config.RestCatalog{URI: server.URL, Warehouse: "warehouse"},
+		},
+	})
+	assert.NoError(t, err)
+	return store
+}
+
+func newTestTableData(topicConfig kafkalib.TopicConfig, mode config.Mode, extraCols ...columns.Column) *optimization.TableData {
+	cols := columns.NewColumns(nil)
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn("price", typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(10, 2))))
+	cols.AddColumn(columns.NewColumn("updated_at", typing.TimestampTZ))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+	for _, col := range extraCols {
+		cols.AddColumn(col)
+	}
+
+	return optimization.NewTableData(cols, mode, []string{"id"}, topicConfig, "orders")
+}
+
+func insertRow(t *testing.T, tableData *optimization.TableData, id int64, row map[string]any, _delete bool) {
+	row["id"] = id
+	row[constants.DeleteColumnMarker] = _delete
+	assert.NoError(t, tableData.InsertRow(fmt.Sprint(id), row, _delete))
+}
+
+// readRows returns the table's rows keyed by id, values are formatted with [arrow.Array.ValueStr].
+func readRows(t *testing.T, store NativeStore, tableID dialect.TableIdentifier) map[string]map[string]string {
+	tbl, err := store.catalog.LoadTable(t.Context(), tableID.Namespace(), tableID.Table())
+	assert.NoError(t, err)
+
+	arrowTable, err := tbl.Scan().ToArrowTable(t.Context())
+	assert.NoError(t, err)
+	defer arrowTable.Release()
+
+	out := make(map[string]map[string]string)
+	reader := array.NewTableReader(arrowTable, -1)
+	defer reader.Release()
+	for reader.Next() {
+		record := reader.RecordBatch()
+		for i := range int(record.NumRows()) {
+			row := make(map[string]string)
+			for j, field := range record.Schema().Fields() {
+				if record.Column(j).IsNull(i) {
+					row[field.Name] = "<null>"
+				} else {
+					row[field.Name] = record.Column(j).ValueStr(i)
+				}
+			}
+			out[row["id"]] = row
+		}
+	}
+
+	return out
+}
+
+func TestNativeStore_Merge(t *testing.T) {
+	topicConfig := kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders"}
+	store := newTestNativeStore(t, &topicConfig)
+	tableID := store.IdentifierFor(topicConfig.BuildDatabaseAndSchemaPair(), "orders").(dialect.TableIdentifier)
+	updatedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	{
+		// Creates the table and inserts the rows
+		tableData := newTestTableData(topicConfig, config.Replication)
+		for id := range int64(3) {
+			insertRow(t, tableData, id+1, map[string]any{
+				"name":       fmt.Sprintf("order %d", id+1),
+				"price":      decimal.NewDecimal(numbers.MustParseDecimal("12.34")),
+				"updated_at": updatedAt,
+			}, false)
+		}
+
+		commit, err := store.Merge(t.Context(), tableData, nil)
+		assert.NoError(t, err)
+		assert.True(t, commit)
+
+		rows := readRows(t, store, tableID)
+		assert.Len(t, rows, 3)
+		assert.Equal(t, "order 2", rows["2"]["name"])
+		assert.Equal(t, "12.34", rows["2"]["price"])
+	}
+	{
+		// Updates, deletes and TOAST values
+		tableData := newTestTableData(topicConfig, config.Replication, columns.NewColumn("email", typing.String))
+		insertRow(t, tableData, 1, map[string]any{"name": constants.ToastUnavailableValuePlaceholder, "price": decimal.NewDecimal(numbers.MustParseDecimal("1.50")), "updated_at": updatedAt}, false)
+		insertRow(t, tableData, 2, map[string]any{"name": "updated", "email": "a@b.com", "updated_at": updatedAt}, false)
+		insertRow(t, tableData, 3, map[string]any{}, true)
+		insertRow(t, tableData, 4, map[string]any{"name": "order 4", "updated_at": updatedAt}, false)
+
+		_, err := store.Merge(t.Context(), tableData, nil)
+		assert.NoError(t, err)
+
+		rows := readRows(t, store, tableID)
+		assert.Len(t, rows, 3)
+		// The TOAST value is kept from the existing row
+		assert.Equal(t, "order 1", rows["1"]["name"])
+		assert.Equal(t, "1.5", rows["1"]["price"])
+		assert.Equal(t, "<null>", rows["1"]["email"])
+		assert.Equal(t, "updated", rows["2"]["name"])
+		assert.Equal(t, "a@b.com", rows["2"]["email"])
+		assert.Equal(t, "<null>", rows["2"]["price"])
+		assert.NotContains(t, rows, "3")
+		assert.Equal(t, "order 4", rows["4"]["name"])
+
+		// The existing data file is kept and the replaced rows are masked with a position delete file.
+		tbl, err := store.catalog.LoadTable(t.Context(), tableID.Namespace(), tableID.Table())
+		assert.NoError(t, err)
+		assert.Equal(t, table.OpOverwrite, tbl.CurrentSnapshot().Summary.Operation)
+		assert.Equal(t, "1", tbl.CurrentSnapshot().Summary.Properties["added-position-delete-files"])
+		assert.Equal(t, "3", tbl.CurrentSnapshot().Summary.Properties["added-position-deletes"])
+		tasks, err := tbl.Scan().PlanFiles(t.Context())
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 2)
+		var deleteFiles []iceberggo.DataFile
+		for _, task := range tasks {
+			deleteFiles = append(deleteFiles, task.DeleteFiles...)
+		}
+		assert.Len(t, deleteFiles, 1)
+		assert.Equal(t, iceberggo.EntryContentPosDeletes, deleteFiles[0].ContentType())
+		assert.Equal(t, int64(3), deleteFiles[0].Count())
+	}
+	{
+		// Deleting a row that does not exist is a no-op
+		tableData := newTestTableData(topicConfig, config.Replication)
+		insertRow(t, tableData, 100, map[string]any{}, true)
+
+		_, err := store.Merge(t.Context(), tableData, nil)
+		assert.NoError(t, err)
+		assert.Len(t, readRows(t, store, tableID), 3)
+	}
+	{
+		// Rows that were already deleted are skipped, so TOAST values come from the latest version of the row
+		tableData := newTestTableData(topicConfig, config.Replication)
+		insertRow(t, tableData, 2, map[string]any{"name": constants.ToastUnavailableValuePlaceholder, "price": decimal.NewDecimal(numbers.MustParseDecimal("2.50")), "updated_at": updatedAt}, false)
+		insertRow(t, tableData, 4, map[string]any{}, true)
+
+		_, err := store.Merge(t.Context(), tableData, nil)
+		assert.NoError(t, err)
+
+		rows := readRows(t, store, tableID)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, "updated", rows["2"]["name"])
+		assert.Equal(t, "2.5", rows["2"]["price"])
+		assert.NotContains(t, rows, "4")
+	}
+	{
+		// Only deleting rows doesn't add a data file
+		tableData := newTestTableData(topicConfig, config.Replication)
+		insertRow(t, tableData, 1, map[string]any{}, true)
+
+		_, err := store.Merge(t.Context(), tableData, nil)
+		assert.NoError(t, err)
+
+		rows := readRows(t, store, tableID)
+		assert.Len(t, rows, 1)
+		assert.Contains(t, rows, "2")
+
+		tbl, err := store.catalog.LoadTable(t.Context(), tableID.Namespace(), tableID.Table())
+		assert.NoError(t, err)
+		assert.NotContains(t, tbl.CurrentSnapshot().Summary.Properties, "added-data-files")
+		assert.Equal(t, "3", tbl.CurrentSnapshot().Summary.Properties["total-delete-files"])
+	}
+}
+
+func TestNativeStore_Merge_SoftDelete(t *testing.T) {
+	topicConfig := kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders", SoftDelete: true}
+	store := newTestNativeStore(t, &topicConfig)
+	tableID := store.IdentifierFor(topicConfig.BuildDatabaseAndSchemaPair(), "orders").(dialect.TableIdentifier)
+
+	tableData := newTestTableData(topicConfig, config.Replication)
+	insertRow(t, tableData, 1, map[string]any{"name": "order 1"}, false)
+	_, err := store.Merge(t.Context(), tableData, nil)
+	assert.NoError(t, err)
+
+	// Only the primary key is known, so only the delete marker should be flipped.
+	tableData = newTestTableData(topicConfig, config.Replication)
+	insertRow(t, tableData, 1, map[string]any{constants.OnlySetDeleteColumnMarker: true}, true)
+	_, err = store.Merge(t.Context(), tableData, nil)
+	assert.NoError(t, err)
+
+	rows := readRows(t, store, tableID)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "order 1", rows["1"]["name"])
+	assert.Equal(t, "true", rows["1"][constants.DeleteColumnMarker])
+}
+
+func TestNativeStore_Append(t *testing.T) {
+	topicConfig := kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders"}
+	store := newTestNativeStore(t, &topicConfig)
+	tableID := store.IdentifierFor(topicConfig.BuildDatabaseAndSchemaPair(), "orders").(dialect.TableIdentifier)
+
+	for _, id := range []int64{1, 2} {
+		tableData := newTestTableData(topicConfig, config.Replication)
+		insertRow(t, tableData, id, map[string]any{"name": "order"}, false)
+		assert.NoError(t, store.Append(t.Context(), tableData, nil, false))
+	}
+
+	rows := readRows(t, store, tableID)
+	assert.Len(t, rows, 2)
+
+	// Dropping the table should also clear the cached table config.
+	assert.NoError(t, store.DropTable(t.Context(), tableID))
+	assert.Nil(t, store.cm.GetTableConfig(tableID))
+	_, err := store.catalog.LoadTable(t.Context(), tableID.Namespace(), tableID.Table())
+	assert.ErrorIs(t, err, catalog.ErrNoSuchTable)
+}
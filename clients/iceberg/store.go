@@ -135,9 +135,13 @@ func (s Store) append(ctx context.Context, client *apachelivy.Client, tableData
 }
 
 func (s Store) EnsureNamespaceExists(ctx context.Context, namespace string) error {
-	if _, err := s.catalog.GetNamespace(ctx, namespace); err != nil {
+	return ensureNamespaceExists(ctx, s.catalog, namespace)
+}
+
+func ensureNamespaceExists(ctx context.Context, catalog iceberg.IcebergCatalog, namespace string) error {
+	if _, err := catalog.GetNamespace(ctx, namespace); err != nil {
 		if awslib.IsNotFoundError(err) || iceberg.NamespaceNotFoundError(err) {
-			return s.catalog.CreateNamespace(ctx, namespace)
+			return catalog.CreateNamespace(ctx, namespace)
 		}
 
 		return fmt.Errorf("failed to get namespace: %w", err)
@@ -284,37 +288,46 @@ func SweepTemporaryTables(ctx context.Context, catalog iceberg.IcebergCatalog, _
 }
 
 func LoadStore(ctx context.Context, cfg config.Config) (Store, error) {
-	var store Store
-	var err error
-
-	switch {
-	case cfg.Iceberg.S3Tables != nil:
-		store, err = loadS3TablesStore(cfg)
-	case cfg.Iceberg.RestCatalog != nil:
-		store, err = loadRestCatalogStore(ctx, cfg)
-	default:
-		return Store{}, fmt.Errorf("no catalog configuration provided (s3Tables or restCatalog)")
-	}
-
+	store, err := LoadReadOnlyStore(ctx, cfg)
 	if err != nil {
 		return Store{}, err
 	}
 
 	// Ensure all namespaces exist (including staging namespaces)
-	for _, schema := range kafkalib.GetAllUniqueSchemas(cfg.Kafka.TopicConfigs) {
+	for _, schema := range kafkalib.GetAllUniqueSchemas(cfg.TopicConfigs()) {
 		if err := store.EnsureNamespaceExists(ctx, store.Dialect().BuildIdentifier(schema)); err != nil {
 			return Store{}, fmt.Errorf("failed to ensure namespace exists: %w", err)
 		}
 	}
 
 	// Sweep the temporary tables from staging namespaces only.
-	if err = SweepTemporaryTables(ctx, store.catalog, store.Dialect(), kafkalib.GetUniqueStagingSchemas(cfg.Kafka.TopicConfigs)); err != nil {
+	if err = SweepTemporaryTables(ctx, store.catalog, store.Dialect(), kafkalib.GetUniqueStagingSchemas(cfg.TopicConfigs())); err != nil {
 		return Store{}, fmt.Errorf("failed to sweep temporary tables: %w", err)
 	}
 
 	return store, nil
 }
 
+// LoadReadOnlyStore is like [LoadStore], but it doesn't create namespaces or sweep temporary tables.
+func LoadReadOnlyStore(ctx context.Context, cfg config.Config) (Store, error) {
+	switch {
+	case cfg.Iceberg.S3Tables != nil:
+		return loadS3TablesStore(cfg)
+	case cfg.Iceberg.RestCatalog != nil:
+		return loadRestCatalogStore(ctx, cfg)
+	default:
+		return Store{}, fmt.Errorf("no catalog configuration provided (s3Tables or restCatalog)")
+	}
+}
+
+func (s Store) CheckConnectivity(ctx context.Context) error {
+	if _, err := s.catalog.ListNamespaces(ctx); err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	return nil
+}
+
 func loadS3TablesStore(cfg config.Config) (Store, error) {
 	pool := apachelivy.NewClientPool(
 		cfg.Iceberg.ApacheLivyURL,
@@ -0,0 +1,188 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hamba/avro/v2"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/converters"
+	"github.com/artie-labs/transfer/lib/typing/converters/primitives"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/values"
+)
+
+const avroNamespace = "artie"
+
+// avroField is a nullable field, [branch] is the name of the non-null union branch that values are encoded with.
+type avroField struct {
+	name   string
+	column columns.Column
+	branch string
+}
+
+type avroSchema struct {
+	// fullName is used to build the subject with the TopicRecordNameStrategy.
+	fullName string
+	schema   avro.Schema
+	fields   []avroField
+}
+
+// avroTypeForKind returns the Avro type for [kd] and the name of its union branch.
+func avroTypeForKind(kd typing.KindDetails) (any, string) {
+	switch kd.Kind {
+	case typing.Boolean.Kind:
+		return "boolean", "boolean"
+	case typing.Integer.Kind:
+		return "long", "long"
+	case typing.Float.Kind:
+		return "double", "double"
+	case typing.EDecimal.Kind:
+		if kd.ExtendedDecimalDetails == nil {
+			return "string", "string"
+		}
+
+		precision := kd.ExtendedDecimalDetails.Precision()
+		if precision == decimal.PrecisionNotSpecified || precision > decimal.MaxPrecisionBeforeString {
+			return "string", "string"
+		}
+
+		return map[string]any{
+			"type":        "bytes",
+			"logicalType": "decimal",
+			"precision":   precision,
+			"scale":       kd.ExtendedDecimalDetails.Scale(),
+		}, "bytes.decimal"
+	case typing.Date.Kind:
+		return map[string]any{"type": "int", "logicalType": "date"}, "int.date"
+	case typing.TimestampTZ.Kind:
+		return map[string]any{"type": "long", "logicalType": "timestamp-micros"}, "long.timestamp-micros"
+	case typing.TimestampNTZ.Kind:
+		return map[string]any{"type": "long", "logicalType": "local-timestamp-micros"}, "long.local-timestamp-micros"
+	default:
+		// Structs and arrays are written as JSON strings, since their shape is not known ahead of time.
+		return "string", "string"
+	}
+}
+
+// avroName replaces the characters that are not allowed in Avro names with underscores.
+func avroName(name string) string {
+	var out strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			out.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				out.WriteRune('_')
+			}
+			out.WriteRune(r)
+		default:
+			out.WriteRune('_')
+		}
+	}
+
+	return out.String()
+}
+
+func buildAvroSchema(tableName string, cols []columns.Column) (avroSchema, error) {
+	recordName := avroName(tableName)
+	fields := make([]avroField, len(cols))
+	schemaFields := make([]map[string]any, len(cols))
+	seen := make(map[string]string, len(cols))
+	for i, col := range cols {
+		name := avroName(col.Name())
+		if existing, ok := seen[name]; ok {
+			return avroSchema{}, fmt.Errorf("columns %q and %q have the same Avro name %q", existing, col.Name(), name)
+		}
+		seen[name] = col.Name()
+
+		avroType, branch := avroTypeForKind(col.KindDetails)
+		fields[i] = avroField{name: name, column: col, branch: branch}
+		schemaFields[i] = map[string]any{"name": name, "type": []any{"null", avroType}, "default": nil}
+	}
+
+	out, err := json.Marshal(map[string]any{
+		"type":      "record",
+		"name":      recordName,
+		"namespace": avroNamespace,
+		"fields":    schemaFields,
+	})
+	if err != nil {
+		return avroSchema{}, fmt.Errorf("failed to marshal avro schema: %w", err)
+	}
+
+	schema, err := avro.Parse(string(out))
+	if err != nil {
+		return avroSchema{}, fmt.Errorf("failed to parse avro schema: %w", err)
+	}
+
+	return avroSchema{fullName: avroNamespace + "." + recordName, schema: schema, fields: fields}, nil
+}
+
+// toAvroValue converts [value] into the Go type that hamba/avro expects for [field.branch].
+func toAvroValue(field avroField, value any, cfg config.SharedDestinationSettings) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if value == constants.ToastUnavailableValuePlaceholder && field.branch != "string" {
+		// The placeholder can only be represented in string columns.
+		return nil, nil
+	}
+
+	var castedValue any
+	var err error
+	switch field.branch {
+	case "boolean":
+		castedValue, err = primitives.BooleanConverter{}.Convert(value)
+	case "long":
+		castedValue, err = primitives.Int64Converter{}.Convert(value)
+	case "double":
+		castedValue, err = primitives.Float64Converter{}.Convert(value)
+	case "bytes.decimal":
+		var stringValue string
+		if stringValue, err = values.ToString(value, field.column.KindDetails); err == nil {
+			rat, ok := new(big.Rat).SetString(stringValue)
+			if !ok {
+				return nil, fmt.Errorf("failed to parse decimal %q", stringValue)
+			}
+			castedValue = rat
+		}
+	case "int.date":
+		castedValue, err = typing.ParseDateFromAny(value)
+	case "long.timestamp-micros":
+		castedValue, err = typing.ParseTimestampTZFromAny(value)
+	case "long.local-timestamp-micros":
+		castedValue, err = typing.ParseTimestampNTZFromAny(value)
+	default:
+		castedValue, err = values.ToStringOpts(value, field.column.KindDetails, converters.GetStringConverterOpts{UseNewStringMethod: cfg.UseNewStringMethod})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Nullable unions are encoded as a map keyed by the branch name.
+	return map[string]any{field.branch: castedValue}, nil
+}
+
+func (a avroSchema) encode(row map[string]any, cfg config.SharedDestinationSettings) ([]byte, error) {
+	record := make(map[string]any, len(a.fields))
+	for _, field := range a.fields {
+		value, err := toAvroValue(field, row[field.column.Name()], cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert column %q: %w", field.column.Name(), err)
+		}
+
+		record[field.name] = value
+	}
+
+	return avro.Marshal(a.schema, record)
+}
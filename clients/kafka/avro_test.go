@@ -0,0 +1,79 @@
+package kafka
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+func TestAvroName(t *testing.T) {
+	assert.Equal(t, "orders", avroName("orders"))
+	assert.Equal(t, "order_items", avroName("order-items"))
+	assert.Equal(t, "_1st_place", avroName("1st place"))
+	assert.Equal(t, "__artie_delete", avroName(constants.DeleteColumnMarker))
+}
+
+func TestBuildAvroSchema(t *testing.T) {
+	{
+		// Duplicate names
+		_, err := buildAvroSchema("orders", []columns.Column{
+			columns.NewColumn("first-name", typing.String),
+			columns.NewColumn("first_name", typing.String),
+		})
+		assert.ErrorContains(t, err, `columns "first-name" and "first_name" have the same Avro name "first_name"`)
+	}
+	{
+		// Large decimals are written as strings
+		schema, err := buildAvroSchema("orders", []columns.Column{
+			columns.NewColumn("small", typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(10, 2))),
+			columns.NewColumn("large", typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(50, 2))),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "artie.orders", schema.fullName)
+		assert.Equal(t, "bytes.decimal", schema.fields[0].branch)
+		assert.Equal(t, "string", schema.fields[1].branch)
+	}
+}
+
+func TestToAvroValue(t *testing.T) {
+	cfg := config.SharedDestinationSettings{}
+	{
+		// Nil
+		value, err := toAvroValue(avroField{branch: "long"}, nil, cfg)
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+	}
+	{
+		// TOAST placeholder in a non-string column
+		value, err := toAvroValue(avroField{branch: "long"}, constants.ToastUnavailableValuePlaceholder, cfg)
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+	}
+	{
+		// Decimal
+		field := avroField{column: columns.NewColumn("price", typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(10, 2))), branch: "bytes.decimal"}
+		value, err := toAvroValue(field, "12.34", cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"bytes.decimal": big.NewRat(1234, 100)}, value)
+	}
+	{
+		// Date
+		field := avroField{column: columns.NewColumn("created", typing.Date), branch: "int.date"}
+		value, err := toAvroValue(field, "2025-01-02", cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"int.date": time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)}, value)
+	}
+	{
+		// Invalid integer
+		_, err := toAvroValue(avroField{column: columns.NewColumn("id", typing.Integer), branch: "long"}, "abc", cfg)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,231 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+	sqllib "github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/webhooks"
+)
+
+type producer interface {
+	ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults
+}
+
+// Store re-publishes the flushed rows to Kafka, one message per row keyed by the primary key.
+type Store struct {
+	config   config.Config
+	producer producer
+	// registry is only set for the Avro output format, registered schema IDs are cached by the client.
+	registry *schemaregistry.Client
+}
+
+func (s *Store) Label() constants.DestinationKind {
+	return s.config.Output
+}
+
+func (s *Store) GetConfig() config.Config {
+	return s.config
+}
+
+func (s *Store) IsOLTP() bool {
+	return false
+}
+
+func (s *Store) Validate() error {
+	return s.config.KafkaSink.Validate()
+}
+
+func (s *Store) IdentifierFor(topicConfig kafkalib.DatabaseAndSchemaPair, table string) sqllib.TableIdentifier {
+	return NewTableIdentifier(topicConfig.Database, topicConfig.Schema, table)
+}
+
+func (s *Store) Append(ctx context.Context, tableData *optimization.TableData, whClient *webhooks.Client, _ bool) error {
+	// For Kafka, append and merge are identical - we always produce new messages
+	if _, err := s.Merge(ctx, tableData, whClient); err != nil {
+		return fmt.Errorf("failed to append: %w", err)
+	}
+	return nil
+}
+
+// topicFor returns the topic that [tableData] should be written to.
+func (s *Store) topicFor(tableData *optimization.TableData) string {
+	topicConfig := tableData.TopicConfig()
+	return s.config.KafkaSink.TopicName(topicConfig.Database, topicConfig.Schema, tableData.Name(), topicConfig.Topic)
+}
+
+func (s *Store) buildValueEncoder(ctx context.Context, topic string, tableName string, cols []columns.Column) (valueEncoder, error) {
+	cfg := s.config.SharedDestinationSettings
+	if s.config.KafkaSink.GetOutputFormat() != config.KafkaSinkOutputAvro {
+		return newJSONEncoder(cols, cfg), nil
+	}
+
+	schema, err := buildAvroSchema(tableName, cols)
+	if err != nil {
+		return nil, err
+	}
+
+	// TopicRecordNameStrategy, so that tables that share a topic don't have to share a schema.
+	subject := topic + "-" + schema.fullName
+	schemaID, err := s.registry.Register(ctx, subject, schemaregistry.Schema{Schema: schema.schema.String(), SchemaType: schemaregistry.Avro})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(row map[string]any) ([]byte, error) {
+		payload, err := schema.encode(row, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return schemaregistry.BuildWireFormat(schemaID, payload), nil
+	}, nil
+}
+
+// Merge produces a message for every row in [tableData], the messages are only acknowledged once they have been written to all in-sync replicas.
+func (s *Store) Merge(ctx context.Context, tableData *optimization.TableData, _ *webhooks.Client) (bool, error) {
+	if tableData.ShouldSkipUpdate() {
+		return false, nil
+	}
+
+	var cols []columns.Column
+	for _, col := range tableData.ReadOnlyInMemoryCols().ValidColumns() {
+		if col.Name() == constants.OnlySetDeleteColumnMarker {
+			continue
+		}
+
+		cols = append(cols, col)
+	}
+
+	var primaryKeys []columns.Column
+	for _, pk := range tableData.PrimaryKeys() {
+		col, ok := tableData.ReadOnlyInMemoryCols().GetColumn(pk)
+		if !ok {
+			return false, fmt.Errorf("primary key %q does not exist in the columns", pk)
+		}
+
+		primaryKeys = append(primaryKeys, col)
+	}
+
+	topic := s.topicFor(tableData)
+	encodeValue, err := s.buildValueEncoder(ctx, topic, tableData.Name(), cols)
+	if err != nil {
+		return false, fmt.Errorf("failed to build value encoder: %w", err)
+	}
+
+	records := make([]*kgo.Record, 0, tableData.NumberOfRows())
+	for row, err := range tableData.AllRows() {
+		if err != nil {
+			return false, fmt.Errorf("failed to read row: %w", err)
+		}
+
+		data := row.GetData()
+		key, err := buildKey(data, primaryKeys, s.config.SharedDestinationSettings)
+		if err != nil {
+			return false, err
+		}
+
+		value, err := encodeValue(data)
+		if err != nil {
+			return false, fmt.Errorf("failed to encode row: %w", err)
+		}
+
+		headers, err := buildHeaders(data, tableData.TopicConfig(), tableData.Name())
+		if err != nil {
+			return false, err
+		}
+
+		records = append(records, &kgo.Record{Topic: topic, Key: key, Value: value, Headers: headers})
+	}
+
+	if len(records) == 0 {
+		return false, nil
+	}
+
+	if err = s.producer.ProduceSync(ctx, records...).FirstErr(); err != nil {
+		return false, fmt.Errorf("failed to produce to %q: %w", topic, err)
+	}
+
+	slog.Info("Successfully produced records to Kafka", slog.String("topic", topic), slog.Int("recordCount", len(records)))
+	return true, nil
+}
+
+func (s *Store) IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if db.IsRetryableError(err) {
+		return true
+	}
+
+	var kafkaErr *kerr.Error
+	if errors.As(err, &kafkaErr) {
+		return kafkaErr.Retriable
+	}
+
+	return errors.Is(err, kgo.ErrRecordTimeout)
+}
+
+func (s *Store) DropTable(_ context.Context, tableID sqllib.TableIdentifier) error {
+	// Deleting topics would also affect any other producer or consumer of the topic, so this is left to the operator.
+	slog.Warn("Dropping Kafka topics is not supported, skipping", slog.String("table", tableID.FullyQualifiedName()))
+	return nil
+}
+
+func LoadStore(ctx context.Context, cfg config.Config) (*Store, error) {
+	if cfg.KafkaSink == nil {
+		return nil, fmt.Errorf("kafka sink config is nil")
+	}
+
+	if err := cfg.KafkaSink.Validate(); err != nil {
+		return nil, err
+	}
+
+	settings := cfg.KafkaSink
+	kafkaConn := kafkalib.NewConnection(settings.EnableAWSMSKIAM, settings.DisableTLS, settings.Username, settings.Password, kafkalib.DefaultTimeout)
+	clientOpts, err := kafkaConn.ClientOptions(ctx, strings.Split(settings.BootstrapServer, ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client options: %w", err)
+	}
+
+	// Idempotent writes are enabled by default in franz-go and require acks from all in-sync replicas.
+	clientOpts = append(clientOpts, kgo.RequiredAcks(kgo.AllISRAcks()))
+	if settings.AllowAutoTopicCreation {
+		clientOpts = append(clientOpts, kgo.AllowAutoTopicCreation())
+	}
+
+	client, err := kgo.NewClient(clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+
+	if err = client.Ping(ctx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Kafka: %w", err)
+	}
+
+	store := &Store{
+		config:   cfg,
+		producer: client,
+	}
+
+	if settings.GetOutputFormat() == config.KafkaSinkOutputAvro {
+		store.registry = schemaregistry.NewClient(settings.SchemaRegistry.URL, settings.SchemaRegistry.Username, settings.SchemaRegistry.Password)
+	}
+
+	return store, nil
+}
@@ -0,0 +1,192 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/numbers"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+type fakeProducer struct {
+	records []*kgo.Record
+	err     error
+}
+
+func (f *fakeProducer) ProduceSync(_ context.Context, rs ...*kgo.Record) kgo.ProduceResults {
+	var results kgo.ProduceResults
+	for _, r := range rs {
+		if f.err == nil {
+			f.records = append(f.records, r)
+		}
+		results = append(results, kgo.ProduceResult{Record: r, Err: f.err})
+	}
+	return results
+}
+
+func headersToMap(headers []kgo.RecordHeader) map[string]string {
+	out := make(map[string]string, len(headers))
+	for _, header := range headers {
+		out[header.Key] = string(header.Value)
+	}
+	return out
+}
+
+func newTestTableData() *optimization.TableData {
+	cols := columns.NewColumns(nil)
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn("price", typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(10, 2))))
+	cols.AddColumn(columns.NewColumn("tags", typing.Struct))
+	cols.AddColumn(columns.NewColumn("updated_at", typing.TimestampTZ))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+	cols.AddColumn(columns.NewColumn(constants.OperationColumnMarker, typing.String))
+
+	topicConfig := kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "dbserver1.public.orders"}
+	tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, topicConfig, "orders")
+	for id, row := range []map[string]any{
+		{"name": "order 1", "price": decimal.NewDecimal(numbers.MustParseDecimal("12.34")), "tags": map[string]any{"a": "b"}, "updated_at": time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), constants.OperationColumnMarker: "c"},
+		{"name": "order 2", constants.OperationColumnMarker: "d", constants.DeleteColumnMarker: true},
+	} {
+		row["id"] = int64(id + 1)
+		if _, ok := row[constants.DeleteColumnMarker]; !ok {
+			row[constants.DeleteColumnMarker] = false
+		}
+		if err := tableData.InsertRow(fmt.Sprint(id+1), row, row[constants.DeleteColumnMarker].(bool)); err != nil {
+			panic(err)
+		}
+	}
+
+	return tableData
+}
+
+func TestStore_Merge_JSON(t *testing.T) {
+	producer := &fakeProducer{}
+	store := &Store{
+		config:   config.Config{Output: constants.KafkaSink, KafkaSink: &config.KafkaSinkSettings{BootstrapServer: "localhost:9092"}},
+		producer: producer,
+	}
+
+	commit, err := store.Merge(t.Context(), newTestTableData(), nil)
+	assert.NoError(t, err)
+	assert.True(t, commit)
+	assert.Len(t, producer.records, 2)
+
+	records := make(map[string]*kgo.Record)
+	for _, record := range producer.records {
+		assert.Equal(t, "shop.public.orders", record.Topic)
+		records[string(record.Key)] = record
+	}
+
+	{
+		// Insert
+		record := records[`{"id":1}`]
+		var value map[string]any
+		assert.NoError(t, json.Unmarshal(record.Value, &value))
+		assert.Equal(t, map[string]any{
+			"id":                            float64(1),
+			"name":                          "order 1",
+			"price":                         "12.34",
+			"tags":                          map[string]any{"a": "b"},
+			"updated_at":                    "2025-01-02T03:04:05Z",
+			constants.DeleteColumnMarker:    false,
+			constants.OperationColumnMarker: "c",
+		}, value)
+
+		assert.Equal(t, map[string]string{
+			HeaderOperation:      "c",
+			HeaderDeleted:        "false",
+			HeaderSourceDatabase: "shop",
+			HeaderSourceSchema:   "public",
+			HeaderSourceTable:    "orders",
+			HeaderSourceTopic:    "dbserver1.public.orders",
+		}, headersToMap(record.Headers))
+	}
+	{
+		// Delete
+		record := records[`{"id":2}`]
+		headers := headersToMap(record.Headers)
+		assert.Equal(t, "d", headers[HeaderOperation])
+		assert.Equal(t, "true", headers[HeaderDeleted])
+	}
+	{
+		// Produce failure
+		store.producer = &fakeProducer{err: kerr.NotEnoughReplicas}
+		_, err = store.Merge(t.Context(), newTestTableData(), nil)
+		assert.ErrorContains(t, err, `failed to produce to "shop.public.orders"`)
+		assert.True(t, store.IsRetryableError(err))
+	}
+}
+
+func TestStore_Merge_Avro(t *testing.T) {
+	var registered schemaregistry.Schema
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subjects/cdc-artie.orders/versions", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&registered))
+		_, _ = w.Write([]byte(`{"id":42}`))
+	}))
+	defer server.Close()
+
+	producer := &fakeProducer{}
+	store := &Store{
+		config: config.Config{Output: constants.KafkaSink, KafkaSink: &config.KafkaSinkSettings{
+			BootstrapServer: "localhost:9092",
+			TopicTemplate:   "cdc",
+			OutputFormat:    config.KafkaSinkOutputAvro,
+			SchemaRegistry:  &kafkalib.SchemaRegistry{URL: server.URL},
+		}},
+		producer: producer,
+		registry: schemaregistry.NewClient(server.URL, "", ""),
+	}
+
+	commit, err := store.Merge(t.Context(), newTestTableData(), nil)
+	assert.NoError(t, err)
+	assert.True(t, commit)
+	assert.Len(t, producer.records, 2)
+
+	schema, err := avro.Parse(registered.Schema)
+	assert.NoError(t, err)
+	for _, record := range producer.records {
+		assert.Equal(t, "cdc", record.Topic)
+
+		schemaID, payload, err := schemaregistry.ParseWireFormat(record.Value)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, schemaID)
+
+		var value map[string]any
+		assert.NoError(t, avro.Unmarshal(schema, payload, &value))
+		if string(record.Key) != `{"id":1}` {
+			continue
+		}
+
+		assert.Equal(t, int64(1), value["id"])
+		assert.Equal(t, "order 1", value["name"])
+		assert.Equal(t, `{"a":"b"}`, value["tags"])
+		assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), value["updated_at"])
+	}
+}
+
+func TestStore_IsRetryableError(t *testing.T) {
+	store := &Store{}
+	assert.False(t, store.IsRetryableError(nil))
+	assert.True(t, store.IsRetryableError(fmt.Errorf("failed to produce: %w", kerr.LeaderNotAvailable)))
+	assert.True(t, store.IsRetryableError(kgo.ErrRecordTimeout))
+	assert.False(t, store.IsRetryableError(kerr.TopicAuthorizationFailed))
+}
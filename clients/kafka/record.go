@@ -0,0 +1,99 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/converters"
+	"github.com/artie-labs/transfer/lib/typing/values"
+)
+
+const (
+	// HeaderOperation is only set when the row contains [constants.OperationColumnMarker] (includeArtieOperation).
+	HeaderOperation      = "artie.operation"
+	HeaderDeleted        = "artie.deleted"
+	HeaderSourceDatabase = "artie.source.database"
+	HeaderSourceSchema   = "artie.source.schema"
+	HeaderSourceTable    = "artie.source.table"
+	HeaderSourceTopic    = "artie.source.topic"
+	// HeaderSourceMetadata is only set when the row contains [constants.SourceMetadataColumnMarker] (includeSourceMetadata).
+	HeaderSourceMetadata = "artie.source.metadata"
+)
+
+// valueEncoder serializes a row into the message value.
+type valueEncoder func(row map[string]any) ([]byte, error)
+
+func toJSONObject(row map[string]any, cols []columns.Column, cfg config.SharedDestinationSettings) (map[string]any, error) {
+	out := make(map[string]any, len(cols))
+	for _, col := range cols {
+		value, err := values.ToJSONValue(row[col.Name()], col.KindDetails, converters.GetStringConverterOpts{UseNewStringMethod: cfg.UseNewStringMethod})
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert column %q: %w", col.Name(), err)
+		}
+
+		out[col.Name()] = value
+	}
+
+	return out, nil
+}
+
+func newJSONEncoder(cols []columns.Column, cfg config.SharedDestinationSettings) valueEncoder {
+	return func(row map[string]any) ([]byte, error) {
+		object, err := toJSONObject(row, cols, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(object)
+	}
+}
+
+// buildKey returns the JSON-encoded primary key, this is used as the message key regardless of the output format.
+func buildKey(row map[string]any, primaryKeys []columns.Column, cfg config.SharedDestinationSettings) ([]byte, error) {
+	object, err := toJSONObject(row, primaryKeys, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build key: %w", err)
+	}
+
+	return json.Marshal(object)
+}
+
+func buildHeaders(row map[string]any, topicConfig kafkalib.TopicConfig, tableName string) ([]kgo.RecordHeader, error) {
+	deleted, _ := row[constants.DeleteColumnMarker].(bool)
+	headers := []kgo.RecordHeader{
+		{Key: HeaderDeleted, Value: fmt.Appendf(nil, "%t", deleted)},
+		{Key: HeaderSourceDatabase, Value: []byte(topicConfig.Database)},
+		{Key: HeaderSourceSchema, Value: []byte(topicConfig.Schema)},
+		{Key: HeaderSourceTable, Value: []byte(tableName)},
+		{Key: HeaderSourceTopic, Value: []byte(topicConfig.Topic)},
+	}
+
+	if operation, ok := row[constants.OperationColumnMarker].(string); ok {
+		headers = append(headers, kgo.RecordHeader{Key: HeaderOperation, Value: []byte(operation)})
+	}
+
+	if metadata, ok := row[constants.SourceMetadataColumnMarker]; ok && metadata != nil {
+		var value []byte
+		switch castedMetadata := metadata.(type) {
+		case string:
+			value = []byte(castedMetadata)
+		case []byte:
+			value = castedMetadata
+		default:
+			var err error
+			if value, err = json.Marshal(castedMetadata); err != nil {
+				return nil, fmt.Errorf("failed to marshal source metadata: %w", err)
+			}
+		}
+
+		headers = append(headers, kgo.RecordHeader{Key: HeaderSourceMetadata, Value: value})
+	}
+
+	return headers, nil
+}
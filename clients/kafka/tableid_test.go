@@ -0,0 +1,29 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableIdentifier(t *testing.T) {
+	ti := NewTableIdentifier("mydb", "public", "users")
+
+	assert.Equal(t, "mydb", ti.Database())
+	assert.Equal(t, "public", ti.Schema())
+	assert.Equal(t, "users", ti.Table())
+	assert.Equal(t, "users", ti.EscapedTable())
+	assert.Equal(t, "mydb.public.users", ti.FullyQualifiedName())
+	assert.Equal(t, "public.users", NewTableIdentifier("", "public", "users").FullyQualifiedName())
+	assert.Equal(t, "mydb.users", NewTableIdentifier("mydb", "", "users").FullyQualifiedName())
+}
+
+func TestTableIdentifier_WithTable(t *testing.T) {
+	ti := NewTableIdentifier("mydb", "public", "users")
+	newTI, ok := ti.WithTable("orders").(TableIdentifier)
+	assert.True(t, ok)
+	assert.Equal(t, "mydb.public.orders", newTI.FullyQualifiedName())
+
+	// Kafka doesn't support temporary tables, so this should return the same
+	assert.False(t, ti.WithTemporaryTable(true).TemporaryTable())
+}
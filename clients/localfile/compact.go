@@ -0,0 +1,200 @@
+package localfile
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/artie-labs/transfer/clients/s3"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+// stateFileName holds the compacted rows so that the output file can be rebuilt without having to read it back.
+const stateFileName = ".artie_state.gob"
+
+// compactState is what gets persisted in [stateFileName], the value types are registered with gob by the optimization package.
+type compactState struct {
+	// Columns preserves the order of the columns across flushes.
+	Columns []string
+	// Rows is keyed by the primary key.
+	Rows map[string]map[string]any
+}
+
+func readState(fp string) (compactState, error) {
+	data, err := os.ReadFile(fp)
+	if errors.Is(err, os.ErrNotExist) {
+		return compactState{Rows: make(map[string]map[string]any)}, nil
+	} else if err != nil {
+		return compactState{}, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state compactState
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return compactState{}, fmt.Errorf("failed to decode state file: %w", err)
+	}
+
+	if state.Rows == nil {
+		state.Rows = make(map[string]map[string]any)
+	}
+
+	return state, nil
+}
+
+func writeState(fp string, state compactState) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return fmt.Errorf("failed to encode state file: %w", err)
+	}
+
+	return writeFileAtomically(fp, buf.Bytes())
+}
+
+func writeFileAtomically(fp string, data []byte) error {
+	tmpPath := fp + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return os.Rename(tmpPath, fp)
+}
+
+func primaryKeyValue(data map[string]any, primaryKeys []string) string {
+	var key string
+	for _, pk := range primaryKeys {
+		key += fmt.Sprintf("%s=%v", pk, data[pk])
+	}
+
+	return key
+}
+
+// mergeRows applies [rows] on top of [state] the same way a SQL merge would.
+func mergeRows(state *compactState, rows iter.Seq2[optimization.Row, error], primaryKeys []string, softDelete bool) error {
+	for row, err := range rows {
+		if err != nil {
+			return fmt.Errorf("failed to read row: %w", err)
+		}
+
+		data := row.GetData()
+		key := primaryKeyValue(data, primaryKeys)
+		existing, exists := state.Rows[key]
+
+		deleted, _ := data[constants.DeleteColumnMarker].(bool)
+		if deleted && !softDelete {
+			delete(state.Rows, key)
+			continue
+		}
+
+		if onlySetDelete, _ := data[constants.OnlySetDeleteColumnMarker].(bool); onlySetDelete && exists {
+			// The delete event won't have the full row, so we should only be updating the delete marker.
+			existing[constants.DeleteColumnMarker] = deleted
+			continue
+		}
+
+		merged := maps.Clone(data)
+		delete(merged, constants.OnlySetDeleteColumnMarker)
+		for col, value := range merged {
+			if value == constants.ToastUnavailableValuePlaceholder && exists {
+				merged[col] = existing[col]
+			}
+		}
+
+		state.Rows[key] = merged
+	}
+
+	return nil
+}
+
+// buildColumns returns the columns for the compacted file, columns that are only in [state] will have their kind inferred from the data.
+func buildColumns(state *compactState, tableData *optimization.TableData) ([]columns.Column, error) {
+	inMemoryCols := tableData.ReadOnlyInMemoryCols()
+	for _, col := range inMemoryCols.ValidColumns() {
+		if col.Name() != constants.OnlySetDeleteColumnMarker && !slices.Contains(state.Columns, col.Name()) {
+			state.Columns = append(state.Columns, col.Name())
+		}
+	}
+
+	out := make([]columns.Column, 0, len(state.Columns))
+	for _, name := range state.Columns {
+		if col, ok := inMemoryCols.GetColumn(name); ok && col.KindDetails != typing.Invalid {
+			out = append(out, col)
+			continue
+		}
+
+		kindDetails := typing.String
+		for _, row := range state.Rows {
+			if value := row[name]; value != nil {
+				var err error
+				if kindDetails, err = typing.ParseValue(name, nil, value); err != nil {
+					return nil, fmt.Errorf("failed to infer the type of column %q: %w", name, err)
+				}
+				break
+			}
+		}
+
+		out = append(out, columns.NewColumn(name, kindDetails))
+	}
+
+	return out, nil
+}
+
+// compact merges [tableData] into the table's state and rewrites the compacted file.
+func (s *Store) compact(tableData *optimization.TableData) error {
+	settings := s.config.LocalFile
+	tableID := s.tableID(tableData)
+	directory := s.tableDirectory(tableID)
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	statePath := filepath.Join(directory, stateFileName)
+	state, err := readState(statePath)
+	if err != nil {
+		return err
+	}
+
+	if err = mergeRows(&state, tableData.AllRows(), tableData.PrimaryKeys(), tableData.TopicConfig().SoftDelete); err != nil {
+		return err
+	}
+
+	cols, err := buildColumns(&state, tableData)
+	if err != nil {
+		return err
+	}
+
+	rows := func(yield func(optimization.Row, error) bool) {
+		for _, key := range slices.Sorted(maps.Keys(state.Rows)) {
+			if !yield(optimization.NewRow(state.Rows[key]), nil) {
+				return
+			}
+		}
+	}
+
+	outputPath := filepath.Join(directory, tableID.FullyQualifiedName()+s3.FileExtension(settings.GetOutputFormat(), settings.FormatOptions))
+	tmpPath := outputPath + ".tmp"
+	if err = s3.WriteFile(cols, rows, tmpPath, settings.GetOutputFormat(), settings.FormatOptions, s.config.SharedDestinationSettings); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err = os.Rename(tmpPath, outputPath); err != nil {
+		return fmt.Errorf("failed to replace compacted file: %w", err)
+	}
+
+	// The state is written last, if we fail before this the next flush will re-apply the same rows which is idempotent.
+	if err = writeState(statePath, state); err != nil {
+		return err
+	}
+
+	slog.Info("Successfully compacted file", slog.String("filePath", outputPath), slog.Int("rows", len(state.Rows)))
+	return nil
+}
@@ -0,0 +1,191 @@
+package localfile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/artie-labs/transfer/clients/s3"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	sqllib "github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/webhooks"
+)
+
+type Store struct {
+	config config.Config
+}
+
+func (s *Store) Label() constants.DestinationKind {
+	return s.config.Output
+}
+
+func (s *Store) GetConfig() config.Config {
+	return s.config
+}
+
+func (s *Store) IsOLTP() bool {
+	return false
+}
+
+func (s *Store) Validate() error {
+	return s.config.LocalFile.Validate()
+}
+
+func (s *Store) IdentifierFor(topicConfig kafkalib.DatabaseAndSchemaPair, table string) sqllib.TableIdentifier {
+	return NewTableIdentifier(topicConfig.Database, topicConfig.Schema, table, s.config.LocalFile.TableNameSeparator)
+}
+
+func (s *Store) tableID(tableData *optimization.TableData) TableIdentifier {
+	return NewTableIdentifier(tableData.TopicConfig().Database, tableData.TopicConfig().Schema, tableData.Name(), s.config.LocalFile.TableNameSeparator)
+}
+
+// tableDirectory is where all the files for a table are written to, unless the topic has an object key template.
+func (s *Store) tableDirectory(tableID TableIdentifier) string {
+	return filepath.Join(s.config.LocalFile.Directory, tableID.FullyQualifiedName())
+}
+
+// ObjectPrefix mirrors the S3 layout without the folder name:
+// > fullyQualifiedTableName/date=YYYY-MM-DD
+func (s *Store) ObjectPrefix(tableData *optimization.TableData) string {
+	// Adding date= prefix so that it adheres to the partitioning format for Hive.
+	return strings.Join([]string{s.tableID(tableData).FullyQualifiedName(), fmt.Sprintf("date=%s", time.Now().Format(time.DateOnly))}, "/")
+}
+
+func (s *Store) Append(_ context.Context, tableData *optimization.TableData, _ *webhooks.Client, _ bool) error {
+	if err := s.writeFiles(tableData); err != nil {
+		return fmt.Errorf("failed to append: %w", err)
+	}
+
+	return nil
+}
+
+// Merge will write a new file for every flush, if [config.LocalFileSettings.Compact] is enabled the rows are merged into a single file per table instead.
+func (s *Store) Merge(_ context.Context, tableData *optimization.TableData, _ *webhooks.Client) (bool, error) {
+	if tableData.ShouldSkipUpdate() {
+		return false, nil
+	}
+
+	if s.config.LocalFile.Compact {
+		if err := s.compact(tableData); err != nil {
+			return false, fmt.Errorf("failed to compact: %w", err)
+		}
+
+		return true, nil
+	}
+
+	if err := s.writeFiles(tableData); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// writeFiles writes the rows into new files, this uses the same layout and file names as the object storage destinations.
+func (s *Store) writeFiles(tableData *optimization.TableData) error {
+	settings := s.config.LocalFile
+	groups, err := s3.GroupRowsByPrefix(tableData, "", s.tableID(tableData).FullyQualifiedName(), s.ObjectPrefix(tableData))
+	if err != nil {
+		return err
+	}
+
+	return s3.WriteObjects(tableData, groups, settings.GetOutputFormat(), settings.FormatOptions, s.config.SharedDestinationSettings, func(prefix string, filePath string) error {
+		outputPath := filepath.Join(settings.Directory, filepath.FromSlash(prefix), filepath.Base(filePath))
+		if err := copyFile(filePath, outputPath); err != nil {
+			return err
+		}
+
+		slog.Info("Successfully wrote file", slog.String("format", string(settings.GetOutputFormat())), slog.String("filePath", outputPath))
+		return nil
+	})
+}
+
+// copyFile copies [src] to [dst] through a temporary file so that readers never see a partially written file.
+func copyFile(src string, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer in.Close()
+
+	tmpPath := dst + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	if err = out.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+
+	return os.Rename(tmpPath, dst)
+}
+
+func (s *Store) IsRetryableError(_ error) bool {
+	return false // not supported for local files
+}
+
+func (s *Store) DropTable(_ context.Context, tableID sqllib.TableIdentifier) error {
+	castedTableID, ok := tableID.(TableIdentifier)
+	if !ok {
+		return fmt.Errorf("expected tableID to be a TableIdentifier, got %T", tableID)
+	}
+
+	return os.RemoveAll(s.tableDirectory(castedTableID))
+}
+
+func LoadStore(_ context.Context, cfg config.Config) (*Store, error) {
+	if err := cfg.LocalFile.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(cfg.LocalFile.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %q: %w", cfg.LocalFile.Directory, err)
+	}
+
+	return &Store{config: cfg}, nil
+}
+
+// LoadReadOnlyStore is like [LoadStore], but it doesn't create the output directory.
+func LoadReadOnlyStore(cfg config.Config) (*Store, error) {
+	if err := cfg.LocalFile.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Store{config: cfg}, nil
+}
+
+// CheckConnectivity checks that the output directory is a directory if it exists, it will be created on the first flush otherwise.
+func (s *Store) CheckConnectivity(_ context.Context) error {
+	info, err := os.Stat(s.config.LocalFile.Directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat directory %q: %w", s.config.LocalFile.Directory, err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%q is not a directory", s.config.LocalFile.Directory)
+	}
+
+	return nil
+}
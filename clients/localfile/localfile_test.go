@@ -0,0 +1,194 @@
+package localfile
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func newStore(t *testing.T, compact bool) *Store {
+	store, err := LoadStore(t.Context(), config.Config{
+		Output: constants.LocalFile,
+		LocalFile: &config.LocalFileSettings{
+			Directory:     filepath.Join(t.TempDir(), "out"),
+			OutputFormat:  constants.JSONLFormat,
+			FormatOptions: config.FileFormatOptions{Compression: config.NoCompression},
+			Compact:       compact,
+		},
+	})
+	assert.NoError(t, err)
+	return store
+}
+
+func newTableData(t *testing.T, topicConfig kafkalib.TopicConfig, rows ...map[string]any) *optimization.TableData {
+	cols := columns.NewColumns(nil)
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+	cols.AddColumn(columns.NewColumn(constants.OnlySetDeleteColumnMarker, typing.Boolean))
+
+	tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, topicConfig, "orders")
+	tableData.SetLatestTimestamp(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	for _, row := range rows {
+		deleted, _ := row[constants.DeleteColumnMarker].(bool)
+		assert.NoError(t, tableData.InsertRow(fmt.Sprint(row["id"]), row, deleted))
+	}
+
+	return tableData
+}
+
+func readJSONL(t *testing.T, fp string) []map[string]any {
+	file, err := os.Open(fp)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var out []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var row map[string]any
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+		out = append(out, row)
+	}
+
+	assert.NoError(t, scanner.Err())
+	return out
+}
+
+func listFiles(t *testing.T, directory string) []string {
+	var files []string
+	assert.NoError(t, filepath.WalkDir(directory, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			relPath, _ := filepath.Rel(directory, path)
+			files = append(files, filepath.ToSlash(relPath))
+		}
+		return err
+	}))
+	return files
+}
+
+func TestLoadStore(t *testing.T) {
+	{
+		// Invalid settings
+		_, err := LoadStore(t.Context(), config.Config{Output: constants.LocalFile, LocalFile: &config.LocalFileSettings{}})
+		assert.ErrorContains(t, err, "local file directory is required")
+	}
+	{
+		// The directory is created
+		store := newStore(t, false)
+		_, err := os.Stat(store.config.LocalFile.Directory)
+		assert.NoError(t, err)
+	}
+}
+
+func TestLoadReadOnlyStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "output")
+	store, err := LoadReadOnlyStore(config.Config{Output: constants.LocalFile, LocalFile: &config.LocalFileSettings{Directory: dir}})
+	assert.NoError(t, err)
+	{
+		// The directory is not created
+		assert.NoError(t, store.CheckConnectivity(t.Context()))
+		_, err = os.Stat(dir)
+		assert.True(t, os.IsNotExist(err))
+	}
+	{
+		// The directory is a file
+		assert.NoError(t, os.WriteFile(dir, []byte("hello"), 0o644))
+		assert.ErrorContains(t, store.CheckConnectivity(t.Context()), "is not a directory")
+	}
+}
+
+func TestStore_Merge_Append(t *testing.T) {
+	store := newStore(t, false)
+	topicConfig := kafkalib.TopicConfig{Database: "shop", Schema: "public"}
+	for i := range 2 {
+		commit, err := store.Merge(t.Context(), newTableData(t, topicConfig, map[string]any{"id": int64(i), "name": "foo", constants.DeleteColumnMarker: false}), nil)
+		assert.NoError(t, err)
+		assert.True(t, commit)
+	}
+
+	// Every flush writes a new file
+	files := listFiles(t, store.config.LocalFile.Directory)
+	assert.Len(t, files, 2)
+	for _, file := range files {
+		assert.True(t, strings.HasPrefix(file, fmt.Sprintf("shop.public.orders/date=%s/1735787045000_", time.Now().Format(time.DateOnly))), file)
+		assert.True(t, strings.HasSuffix(file, ".jsonl"), file)
+	}
+
+	{
+		// Drop table
+		tableID := store.IdentifierFor(topicConfig.BuildDatabaseAndSchemaPair(), "orders")
+		assert.NoError(t, store.DropTable(t.Context(), tableID))
+		assert.Empty(t, listFiles(t, store.config.LocalFile.Directory))
+	}
+}
+
+func TestStore_Merge_ObjectKeyTemplate(t *testing.T) {
+	store := newStore(t, false)
+	topicConfig := kafkalib.TopicConfig{Database: "shop", Schema: "public", ObjectKeyTemplate: "{table}/dt={yyyy}-{MM}-{dd}"}
+	assert.NoError(t, store.Append(t.Context(), newTableData(t, topicConfig, map[string]any{"id": int64(1), "name": "foo"}), nil, false))
+
+	files := listFiles(t, store.config.LocalFile.Directory)
+	assert.Len(t, files, 1)
+	assert.True(t, strings.HasPrefix(files[0], "orders/dt=2025-01-02/"), files[0])
+}
+
+func TestStore_Merge_Compact(t *testing.T) {
+	store := newStore(t, true)
+	topicConfig := kafkalib.TopicConfig{Database: "shop", Schema: "public"}
+	compactedFile := filepath.Join(store.config.LocalFile.Directory, "shop.public.orders", "shop.public.orders.jsonl")
+	{
+		// Initial load
+		commit, err := store.Merge(t.Context(), newTableData(t, topicConfig,
+			map[string]any{"id": int64(1), "name": "foo", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(2), "name": "bar", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(3), "name": "baz", constants.DeleteColumnMarker: false},
+		), nil)
+		assert.NoError(t, err)
+		assert.True(t, commit)
+		assert.Len(t, readJSONL(t, compactedFile), 3)
+	}
+	{
+		// Update, delete and TOAST
+		_, err := store.Merge(t.Context(), newTableData(t, topicConfig,
+			map[string]any{"id": int64(1), "name": "updated", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(2), constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true},
+			map[string]any{"id": int64(3), "name": constants.ToastUnavailableValuePlaceholder, constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(4), "name": "new", constants.DeleteColumnMarker: false},
+		), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []map[string]any{
+			{"id": float64(1), "name": "updated", constants.DeleteColumnMarker: false},
+			{"id": float64(3), "name": "baz", constants.DeleteColumnMarker: false},
+			{"id": float64(4), "name": "new", constants.DeleteColumnMarker: false},
+		}, readJSONL(t, compactedFile))
+	}
+	{
+		// Only the compacted file and the state are kept
+		assert.ElementsMatch(t, []string{"shop.public.orders/shop.public.orders.jsonl", "shop.public.orders/" + stateFileName}, listFiles(t, store.config.LocalFile.Directory))
+	}
+	{
+		// Soft deletes keep the row around
+		softDelete := kafkalib.TopicConfig{Database: "shop", Schema: "public", SoftDelete: true}
+		_, err := store.Merge(t.Context(), newTableData(t, softDelete,
+			map[string]any{"id": int64(4), constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true},
+		), nil)
+		assert.NoError(t, err)
+		rows := readJSONL(t, compactedFile)
+		assert.Len(t, rows, 3)
+		assert.Equal(t, map[string]any{"id": float64(4), "name": "new", constants.DeleteColumnMarker: true}, rows[2])
+	}
+}
@@ -0,0 +1,89 @@
+package dialect
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/array"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/converters"
+	"github.com/artie-labs/transfer/lib/typing/values"
+)
+
+// ConvertValue converts [value] into a type that can be appended to or bound to a DuckDB column of kind [kd].
+func ConvertValue(value any, kd typing.KindDetails) (driver.Value, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch kd.Kind {
+	case typing.String.Kind:
+		str, err := values.ToStringOpts(value, kd, converters.GetStringConverterOpts{
+			UseNewStringMethod: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return str, nil
+	case typing.Boolean.Kind:
+		castedValue, err := typing.AssertType[bool](value)
+		if err != nil {
+			return nil, err
+		}
+		return castedValue, nil
+	case typing.Struct.Kind:
+		// For structs, convert to JSON string
+		str, err := values.ToString(value, kd)
+		if err != nil {
+			return nil, err
+		}
+		return str, nil
+	case typing.Array.Kind:
+		arrayStr, err := array.InterfaceToArrayString(value, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert array: %w", err)
+		}
+		return arrayStr, nil
+	case typing.Integer.Kind, typing.Float.Kind:
+		// Return as-is, DuckDB appender will handle conversion
+		return value, nil
+	case typing.EDecimal.Kind:
+		// Convert decimal to string for DuckDB
+		str, err := values.ToString(value, kd)
+		if err != nil {
+			return nil, err
+		}
+		return str, nil
+	case typing.Date.Kind:
+		// Parse date strings into time.Time for DuckDB appender
+		timeVal, err := typing.ParseDateFromAny(value)
+		if err != nil {
+			return nil, err
+		}
+		return timeVal, nil
+	case typing.TimeKindDetails.Kind:
+		// Parse time strings into time.Time for DuckDB appender
+		timeVal, err := typing.ParseTimeFromAny(value)
+		if err != nil {
+			return nil, err
+		}
+		return timeVal, nil
+	case typing.TimestampNTZ.Kind:
+		// Parse timestamp without timezone into time.Time for DuckDB appender
+		timeVal, err := typing.ParseTimestampNTZFromAny(value)
+		if err != nil {
+			return nil, err
+		}
+		return timeVal, nil
+	case typing.TimestampTZ.Kind:
+		// Parse timestamp with timezone into time.Time for DuckDB appender
+		timeVal, err := typing.ParseTimestampTZFromAny(value)
+		if err != nil {
+			return nil, err
+		}
+		return timeVal, nil
+	default:
+		// For any other types, return as-is and let DuckDB handle it
+		return value, nil
+	}
+}
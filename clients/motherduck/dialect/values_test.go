@@ -0,0 +1,257 @@
+package dialect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+func TestConvertValue(t *testing.T) {
+	// Nil value
+	{
+		result, err := ConvertValue(nil, typing.String)
+		assert.NoError(t, err)
+		assert.Nil(t, result)
+	}
+
+	// String type
+	{
+		result, err := ConvertValue("hello world", typing.String)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", result)
+	}
+	{
+		// Non-string value for string type should be converted to string
+		result, err := ConvertValue(123, typing.String)
+		assert.NoError(t, err)
+		assert.Equal(t, "123", result)
+	}
+	{
+		// map[string]interface{} for string type should be JSON-marshaled
+		result, err := ConvertValue(map[string]interface{}{"key": "value"}, typing.String)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"key":"value"}`, result)
+	}
+
+	// Boolean type
+	{
+		result, err := ConvertValue(true, typing.Boolean)
+		assert.NoError(t, err)
+		assert.Equal(t, true, result)
+	}
+	{
+		result, err := ConvertValue(false, typing.Boolean)
+		assert.NoError(t, err)
+		assert.Equal(t, false, result)
+	}
+	{
+		// Non-boolean value for boolean type should fail
+		_, err := ConvertValue("true", typing.Boolean)
+		assert.Error(t, err)
+	}
+
+	// Integer type
+	{
+		result, err := ConvertValue(42, typing.BuildIntegerKind(typing.IntegerKind))
+		assert.NoError(t, err)
+		assert.Equal(t, 42, result)
+	}
+	{
+		result, err := ConvertValue(int64(9223372036854775807), typing.BuildIntegerKind(typing.BigIntegerKind))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(9223372036854775807), result)
+	}
+
+	// Float type
+	{
+		result, err := ConvertValue(3.14, typing.Float)
+		assert.NoError(t, err)
+		assert.Equal(t, 3.14, result)
+	}
+	{
+		result, err := ConvertValue(float32(2.5), typing.Float)
+		assert.NoError(t, err)
+		assert.Equal(t, float32(2.5), result)
+	}
+
+	// Decimal type - using string representation
+	{
+		result, err := ConvertValue("123.45", typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(10, 2)))
+		assert.NoError(t, err)
+		assert.IsType(t, "", result) // Should be string
+	}
+
+	// Date type
+	{
+		result, err := ConvertValue("2024-01-15", typing.Date)
+		assert.NoError(t, err)
+		assert.IsType(t, time.Time{}, result)
+	}
+	{
+		now := time.Now()
+		result, err := ConvertValue(now, typing.Date)
+		assert.NoError(t, err)
+		assert.IsType(t, time.Time{}, result)
+	}
+
+	// Time type
+	{
+		result, err := ConvertValue("14:30:00", typing.TimeKindDetails)
+		assert.NoError(t, err)
+		assert.IsType(t, time.Time{}, result)
+	}
+
+	// Timestamp NTZ
+	{
+		result, err := ConvertValue("2024-01-15T14:30:00", typing.TimestampNTZ)
+		assert.NoError(t, err)
+		assert.IsType(t, time.Time{}, result)
+	}
+
+	// Timestamp TZ
+	{
+		result, err := ConvertValue("2024-01-15T14:30:00Z", typing.TimestampTZ)
+		assert.NoError(t, err)
+		assert.IsType(t, time.Time{}, result)
+	}
+}
+
+func TestConvertValue_Struct(t *testing.T) {
+	// Struct as map
+	{
+		input := map[string]interface{}{
+			"name": "Alice",
+			"age":  30,
+		}
+		result, err := ConvertValue(input, typing.Struct)
+		assert.NoError(t, err)
+		assert.IsType(t, "", result)
+		assert.Contains(t, result.(string), "Alice")
+		assert.Contains(t, result.(string), "30")
+	}
+
+	// Struct as JSON string - values.ToString will JSON-encode it again
+	{
+		input := `{"name":"Bob","age":25}`
+		result, err := ConvertValue(input, typing.Struct)
+		assert.NoError(t, err)
+		// The string gets JSON-encoded by values.ToString(), so it's quoted
+		assert.IsType(t, "", result)
+		assert.Contains(t, result.(string), "Bob")
+	}
+
+	// Empty struct
+	{
+		input := map[string]interface{}{}
+		result, err := ConvertValue(input, typing.Struct)
+		assert.NoError(t, err)
+		assert.IsType(t, "", result)
+	}
+}
+
+func TestConvertValue_Array(t *testing.T) {
+	// Array as []interface{}
+	{
+		input := []interface{}{"apple", "banana", "cherry"}
+		result, err := ConvertValue(input, typing.Array)
+		assert.NoError(t, err)
+		assert.IsType(t, []string{}, result)
+		assert.Equal(t, []string{"apple", "banana", "cherry"}, result)
+	}
+
+	// Array as []string
+	{
+		input := []string{"red", "green", "blue"}
+		result, err := ConvertValue(input, typing.Array)
+		assert.NoError(t, err)
+		assert.IsType(t, []string{}, result)
+		resultSlice := result.([]string)
+		assert.Len(t, resultSlice, 3)
+		assert.Equal(t, "red", resultSlice[0])
+		assert.Equal(t, "green", resultSlice[1])
+		assert.Equal(t, "blue", resultSlice[2])
+	}
+
+	// Empty array
+	{
+		input := []interface{}{}
+		result, err := ConvertValue(input, typing.Array)
+		assert.NoError(t, err)
+		assert.IsType(t, []string{}, result)
+		assert.Len(t, result.([]string), 0)
+	}
+}
+
+func TestConvertValue_ArrayRoundTrip(t *testing.T) {
+	// This test verifies that arrays maintain their integrity through conversion
+	// Now arrays are converted to []string for DuckDB's text[] columns
+
+	// Simple string array
+	{
+		input := []string{"alpha", "beta", "gamma"}
+		expected := []string{"alpha", "beta", "gamma"}
+
+		result, err := ConvertValue(input, typing.Array)
+		assert.NoError(t, err)
+		assert.IsType(t, []string{}, result)
+
+		resultSlice := result.([]string)
+		assert.Equal(t, len(expected), len(resultSlice))
+		for i, expectedVal := range expected {
+			assert.Equal(t, expectedVal, resultSlice[i])
+		}
+	}
+
+	// Interface array - all elements converted to strings
+	{
+		input := []interface{}{"one", 2, true}
+		expected := []string{"one", "2", "true"}
+
+		result, err := ConvertValue(input, typing.Array)
+		assert.NoError(t, err)
+		assert.IsType(t, []string{}, result)
+
+		resultSlice := result.([]string)
+		assert.Equal(t, len(expected), len(resultSlice))
+		for i, expectedVal := range expected {
+			assert.Equal(t, expectedVal, resultSlice[i])
+		}
+	}
+}
+
+func TestConvertValue_DriverValue(t *testing.T) {
+	// Ensure returned values are valid driver.Value types
+
+	// String returns driver.Value
+	{
+		result, err := ConvertValue("test", typing.String)
+		assert.NoError(t, err)
+		assert.IsType(t, "", result)
+	}
+
+	// Boolean returns driver.Value
+	{
+		result, err := ConvertValue(true, typing.Boolean)
+		assert.NoError(t, err)
+		assert.IsType(t, true, result)
+	}
+
+	// Array returns driver.Value
+	{
+		result, err := ConvertValue([]string{"a", "b"}, typing.Array)
+		assert.NoError(t, err)
+		// DuckDB appender accepts []string for text[] columns
+		assert.IsType(t, []string{}, result)
+	}
+
+	// Nil returns nil driver.Value
+	{
+		result, err := ConvertValue(nil, typing.String)
+		assert.NoError(t, err)
+		assert.Nil(t, result)
+	}
+}
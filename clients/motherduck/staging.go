@@ -2,22 +2,17 @@ package motherduck
 
 import (
 	"context"
-	"database/sql/driver"
 	"fmt"
 
 	"github.com/artie-labs/ducktape/api/pkg/ducktape"
 
 	"github.com/artie-labs/transfer/clients/motherduck/dialect"
 	"github.com/artie-labs/transfer/clients/shared"
-	"github.com/artie-labs/transfer/lib/array"
 	"github.com/artie-labs/transfer/lib/destination/types"
 	"github.com/artie-labs/transfer/lib/optimization"
 	"github.com/artie-labs/transfer/lib/retry"
 	"github.com/artie-labs/transfer/lib/sql"
-	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/typing/columns"
-	"github.com/artie-labs/transfer/lib/typing/converters"
-	"github.com/artie-labs/transfer/lib/typing/values"
 )
 
 func (s Store) LoadDataIntoTable(ctx context.Context, tableData *optimization.TableData, dwh *types.DestinationTableConfig, tableID, _ sql.TableIdentifier, opts types.AdditionalSettings, createTempTable bool) error {
@@ -31,7 +26,7 @@ func (s Store) LoadDataIntoTable(ctx context.Context, tableData *optimization.Ta
 }
 
 func appendRows(ctx context.Context, store Store, tableData *optimization.TableData, dwh *types.DestinationTableConfig, tableID sql.TableIdentifier) error {
-	if len(tableData.Rows()) == 0 {
+	if tableData.NumberOfRows() == 0 {
 		return nil
 	}
 
@@ -57,7 +52,13 @@ func appendRows(ctx context.Context, store Store, tableData *optimization.TableD
 	}
 
 	streamIterator := func(yield func(ducktape.RowMessageResult) bool) {
-		for _, row := range tableData.Rows() {
+		for row, err := range tableData.AllRows() {
+			if err != nil {
+				errMsg := fmt.Sprintf("failed to read row while appending: %v", err)
+				yield(ducktape.RowMessageResult{Error: &errMsg})
+				return
+			}
+
 			var rowValues []any
 			for _, col := range cols {
 				// Skip columns that should not be included (e.g., invalid columns)
@@ -66,7 +67,7 @@ func appendRows(ctx context.Context, store Store, tableData *optimization.TableD
 				}
 
 				value, _ := row.GetValue(col.Name())
-				convertedValue, err := convertValue(value, col.KindDetails)
+				convertedValue, err := dialect.ConvertValue(value, col.KindDetails)
 				if err != nil {
 					errMsg := fmt.Sprintf("failed to convert value while appending: %v", err)
 					yield(ducktape.RowMessageResult{Error: &errMsg})
@@ -113,80 +114,3 @@ func appendRows(ctx context.Context, store Store, tableData *optimization.TableD
 
 	return nil
 }
-
-func convertValue(value any, kd typing.KindDetails) (driver.Value, error) {
-	if value == nil {
-		return nil, nil
-	}
-
-	switch kd.Kind {
-	case typing.String.Kind:
-		str, err := values.ToStringOpts(value, kd, converters.GetStringConverterOpts{
-			UseNewStringMethod: true,
-		})
-		if err != nil {
-			return nil, err
-		}
-		return str, nil
-	case typing.Boolean.Kind:
-		castedValue, err := typing.AssertType[bool](value)
-		if err != nil {
-			return nil, err
-		}
-		return castedValue, nil
-	case typing.Struct.Kind:
-		// For structs, convert to JSON string
-		str, err := values.ToString(value, kd)
-		if err != nil {
-			return nil, err
-		}
-		return str, nil
-	case typing.Array.Kind:
-		arrayStr, err := array.InterfaceToArrayString(value, true)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert array: %w", err)
-		}
-		return arrayStr, nil
-	case typing.Integer.Kind, typing.Float.Kind:
-		// Return as-is, DuckDB appender will handle conversion
-		return value, nil
-	case typing.EDecimal.Kind:
-		// Convert decimal to string for DuckDB
-		str, err := values.ToString(value, kd)
-		if err != nil {
-			return nil, err
-		}
-		return str, nil
-	case typing.Date.Kind:
-		// Parse date strings into time.Time for DuckDB appender
-		timeVal, err := typing.ParseDateFromAny(value)
-		if err != nil {
-			return nil, err
-		}
-		return timeVal, nil
-	case typing.TimeKindDetails.Kind:
-		// Parse time strings into time.Time for DuckDB appender
-		timeVal, err := typing.ParseTimeFromAny(value)
-		if err != nil {
-			return nil, err
-		}
-		return timeVal, nil
-	case typing.TimestampNTZ.Kind:
-		// Parse timestamp without timezone into time.Time for DuckDB appender
-		timeVal, err := typing.ParseTimestampNTZFromAny(value)
-		if err != nil {
-			return nil, err
-		}
-		return timeVal, nil
-	case typing.TimestampTZ.Kind:
-		// Parse timestamp with timezone into time.Time for DuckDB appender
-		timeVal, err := typing.ParseTimestampTZFromAny(value)
-		if err != nil {
-			return nil, err
-		}
-		return timeVal, nil
-	default:
-		// For any other types, return as-is and let DuckDB handle it
-		return value, nil
-	}
-}
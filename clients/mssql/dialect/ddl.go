@@ -9,6 +9,7 @@ import (
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/sql"
 	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
 )
 
 const describeTableQuery = `
@@ -24,7 +25,8 @@ SELECT
         ELSE
             DATA_TYPE
     END AS DATA_TYPE,
-    COLUMN_DEFAULT AS DEFAULT_VALUE
+    COLUMN_DEFAULT AS DEFAULT_VALUE,
+    IS_NULLABLE
 FROM
     INFORMATION_SCHEMA.COLUMNS
 WHERE
@@ -48,6 +50,34 @@ func (MSSQLDialect) BuildDropColumnQuery(tableID sql.TableIdentifier, colName st
 	return fmt.Sprintf("ALTER TABLE %s DROP %s", tableID.FullyQualifiedName(), colName)
 }
 
+func quoteLiteral(value string) string {
+	return fmt.Sprintf("N'%s'", strings.ReplaceAll(value, "'", "''"))
+}
+
+// BuildAlterColumnTypeQuery - ALTER COLUMN makes the column nullable unless NOT NULL is specified again.
+// Microsoft SQL Server also won't change the data type of a column that has a default constraint, so we drop the constraint and add it back afterwards.
+func (md MSSQLDialect) BuildAlterColumnTypeQuery(tableID sql.TableIdentifier, col columns.Column, dataType string) (string, bool) {
+	colName := md.QuoteIdentifier(col.Name())
+	nullability := "NULL"
+	if col.NotNull() {
+		nullability = "NOT NULL"
+	}
+
+	alterQuery := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s %s", tableID.FullyQualifiedName(), colName, dataType, nullability)
+	defaultValue, ok := col.DestinationDefault()
+	if !ok {
+		return alterQuery, true
+	}
+
+	objectID := fmt.Sprintf("OBJECT_ID(%s)", quoteLiteral(tableID.FullyQualifiedName()))
+	return strings.Join([]string{
+		fmt.Sprintf("DECLARE @constraint NVARCHAR(256) = (SELECT name FROM sys.default_constraints WHERE parent_object_id = %s AND parent_column_id = COLUMNPROPERTY(%s, %s, 'ColumnId'))", objectID, objectID, quoteLiteral(col.Name())),
+		fmt.Sprintf("EXEC(%s + QUOTENAME(@constraint))", quoteLiteral(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT ", tableID.FullyQualifiedName()))),
+		alterQuery,
+		fmt.Sprintf("ALTER TABLE %s ADD DEFAULT %s FOR %s", tableID.FullyQualifiedName(), defaultValue, colName),
+	}, ";\n"), true
+}
+
 func (MSSQLDialect) BuildCreateTableQuery(tableID sql.TableIdentifier, _ bool, _ config.Mode, colSQLParts []string) string {
 	// Microsoft SQL Server uses the same syntax for temporary and permanent tables.
 	// Microsoft SQL Server doesn't support IF NOT EXISTS
@@ -62,8 +62,45 @@ func (md MSSQLDialect) BuildIsNotToastValueExpression(tableAlias constants.Table
 	return fmt.Sprintf("COALESCE(%s, '') NOT LIKE '%s'", colName, toastedValue)
 }
 
-func (MSSQLDialect) BuildDedupeQueries(tableID, stagingTableID sql.TableIdentifier, primaryKeys []string, includeArtieUpdatedAt bool) []string {
-	panic("not implemented") // We don't currently support deduping for MS SQL.
+// BuildDedupeQueries copies the latest row for every duplicated primary key into the staging table, deletes every copy of
+// those keys from the target table and inserts the survivors back. SQL Server DDL is transactional, so the caller can run
+// all of the statements inside a single transaction.
+func (md MSSQLDialect) BuildDedupeQueries(tableID, stagingTableID sql.TableIdentifier, primaryKeys []string, includeArtieUpdatedAt bool) []string {
+	primaryKeysEscaped := sql.QuoteIdentifiers(primaryKeys, md)
+	orderByCols := append([]string{}, primaryKeysEscaped...)
+	if includeArtieUpdatedAt {
+		orderByCols = append(orderByCols, fmt.Sprintf("%s DESC", md.QuoteIdentifier(constants.UpdateColumnMarker)))
+	}
+
+	var joinClauses []string
+	for _, primaryKeyEscaped := range primaryKeysEscaped {
+		joinClauses = append(joinClauses, fmt.Sprintf("t1.%s = t2.%s", primaryKeyEscaped, primaryKeyEscaped))
+	}
+
+	pks := strings.Join(primaryKeysEscaped, ", ")
+	rowNumberCol := md.QuoteIdentifier("__artie_dedupe_rn")
+	countCol := md.QuoteIdentifier("__artie_dedupe_count")
+	return []string{
+		fmt.Sprintf("SELECT * INTO %s FROM (SELECT t.*, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS %s, COUNT(*) OVER (PARTITION BY %s) AS %s FROM %s t) AS ranked WHERE %s = 1 AND %s > 1",
+			stagingTableID.FullyQualifiedName(),
+			pks,
+			strings.Join(orderByCols, ", "),
+			rowNumberCol,
+			pks,
+			countCol,
+			tableID.FullyQualifiedName(),
+			rowNumberCol,
+			countCol,
+		),
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s, %s", stagingTableID.FullyQualifiedName(), rowNumberCol, countCol),
+		fmt.Sprintf("DELETE t1 FROM %s t1 INNER JOIN %s t2 ON %s",
+			tableID.FullyQualifiedName(),
+			stagingTableID.FullyQualifiedName(),
+			strings.Join(joinClauses, " AND "),
+		),
+		fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", tableID.FullyQualifiedName(), stagingTableID.FullyQualifiedName()),
+		fmt.Sprintf("DROP TABLE %s", stagingTableID.FullyQualifiedName()),
+	}
 }
 
 func (MSSQLDialect) BuildMergeQueryIntoStagingTable(tableID sql.TableIdentifier, subQuery string, primaryKeys []columns.Column, additionalEqualityStrings []string, cols []columns.Column, _ bool) ([]string, error) {
@@ -179,3 +179,47 @@ FROM {SUB_QUERY} AS stg LEFT JOIN database.schema.table AS tgt ON tgt.[id] = stg
 WHERE COALESCE(stg.[__artie_only_set_delete], 0) = 1;`, queries[2])
 	}
 }
+
+func TestMSSQLDialect_BuildDedupeQueries(t *testing.T) {
+	tableID := NewTableIdentifier("dbo", "customers")
+	stagingTableID := NewTableIdentifier("dbo", "customers__artie_dedupe")
+	{
+		// One primary key + no `__artie_updated_at` flag.
+		parts := MSSQLDialect{}.BuildDedupeQueries(tableID, stagingTableID, []string{"id"}, false)
+		assert.Len(t, parts, 5)
+		assert.Equal(t, `SELECT * INTO [dbo].[customers__artie_dedupe] FROM (SELECT t.*, ROW_NUMBER() OVER (PARTITION BY [id] ORDER BY [id]) AS [__artie_dedupe_rn], COUNT(*) OVER (PARTITION BY [id]) AS [__artie_dedupe_count] FROM [dbo].[customers] t) AS ranked WHERE [__artie_dedupe_rn] = 1 AND [__artie_dedupe_count] > 1`, parts[0])
+		assert.Equal(t, `ALTER TABLE [dbo].[customers__artie_dedupe] DROP COLUMN [__artie_dedupe_rn], [__artie_dedupe_count]`, parts[1])
+		assert.Equal(t, `DELETE t1 FROM [dbo].[customers] t1 INNER JOIN [dbo].[customers__artie_dedupe] t2 ON t1.[id] = t2.[id]`, parts[2])
+		assert.Equal(t, `INSERT INTO [dbo].[customers] SELECT * FROM [dbo].[customers__artie_dedupe]`, parts[3])
+		assert.Equal(t, `DROP TABLE [dbo].[customers__artie_dedupe]`, parts[4])
+	}
+	{
+		// Composite keys + `__artie_updated_at` flag.
+		parts := MSSQLDialect{}.BuildDedupeQueries(tableID, stagingTableID, []string{"user_id", "settings"}, true)
+		assert.Len(t, parts, 5)
+		assert.Equal(t, `SELECT * INTO [dbo].[customers__artie_dedupe] FROM (SELECT t.*, ROW_NUMBER() OVER (PARTITION BY [user_id], [settings] ORDER BY [user_id], [settings], [__artie_updated_at] DESC) AS [__artie_dedupe_rn], COUNT(*) OVER (PARTITION BY [user_id], [settings]) AS [__artie_dedupe_count] FROM [dbo].[customers] t) AS ranked WHERE [__artie_dedupe_rn] = 1 AND [__artie_dedupe_count] > 1`, parts[0])
+		assert.Equal(t, `DELETE t1 FROM [dbo].[customers] t1 INNER JOIN [dbo].[customers__artie_dedupe] t2 ON t1.[user_id] = t2.[user_id] AND t1.[settings] = t2.[settings]`, parts[2])
+	}
+}
+
+func TestMSSQLDialect_BuildAlterColumnTypeQuery(t *testing.T) {
+	tableID := NewTableIdentifier("dbo", "orders")
+	{
+		// Nullable without a default
+		query, ok := MSSQLDialect{}.BuildAlterColumnTypeQuery(tableID, columns.NewColumn("count", typing.Integer), "bigint")
+		assert.True(t, ok)
+		assert.Equal(t, `ALTER TABLE [dbo].[orders] ALTER COLUMN [count] bigint NULL`, query)
+	}
+	{
+		// NOT NULL with a default, the default constraint is dropped and added back
+		col := columns.NewColumn("count", typing.Integer)
+		col.SetNotNull(true)
+		col.SetDestinationDefault("((0))")
+		query, ok := MSSQLDialect{}.BuildAlterColumnTypeQuery(tableID, col, "bigint")
+		assert.True(t, ok)
+		assert.Equal(t, `DECLARE @constraint NVARCHAR(256) = (SELECT name FROM sys.default_constraints WHERE parent_object_id = OBJECT_ID(N'[dbo].[orders]') AND parent_column_id = COLUMNPROPERTY(OBJECT_ID(N'[dbo].[orders]'), N'count', 'ColumnId'));
+EXEC(N'ALTER TABLE [dbo].[orders] DROP CONSTRAINT ' + QUOTENAME(@constraint));
+ALTER TABLE [dbo].[orders] ALTER COLUMN [count] bigint NOT NULL;
+ALTER TABLE [dbo].[orders] ADD DEFAULT ((0)) FOR [count]`, query)
+	}
+}
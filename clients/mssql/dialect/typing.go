@@ -91,12 +91,12 @@ func (MSSQLDialect) KindForDataType(rawType string) (typing.KindDetails, error)
 		}, nil
 	case "decimal", "numeric":
 		return typing.ParseNumeric(parameters)
-	case
-		"smallint",
-		"tinyint",
-		"bigint",
-		"int":
-		return typing.Integer, nil
+	case "smallint", "tinyint":
+		return typing.BuildIntegerKind(typing.SmallIntegerKind), nil
+	case "int":
+		return typing.BuildIntegerKind(typing.IntegerKind), nil
+	case "bigint":
+		return typing.BuildIntegerKind(typing.BigIntegerKind), nil
 	case "float", "real":
 		return typing.Float, nil
 	case "datetime", "datetime2":
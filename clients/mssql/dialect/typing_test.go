@@ -57,9 +57,10 @@ func TestMSSQLDialect_KindForDataType(t *testing.T) {
 	}
 
 	colToExpectedKind := map[string]typing.KindDetails{
-		"smallint":       typing.Integer,
-		"tinyint":        typing.Integer,
-		"int":            typing.Integer,
+		"smallint":       typing.BuildIntegerKind(typing.SmallIntegerKind),
+		"tinyint":        typing.BuildIntegerKind(typing.SmallIntegerKind),
+		"int":            typing.BuildIntegerKind(typing.IntegerKind),
+		"bigint":         typing.BuildIntegerKind(typing.BigIntegerKind),
 		"float":          typing.Float,
 		"real":           typing.Float,
 		"bit":            typing.Boolean,
@@ -36,7 +36,11 @@ func (s *Store) LoadDataIntoTable(ctx context.Context, tableData *optimization.T
 
 		defer stmt.Close()
 
-		for _, row := range tableData.Rows() {
+		for row, err := range tableData.AllRows() {
+			if err != nil {
+				return fmt.Errorf("failed to read row: %w", err)
+			}
+
 			var parsedValues []any
 			for _, col := range cols {
 				value, _ := row.GetValue(col.Name())
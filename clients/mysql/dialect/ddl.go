@@ -7,6 +7,7 @@ import (
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/sql"
 	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
 )
 
 const describeTableQuery = `
@@ -24,7 +25,15 @@ SELECT
         ELSE
             DATA_TYPE
     END AS DATA_TYPE,
-    COLUMN_DEFAULT AS DEFAULT_VALUE
+    CASE
+        WHEN COLUMN_DEFAULT IS NULL THEN
+            NULL
+        WHEN EXTRA LIKE '%DEFAULT_GENERATED%' THEN
+            CONCAT('(', COLUMN_DEFAULT, ')')
+        ELSE
+            QUOTE(COLUMN_DEFAULT)
+    END AS DEFAULT_VALUE,
+    IS_NULLABLE
 FROM
     INFORMATION_SCHEMA.COLUMNS
 WHERE
@@ -48,6 +57,20 @@ func (MySQLDialect) BuildDropColumnQuery(tableID sql.TableIdentifier, colName st
 	return sql.DefaultBuildDropColumnQuery(tableID, colName)
 }
 
+// BuildAlterColumnTypeQuery - MODIFY COLUMN replaces the whole column definition, so the nullability and default value need to be specified again.
+func (md MySQLDialect) BuildAlterColumnTypeQuery(tableID sql.TableIdentifier, col columns.Column, dataType string) (string, bool) {
+	definition := []string{dataType}
+	if col.NotNull() {
+		definition = append(definition, "NOT NULL")
+	}
+
+	if defaultValue, ok := col.DestinationDefault(); ok {
+		definition = append(definition, "DEFAULT "+defaultValue)
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", tableID.FullyQualifiedName(), md.QuoteIdentifier(col.Name()), strings.Join(definition, " ")), true
+}
+
 func (MySQLDialect) BuildCreateTableQuery(tableID sql.TableIdentifier, _ bool, _ config.Mode, colSQLParts []string) string {
 	// MySQL uses the same syntax for temporary and permanent tables.
 	// We don't use TEMPORARY keyword because we use connection pooling.
@@ -46,8 +46,76 @@ func (md MySQLDialect) BuildIsNotToastValueExpression(tableAlias constants.Table
 	return fmt.Sprintf("COALESCE(%s, '') NOT LIKE '%s'", colName, toastedValue)
 }
 
-func (MySQLDialect) BuildDedupeQueries(tableID, stagingTableID sql.TableIdentifier, primaryKeys []string, includeArtieUpdatedAt bool) []string {
-	panic("not implemented") // We don't currently support deduping for MySQL.
+const (
+	dedupeRowNumberColumn = "__artie_dedupe_rn"
+	dedupeCountColumn     = "__artie_dedupe_count"
+)
+
+// MySQLDedupePlan is the output of [MySQLDialect.BuildDedupePlan].
+//
+// MySQL implicitly commits around CREATE TABLE and ALTER TABLE, so the plan is split into three groups:
+//
+//   - Prep: copy the latest row for every duplicated primary key into the staging table and drop the helper columns.
+//   - Dedupe (txn): delete every copy of those keys from the target table and insert the survivors back.
+//   - Cleanup: drop the staging table.
+type MySQLDedupePlan struct {
+	Prep    []string
+	Dedupe  []string
+	Cleanup []string
+}
+
+func (md MySQLDialect) BuildDedupePlan(tableID, stagingTableID sql.TableIdentifier, primaryKeys []string, includeArtieUpdatedAt bool) MySQLDedupePlan {
+	primaryKeysEscaped := sql.QuoteIdentifiers(primaryKeys, md)
+	orderByCols := append([]string{}, primaryKeysEscaped...)
+	if includeArtieUpdatedAt {
+		orderByCols = append(orderByCols, fmt.Sprintf("%s DESC", md.QuoteIdentifier(constants.UpdateColumnMarker)))
+	}
+
+	var joinClauses []string
+	for _, primaryKeyEscaped := range primaryKeysEscaped {
+		joinClauses = append(joinClauses, fmt.Sprintf("t1.%s = t2.%s", primaryKeyEscaped, primaryKeyEscaped))
+	}
+
+	pks := strings.Join(primaryKeysEscaped, ", ")
+	rowNumberCol := md.QuoteIdentifier(dedupeRowNumberColumn)
+	countCol := md.QuoteIdentifier(dedupeCountColumn)
+	return MySQLDedupePlan{
+		Prep: []string{
+			fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM (SELECT t.*, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS %s, COUNT(*) OVER (PARTITION BY %s) AS %s FROM %s t) AS ranked WHERE %s = 1 AND %s > 1",
+				stagingTableID.FullyQualifiedName(),
+				pks,
+				strings.Join(orderByCols, ", "),
+				rowNumberCol,
+				pks,
+				countCol,
+				tableID.FullyQualifiedName(),
+				rowNumberCol,
+				countCol,
+			),
+			fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s, DROP COLUMN %s", stagingTableID.FullyQualifiedName(), rowNumberCol, countCol),
+		},
+		Dedupe: []string{
+			fmt.Sprintf("DELETE t1 FROM %s t1 INNER JOIN %s t2 ON %s",
+				tableID.FullyQualifiedName(),
+				stagingTableID.FullyQualifiedName(),
+				strings.Join(joinClauses, " AND "),
+			),
+			fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", tableID.FullyQualifiedName(), stagingTableID.FullyQualifiedName()),
+		},
+		Cleanup: []string{
+			fmt.Sprintf("DROP TABLE %s", stagingTableID.FullyQualifiedName()),
+		},
+	}
+}
+
+// BuildDedupeQueries returns every statement from [MySQLDialect.BuildDedupePlan] in execution order.
+func (md MySQLDialect) BuildDedupeQueries(tableID, stagingTableID sql.TableIdentifier, primaryKeys []string, includeArtieUpdatedAt bool) []string {
+	plan := md.BuildDedupePlan(tableID, stagingTableID, primaryKeys, includeArtieUpdatedAt)
+	var parts []string
+	parts = append(parts, plan.Prep...)
+	parts = append(parts, plan.Dedupe...)
+	parts = append(parts, plan.Cleanup...)
+	return parts
 }
 
 func (MySQLDialect) BuildMergeQueryIntoStagingTable(tableID sql.TableIdentifier, subQuery string, primaryKeys []columns.Column, additionalEqualityStrings []string, cols []columns.Column, _ bool) ([]string, error) {
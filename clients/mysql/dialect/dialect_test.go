@@ -0,0 +1,59 @@
+package dialect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func TestMySQLDialect_BuildDedupePlan(t *testing.T) {
+	tableID := NewTableIdentifier("shop", "customers")
+	stagingTableID := NewTableIdentifier("shop", "customers__artie_dedupe")
+	{
+		// One primary key + no `__artie_updated_at` flag.
+		plan := MySQLDialect{}.BuildDedupePlan(tableID, stagingTableID, []string{"id"}, false)
+		assert.Equal(t, []string{
+			"CREATE TABLE `shop`.`customers__artie_dedupe` AS SELECT * FROM (SELECT t.*, ROW_NUMBER() OVER (PARTITION BY `id` ORDER BY `id`) AS `__artie_dedupe_rn`, COUNT(*) OVER (PARTITION BY `id`) AS `__artie_dedupe_count` FROM `shop`.`customers` t) AS ranked WHERE `__artie_dedupe_rn` = 1 AND `__artie_dedupe_count` > 1",
+			"ALTER TABLE `shop`.`customers__artie_dedupe` DROP COLUMN `__artie_dedupe_rn`, DROP COLUMN `__artie_dedupe_count`",
+		}, plan.Prep)
+		assert.Equal(t, []string{
+			"DELETE t1 FROM `shop`.`customers` t1 INNER JOIN `shop`.`customers__artie_dedupe` t2 ON t1.`id` = t2.`id`",
+			"INSERT INTO `shop`.`customers` SELECT * FROM `shop`.`customers__artie_dedupe`",
+		}, plan.Dedupe)
+		assert.Equal(t, []string{"DROP TABLE `shop`.`customers__artie_dedupe`"}, plan.Cleanup)
+	}
+	{
+		// Composite keys + `__artie_updated_at` flag.
+		plan := MySQLDialect{}.BuildDedupePlan(tableID, stagingTableID, []string{"user_id", "settings"}, true)
+		assert.Equal(t, "CREATE TABLE `shop`.`customers__artie_dedupe` AS SELECT * FROM (SELECT t.*, ROW_NUMBER() OVER (PARTITION BY `user_id`, `settings` ORDER BY `user_id`, `settings`, `__artie_updated_at` DESC) AS `__artie_dedupe_rn`, COUNT(*) OVER (PARTITION BY `user_id`, `settings`) AS `__artie_dedupe_count` FROM `shop`.`customers` t) AS ranked WHERE `__artie_dedupe_rn` = 1 AND `__artie_dedupe_count` > 1", plan.Prep[0])
+		assert.Equal(t, "DELETE t1 FROM `shop`.`customers` t1 INNER JOIN `shop`.`customers__artie_dedupe` t2 ON t1.`user_id` = t2.`user_id` AND t1.`settings` = t2.`settings`", plan.Dedupe[0])
+	}
+	{
+		// BuildDedupeQueries flattens the plan in execution order.
+		queries := MySQLDialect{}.BuildDedupeQueries(tableID, stagingTableID, []string{"id"}, false)
+		assert.Len(t, queries, 5)
+		assert.Equal(t, "DROP TABLE `shop`.`customers__artie_dedupe`", queries[4])
+	}
+}
+
+func TestMySQLDialect_BuildAlterColumnTypeQuery(t *testing.T) {
+	tableID := NewTableIdentifier("shop", "orders")
+	{
+		// Nullable without a default
+		query, ok := MySQLDialect{}.BuildAlterColumnTypeQuery(tableID, columns.NewColumn("count", typing.Integer), "BIGINT")
+		assert.True(t, ok)
+		assert.Equal(t, "ALTER TABLE `shop`.`orders` MODIFY COLUMN `count` BIGINT", query)
+	}
+	{
+		// NOT NULL with a default
+		col := columns.NewColumn("count", typing.Integer)
+		col.SetNotNull(true)
+		col.SetDestinationDefault("'0'")
+		query, ok := MySQLDialect{}.BuildAlterColumnTypeQuery(tableID, col, "BIGINT")
+		assert.True(t, ok)
+		assert.Equal(t, "ALTER TABLE `shop`.`orders` MODIFY COLUMN `count` BIGINT NOT NULL DEFAULT '0'", query)
+	}
+}
@@ -85,14 +85,12 @@ func (MySQLDialect) KindForDataType(rawType string) (typing.KindDetails, error)
 		return typing.String, nil
 	case "decimal", "numeric":
 		return typing.ParseNumeric(parameters)
-	case
-		"tinyint",
-		"smallint",
-		"mediumint",
-		"int",
-		"integer",
-		"bigint":
-		return typing.Integer, nil
+	case "tinyint", "smallint":
+		return typing.BuildIntegerKind(typing.SmallIntegerKind), nil
+	case "mediumint", "int", "integer":
+		return typing.BuildIntegerKind(typing.IntegerKind), nil
+	case "bigint":
+		return typing.BuildIntegerKind(typing.BigIntegerKind), nil
 	case "float", "double", "real":
 		return typing.Float, nil
 	case "datetime", "timestamp":
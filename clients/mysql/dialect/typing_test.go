@@ -111,12 +111,12 @@ func TestMySQLDialect_KindForDataType(t *testing.T) {
 	// Simple type mappings
 	colToExpectedKind := map[string]typing.KindDetails{
 		// Integer types
-		"tinyint":   typing.Integer,
-		"smallint":  typing.Integer,
-		"mediumint": typing.Integer,
-		"int":       typing.Integer,
-		"integer":   typing.Integer,
-		"bigint":    typing.Integer,
+		"tinyint":   typing.BuildIntegerKind(typing.SmallIntegerKind),
+		"smallint":  typing.BuildIntegerKind(typing.SmallIntegerKind),
+		"mediumint": typing.BuildIntegerKind(typing.IntegerKind),
+		"int":       typing.BuildIntegerKind(typing.IntegerKind),
+		"integer":   typing.BuildIntegerKind(typing.IntegerKind),
+		"bigint":    typing.BuildIntegerKind(typing.BigIntegerKind),
 		// Float types
 		"float":  typing.Float,
 		"double": typing.Float,
@@ -4,10 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"slices"
 	"strings"
 
 	"github.com/artie-labs/transfer/clients/shared"
+	"github.com/artie-labs/transfer/lib/batch"
 	"github.com/artie-labs/transfer/lib/db"
 	"github.com/artie-labs/transfer/lib/destination/types"
 	"github.com/artie-labs/transfer/lib/optimization"
@@ -27,9 +27,7 @@ func (s *Store) LoadDataIntoTable(ctx context.Context, tableData *optimization.T
 	}
 
 	cols := tableData.ReadOnlyInMemoryCols().ValidColumns()
-	rows := tableData.Rows()
-
-	if len(rows) == 0 {
+	if tableData.NumberOfRows() == 0 {
 		return nil
 	}
 
@@ -41,13 +39,17 @@ func (s *Store) LoadDataIntoTable(ctx context.Context, tableData *optimization.T
 
 	return db.CommitOrRollback(tx, func(tx *sql.Tx) error {
 		var rowsLoaded int64
-		for batch := range slices.Chunk(rows, batchSize) {
-			affected, err := s.executeBatchInsert(ctx, tx, tableID, cols, batch)
+		err := batch.ByCount(tableData.AllRows(), batchSize, func(rows []optimization.Row) error {
+			affected, err := s.executeBatchInsert(ctx, tx, tableID, cols, rows)
 			if err != nil {
 				return fmt.Errorf("failed to execute batch insert: %w", err)
 			}
 
 			rowsLoaded += affected
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 
 		if expectedRows := int64(tableData.NumberOfRows()); rowsLoaded != expectedRows {
@@ -11,6 +11,7 @@ import (
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/destination"
 	"github.com/artie-labs/transfer/lib/destination/types"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/optimization"
@@ -74,8 +75,23 @@ func (s *Store) SweepTemporaryTables(ctx context.Context) error {
 	return shared.Sweep(ctx, s, s.config.TopicConfigs(), s.dialect().BuildSweepQuery)
 }
 
-func (s *Store) Dedupe(_ context.Context, _ sql.TableIdentifier, _ kafkalib.DatabaseAndSchemaPair, _ []string, _ bool) error {
-	return nil // dedupe is not necessary for MySQL
+func (s *Store) Dedupe(ctx context.Context, tableID sql.TableIdentifier, pair kafkalib.DatabaseAndSchemaPair, primaryKeys []string, includeArtieUpdatedAt bool) error {
+	stagingTableID := shared.BuildStagingTableID(s, pair, tableID)
+	plan := s.dialect().BuildDedupePlan(tableID, stagingTableID, primaryKeys, includeArtieUpdatedAt)
+
+	// DDL statements cause an implicit commit in MySQL, so only the DELETE and INSERT run inside a transaction.
+	for _, query := range plan.Prep {
+		if _, err := s.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to prep dedupe: %w", err)
+		}
+	}
+	if _, err := destination.ExecContextStatements(ctx, s, plan.Dedupe); err != nil {
+		return fmt.Errorf("failed to dedupe: %w", err)
+	}
+	if _, err := destination.ExecContextStatements(ctx, s, plan.Cleanup); err != nil {
+		return fmt.Errorf("failed to drop dedupe staging table: %w", err)
+	}
+	return nil
 }
 
 func (s *Store) GetTableConfig(ctx context.Context, tableID sql.TableIdentifier, dropDeletedColumns bool) (*types.DestinationTableConfig, error) {
@@ -78,8 +78,42 @@ func (PostgresDialect) BuildTruncateTableQuery(tableID sql.TableIdentifier) stri
 	return sql.DefaultBuildTruncateTableQuery(tableID)
 }
 
-func (PostgresDialect) BuildDedupeQueries(tableID, stagingTableID sql.TableIdentifier, primaryKeys []string, includeArtieUpdatedAt bool) []string {
-	panic("not implemented") // We don't currently support deduping for Postgres.
+// BuildDedupeQueries copies the latest row for every duplicated primary key into the staging table, deletes every copy of
+// those keys from the target table and inserts the survivors back. Postgres DDL is transactional, so the caller can run
+// all of the statements inside a single transaction.
+func (pd PostgresDialect) BuildDedupeQueries(tableID, stagingTableID sql.TableIdentifier, primaryKeys []string, includeArtieUpdatedAt bool) []string {
+	primaryKeysEscaped := sql.QuoteIdentifiers(primaryKeys, pd)
+	orderByCols := append([]string{}, primaryKeysEscaped...)
+	if includeArtieUpdatedAt {
+		orderByCols = append(orderByCols, fmt.Sprintf("%s DESC", pd.QuoteIdentifier(constants.UpdateColumnMarker)))
+	}
+
+	var whereClauses []string
+	for _, primaryKeyEscaped := range primaryKeysEscaped {
+		whereClauses = append(whereClauses, fmt.Sprintf("t1.%s = t2.%s", primaryKeyEscaped, primaryKeyEscaped))
+	}
+
+	pks := strings.Join(primaryKeysEscaped, ", ")
+	return []string{
+		// DISTINCT ON keeps the first row for each key, which is the most recent one given the ORDER BY.
+		fmt.Sprintf("CREATE TABLE %s AS SELECT DISTINCT ON (%s) * FROM %s WHERE (%s) IN (SELECT %s FROM %s GROUP BY %s HAVING COUNT(*) > 1) ORDER BY %s",
+			stagingTableID.FullyQualifiedName(),
+			pks,
+			tableID.FullyQualifiedName(),
+			pks,
+			pks,
+			tableID.FullyQualifiedName(),
+			pks,
+			strings.Join(orderByCols, ", "),
+		),
+		fmt.Sprintf("DELETE FROM %s t1 USING %s t2 WHERE %s",
+			tableID.FullyQualifiedName(),
+			stagingTableID.FullyQualifiedName(),
+			strings.Join(whereClauses, " AND "),
+		),
+		fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", tableID.FullyQualifiedName(), stagingTableID.FullyQualifiedName()),
+		fmt.Sprintf("DROP TABLE %s", stagingTableID.FullyQualifiedName()),
+	}
 }
 
 func (PostgresDialect) BuildDescribeTableQuery(tableID sql.TableIdentifier) (string, []any, error) {
@@ -120,6 +154,10 @@ func (PostgresDialect) BuildDropColumnQuery(tableID sql.TableIdentifier, colName
 	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", tableID.FullyQualifiedName(), colName)
 }
 
+func (pd PostgresDialect) BuildAlterColumnTypeQuery(tableID sql.TableIdentifier, col columns.Column, dataType string) (string, bool) {
+	return sql.DefaultBuildAlterColumnTypeQuery(tableID, pd.QuoteIdentifier(col.Name()), dataType), true
+}
+
 func (PostgresDialect) BuildMergeQueryIntoStagingTable(tableID sql.TableIdentifier, subQuery string, primaryKeys []columns.Column, additionalEqualityStrings []string, cols []columns.Column, _ bool) ([]string, error) {
 	return nil, fmt.Errorf("not implemented")
 }
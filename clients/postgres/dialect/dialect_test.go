@@ -309,3 +309,24 @@ func TestDataTypeForKind(t *testing.T) {
 		})
 	}
 }
+
+func TestPostgresDialect_BuildDedupeQueries(t *testing.T) {
+	tableID := NewTableIdentifier("public", "customers")
+	stagingTableID := NewTableIdentifier("public", "customers__artie_dedupe")
+	{
+		// One primary key + no `__artie_updated_at` flag.
+		parts := PostgresDialect{}.BuildDedupeQueries(tableID, stagingTableID, []string{"id"}, false)
+		assert.Len(t, parts, 4)
+		assert.Equal(t, `CREATE TABLE "public"."customers__artie_dedupe" AS SELECT DISTINCT ON ("id") * FROM "public"."customers" WHERE ("id") IN (SELECT "id" FROM "public"."customers" GROUP BY "id" HAVING COUNT(*) > 1) ORDER BY "id"`, parts[0])
+		assert.Equal(t, `DELETE FROM "public"."customers" t1 USING "public"."customers__artie_dedupe" t2 WHERE t1."id" = t2."id"`, parts[1])
+		assert.Equal(t, `INSERT INTO "public"."customers" SELECT * FROM "public"."customers__artie_dedupe"`, parts[2])
+		assert.Equal(t, `DROP TABLE "public"."customers__artie_dedupe"`, parts[3])
+	}
+	{
+		// Composite keys + `__artie_updated_at` flag.
+		parts := PostgresDialect{}.BuildDedupeQueries(tableID, stagingTableID, []string{"user_id", "settings"}, true)
+		assert.Len(t, parts, 4)
+		assert.Equal(t, `CREATE TABLE "public"."customers__artie_dedupe" AS SELECT DISTINCT ON ("user_id", "settings") * FROM "public"."customers" WHERE ("user_id", "settings") IN (SELECT "user_id", "settings" FROM "public"."customers" GROUP BY "user_id", "settings" HAVING COUNT(*) > 1) ORDER BY "user_id", "settings", "__artie_updated_at" DESC`, parts[0])
+		assert.Equal(t, `DELETE FROM "public"."customers" t1 USING "public"."customers__artie_dedupe" t2 WHERE t1."user_id" = t2."user_id" AND t1."settings" = t2."settings"`, parts[1])
+	}
+}
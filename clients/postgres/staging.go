@@ -46,7 +46,11 @@ func (s *stagingIterator) Values() ([]any, error) {
 func (s *Store) buildStagingIterator(tableData *optimization.TableData) (pgx.CopyFromSource, error) {
 	var values [][]any
 	cols := tableData.ReadOnlyInMemoryCols().ValidColumns()
-	for _, row := range tableData.Rows() {
+	for row, err := range tableData.AllRows() {
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+
 		var rowValues []any
 		for _, col := range cols {
 			value, _ := row.GetValue(col.Name())
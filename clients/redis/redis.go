@@ -70,8 +70,7 @@ func (s *Store) Merge(ctx context.Context, tableData *optimization.TableData, wh
 		return false, fmt.Errorf("expected tableID to be a TableIdentifier, got %T", tableID)
 	}
 
-	rows := tableData.Rows()
-	if len(rows) == 0 {
+	if tableData.NumberOfRows() == 0 {
 		return false, nil
 	}
 
@@ -80,7 +79,11 @@ func (s *Store) Merge(ctx context.Context, tableData *optimization.TableData, wh
 	var recordsWritten int
 	pipeline := s.redisClient.Pipeline()
 
-	for _, row := range rows {
+	for row, err := range tableData.AllRows() {
+		if err != nil {
+			return false, fmt.Errorf("failed to read row: %w", err)
+		}
+
 		rowData := make(map[string]any)
 		for _, col := range cols {
 			value, _ := row.GetValue(col.Name())
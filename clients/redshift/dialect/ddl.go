@@ -7,6 +7,7 @@ import (
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/sql"
 	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
 )
 
 const describeTableQuery = `SELECT
@@ -49,6 +50,20 @@ func (RedshiftDialect) BuildDropColumnQuery(tableID sql.TableIdentifier, colName
 	return sql.DefaultBuildDropColumnQuery(tableID, colName)
 }
 
+// BuildAlterColumnTypeQuery - Redshift only supports changing the data type in place for VARCHAR columns.
+func (rd RedshiftDialect) BuildAlterColumnTypeQuery(tableID sql.TableIdentifier, col columns.Column, dataType string) (string, bool) {
+	if col.KindDetails.Kind != typing.String.Kind {
+		return "", false
+	}
+
+	return sql.DefaultBuildAlterColumnTypeQuery(tableID, rd.QuoteIdentifier(col.Name()), dataType), true
+}
+
+// SupportsTransactionalDDL - Redshift can add, rename and drop columns inside of a transaction.
+func (RedshiftDialect) SupportsTransactionalDDL() bool {
+	return true
+}
+
 func (RedshiftDialect) BuildCreateTableQuery(tableID sql.TableIdentifier, _ bool, _ config.Mode, colSQLParts []string) string {
 	// Redshift uses the same syntax for temporary and permanent tables.
 	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s);", tableID.FullyQualifiedName(), strings.Join(colSQLParts, ","))
@@ -10,6 +10,9 @@ import (
 	"github.com/artie-labs/transfer/lib/typing"
 )
 
+// maxStringLength is the maximum length of a VARCHAR column in Redshift.
+const maxStringLength int32 = 65535
+
 func (RedshiftDialect) DataTypeForKind(kd typing.KindDetails, _ bool, _ config.SharedDestinationColumnSettings) (string, error) {
 	switch kd.Kind {
 	case typing.Integer.Kind:
@@ -71,11 +74,19 @@ func (RedshiftDialect) KindForDataType(rawType string) (typing.KindDetails, erro
 	switch dataType {
 	case "numeric":
 		return typing.ParseNumeric(parameters)
-	case "character varying":
+	case "character varying", "varchar":
 		if len(parameters) != 1 {
 			return typing.Invalid, fmt.Errorf("expected 1 parameter for character varying, got %d, value: %q", len(parameters), rawType)
 		}
 
+		if parameters[0] == "max" {
+			// This is what we use when creating string columns, Redshift stores this as VARCHAR(65535).
+			return typing.KindDetails{
+				Kind:                    typing.String.Kind,
+				OptionalStringPrecision: typing.ToPtr(maxStringLength),
+			}, nil
+		}
+
 		precision, err := strconv.ParseInt(parameters[0], 10, 32)
 		if err != nil {
 			return typing.Invalid, fmt.Errorf("failed to parse string precision: %q, err: %w", parameters[0], err)
@@ -89,17 +100,17 @@ func (RedshiftDialect) KindForDataType(rawType string) (typing.KindDetails, erro
 		return typing.KindDetails{Kind: typing.String.Kind}, nil
 	case "super":
 		return typing.Struct, nil
-	case "smallint":
+	case "smallint", "int2":
 		return typing.KindDetails{
 			Kind:                typing.Integer.Kind,
 			OptionalIntegerKind: typing.ToPtr(typing.SmallIntegerKind),
 		}, nil
-	case "integer":
+	case "integer", "int4":
 		return typing.KindDetails{
 			Kind:                typing.Integer.Kind,
 			OptionalIntegerKind: typing.ToPtr(typing.IntegerKind),
 		}, nil
-	case "bigint":
+	case "bigint", "int8":
 		return typing.KindDetails{
 			Kind:                typing.Integer.Kind,
 			OptionalIntegerKind: typing.ToPtr(typing.BigIntegerKind),
@@ -74,6 +74,14 @@ func TestRedshiftDialect_KindForDataType(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, typing.KindDetails{Kind: typing.Integer.Kind, OptionalIntegerKind: typing.ToPtr(typing.BigIntegerKind)}, kd)
 		}
+		{
+			// Aliases that we use when creating columns
+			for dataType, integerKind := range map[string]typing.OptionalIntegerKind{"INT2": typing.SmallIntegerKind, "INT4": typing.IntegerKind, "INT8": typing.BigIntegerKind} {
+				kd, err := dialect.KindForDataType(dataType)
+				assert.NoError(t, err)
+				assert.Equal(t, typing.KindDetails{Kind: typing.Integer.Kind, OptionalIntegerKind: typing.ToPtr(integerKind)}, kd)
+			}
+		}
 	}
 	{
 		// Double
@@ -117,6 +125,12 @@ func TestRedshiftDialect_KindForDataType(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, typing.KindDetails{Kind: typing.String.Kind, OptionalStringPrecision: typing.ToPtr(int32(65535))}, kd)
 	}
+	{
+		// VARCHAR(MAX)
+		kd, err := dialect.KindForDataType("VARCHAR(MAX)")
+		assert.NoError(t, err)
+		assert.Equal(t, typing.KindDetails{Kind: typing.String.Kind, OptionalStringPrecision: typing.ToPtr(int32(65535))}, kd)
+	}
 	{
 		// Character
 		kd, err := dialect.KindForDataType("character")
@@ -0,0 +1,169 @@
+package s3
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/csvwriter"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/converters"
+	"github.com/artie-labs/transfer/lib/typing/values"
+)
+
+// FileExtension returns the file extension (including the leading dot) for the output format.
+func FileExtension(format constants.S3OutputFormat, opts config.FileFormatOptions) string {
+	var extension string
+	switch format {
+	case constants.CSVFormat:
+		extension = ".csv"
+	case constants.JSONLFormat:
+		extension = ".jsonl"
+	default:
+		return ".parquet"
+	}
+
+	if opts.GetCompression() == config.GzipCompression {
+		extension += ".gz"
+	}
+
+	return extension
+}
+
+// WriteFile writes [rows] to [filePath] using the output format.
+func WriteFile(cols []columns.Column, rows iter.Seq2[optimization.Row, error], filePath string, format constants.S3OutputFormat, opts config.FileFormatOptions, cfg config.SharedDestinationSettings) error {
+	switch format {
+	case constants.CSVFormat:
+		return WriteCSVFile(cols, rows, filePath, opts, cfg)
+	case constants.JSONLFormat:
+		return WriteJSONLFile(cols, rows, filePath, opts, cfg)
+	default:
+		return writeParquetFile(cols, rows, filePath)
+	}
+}
+
+// WriteCSVFile writes [rows] to a CSV file, nil values are written as [config.FileFormatOptions.NullToken].
+func WriteCSVFile(cols []columns.Column, rows iter.Seq2[optimization.Row, error], filePath string, opts config.FileFormatOptions, cfg config.SharedDestinationSettings) error {
+	writer, err := csvwriter.NewWriter(filePath, opts.GetDelimiter(), opts.GetCompression() == config.GzipCompression)
+	if err != nil {
+		return fmt.Errorf("failed to create csv writer: %w", err)
+	}
+
+	if err = writeCSVRows(writer, cols, rows, opts, cfg); err != nil {
+		_ = writer.Close()
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("failed to close csv writer: %w", err)
+	}
+
+	return nil
+}
+
+func writeCSVRows(writer *csvwriter.Writer, cols []columns.Column, rows iter.Seq2[optimization.Row, error], opts config.FileFormatOptions, cfg config.SharedDestinationSettings) error {
+	if opts.Header {
+		header := make([]string, len(cols))
+		for i, col := range cols {
+			header[i] = col.Name()
+		}
+
+		if err := writer.Write(header); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+	}
+
+	for row, err := range rows {
+		if err != nil {
+			return fmt.Errorf("failed to read row: %w", err)
+		}
+
+		record := make([]string, len(cols))
+		for i, col := range cols {
+			value, _ := row.GetValue(col.Name())
+			if value == nil {
+				record[i] = opts.NullToken
+				continue
+			}
+
+			castedValue, err := values.ToStringOpts(value, col.KindDetails, converters.GetStringConverterOpts{UseNewStringMethod: cfg.UseNewStringMethod})
+			if err != nil {
+				return fmt.Errorf("failed to convert column %q: %w", col.Name(), err)
+			}
+
+			record[i] = castedValue
+		}
+
+		if err = writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	return writer.Flush()
+}
+
+// WriteJSONLFile writes [rows] to a newline delimited JSON file, one object per row.
+func WriteJSONLFile(cols []columns.Column, rows iter.Seq2[optimization.Row, error], filePath string, opts config.FileFormatOptions, cfg config.SharedDestinationSettings) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create jsonl file: %w", err)
+	}
+
+	var out io.Writer = file
+	var gzipWriter *gzip.Writer
+	if opts.GetCompression() == config.GzipCompression {
+		gzipWriter = gzip.NewWriter(file)
+		out = gzipWriter
+	}
+
+	buffered := bufio.NewWriter(out)
+	if err = writeJSONLRows(buffered, cols, rows, cfg); err == nil {
+		err = buffered.Flush()
+	}
+
+	if gzipWriter != nil {
+		if closeErr := gzipWriter.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to close gzip writer: %w", closeErr)
+		}
+	}
+
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close jsonl file: %w", closeErr)
+	}
+
+	return err
+}
+
+func writeJSONLRows(w io.Writer, cols []columns.Column, rows iter.Seq2[optimization.Row, error], cfg config.SharedDestinationSettings) error {
+	encoder := json.NewEncoder(w)
+	for row, err := range rows {
+		if err != nil {
+			return fmt.Errorf("failed to read row: %w", err)
+		}
+
+		object := make(map[string]any, len(cols))
+		for _, col := range cols {
+			value, _ := row.GetValue(col.Name())
+			castedValue, err := values.ToJSONValue(value, col.KindDetails, converters.GetStringConverterOpts{UseNewStringMethod: cfg.UseNewStringMethod})
+			if err != nil {
+				return fmt.Errorf("failed to convert column %q: %w", col.Name(), err)
+			}
+
+			object[col.Name()] = castedValue
+		}
+
+		// [json.Encoder.Encode] terminates each value with a newline.
+		if err = encoder.Encode(object); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package s3
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func newFilesTableData(t *testing.T) *optimization.TableData {
+	cols := columns.NewColumns(nil)
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn("payload", typing.Struct))
+
+	tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, kafkalib.TopicConfig{Database: "db", Schema: "public"}, "table")
+	assert.NoError(t, tableData.InsertRow("1", map[string]any{"id": int64(1), "name": "foo, bar", "payload": map[string]any{"a": 1}}, false))
+	return tableData
+}
+
+func readFile(t *testing.T, fp string, compressed bool) string {
+	file, err := os.Open(fp)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var reader io.Reader = file
+	if compressed {
+		gzipReader, err := gzip.NewReader(file)
+		assert.NoError(t, err)
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, ".parquet", FileExtension(constants.ParquetFormat, config.FileFormatOptions{}))
+	assert.Equal(t, ".parquet", FileExtension(constants.ParquetFormat, config.FileFormatOptions{Compression: config.NoCompression}))
+	assert.Equal(t, ".csv.gz", FileExtension(constants.CSVFormat, config.FileFormatOptions{}))
+	assert.Equal(t, ".csv", FileExtension(constants.CSVFormat, config.FileFormatOptions{Compression: config.NoCompression}))
+	assert.Equal(t, ".jsonl.gz", FileExtension(constants.JSONLFormat, config.FileFormatOptions{}))
+	assert.Equal(t, ".jsonl", FileExtension(constants.JSONLFormat, config.FileFormatOptions{Compression: config.NoCompression}))
+}
+
+func TestWriteFile_CSV(t *testing.T) {
+	tableData := newFilesTableData(t)
+	assert.NoError(t, tableData.InsertRow("2", map[string]any{"id": int64(2), "name": nil}, false))
+	{
+		// Defaults - comma delimited, no header and gzip compressed
+		fp := filepath.Join(t.TempDir(), "out.csv.gz")
+		assert.NoError(t, WriteFile(tableData.ReadOnlyInMemoryCols().ValidColumns(), tableData.AllRows(), fp, constants.CSVFormat, config.FileFormatOptions{}, config.SharedDestinationSettings{}))
+		lines := strings.Split(strings.TrimSpace(readFile(t, fp, true)), "\n")
+		assert.ElementsMatch(t, []string{`1,"foo, bar","{""a"":1}"`, `2,,`}, lines)
+	}
+	{
+		// Header, custom delimiter and null token without compression
+		fp := filepath.Join(t.TempDir(), "out.csv")
+		opts := config.FileFormatOptions{Delimiter: "|", Header: true, NullToken: `\N`, Compression: config.NoCompression}
+		assert.NoError(t, WriteFile(tableData.ReadOnlyInMemoryCols().ValidColumns(), tableData.AllRows(), fp, constants.CSVFormat, opts, config.SharedDestinationSettings{}))
+		lines := strings.Split(strings.TrimSpace(readFile(t, fp, false)), "\n")
+		assert.Len(t, lines, 3)
+		assert.Equal(t, "id|name|payload", lines[0])
+		assert.ElementsMatch(t, []string{`1|foo, bar|"{""a"":1}"`, `2|\N|\N`}, lines[1:])
+	}
+}
+
+func TestWriteFile_UseNewStringMethod(t *testing.T) {
+	cols := columns.NewColumns(nil)
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("path", typing.String))
+
+	tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, kafkalib.TopicConfig{Database: "db", Schema: "public"}, "table")
+	assert.NoError(t, tableData.InsertRow("1", map[string]any{"id": int64(1), "path": `C:\temp`}, false))
+
+	opts := config.FileFormatOptions{Compression: config.NoCompression}
+	{
+		// CSV - the old method escapes backslashes
+		fp := filepath.Join(t.TempDir(), "old.csv")
+		assert.NoError(t, WriteFile(tableData.ReadOnlyInMemoryCols().ValidColumns(), tableData.AllRows(), fp, constants.CSVFormat, opts, config.SharedDestinationSettings{}))
+		assert.Equal(t, `1,C:\\temp`+"\n", readFile(t, fp, false))
+	}
+	{
+		// CSV - the new method keeps the string as is
+		fp := filepath.Join(t.TempDir(), "new.csv")
+		assert.NoError(t, WriteFile(tableData.ReadOnlyInMemoryCols().ValidColumns(), tableData.AllRows(), fp, constants.CSVFormat, opts, config.SharedDestinationSettings{UseNewStringMethod: true}))
+		assert.Equal(t, `1,C:\temp`+"\n", readFile(t, fp, false))
+	}
+	{
+		// JSONL - the new method keeps the string as is
+		fp := filepath.Join(t.TempDir(), "new.jsonl")
+		assert.NoError(t, WriteFile(tableData.ReadOnlyInMemoryCols().ValidColumns(), tableData.AllRows(), fp, constants.JSONLFormat, opts, config.SharedDestinationSettings{UseNewStringMethod: true}))
+		assert.Equal(t, `{"id":1,"path":"C:\\temp"}`+"\n", readFile(t, fp, false))
+	}
+}
+
+func TestWriteFile_JSONL(t *testing.T) {
+	tableData := newFilesTableData(t)
+	{
+		// Gzip compressed
+		fp := filepath.Join(t.TempDir(), "out.jsonl.gz")
+		assert.NoError(t, WriteFile(tableData.ReadOnlyInMemoryCols().ValidColumns(), tableData.AllRows(), fp, constants.JSONLFormat, config.FileFormatOptions{}, config.SharedDestinationSettings{}))
+		assert.Equal(t, `{"id":1,"name":"foo, bar","payload":{"a":1}}`+"\n", readFile(t, fp, true))
+	}
+	{
+		// Uncompressed
+		fp := filepath.Join(t.TempDir(), "out.jsonl")
+		assert.NoError(t, WriteFile(tableData.ReadOnlyInMemoryCols().ValidColumns(), tableData.AllRows(), fp, constants.JSONLFormat, config.FileFormatOptions{Compression: config.NoCompression}, config.SharedDestinationSettings{}))
+		assert.Equal(t, `{"id":1,"name":"foo, bar","payload":{"a":1}}`+"\n", readFile(t, fp, false))
+	}
+}
@@ -0,0 +1,109 @@
+package s3
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/objectkey"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing/values"
+)
+
+// ObjectGroup is a set of rows that will be written into a single object under [Prefix].
+type ObjectGroup struct {
+	Prefix string
+	// Rows iterates over the rows in this group, spilled rows are read back from disk one at a time.
+	Rows iter.Seq2[optimization.Row, error]
+}
+
+// GroupRowsByPrefix renders the topic's object key template for every row and groups the rows that share a prefix.
+// If the topic does not have a template, all the rows are written under [defaultPrefix].
+// The template is rendered once per row, see [optimization.TableData.GroupRows] for how the rows are kept.
+func GroupRowsByPrefix(tableData *optimization.TableData, folder string, fqTableName string, defaultPrefix string) ([]ObjectGroup, error) {
+	topicConfig := tableData.TopicConfig()
+	if topicConfig.ObjectKeyTemplate == "" {
+		return []ObjectGroup{{Prefix: defaultPrefix, Rows: tableData.AllRows()}}, nil
+	}
+
+	template, err := objectkey.Parse(topicConfig.ObjectKeyTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := tableData.ReadOnlyInMemoryCols()
+	renderPrefix := func(row optimization.Row) (string, error) {
+		metadata := row.Metadata()
+		prefix, err := template.Render(objectkey.Values{
+			Folder:                  folder,
+			Database:                topicConfig.Database,
+			Schema:                  topicConfig.Schema,
+			Table:                   tableData.Name(),
+			FullyQualifiedTableName: fqTableName,
+			// Rows that were inserted without metadata will fall back to the latest event in this batch.
+			ExecutionTime: cmp.Or(metadata.ExecutionTime, tableData.GetLatestTimestamp()),
+			Operation:     metadata.Operation,
+			Partition:     metadata.Partition,
+			Column: func(name string) (string, bool, error) {
+				value, _ := row.GetValue(name)
+				col, ok := cols.GetColumn(name)
+				if value == nil || !ok {
+					return "", false, nil
+				}
+
+				castedValue, err := values.ToString(value, col.KindDetails)
+				if err != nil {
+					return "", false, err
+				}
+
+				return castedValue, true, nil
+			},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to render object key: %w", err)
+		}
+
+		return prefix, nil
+	}
+
+	groups, err := tableData.GroupRows(renderPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]ObjectGroup, 0, len(groups))
+	for _, prefix := range slices.Sorted(maps.Keys(groups)) {
+		out = append(out, ObjectGroup{Prefix: prefix, Rows: groups[prefix]})
+	}
+
+	return out, nil
+}
+
+// WriteObjects writes each group into a temporary file and hands it over to [upload], the temporary files are always removed afterwards.
+func WriteObjects(tableData *optimization.TableData, groups []ObjectGroup, format constants.S3OutputFormat, opts config.FileFormatOptions, cfg config.SharedDestinationSettings, upload func(prefix string, filePath string) error) error {
+	cols := tableData.ReadOnlyInMemoryCols().ValidColumns()
+	for _, group := range groups {
+		fp := buildTemporaryFilePath(tableData, FileExtension(format, opts))
+		err := WriteFile(cols, group.Rows, fp, format, opts, cfg)
+		if err == nil {
+			err = upload(group.Prefix, fp)
+		}
+
+		// Delete the file regardless of outcome to avoid fs build up.
+		if removeErr := os.RemoveAll(fp); removeErr != nil {
+			slog.Warn("Failed to delete temp file", slog.Any("err", removeErr), slog.String("filePath", fp))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
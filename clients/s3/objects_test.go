@@ -0,0 +1,144 @@
+package s3
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func newObjectsTableData(t *testing.T, template string, spillSettings ...config.SpillSettings) *optimization.TableData {
+	cols := columns.NewColumns(nil)
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("region", typing.String))
+
+	topicConfig := kafkalib.TopicConfig{Database: "db", Schema: "public", ObjectKeyTemplate: template}
+	tableData := optimization.NewTableData(cols, config.History, []string{"id"}, topicConfig, "orders")
+	for _, settings := range spillSettings {
+		tableData.EnableSpill(settings)
+	}
+
+	for i, row := range []struct {
+		region        any
+		executionTime time.Time
+		partition     int
+	}{
+		{region: "us", executionTime: time.Date(2025, 1, 1, 23, 59, 0, 0, time.UTC), partition: 0},
+		{region: "eu", executionTime: time.Date(2025, 1, 2, 0, 1, 0, 0, time.UTC), partition: 1},
+		{region: "us", executionTime: time.Date(2025, 1, 2, 0, 2, 0, 0, time.UTC), partition: 1},
+		{region: nil, executionTime: time.Date(2025, 1, 2, 0, 3, 0, 0, time.UTC), partition: 1},
+	} {
+		metadata := optimization.RowMetadata{ExecutionTime: row.executionTime, Operation: "c", Partition: row.partition}
+		assert.NoError(t, tableData.InsertRowWithMetadata(fmt.Sprint(i), map[string]any{"id": int64(i), "region": row.region}, false, metadata))
+	}
+
+	return tableData
+}
+
+func countRows(t *testing.T, rows iter.Seq2[optimization.Row, error]) int {
+	var count int
+	for _, err := range rows {
+		assert.NoError(t, err)
+		count++
+	}
+
+	return count
+}
+
+func TestGroupRowsByPrefix(t *testing.T) {
+	{
+		// No template
+		tableData := newObjectsTableData(t, "")
+		groups, err := GroupRowsByPrefix(tableData, "folder", "db.public.orders", "folder/db.public.orders/date=2025-01-02")
+		assert.NoError(t, err)
+		assert.Len(t, groups, 1)
+		assert.Equal(t, "folder/db.public.orders/date=2025-01-02", groups[0].Prefix)
+		assert.Equal(t, 4, countRows(t, groups[0].Rows))
+	}
+	{
+		// Partitioned by event time, late events land in the day they happened
+		tableData := newObjectsTableData(t, "{folder}/{fqTableName}/dt={yyyy}-{MM}-{dd}")
+		groups, err := GroupRowsByPrefix(tableData, "folder", "db.public.orders", "unused")
+		assert.NoError(t, err)
+		assert.Len(t, groups, 2)
+		assert.Equal(t, "folder/db.public.orders/dt=2025-01-01", groups[0].Prefix)
+		assert.Equal(t, 1, countRows(t, groups[0].Rows))
+		assert.Equal(t, "folder/db.public.orders/dt=2025-01-02", groups[1].Prefix)
+		assert.Equal(t, 3, countRows(t, groups[1].Rows))
+	}
+	{
+		// Partitioned by a column, the operation and the Kafka partition
+		tableData := newObjectsTableData(t, "{table}/region={column:region}/op={operation}/partition={partition}")
+		groups, err := GroupRowsByPrefix(tableData, "", "db.public.orders", "unused")
+		assert.NoError(t, err)
+
+		var prefixes []string
+		for _, group := range groups {
+			prefixes = append(prefixes, group.Prefix)
+		}
+
+		assert.Equal(t, []string{
+			"orders/region=__HIVE_DEFAULT_PARTITION__/op=c/partition=1",
+			"orders/region=eu/op=c/partition=1",
+			"orders/region=us/op=c/partition=0",
+			"orders/region=us/op=c/partition=1",
+		}, prefixes)
+	}
+}
+
+func TestWriteObjects(t *testing.T) {
+	tableData := newObjectsTableData(t, "{column:region}")
+	groups, err := GroupRowsByPrefix(tableData, "", "db.public.orders", "unused")
+	assert.NoError(t, err)
+
+	opts := config.FileFormatOptions{Compression: config.NoCompression}
+	uploaded := make(map[string]string)
+	var filePaths []string
+	assert.NoError(t, WriteObjects(tableData, groups, constants.JSONLFormat, opts, config.SharedDestinationSettings{}, func(prefix string, filePath string) error {
+		assert.True(t, strings.HasSuffix(filePath, ".jsonl"), filePath)
+		uploaded[prefix] = readFile(t, filePath, false)
+		filePaths = append(filePaths, filePath)
+		return nil
+	}))
+
+	assert.Len(t, uploaded, 3)
+	assert.Equal(t, `{"id":1,"region":"eu"}`+"\n", uploaded["eu"])
+	assert.Equal(t, `{"id":0,"region":"us"}`+"\n"+`{"id":2,"region":"us"}`+"\n", uploaded["us"])
+	for _, filePath := range filePaths {
+		// Temporary files should be removed
+		_, err = os.Stat(filePath)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	}
+	{
+		// Spilled rows are streamed from disk into their group
+		spilledTableData := newObjectsTableData(t, "{column:region}", config.SpillSettings{Enabled: true, Directory: t.TempDir()})
+		assert.Equal(t, 4, spilledTableData.SpilledRows())
+		spilledGroups, err := GroupRowsByPrefix(spilledTableData, "", "db.public.orders", "unused")
+		assert.NoError(t, err)
+
+		spilledUploads := make(map[string]string)
+		assert.NoError(t, WriteObjects(spilledTableData, spilledGroups, constants.JSONLFormat, opts, config.SharedDestinationSettings{}, func(prefix string, filePath string) error {
+			spilledUploads[prefix] = readFile(t, filePath, false)
+			return nil
+		}))
+		assert.Equal(t, uploaded, spilledUploads)
+	}
+	{
+		// Upload failure
+		err = WriteObjects(tableData, groups, constants.JSONLFormat, opts, config.SharedDestinationSettings{}, func(_ string, _ string) error {
+			return fmt.Errorf("upload failed")
+		})
+		assert.ErrorContains(t, err, "upload failed")
+	}
+}
@@ -3,9 +3,9 @@ package s3
 import (
 	"context"
 	"fmt"
+	"iter"
 	"log/slog"
 	"os"
-	"slices"
 	"strings"
 	"time"
 
@@ -22,6 +22,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"github.com/artie-labs/transfer/lib/awslib"
+	"github.com/artie-labs/transfer/lib/batch"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/kafkalib"
@@ -29,6 +30,7 @@ import (
 	"github.com/artie-labs/transfer/lib/parquetutil"
 	"github.com/artie-labs/transfer/lib/sql"
 	"github.com/artie-labs/transfer/lib/stringutil"
+	"github.com/artie-labs/transfer/lib/typing/columns"
 	"github.com/artie-labs/transfer/lib/webhooks"
 )
 
@@ -59,6 +61,10 @@ func (s Store) Validate() error {
 	return nil
 }
 
+func (s Store) CheckConnectivity(ctx context.Context) error {
+	return s.s3Client.HeadBucket(ctx, s.config.S3.Bucket)
+}
+
 func (s *Store) IdentifierFor(topicConfig kafkalib.DatabaseAndSchemaPair, table string) sql.TableIdentifier {
 	return NewTableIdentifier(topicConfig.Database, topicConfig.Schema, table, s.config.S3.TableNameSeparator)
 }
@@ -87,13 +93,17 @@ func (s *Store) Append(ctx context.Context, tableData *optimization.TableData, w
 	return nil
 }
 
-func buildTemporaryFilePath(tableData *optimization.TableData) string {
-	return fmt.Sprintf("/tmp/%d_%s.parquet", tableData.GetLatestTimestamp().UnixMilli(), stringutil.Random(4))
+func buildTemporaryFilePath(tableData *optimization.TableData, extension string) string {
+	return fmt.Sprintf("/tmp/%d_%s%s", tableData.GetLatestTimestamp().UnixMilli(), stringutil.Random(4), extension)
 }
 
 // WriteParquetFiles writes the table data to a parquet file at the specified path using Arrow and returns an error if any step of the writing process fails.
 func WriteParquetFiles(tableData *optimization.TableData, filePath string) error {
-	arrowSchema, err := parquetutil.BuildArrowSchemaFromColumns(tableData.ReadOnlyInMemoryCols().ValidColumns())
+	return writeParquetFile(tableData.ReadOnlyInMemoryCols().ValidColumns(), tableData.AllRows(), filePath)
+}
+
+func writeParquetFile(cols []columns.Column, rows iter.Seq2[optimization.Row, error], filePath string) error {
+	arrowSchema, err := parquetutil.BuildArrowSchemaFromColumns(cols)
 	if err != nil {
 		return fmt.Errorf("failed to generate arrow schema: %w", err)
 	}
@@ -113,7 +123,7 @@ func WriteParquetFiles(tableData *optimization.TableData, filePath string) error
 	defer writer.Close()
 
 	// Use streaming approach to write data in batches
-	if err := writeArrowRecordsInBatches(writer, arrowSchema, tableData, batchSize); err != nil {
+	if err := writeArrowRecordsInBatches(writer, arrowSchema, cols, rows, batchSize); err != nil {
 		return fmt.Errorf("failed to write records in batches: %w", err)
 	}
 
@@ -121,19 +131,17 @@ func WriteParquetFiles(tableData *optimization.TableData, filePath string) error
 }
 
 // writeArrowRecordsInBatches processes table data in configurable batch sizes and writes incrementally to reduce memory usage.
-func writeArrowRecordsInBatches(writer *pqarrow.FileWriter, schema *arrow.Schema, tableData *optimization.TableData, batchSize int) error {
+func writeArrowRecordsInBatches(writer *pqarrow.FileWriter, schema *arrow.Schema, cols []columns.Column, rows iter.Seq2[optimization.Row, error], batchSize int) error {
 	pool := memory.NewGoAllocator()
-	rows := tableData.Rows()
-	cols := tableData.ReadOnlyInMemoryCols().ValidColumns()
 	writer.NewBufferedRowGroup()
-	for batch := range slices.Chunk(rows, batchSize) {
+	return batch.ByCount(rows, batchSize, func(chunk []optimization.Row) error {
 		var builders []array.Builder
 		for _, field := range schema.Fields() {
 			builders = append(builders, array.NewBuilder(pool, field.Type))
 		}
 
 		// Process the current batch of rows
-		for _, row := range batch {
+		for _, row := range chunk {
 			for i, col := range cols {
 				value, _ := row.GetValue(col.Name())
 
@@ -161,7 +169,7 @@ func writeArrowRecordsInBatches(writer *pqarrow.FileWriter, schema *arrow.Schema
 			arrays = append(arrays, builder.NewArray())
 		}
 
-		record := array.NewRecordBatch(schema, arrays, int64(len(batch)))
+		record := array.NewRecordBatch(schema, arrays, int64(len(chunk)))
 		if err := writer.WriteBuffered(record); err != nil {
 			record.Release()
 			for _, arr := range arrays {
@@ -182,42 +190,48 @@ func writeArrowRecordsInBatches(writer *pqarrow.FileWriter, schema *arrow.Schema
 		for _, builder := range builders {
 			builder.Release()
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 // Merge - will take tableData, write it into a particular file in the specified format, in these steps:
-// 1. Load a ParquetWriter from a JSON schema (auto-generated)
-// 2. Load the temporary file, under this format: s3://bucket/folderName/fullyQualifiedTableName/YYYY-MM-DD/{{unix_timestamp}}.parquet
-// 3. It will then upload this to S3
+// 1. Group the rows by their object prefix, see [GroupRowsByPrefix]
+// 2. Write each group into a temporary file using the configured output format (parquet, csv or jsonl)
+// 3. It will then upload this to S3, under this format: s3://bucket/{{prefix}}/{{unix_timestamp}}.{{extension}}
 // 4. Delete the temporary file
 func (s *Store) Merge(ctx context.Context, tableData *optimization.TableData, whClient *webhooks.Client) (bool, error) {
 	if tableData.ShouldSkipUpdate() {
 		return false, nil
 	}
 
-	fp := buildTemporaryFilePath(tableData)
-	if err := WriteParquetFiles(tableData, fp); err != nil {
+	settings := s.config.S3
+	tableID := s.IdentifierFor(tableData.TopicConfig().BuildDatabaseAndSchemaPair(), tableData.Name())
+	groups, err := GroupRowsByPrefix(tableData, settings.FolderName, tableID.FullyQualifiedName(), s.ObjectPrefix(tableData))
+	if err != nil {
 		return false, err
 	}
 
-	defer func() {
-		// Delete the file regardless of outcome to avoid fs build up.
-		if removeErr := os.RemoveAll(fp); removeErr != nil {
-			slog.Warn("Failed to delete temp file", slog.Any("err", removeErr), slog.String("filePath", fp))
+	if err = WriteObjects(tableData, groups, settings.OutputFormat, settings.FormatOptions, s.config.SharedDestinationSettings, func(prefix string, filePath string) error {
+		s3Path, err := s.s3Client.UploadLocalFileToS3(ctx, settings.Bucket, prefix, filePath)
+		if err != nil {
+			return fmt.Errorf("failed to upload file to s3: %w", err)
 		}
-	}()
 
-	s3Path, err := s.s3Client.UploadLocalFileToS3(ctx, s.config.S3.Bucket, s.ObjectPrefix(tableData), fp)
-	if err != nil {
-		return false, fmt.Errorf("failed to upload file to s3: %w", err)
+		slog.Info("Successfully wrote and uploaded file to S3", slog.String("format", string(settings.OutputFormat)), slog.String("filePath", filePath), slog.String("s3Path", s3Path))
+		return nil
+	}); err != nil {
+		return false, err
 	}
 
-	slog.Info("Successfully wrote and uploaded Parquet file to S3", slog.String("filePath", fp), slog.String("s3Path", s3Path))
 	return true, nil
 }
 
+// ShouldDeferFlush implements [destination.FlushDeferrer] so that small flushes can be rolled up into larger files.
+func (s *Store) ShouldDeferFlush(tableData *optimization.TableData, lastFlushTime time.Time) bool {
+	return s.config.S3.Rollup.ShouldDefer(tableData.ApproxSize(), lastFlushTime)
+}
+
 func (s *Store) IsRetryableError(_ error) bool {
 	return false // not supported for S3
 }
@@ -19,7 +19,7 @@ func TestBuildTemporaryFilePath(t *testing.T) {
 	td := &optimization.TableData{}
 	td.SetLatestTimestamp(ts)
 
-	fp := buildTemporaryFilePath(td)
+	fp := buildTemporaryFilePath(td, ".parquet")
 	assert.True(t, strings.HasPrefix(fp, "/tmp/1577836800000_"), fp)
 	assert.True(t, strings.HasSuffix(fp, ".parquet"), fp)
 }
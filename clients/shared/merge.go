@@ -2,11 +2,14 @@ package shared
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"slices"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/artie-labs/transfer/lib"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/destination"
@@ -14,6 +17,7 @@ import (
 	"github.com/artie-labs/transfer/lib/destination/types"
 	"github.com/artie-labs/transfer/lib/jitter"
 	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/telemetry/tracing"
 	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/typing/columns"
 	"github.com/artie-labs/transfer/lib/webhooks"
@@ -25,12 +29,17 @@ const (
 	heartbeatsInterval     = 2 * time.Minute
 )
 
-func Merge(ctx context.Context, dest destination.SQLDestination, tableData *optimization.TableData, opts types.MergeOpts, whClient *webhooks.Client) error {
+func Merge(ctx context.Context, dest destination.SQLDestination, tableData *optimization.TableData, opts types.MergeOpts, whClient *webhooks.Client) (err error) {
 	if tableData.ShouldSkipUpdate() {
 		return nil
 	}
 
 	tableID := dest.IdentifierFor(tableData.TopicConfig().BuildDatabaseAndSchemaPair(), tableData.Name())
+	ctx, span := tracing.Start(ctx, "merge", attribute.String("table", tableID.FullyQualifiedName()), attribute.Int("rows", int(tableData.NumberOfRows())))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	hb := lib.NewHeartbeats(heartbeatsInitialDelay, heartbeatsInterval, "merge", map[string]any{
 		"table":   tableData.Name(),
 		"tableID": tableID.FullyQualifiedName(),
@@ -39,11 +48,22 @@ func Merge(ctx context.Context, dest destination.SQLDestination, tableData *opti
 	stop := hb.Start()
 	defer stop()
 
-	tableConfig, err := dest.GetTableConfig(ctx, tableID, tableData.TopicConfig().DropDeletedColumns)
+	tableConfig, err := tracing.RunWithResult(ctx, "merge.get_table_config", func(ctx context.Context) (*types.DestinationTableConfig, error) {
+		return dest.GetTableConfig(ctx, tableID, tableData.TopicConfig().DropDeletedColumns)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to get table config: %w", err)
 	}
 
+	if !tableConfig.CreateTable() && dest.GetConfig().SharedDestinationSettings.WidenColumnTypes {
+		// This needs to happen before the columns are diffed, otherwise a column that failed in the middle of being widened would be added back.
+		if err = tracing.Run(ctx, "merge.recover_widened_columns", func(ctx context.Context) error {
+			return recoverWidenedColumns(ctx, dest, tableConfig, tableID, whClient)
+		}); err != nil {
+			return fmt.Errorf("failed to recover widened columns for table %q: %w", tableID.Table(), err)
+		}
+	}
+
 	srcKeysMissing, targetKeysMissing := columns.DiffAndFilter(
 		tableData.ReadOnlyInMemoryCols().GetColumns(),
 		tableConfig.GetColumns(),
@@ -53,16 +73,30 @@ func Merge(ctx context.Context, dest destination.SQLDestination, tableData *opti
 	columnSettings := opts.ColumnSettings
 	columnSettings.SkipPrimaryKeyCreation = tableData.TopicConfig().SkipPrimaryKeyCreation
 	if tableConfig.CreateTable() {
-		if err = CreateTable(ctx, dest, tableData.Mode(), tableConfig, columnSettings, tableID, false, targetKeysMissing, whClient); err != nil {
+		if err = tracing.Run(ctx, "merge.create_table", func(ctx context.Context) error {
+			return CreateTable(ctx, dest, tableData.Mode(), tableConfig, columnSettings, tableID, false, targetKeysMissing, whClient)
+		}); err != nil {
 			return fmt.Errorf("failed to create table: %w", err)
 		}
 	} else {
-		if err = AlterTableAddColumns(ctx, dest, tableConfig, columnSettings, tableID, targetKeysMissing, whClient); err != nil {
+		if err = tracing.Run(ctx, "merge.alter_table_add_columns", func(ctx context.Context) error {
+			return AlterTableAddColumns(ctx, dest, tableConfig, columnSettings, tableID, targetKeysMissing, whClient)
+		}, attribute.Int("columns", len(targetKeysMissing))); err != nil {
 			return fmt.Errorf("failed to add columns for table %q: %w", tableID.Table(), err)
 		}
+
+		if dest.GetConfig().SharedDestinationSettings.WidenColumnTypes {
+			if err = tracing.Run(ctx, "merge.alter_table_widen_columns", func(ctx context.Context) error {
+				return AlterTableWidenColumns(ctx, dest, tableConfig, columnSettings, tableID, tableData.ReadOnlyInMemoryCols().GetColumns(), whClient)
+			}); err != nil {
+				return fmt.Errorf("failed to widen columns for table %q: %w", tableID.Table(), err)
+			}
+		}
 	}
 
-	if err = AlterTableDropColumns(ctx, dest, tableConfig, tableID, srcKeysMissing, tableData.GetLatestTimestamp(), tableData.ContainsOtherOperations(), whClient); err != nil {
+	if err = tracing.Run(ctx, "merge.alter_table_drop_columns", func(ctx context.Context) error {
+		return AlterTableDropColumns(ctx, dest, tableConfig, tableID, srcKeysMissing, tableData.GetLatestTimestamp(), tableData.ContainsOtherOperations(), whClient)
+	}); err != nil {
 		return fmt.Errorf("failed to drop columns for table %q: %w", tableID.Table(), err)
 	}
 
@@ -84,7 +118,9 @@ func Merge(ctx context.Context, dest destination.SQLDestination, tableData *opti
 					config.GetStagingTableSuffix(),
 				),
 			).WithTemporaryTable(true)
-			if err = stagingManager.PrepareReusableStagingTable(ctx, tableData, tableConfig, stagingTableID, tableID, types.AdditionalSettings{ColumnSettings: opts.ColumnSettings}); err != nil {
+			if err = tracing.Run(ctx, "merge.prepare_reusable_staging_table", func(ctx context.Context) error {
+				return stagingManager.PrepareReusableStagingTable(ctx, tableData, tableConfig, stagingTableID, tableID, types.AdditionalSettings{ColumnSettings: opts.ColumnSettings})
+			}); err != nil {
 				return fmt.Errorf("failed to prepare reusable staging table: %w", err)
 			}
 
@@ -99,7 +135,9 @@ func Merge(ctx context.Context, dest destination.SQLDestination, tableData *opti
 			}
 		}()
 
-		if err = dest.LoadDataIntoTable(ctx, tableData, tableConfig, temporaryTableID, tableID, types.AdditionalSettings{ColumnSettings: opts.ColumnSettings}, true); err != nil {
+		if err = tracing.Run(ctx, "merge.load_data_into_table", func(ctx context.Context) error {
+			return dest.LoadDataIntoTable(ctx, tableData, tableConfig, temporaryTableID, tableID, types.AdditionalSettings{ColumnSettings: opts.ColumnSettings}, true)
+		}); err != nil {
 			return fmt.Errorf("failed to prepare temporary table: %w", err)
 		}
 
@@ -116,9 +154,10 @@ func Merge(ctx context.Context, dest destination.SQLDestination, tableData *opti
 			continue
 		}
 
+		backfillCtx, backfillSpan := tracing.Start(ctx, "merge.backfill_column", attribute.String("column", col.Name()))
 		var backfillErr error
 		for attempts := 0; attempts < backfillMaxRetries; attempts++ {
-			backfillErr = BackfillColumn(ctx, dest, col, tableID)
+			backfillErr = BackfillColumn(backfillCtx, dest, col, tableID)
 			if backfillErr == nil {
 				if err := tableConfig.UpsertColumn(col.Name(), columns.UpsertColumnArg{
 					Backfilled: typing.ToPtr(true),
@@ -138,6 +177,7 @@ func Merge(ctx context.Context, dest destination.SQLDestination, tableData *opti
 			}
 		}
 
+		tracing.End(backfillSpan, backfillErr)
 		if backfillErr != nil {
 			return fmt.Errorf("failed to backfill col: %s, default value: %v, err: %w", col.Name(), col.DefaultValue(), backfillErr)
 		}
@@ -186,7 +226,9 @@ func Merge(ctx context.Context, dest destination.SQLDestination, tableData *opti
 		return fmt.Errorf("failed to generate merge statements: %w", err)
 	}
 
-	results, err := destination.ExecContextStatements(ctx, dest, mergeStatements)
+	results, err := tracing.RunWithResult(ctx, "merge.execute_merge_queries", func(ctx context.Context) ([]sql.Result, error) {
+		return destination.ExecContextStatements(ctx, dest, mergeStatements)
+	}, attribute.Int("statements", len(mergeStatements)))
 	if err != nil {
 		return fmt.Errorf("failed to execute merge statements: %w", err)
 	}
@@ -0,0 +1,139 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/ddl"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+// TablePlan contains the statements that flushing a table would run against the destination.
+type TablePlan struct {
+	TableID sql.TableIdentifier
+	Rows    uint
+	// CreateTable is true if the target table does not exist yet.
+	CreateTable bool
+	// DDL creates the target table or adds and widens its columns.
+	DDL []string
+	// DropColumns are only run once the columns have been missing for [constants.DeletionConfidencePadding] and [kafkalib.TopicConfig.DropDeletedColumns] is enabled.
+	DropColumns []string
+	// Staging creates the temporary table that rows are loaded into before merging, this is empty for append-only tables.
+	Staging []string
+	// Merge merges the staging table into the target table, this is empty for append-only tables since rows are loaded directly.
+	Merge []string
+}
+
+// BuildPlan returns the statements that [Merge] (or [Append] for history mode and append-only topics) would run for [tableData].
+// The destination is only read from to retrieve the table's current schema, nothing is executed.
+// Destinations may pass additional [types.MergeOpts] when merging, so the merge statements are built with the defaults.
+func BuildPlan(ctx context.Context, dest destination.SQLDestination, tableData *optimization.TableData) (TablePlan, error) {
+	tableID := dest.IdentifierFor(tableData.TopicConfig().BuildDatabaseAndSchemaPair(), tableData.Name())
+	plan := TablePlan{TableID: tableID, Rows: tableData.NumberOfRows()}
+
+	tableConfig, err := dest.GetTableConfig(ctx, tableID, tableData.TopicConfig().DropDeletedColumns)
+	if err != nil {
+		return TablePlan{}, fmt.Errorf("failed to get table config: %w", err)
+	}
+
+	srcKeysMissing, targetKeysMissing := columns.DiffAndFilter(
+		tableData.ReadOnlyInMemoryCols().GetColumns(),
+		tableConfig.GetColumns(),
+		tableData.BuildColumnsToKeep(),
+	)
+
+	cfg := dest.GetConfig()
+	columnSettings := cfg.SharedDestinationSettings.ColumnSettings
+	columnSettings.SkipPrimaryKeyCreation = tableData.TopicConfig().SkipPrimaryKeyCreation
+	plan.CreateTable = tableConfig.CreateTable()
+	if plan.CreateTable {
+		if cols := getValidColumns(targetKeysMissing); len(cols) > 0 {
+			query, err := ddl.BuildCreateTableSQL(columnSettings, dest.Dialect(), tableID, false, tableData.Mode(), cols)
+			if err != nil {
+				return TablePlan{}, fmt.Errorf("failed to build create table sql: %w", err)
+			}
+			plan.DDL = append(plan.DDL, query)
+		}
+	} else {
+		queries, err := ddl.BuildAlterTableAddColumns(columnSettings, dest.Dialect(), tableID, getValidColumns(targetKeysMissing))
+		if err != nil {
+			return TablePlan{}, fmt.Errorf("failed to build alter table add columns: %w", err)
+		}
+		plan.DDL = append(plan.DDL, queries...)
+
+		if cfg.SharedDestinationSettings.WidenColumnTypes {
+			queries, err = buildWidenColumnsQueries(dest.Dialect(), tableConfig, columnSettings, tableID, tableData.ReadOnlyInMemoryCols().GetColumns())
+			if err != nil {
+				return TablePlan{}, fmt.Errorf("failed to build widen columns queries: %w", err)
+			}
+			plan.DDL = append(plan.DDL, queries...)
+		}
+	}
+
+	appendOnly := tableData.Mode() == config.History || tableData.TopicConfig().AppendOnly
+	if !appendOnly && tableConfig.DropDeletedColumns() && tableData.ContainsOtherOperations() {
+		for _, col := range srcKeysMissing {
+			query, err := ddl.BuildAlterTableDropColumns(dest.Dialect(), tableID, col)
+			if err != nil {
+				return TablePlan{}, fmt.Errorf("failed to build alter table drop columns: %w", err)
+			}
+			plan.DropColumns = append(plan.DropColumns, query)
+		}
+	}
+
+	if appendOnly {
+		return plan, nil
+	}
+
+	if err = tableData.MergeColumnsFromDestination(tableConfig.GetColumns()...); err != nil {
+		return TablePlan{}, fmt.Errorf("failed to merge columns from destination: %w", err)
+	}
+
+	temporaryTableID := TempTableIDWithSuffix(dest, dest.IdentifierFor(tableData.TopicConfig().BuildStagingDatabaseAndSchemaPair(), tableData.Name()), tableData.TempTableSuffix())
+	if cols := getValidColumns(tableData.ReadOnlyInMemoryCols().GetColumns()); len(cols) > 0 {
+		query, err := ddl.BuildCreateTableSQL(columnSettings, dest.Dialect(), temporaryTableID, true, tableData.Mode(), cols)
+		if err != nil {
+			return TablePlan{}, fmt.Errorf("failed to build create temporary table sql: %w", err)
+		}
+		plan.Staging = append(plan.Staging, query)
+	}
+
+	cols := tableData.ReadOnlyInMemoryCols()
+	var primaryKeys []columns.Column
+	for _, primaryKey := range tableData.PrimaryKeys() {
+		column, ok := cols.GetColumn(primaryKey)
+		if !ok {
+			return TablePlan{}, fmt.Errorf("column for primary key %q does not exist", primaryKey)
+		}
+		primaryKeys = append(primaryKeys, column)
+	}
+
+	if len(primaryKeys) == 0 {
+		return TablePlan{}, fmt.Errorf("primary keys cannot be empty")
+	}
+
+	validColumns := cols.ValidColumns()
+	if len(validColumns) == 0 {
+		return TablePlan{}, fmt.Errorf("columns cannot be empty")
+	}
+
+	plan.Merge, err = dest.Dialect().BuildMergeQueries(
+		tableID,
+		temporaryTableID.FullyQualifiedName(),
+		primaryKeys,
+		nil,
+		validColumns,
+		tableData.TopicConfig().SoftDelete,
+		tableData.ContainsHardDeletes(),
+		false,
+	)
+	if err != nil {
+		return TablePlan{}, fmt.Errorf("failed to generate merge statements: %w", err)
+	}
+
+	return plan, nil
+}
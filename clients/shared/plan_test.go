@@ -0,0 +1,104 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	postgresDialect "github.com/artie-labs/transfer/clients/postgres/dialect"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func buildPlanDestination(destCols []columns.Column, cfg config.Config) *mocks.FakeSQLDestination {
+	fakeDest := &mocks.FakeSQLDestination{}
+	fakeDest.DialectReturns(postgresDialect.PostgresDialect{})
+	fakeDest.GetConfigReturns(cfg)
+	fakeDest.GetTableConfigReturns(types.NewDestinationTableConfig(destCols, true), nil)
+	fakeDest.IdentifierForCalls(func(pair kafkalib.DatabaseAndSchemaPair, table string) sql.TableIdentifier {
+		return postgresDialect.NewTableIdentifier(pair.Schema, table)
+	})
+	return fakeDest
+}
+
+func buildPlanTableData(t *testing.T, tc kafkalib.TopicConfig, mode config.Mode) *optimization.TableData {
+	id := columns.NewColumn("id", typing.Integer)
+	id.SetPrimaryKeyForTest(true)
+	cols := columns.NewColumns([]columns.Column{
+		id,
+		columns.NewColumn("name", typing.String),
+		columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean),
+		columns.NewColumn(constants.OnlySetDeleteColumnMarker, typing.Boolean),
+	})
+
+	tableData := optimization.NewTableData(cols, mode, []string{"id"}, tc, "orders")
+	assert.NoError(t, tableData.InsertRow("1", map[string]any{"id": 1, "name": "foo"}, false))
+	return tableData
+}
+
+func TestBuildPlan(t *testing.T) {
+	tc := kafkalib.TopicConfig{Schema: "public"}
+	{
+		// Table does not exist yet
+		fakeDest := buildPlanDestination(nil, config.Config{})
+		plan, err := BuildPlan(t.Context(), fakeDest, buildPlanTableData(t, tc, config.Replication))
+		assert.NoError(t, err)
+		assert.Equal(t, `"public"."orders"`, plan.TableID.FullyQualifiedName())
+		assert.Equal(t, uint(1), plan.Rows)
+		assert.True(t, plan.CreateTable)
+		assert.Equal(t, []string{`CREATE TABLE "public"."orders" ("id" bigint,"name" text,PRIMARY KEY ("id"));`}, plan.DDL)
+		assert.Empty(t, plan.DropColumns)
+		assert.Len(t, plan.Staging, 1)
+		assert.Contains(t, plan.Staging[0], `CREATE TABLE "public"."orders___artie_`)
+		assert.Len(t, plan.Merge, 1)
+		assert.Contains(t, plan.Merge[0], `MERGE INTO "public"."orders"`)
+
+		// Nothing should have been executed
+		assert.Zero(t, fakeDest.ExecContextCallCount())
+	}
+	{
+		// Table exists, a column is added and another one is missing from the batch
+		destCols := []columns.Column{
+			columns.NewColumn("id", typing.Integer),
+			columns.NewColumn("deleted_column", typing.String),
+		}
+
+		fakeDest := buildPlanDestination(destCols, config.Config{})
+		plan, err := BuildPlan(t.Context(), fakeDest, buildPlanTableData(t, tc, config.Replication))
+		assert.NoError(t, err)
+		assert.False(t, plan.CreateTable)
+		assert.Equal(t, []string{`ALTER TABLE "public"."orders" ADD COLUMN IF NOT EXISTS "name" text`}, plan.DDL)
+		assert.Equal(t, []string{`ALTER TABLE "public"."orders" DROP COLUMN IF EXISTS "deleted_column"`}, plan.DropColumns)
+		assert.Len(t, plan.Staging, 1)
+		assert.Len(t, plan.Merge, 1)
+		assert.Zero(t, fakeDest.ExecContextCallCount())
+	}
+	{
+		// Widening column types
+		destCols := []columns.Column{
+			columns.NewColumn("id", typing.Integer),
+			columns.NewColumn("name", typing.KindDetails{Kind: typing.String.Kind, OptionalStringPrecision: typing.ToPtr(int32(50))}),
+		}
+
+		fakeDest := buildPlanDestination(destCols, config.Config{SharedDestinationSettings: config.SharedDestinationSettings{WidenColumnTypes: true}})
+		plan, err := BuildPlan(t.Context(), fakeDest, buildPlanTableData(t, tc, config.Replication))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`ALTER TABLE "public"."orders" ALTER COLUMN "name" TYPE text`}, plan.DDL)
+	}
+	{
+		// Append-only topics are loaded directly into the target table
+		fakeDest := buildPlanDestination(nil, config.Config{})
+		plan, err := BuildPlan(t.Context(), fakeDest, buildPlanTableData(t, kafkalib.TopicConfig{Schema: "public", AppendOnly: true}, config.Replication))
+		assert.NoError(t, err)
+		assert.Len(t, plan.DDL, 1)
+		assert.Empty(t, plan.Staging)
+		assert.Empty(t, plan.Merge)
+	}
+}
@@ -129,6 +129,9 @@ func (g GetTableCfgArgs) buildColumnFromRow(row map[string]any) (columns.Column,
 		val, ok := row["default_value"]
 		if ok && val != nil {
 			col.SetBackfilled(true)
+			if defaultValue, err := asString(val); err == nil {
+				col.SetDestinationDefault(defaultValue)
+			}
 		}
 
 	case sql.NotImplemented:
@@ -137,6 +140,15 @@ func (g GetTableCfgArgs) buildColumnFromRow(row map[string]any) (columns.Column,
 		return columns.Column{}, fmt.Errorf("unknown default value strategy: %q", strategy)
 	}
 
+	if val, ok := row["is_nullable"]; ok && val != nil {
+		isNullable, err := asString(val)
+		if err != nil {
+			return columns.Column{}, fmt.Errorf("failed to get nullability: %w", err)
+		}
+
+		col.SetNotNull(strings.EqualFold(isNullable, "NO"))
+	}
+
 	return col, nil
 }
 
@@ -88,7 +88,11 @@ func (t TemporaryDataFile) WriteTemporaryTableFile(tableData *optimization.Table
 
 	columnToNewLengthMap := make(map[string]int32)
 	columns := tableData.ReadOnlyInMemoryCols().ValidColumns()
-	for _, row := range tableData.Rows() {
+	for row, err := range tableData.AllRows() {
+		if err != nil {
+			return File{}, AdditionalOutput{}, fmt.Errorf("failed to read row: %w", err)
+		}
+
 		var csvValues []string
 		for _, col := range columns {
 			value, _ := row.GetValue(col.Name())
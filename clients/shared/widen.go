@@ -0,0 +1,267 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/webhooks"
+)
+
+const (
+	widenedColumnSuffix  = constants.ArtiePrefix + "_widened"
+	previousColumnSuffix = constants.ArtiePrefix + "_previous"
+)
+
+type columnWidening struct {
+	// column is the destination column with its [KindDetails] set to the widened kind.
+	column     columns.Column
+	dataType   string
+	primaryKey bool
+}
+
+// columnsToWiden returns the destination columns that are narrower than their in-memory counterpart.
+// The in-memory kind is round-tripped through the dialect so that we compare against what the destination will create (e.g. an unspecified integer becomes BIGINT)
+// and what it will report back afterwards, otherwise we would keep widening the same column.
+func columnsToWiden(dialect sql.Dialect, settings config.SharedDestinationColumnSettings, inMemoryCols []columns.Column, tc *types.DestinationTableConfig) ([]columnWidening, error) {
+	destCols := columns.NewColumns(tc.GetColumns())
+
+	var widenings []columnWidening
+	for _, col := range inMemoryCols {
+		if col.ShouldSkip() || strings.Contains(col.Name(), constants.ArtiePrefix) {
+			continue
+		}
+
+		destCol, ok := destCols.GetColumn(col.Name())
+		if !ok || destCol.KindDetails.Kind != col.KindDetails.Kind {
+			continue
+		}
+
+		dataType, err := dialect.DataTypeForKind(col.KindDetails, col.PrimaryKey() && !settings.SkipPrimaryKeyCreation, settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get data type for column %q: %w", col.Name(), err)
+		}
+
+		widenedKind, err := dialect.KindForDataType(dataType)
+		if err != nil {
+			widenedKind = col.KindDetails
+		}
+
+		if !typing.IsWidening(destCol.KindDetails, widenedKind) {
+			continue
+		}
+
+		destCol.KindDetails = widenedKind
+		widenings = append(widenings, columnWidening{column: destCol, dataType: dataType, primaryKey: col.PrimaryKey()})
+	}
+
+	return widenings, nil
+}
+
+func execDDL(ctx context.Context, dest destination.SQLDestination, tableID sql.TableIdentifier, query string, whClient *webhooks.Client) error {
+	slog.Info("[DDL] Executing query", slog.String("query", query))
+	if _, err := dest.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	emitDDLApplied(ctx, whClient, tableID, query)
+	return nil
+}
+
+type copySwapQueries struct {
+	addColumn   string
+	copyValues  string
+	swapColumns []string
+}
+
+func buildCopySwapQueries(dialect sql.Dialect, tableID sql.TableIdentifier, widening columnWidening) copySwapQueries {
+	colName := dialect.QuoteIdentifier(widening.column.Name())
+	widenedColName := dialect.QuoteIdentifier(widening.column.Name() + widenedColumnSuffix)
+	previousColName := dialect.QuoteIdentifier(widening.column.Name() + previousColumnSuffix)
+	return copySwapQueries{
+		addColumn:  dialect.BuildAddColumnQuery(tableID, fmt.Sprintf("%s %s", widenedColName, widening.dataType)),
+		copyValues: fmt.Sprintf("UPDATE %s SET %s = %s WHERE TRUE", tableID.FullyQualifiedName(), widenedColName, colName),
+		swapColumns: []string{
+			sql.DefaultBuildRenameColumnQuery(tableID, colName, previousColName),
+			sql.DefaultBuildRenameColumnQuery(tableID, widenedColName, colName),
+			dialect.BuildDropColumnQuery(tableID, previousColName),
+		},
+	}
+}
+
+// columnRenameValidator is implemented by destinations that can only rename and drop columns on some tables, e.g. Databricks requires column mapping.
+type columnRenameValidator interface {
+	ValidateColumnRename(ctx context.Context, tableID sql.TableIdentifier) error
+}
+
+// copySwapColumn widens a column for dialects that cannot change the data type in place.
+// The values are copied into a new column which then takes over the original column's name, the original column is renamed before it is dropped so that its values are kept if we fail midway.
+// If the dialect supports [sql.TransactionalDDL] this is done in a single transaction, otherwise [recoverWidenedColumns] will finish or undo the swap on the next flush.
+func copySwapColumn(ctx context.Context, dest destination.SQLDestination, tableID sql.TableIdentifier, widening columnWidening, whClient *webhooks.Client) error {
+	if validator, ok := dest.(columnRenameValidator); ok {
+		if err := validator.ValidateColumnRename(ctx, tableID); err != nil {
+			return err
+		}
+	}
+
+	queries := buildCopySwapQueries(dest.Dialect(), tableID, widening)
+	if transactional, ok := dest.Dialect().(sql.TransactionalDDL); ok && transactional.SupportsTransactionalDDL() {
+		statements := append([]string{queries.addColumn, queries.copyValues}, queries.swapColumns...)
+		slog.Info("[DDL] Executing queries in a transaction", slog.Any("queries", statements))
+		if _, err := destination.ExecContextStatements(ctx, dest, statements); err != nil {
+			return fmt.Errorf("failed to swap columns: %w", err)
+		}
+
+		for _, query := range append([]string{queries.addColumn}, queries.swapColumns...) {
+			emitDDLApplied(ctx, whClient, tableID, query)
+		}
+
+		return nil
+	}
+
+	if err := addColumn(ctx, dest, tableID, queries.addColumn, 0, whClient); err != nil {
+		return fmt.Errorf("failed to add column: %w", err)
+	}
+
+	slog.Info("Copying column values", slog.String("query", queries.copyValues))
+	if _, err := dest.ExecContext(ctx, queries.copyValues); err != nil {
+		return fmt.Errorf("failed to copy column values: %w", err)
+	}
+
+	for _, query := range queries.swapColumns {
+		if err := execDDL(ctx, dest, tableID, query, whClient); err != nil {
+			return fmt.Errorf("failed to swap columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// recoverWidenedColumns finishes or undoes a [copySwapColumn] that failed midway, based on the columns that it left behind.
+// This needs to run before any columns are added, otherwise a column that failed in between the renames would be added back as an empty column.
+func recoverWidenedColumns(ctx context.Context, dest destination.SQLDestination, tc *types.DestinationTableConfig, tableID sql.TableIdentifier, whClient *webhooks.Client) error {
+	destCols := columns.NewColumns(tc.GetColumns())
+	names := make(map[string]bool)
+	for _, col := range destCols.GetColumns() {
+		if name, ok := strings.CutSuffix(col.Name(), widenedColumnSuffix); ok {
+			names[name] = true
+		} else if name, ok = strings.CutSuffix(col.Name(), previousColumnSuffix); ok {
+			names[name] = true
+		}
+	}
+
+	dialect := dest.Dialect()
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		_, hasOriginal := destCols.GetColumn(name)
+		widened, hasWidened := destCols.GetColumn(name + widenedColumnSuffix)
+		previous, hasPrevious := destCols.GetColumn(name + previousColumnSuffix)
+
+		var queries []string
+		switch {
+		case hasOriginal && hasWidened && !hasPrevious:
+			// We failed while adding the widened column or copying the values over, drop it so that the widening starts over.
+			queries = []string{dialect.BuildDropColumnQuery(tableID, dialect.QuoteIdentifier(widened.Name()))}
+			tc.MutateInMemoryColumns(constants.DropColumn, []columns.Column{widened})
+		case !hasOriginal && hasWidened && hasPrevious:
+			// We failed in between the renames, the widened column already has every value so it can take over the original column's name.
+			queries = []string{
+				sql.DefaultBuildRenameColumnQuery(tableID, dialect.QuoteIdentifier(widened.Name()), dialect.QuoteIdentifier(name)),
+				dialect.BuildDropColumnQuery(tableID, dialect.QuoteIdentifier(previous.Name())),
+			}
+			tc.MutateInMemoryColumns(constants.DropColumn, []columns.Column{widened, previous})
+			tc.MutateInMemoryColumns(constants.AddColumn, []columns.Column{columns.NewColumn(name, widened.KindDetails)})
+		case hasOriginal && !hasWidened && hasPrevious:
+			// We failed before dropping the original column.
+			queries = []string{dialect.BuildDropColumnQuery(tableID, dialect.QuoteIdentifier(previous.Name()))}
+			tc.MutateInMemoryColumns(constants.DropColumn, []columns.Column{previous})
+		default:
+			return fmt.Errorf("column %q was left in an unexpected state by a failed widening (original: %t, widened: %t, previous: %t), this needs to be resolved manually", name, hasOriginal, hasWidened, hasPrevious)
+		}
+
+		slog.Warn("Recovering column from a failed widening", slog.String("column", name), slog.String("table", tableID.FullyQualifiedName()))
+		for _, query := range queries {
+			if err := execDDL(ctx, dest, tableID, query, whClient); err != nil {
+				return fmt.Errorf("failed to recover column %q: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildWidenColumnsQueries returns the statements that [AlterTableWidenColumns] would run, without executing them.
+func buildWidenColumnsQueries(dialect sql.Dialect, tc *types.DestinationTableConfig, settings config.SharedDestinationColumnSettings, tableID sql.TableIdentifier, cols []columns.Column) ([]string, error) {
+	widenings, err := columnsToWiden(dialect, settings, cols, tc)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []string
+	for _, widening := range widenings {
+		if alterer, ok := dialect.(sql.ColumnTypeAlterer); ok {
+			if query, ok := alterer.BuildAlterColumnTypeQuery(tableID, widening.column, widening.dataType); ok {
+				queries = append(queries, query)
+				continue
+			}
+		}
+
+		if widening.primaryKey {
+			continue
+		}
+
+		copySwap := buildCopySwapQueries(dialect, tableID, widening)
+		queries = append(queries, copySwap.addColumn, copySwap.copyValues)
+		queries = append(queries, copySwap.swapColumns...)
+	}
+
+	return queries, nil
+}
+
+// AlterTableWidenColumns widens destination columns whose type is narrower than the in-memory column, e.g. INT to BIGINT or VARCHAR(50) to TEXT.
+// Dialects that implement [sql.ColumnTypeAlterer] will change the data type in place, otherwise the values will be copied over to a new column.
+func AlterTableWidenColumns(ctx context.Context, dest destination.SQLDestination, tc *types.DestinationTableConfig, settings config.SharedDestinationColumnSettings, tableID sql.TableIdentifier, cols []columns.Column, whClient *webhooks.Client) error {
+	widenings, err := columnsToWiden(dest.Dialect(), settings, cols, tc)
+	if err != nil {
+		return err
+	}
+
+	for _, widening := range widenings {
+		if alterer, ok := dest.Dialect().(sql.ColumnTypeAlterer); ok {
+			if query, ok := alterer.BuildAlterColumnTypeQuery(tableID, widening.column, widening.dataType); ok {
+				if err = execDDL(ctx, dest, tableID, query, whClient); err != nil {
+					return fmt.Errorf("failed to alter column %q: %w", widening.column.Name(), err)
+				}
+
+				tc.UpdateColumn(widening.column)
+				continue
+			}
+		}
+
+		if widening.primaryKey {
+			// Dropping the original column would also drop the primary key constraint.
+			slog.Warn("Skipping widening primary key column since it cannot be altered in place",
+				slog.String("column", widening.column.Name()),
+				slog.String("dataType", widening.dataType),
+			)
+			continue
+		}
+
+		if err = copySwapColumn(ctx, dest, tableID, widening, whClient); err != nil {
+			return fmt.Errorf("failed to widen column %q: %w", widening.column.Name(), err)
+		}
+
+		tc.UpdateColumn(widening.column)
+	}
+
+	return nil
+}
@@ -0,0 +1,322 @@
+package shared
+
+import (
+	"context"
+	gosql "database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/stretchr/testify/assert"
+
+	bigqueryDialect "github.com/artie-labs/transfer/clients/bigquery/dialect"
+	databricksDialect "github.com/artie-labs/transfer/clients/databricks/dialect"
+	mssqlDialect "github.com/artie-labs/transfer/clients/mssql/dialect"
+	mysqlDialect "github.com/artie-labs/transfer/clients/mysql/dialect"
+	postgresDialect "github.com/artie-labs/transfer/clients/postgres/dialect"
+	redshiftDialect "github.com/artie-labs/transfer/clients/redshift/dialect"
+	snowflakeDialect "github.com/artie-labs/transfer/clients/snowflake/dialect"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/mocks"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+func buildWideningColumns() ([]columns.Column, []columns.Column) {
+	destCols := []columns.Column{
+		columns.NewColumn("id", typing.BuildIntegerKind(typing.BigIntegerKind)),
+		columns.NewColumn("count", typing.BuildIntegerKind(typing.IntegerKind)),
+		columns.NewColumn("name", typing.KindDetails{Kind: typing.String.Kind, OptionalStringPrecision: typing.ToPtr(int32(50))}),
+		columns.NewColumn("price", typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(10, 2))),
+		columns.NewColumn("active", typing.Boolean),
+	}
+
+	inMemoryCols := []columns.Column{
+		columns.NewColumn("id", typing.Integer),
+		columns.NewColumn("count", typing.Integer),
+		columns.NewColumn("name", typing.String),
+		columns.NewColumn("price", typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(20, 4))),
+		columns.NewColumn("active", typing.Boolean),
+		columns.NewColumn("new_column", typing.String),
+	}
+
+	return destCols, inMemoryCols
+}
+
+func TestAlterTableWidenColumns_InPlace(t *testing.T) {
+	destCols, inMemoryCols := buildWideningColumns()
+	tc := types.NewDestinationTableConfig(destCols, false)
+	fakeDest := &mocks.FakeSQLDestination{}
+	fakeDest.DialectReturns(postgresDialect.PostgresDialect{})
+
+	tableID := postgresDialect.NewTableIdentifier("public", "orders")
+	assert.NoError(t, AlterTableWidenColumns(t.Context(), fakeDest, tc, config.SharedDestinationColumnSettings{}, tableID, inMemoryCols, nil))
+	assert.Equal(t, 3, fakeDest.ExecContextCallCount())
+
+	var queries []string
+	for i := range fakeDest.ExecContextCallCount() {
+		_, query, _ := fakeDest.ExecContextArgsForCall(i)
+		queries = append(queries, query)
+	}
+
+	assert.Equal(t, []string{
+		`ALTER TABLE "public"."orders" ALTER COLUMN "count" TYPE bigint`,
+		`ALTER TABLE "public"."orders" ALTER COLUMN "name" TYPE text`,
+		`ALTER TABLE "public"."orders" ALTER COLUMN "price" TYPE NUMERIC(20, 4)`,
+	}, queries)
+
+	// The table config should be updated, so running it again should be a no-op.
+	updatedCols := columns.NewColumns(tc.GetColumns())
+	count, ok := updatedCols.GetColumn("count")
+	assert.True(t, ok)
+	assert.Equal(t, typing.BuildIntegerKind(typing.BigIntegerKind), count.KindDetails)
+	name, ok := updatedCols.GetColumn("name")
+	assert.True(t, ok)
+	assert.Nil(t, name.KindDetails.OptionalStringPrecision)
+
+	assert.NoError(t, AlterTableWidenColumns(t.Context(), fakeDest, tc, config.SharedDestinationColumnSettings{}, tableID, inMemoryCols, nil))
+	assert.Equal(t, 3, fakeDest.ExecContextCallCount())
+}
+
+func TestAlterTableWidenColumns_CopySwap(t *testing.T) {
+	destCols, inMemoryCols := buildWideningColumns()
+	tc := types.NewDestinationTableConfig(destCols, false)
+	fakeDest := &mocks.FakeSQLDestination{}
+	fakeDest.DialectReturns(redshiftDialect.RedshiftDialect{})
+
+	// Redshift supports transactional DDL, so each column is swapped in a single transaction.
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	fakeDest.BeginCalls(func(ctx context.Context) (*gosql.Tx, error) {
+		return db.BeginTx(ctx, nil)
+	})
+
+	for _, queries := range [][]string{
+		// Redshift cannot change integer columns in place
+		{
+			`ALTER TABLE public."orders" ADD COLUMN "count__artie_widened" INT8`,
+			`UPDATE public."orders" SET "count__artie_widened" = "count" WHERE TRUE`,
+			`ALTER TABLE public."orders" RENAME COLUMN "count" TO "count__artie_previous"`,
+			`ALTER TABLE public."orders" RENAME COLUMN "count__artie_widened" TO "count"`,
+			`ALTER TABLE public."orders" DROP COLUMN "count__artie_previous"`,
+		},
+		{
+			`ALTER TABLE public."orders" ADD COLUMN "price__artie_widened" NUMERIC(20, 4)`,
+			`UPDATE public."orders" SET "price__artie_widened" = "price" WHERE TRUE`,
+			`ALTER TABLE public."orders" RENAME COLUMN "price" TO "price__artie_previous"`,
+			`ALTER TABLE public."orders" RENAME COLUMN "price__artie_widened" TO "price"`,
+			`ALTER TABLE public."orders" DROP COLUMN "price__artie_previous"`,
+		},
+	} {
+		mock.ExpectBegin()
+		for _, query := range queries {
+			mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectCommit()
+	}
+
+	tableID := redshiftDialect.NewTableIdentifier("public", "orders")
+	assert.NoError(t, AlterTableWidenColumns(t.Context(), fakeDest, tc, config.SharedDestinationColumnSettings{}, tableID, inMemoryCols, nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, 2, fakeDest.BeginCallCount())
+
+	// But it can for VARCHAR columns
+	assert.Equal(t, 1, fakeDest.ExecContextCallCount())
+	_, query, _ := fakeDest.ExecContextArgsForCall(0)
+	assert.Equal(t, `ALTER TABLE public."orders" ALTER COLUMN "name" TYPE VARCHAR(MAX)`, query)
+
+	// VARCHAR(MAX) is reported back as VARCHAR(65535), so it should not be widened again.
+	name, ok := columns.NewColumns(tc.GetColumns()).GetColumn("name")
+	assert.True(t, ok)
+	assert.Equal(t, typing.ToPtr(int32(65535)), name.KindDetails.OptionalStringPrecision)
+
+	assert.NoError(t, AlterTableWidenColumns(t.Context(), fakeDest, tc, config.SharedDestinationColumnSettings{}, tableID, inMemoryCols, nil))
+	assert.Equal(t, 1, fakeDest.ExecContextCallCount())
+	assert.Equal(t, 2, fakeDest.BeginCallCount())
+}
+
+func TestAlterTableWidenColumns_CopySwapFailure(t *testing.T) {
+	tableID := databricksDialect.NewTableIdentifier("catalog", "public", "orders")
+	inMemoryCols := []columns.Column{columns.NewColumn("id", typing.Integer), columns.NewColumn("count", typing.Integer)}
+	destCols := []columns.Column{
+		columns.NewColumn("id", typing.BuildIntegerKind(typing.BigIntegerKind)),
+		columns.NewColumn("count", typing.BuildIntegerKind(typing.IntegerKind)),
+	}
+
+	fakeDest := &mocks.FakeSQLDestination{}
+	fakeDest.DialectReturns(databricksDialect.DatabricksDialect{})
+	// Fail in between renaming the original column and renaming the widened column.
+	fakeDest.ExecContextReturnsOnCall(3, nil, assert.AnError)
+
+	err := AlterTableWidenColumns(t.Context(), fakeDest, types.NewDestinationTableConfig(destCols, false), config.SharedDestinationColumnSettings{}, tableID, inMemoryCols, nil)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 4, fakeDest.ExecContextCallCount())
+
+	// This is what the next flush will get back from the destination, the original column is gone.
+	tc := types.NewDestinationTableConfig([]columns.Column{
+		columns.NewColumn("id", typing.BuildIntegerKind(typing.BigIntegerKind)),
+		columns.NewColumn("count__artie_widened", typing.BuildIntegerKind(typing.BigIntegerKind)),
+		columns.NewColumn("count__artie_previous", typing.BuildIntegerKind(typing.IntegerKind)),
+	}, false)
+
+	fakeDest = &mocks.FakeSQLDestination{}
+	fakeDest.DialectReturns(databricksDialect.DatabricksDialect{})
+	assert.NoError(t, recoverWidenedColumns(t.Context(), fakeDest, tc, tableID, nil))
+
+	var queries []string
+	for i := range fakeDest.ExecContextCallCount() {
+		_, query, _ := fakeDest.ExecContextArgsForCall(i)
+		queries = append(queries, query)
+	}
+
+	// The widened column already has the values, so the swap is finished instead of adding [count] back as an empty column.
+	assert.Equal(t, []string{
+		"ALTER TABLE `catalog`.`public`.`orders` RENAME COLUMN `count__artie_widened` TO `count`",
+		"ALTER TABLE `catalog`.`public`.`orders` DROP COLUMN `count__artie_previous`",
+	}, queries)
+
+	count, ok := columns.NewColumns(tc.GetColumns()).GetColumn("count")
+	assert.True(t, ok)
+	assert.Equal(t, typing.BuildIntegerKind(typing.BigIntegerKind), count.KindDetails)
+	assert.Len(t, tc.GetColumns(), 2)
+
+	// Nothing is left to add or widen.
+	assert.NoError(t, AlterTableWidenColumns(t.Context(), fakeDest, tc, config.SharedDestinationColumnSettings{}, tableID, inMemoryCols, nil))
+	assert.Equal(t, 2, fakeDest.ExecContextCallCount())
+}
+
+func TestRecoverWidenedColumns(t *testing.T) {
+	tableID := databricksDialect.NewTableIdentifier("catalog", "public", "orders")
+	recoverColumns := func(t *testing.T, names ...string) ([]string, []string, error) {
+		var cols []columns.Column
+		for _, name := range names {
+			cols = append(cols, columns.NewColumn(name, typing.BuildIntegerKind(typing.BigIntegerKind)))
+		}
+
+		tc := types.NewDestinationTableConfig(cols, false)
+		fakeDest := &mocks.FakeSQLDestination{}
+		fakeDest.DialectReturns(databricksDialect.DatabricksDialect{})
+		err := recoverWidenedColumns(t.Context(), fakeDest, tc, tableID, nil)
+
+		var queries []string
+		for i := range fakeDest.ExecContextCallCount() {
+			_, query, _ := fakeDest.ExecContextArgsForCall(i)
+			queries = append(queries, query)
+		}
+
+		var remaining []string
+		for _, col := range tc.GetColumns() {
+			remaining = append(remaining, col.Name())
+		}
+
+		return queries, remaining, err
+	}
+	{
+		// Nothing to recover
+		queries, remaining, err := recoverColumns(t, "id", "count")
+		assert.NoError(t, err)
+		assert.Empty(t, queries)
+		assert.Equal(t, []string{"id", "count"}, remaining)
+	}
+	{
+		// Failed while copying the values, the widened column is dropped so that the widening starts over
+		queries, remaining, err := recoverColumns(t, "id", "count", "count__artie_widened")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ALTER TABLE `catalog`.`public`.`orders` DROP COLUMN `count__artie_widened`"}, queries)
+		assert.Equal(t, []string{"id", "count"}, remaining)
+	}
+	{
+		// Failed before dropping the original column
+		queries, remaining, err := recoverColumns(t, "id", "count", "count__artie_previous")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ALTER TABLE `catalog`.`public`.`orders` DROP COLUMN `count__artie_previous`"}, queries)
+		assert.Equal(t, []string{"id", "count"}, remaining)
+	}
+	{
+		// Unexpected state
+		queries, _, err := recoverColumns(t, "id", "count", "count__artie_widened", "count__artie_previous")
+		assert.ErrorContains(t, err, `column "count" was left in an unexpected state by a failed widening (original: true, widened: true, previous: true)`)
+		assert.Empty(t, queries)
+	}
+}
+
+func TestColumnsToWiden_DescribeOutput(t *testing.T) {
+	// [describedTypes] are the data types as they are reported back by each destination's describe query.
+	buildTableConfig := func(t *testing.T, dialect sql.Dialect, describedTypes map[string]string) *types.DestinationTableConfig {
+		var cols []columns.Column
+		for name, dataType := range describedTypes {
+			kd, err := dialect.KindForDataType(dataType)
+			assert.NoError(t, err, dataType)
+			cols = append(cols, columns.NewColumn(name, kd))
+		}
+
+		return types.NewDestinationTableConfig(cols, false)
+	}
+
+	widenedColumnNames := func(t *testing.T, dialect sql.Dialect, tc *types.DestinationTableConfig) []string {
+		inMemoryCols := []columns.Column{
+			columns.NewColumn("id", typing.Integer),
+			columns.NewColumn("small_count", typing.Integer),
+			columns.NewColumn("count", typing.Integer),
+			columns.NewColumn("name", typing.String),
+			columns.NewColumn("description", typing.String),
+		}
+
+		widenings, err := columnsToWiden(dialect, config.SharedDestinationColumnSettings{}, inMemoryCols, tc)
+		assert.NoError(t, err)
+
+		var names []string
+		for _, widening := range widenings {
+			names = append(names, widening.column.Name())
+		}
+
+		return names
+	}
+	{
+		// MySQL
+		tc := buildTableConfig(t, mysqlDialect.MySQLDialect{}, map[string]string{
+			"id":          "bigint",
+			"small_count": "smallint",
+			"count":       "int",
+			"name":        "varchar(50)",
+			"description": "text",
+		})
+		assert.Equal(t, []string{"small_count", "count", "name"}, widenedColumnNames(t, mysqlDialect.MySQLDialect{}, tc))
+	}
+	{
+		// Microsoft SQL Server
+		tc := buildTableConfig(t, mssqlDialect.MSSQLDialect{}, map[string]string{
+			"id":          "bigint",
+			"small_count": "smallint",
+			"count":       "int",
+			"name":        "varchar(50)",
+			"description": "varchar(-1)",
+		})
+		assert.Equal(t, []string{"small_count", "count", "name"}, widenedColumnNames(t, mssqlDialect.MSSQLDialect{}, tc))
+	}
+	{
+		// Snowflake, integers are all NUMBER(38, 0) and strings that were created by us are reported as VARCHAR(16777216).
+		tc := buildTableConfig(t, snowflakeDialect.SnowflakeDialect{}, map[string]string{
+			"id":          "NUMBER(38,0)",
+			"small_count": "NUMBER(38,0)",
+			"count":       "NUMBER(38,0)",
+			"name":        "VARCHAR(50)",
+			"description": "VARCHAR(16777216)",
+		})
+		assert.Equal(t, []string{"name"}, widenedColumnNames(t, snowflakeDialect.SnowflakeDialect{}, tc))
+	}
+	{
+		// BigQuery only has INT64 and STRING, so there's nothing to widen.
+		tc := buildTableConfig(t, bigqueryDialect.BigQueryDialect{}, map[string]string{
+			"id":          "INT64",
+			"small_count": "INT64",
+			"count":       "INT64",
+			"name":        "STRING",
+			"description": "STRING",
+		})
+		assert.Empty(t, widenedColumnNames(t, bigqueryDialect.BigQueryDialect{}, tc))
+	}
+}
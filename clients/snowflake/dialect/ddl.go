@@ -7,6 +7,8 @@ import (
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
 )
 
 func (SnowflakeDialect) BuildCreateTableQuery(tableID sql.TableIdentifier, temporary bool, _ config.Mode, colSQLParts []string) string {
@@ -39,6 +41,15 @@ func (SnowflakeDialect) BuildDropColumnQuery(tableID sql.TableIdentifier, colNam
 	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", tableID.FullyQualifiedName(), colName)
 }
 
+// BuildAlterColumnTypeQuery - Snowflake can increase the length of a VARCHAR column in place, but it cannot change the scale of a NUMBER column.
+func (sd SnowflakeDialect) BuildAlterColumnTypeQuery(tableID sql.TableIdentifier, col columns.Column, dataType string) (string, bool) {
+	if col.KindDetails.Kind != typing.String.Kind {
+		return "", false
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DATA TYPE %s", tableID.FullyQualifiedName(), sd.QuoteIdentifier(col.Name()), dataType), true
+}
+
 func (SnowflakeDialect) BuildDescribeTableQuery(tableID sql.TableIdentifier) (string, []any, error) {
 	return fmt.Sprintf("DESC TABLE %s", tableID.FullyQualifiedName()), nil, nil
 }
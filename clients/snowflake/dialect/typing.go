@@ -10,6 +10,9 @@ import (
 	"github.com/artie-labs/transfer/lib/typing"
 )
 
+// maxStringLength is the length that Snowflake reports for a VARCHAR column that was created without one.
+const maxStringLength int32 = 16777216
+
 func destinationTimestampDataType(kindDetails typing.KindDetails) (string, bool) {
 	if kindDetails.OptionalDestinationDataType == nil {
 		return "", false
@@ -98,10 +101,19 @@ func (SnowflakeDialect) KindForDataType(snowflakeType string) (typing.KindDetail
 		"float8", "double", "double precision", "real":
 		return typing.Float, nil
 	case "int", "integer", "bigint", "smallint", "tinyint", "byteint":
-		return typing.Integer, nil
+		// These are all synonymous with NUMBER(38, 0).
+		return typing.BuildIntegerKind(typing.BigIntegerKind), nil
 	case "varchar", "char", "character", "string", "text":
 		switch len(parameters) {
 		case 0:
+			if dataType == "varchar" || dataType == "string" || dataType == "text" {
+				// This is what we use when creating string columns, Snowflake stores this as VARCHAR(16777216).
+				return typing.KindDetails{
+					Kind:                    typing.String.Kind,
+					OptionalStringPrecision: typing.ToPtr(maxStringLength),
+				}, nil
+			}
+
 			return typing.String, nil
 		case 1:
 			precision, err := strconv.ParseInt(parameters[0], 10, 32)
@@ -178,17 +178,26 @@ func TestSnowflakeDialect_KindForDataType(t *testing.T) {
 		for _, expectedInteger := range expectedIntegers {
 			kd, err := SnowflakeDialect{}.KindForDataType(expectedInteger)
 			assert.NoError(t, err)
-			assert.Equal(t, typing.Integer, kd, expectedInteger)
+			assert.Equal(t, typing.BuildIntegerKind(typing.BigIntegerKind), kd, expectedInteger)
 		}
 	}
 	{
 		// String
-		expectedStrings := []string{"CHARACTER", "CHAR", "STRING", "TEXT"}
+		expectedStrings := []string{"CHARACTER", "CHAR"}
 		for _, expectedString := range expectedStrings {
 			kd, err := SnowflakeDialect{}.KindForDataType(expectedString)
 			assert.NoError(t, err)
 			assert.Equal(t, typing.String, kd, expectedString)
 		}
+	}
+	{
+		// String without a length, Snowflake reports these as VARCHAR(16777216)
+		for _, expectedString := range []string{"VARCHAR", "STRING", "TEXT"} {
+			kd, err := SnowflakeDialect{}.KindForDataType(expectedString)
+			assert.NoError(t, err)
+			assert.Equal(t, typing.String.Kind, kd.Kind, expectedString)
+			assert.Equal(t, int32(16777216), *kd.OptionalStringPrecision, expectedString)
+		}
 
 		{
 			kd, err := SnowflakeDialect{}.KindForDataType("VARCHAR (255)")
@@ -106,12 +106,13 @@ func (s *Store) Dedupe(ctx context.Context, tableID sql.TableIdentifier, pair ka
 }
 
 func LoadStore(ctx context.Context, cfg config.Config, _store *db.Store) (*Store, error) {
-	retryConfig, err := retry.NewJitterRetryConfig(1_000, 30_000, 10, retry.AlwaysRetryNonCancelled)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create retry config: %w", err)
-	}
 	if _store != nil {
 		// Used for tests.
+		retryConfig, err := retry.NewJitterRetryConfig(1_000, 30_000, 10, retry.AlwaysRetryNonCancelled)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create retry config: %w", err)
+		}
+
 		return &Store{
 			configMap: &types.DestinationTableConfigMap{},
 			config:    cfg,
@@ -125,23 +126,11 @@ func LoadStore(ctx context.Context, cfg config.Config, _store *db.Store) (*Store
 		return nil, fmt.Errorf("failed to get Snowflake config: %w", err)
 	}
 
-	dsn, err := gosnowflake.DSN(snowflakeCfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get Snowflake DSN: %w", err)
-	}
-
-	store, err := db.Open("snowflake", dsn)
+	s, err := openStore(cfg, snowflakeCfg)
 	if err != nil {
 		return nil, err
 	}
 
-	s := &Store{
-		configMap: &types.DestinationTableConfigMap{},
-		config:    cfg,
-		Store:     store,
-		retryCfg:  retryConfig,
-	}
-
 	if err = s.ensureExternalStageExists(ctx); err != nil {
 		return nil, fmt.Errorf("failed to set up external stage: %w", err)
 	}
@@ -168,6 +157,40 @@ func LoadStore(ctx context.Context, cfg config.Config, _store *db.Store) (*Store
 	return s, nil
 }
 
+// LoadReadOnlyStore only connects to Snowflake, unlike [LoadStore] it won't create the external stage or set up streaming.
+func LoadReadOnlyStore(cfg config.Config) (*Store, error) {
+	snowflakeCfg, err := cfg.Snowflake.ToConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Snowflake config: %w", err)
+	}
+
+	return openStore(cfg, snowflakeCfg)
+}
+
+func openStore(cfg config.Config, snowflakeCfg *gosnowflake.Config) (*Store, error) {
+	retryConfig, err := retry.NewJitterRetryConfig(1_000, 30_000, 10, retry.AlwaysRetryNonCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retry config: %w", err)
+	}
+
+	dsn, err := gosnowflake.DSN(snowflakeCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Snowflake DSN: %w", err)
+	}
+
+	store, err := db.Open("snowflake", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		configMap: &types.DestinationTableConfigMap{},
+		config:    cfg,
+		Store:     store,
+		retryCfg:  retryConfig,
+	}, nil
+}
+
 func (s Store) GetS3Client() (awslib.S3Client, error) {
 	if !s.useExternalStage() {
 		return awslib.S3Client{}, fmt.Errorf("external stage is not enabled")
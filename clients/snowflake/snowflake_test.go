@@ -355,7 +355,9 @@ func (s *SnowflakeTestSuite) TestExecuteMergeDeletionFlagRemoval() {
 	assert.True(s.T(), ok)
 
 	// Now try to execute merge where 1 of the rows have the column now
-	for _, row := range tableData.Rows() {
+	rows, err := tableData.Rows()
+	assert.NoError(s.T(), err)
+	for _, row := range rows {
 		pk, ok := row.GetValue("id")
 		assert.True(s.T(), ok)
 
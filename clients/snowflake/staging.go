@@ -197,7 +197,7 @@ func (s *Store) LoadDataIntoTable(ctx context.Context, tableData *optimization.T
 		rowsLoaded += _rowsLoaded
 	}
 
-	expectedRows := int64(len(tableData.Rows()))
+	expectedRows := int64(tableData.NumberOfRows())
 	if rowsLoaded != expectedRows {
 		return fmt.Errorf("expected %d rows to be inserted, but got %d", expectedRows, rowsLoaded)
 	}
@@ -212,8 +212,8 @@ func (s *SnowpipeStreamingChannelManager) LoadData(ctx context.Context, db, sche
 		return err
 	}
 
-	_, err = batch.BySize(
-		data.Rows(),
+	_, err = batch.BySizeSeq(
+		data.AllRows(),
 		maxChunkSize,
 		true,
 		func(row optimization.Row) ([]byte, error) {
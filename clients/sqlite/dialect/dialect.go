@@ -0,0 +1,172 @@
+package dialect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+// https://www.sqlite.org/pragma.html#pragma_table_info
+const describeTableQuery = `SELECT name AS column_name, type AS data_type, dflt_value AS default_value FROM pragma_table_info(?)`
+
+type SQLiteDialect struct{}
+
+func (SQLiteDialect) ReservedColumnNames() map[string]bool {
+	return nil
+}
+
+func (SQLiteDialect) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(identifier, `"`, `""`))
+}
+
+func (SQLiteDialect) EscapeStruct(value string) string {
+	return sql.QuoteLiteral(value)
+}
+
+func (SQLiteDialect) BuildNullSafeEqualityCond(colA, colB string) (string, error) {
+	// https://www.sqlite.org/lang_expr.html#isisnot
+	return fmt.Sprintf("%s IS %s", colA, colB), nil
+}
+
+func (SQLiteDialect) IsColumnAlreadyExistsErr(err error) bool {
+	// SQLite does not support ADD COLUMN IF NOT EXISTS.
+	return strings.Contains(err.Error(), "duplicate column name")
+}
+
+func (SQLiteDialect) IsTableDoesNotExistErr(err error) bool {
+	return strings.Contains(err.Error(), "no such table")
+}
+
+func (SQLiteDialect) BuildCreateTableQuery(tableID sql.TableIdentifier, _ bool, _ config.Mode, colSQLParts []string) string {
+	// Temporary tables are created as regular tables since SQLite's temporary tables are scoped to a single connection.
+	return fmt.Sprintf("CREATE TABLE %s (%s);", tableID.FullyQualifiedName(), strings.Join(colSQLParts, ","))
+}
+
+func (SQLiteDialect) BuildDropTableQuery(tableID sql.TableIdentifier) string {
+	return sql.DefaultBuildDropTableQuery(tableID)
+}
+
+func (SQLiteDialect) BuildTruncateTableQuery(tableID sql.TableIdentifier) string {
+	// SQLite does not have TRUNCATE, an unqualified DELETE is optimized to the same thing.
+	return fmt.Sprintf("DELETE FROM %s", tableID.FullyQualifiedName())
+}
+
+// BuildDedupeQueries returns no queries since we don't currently support deduping for SQLite, the store returns an error instead.
+func (SQLiteDialect) BuildDedupeQueries(_, _ sql.TableIdentifier, _ []string, _ bool) []string {
+	return nil
+}
+
+func (SQLiteDialect) BuildDescribeTableQuery(tableID sql.TableIdentifier) (string, []any, error) {
+	castedTableID, err := typing.AssertType[TableIdentifier](tableID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return describeTableQuery, []any{castedTableID.Table()}, nil
+}
+
+func (sd SQLiteDialect) BuildIsNotToastValueExpression(tableAlias constants.TableAlias, column columns.Column) string {
+	// LIKE will cast blobs and numbers into text, so this works for every column type.
+	return fmt.Sprintf("COALESCE(%s, '') NOT LIKE '%s'", sql.QuoteTableAliasColumn(tableAlias, column, sd), "%"+constants.ToastUnavailableValuePlaceholder+"%")
+}
+
+func (SQLiteDialect) GetDefaultValueStrategy() sql.DefaultValueStrategy {
+	return sql.NotImplemented
+}
+
+func (SQLiteDialect) BuildAddColumnQuery(tableID sql.TableIdentifier, sqlPart string) string {
+	return sql.DefaultBuildAddColumnQuery(tableID, sqlPart)
+}
+
+func (SQLiteDialect) BuildDropColumnQuery(tableID sql.TableIdentifier, colName string) string {
+	return sql.DefaultBuildDropColumnQuery(tableID, colName)
+}
+
+func (SQLiteDialect) BuildMergeQueryIntoStagingTable(_ sql.TableIdentifier, _ string, _ []columns.Column, _ []string, _ []columns.Column, _ bool) ([]string, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// BuildMergeQueries - SQLite does not have MERGE, so this is broken down into UPDATE ... FROM, INSERT ... SELECT and DELETE statements.
+func (sd SQLiteDialect) BuildMergeQueries(
+	tableID sql.TableIdentifier,
+	subQuery string,
+	primaryKeys []columns.Column,
+	additionalEqualityStrings []string,
+	cols []columns.Column,
+	softDelete bool,
+	containsHardDeletes bool,
+	useEqualNull bool,
+) ([]string, error) {
+	cols, err := columns.RemoveOnlySetDeleteColumnMarker(cols)
+	if err != nil {
+		return nil, err
+	}
+
+	joinClauses, err := sql.BuildColumnComparisonsWithEqualNull(primaryKeys, constants.TargetAlias, constants.StagingAlias, sql.Equal, sd, useEqualNull)
+	if err != nil {
+		return nil, err
+	}
+
+	joinCondition := strings.Join(append(joinClauses, additionalEqualityStrings...), " AND ")
+	if softDelete {
+		return []string{
+			sd.buildUpdateQuery(tableID, subQuery, joinCondition, cols, fmt.Sprintf("COALESCE(%s, false) = false", sql.GetQuotedOnlySetDeleteColumnMarker(constants.StagingAlias, sd))),
+			sd.buildUpdateQuery(tableID, subQuery, joinCondition, []columns.Column{columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean)},
+				fmt.Sprintf("COALESCE(%s, false) = true", sql.GetQuotedOnlySetDeleteColumnMarker(constants.StagingAlias, sd)),
+			),
+			sd.buildInsertQuery(tableID, subQuery, joinCondition, primaryKeys[0], cols, ""),
+		}, nil
+	}
+
+	// Remove __artie flags since they don't exist in the destination table
+	cols, err = columns.RemoveDeleteColumnMarker(cols)
+	if err != nil {
+		return nil, err
+	}
+
+	notDeleted := fmt.Sprintf("COALESCE(%s, false) = false", sql.QuotedDeleteColumnMarker(constants.StagingAlias, sd))
+	parts := []string{
+		sd.buildUpdateQuery(tableID, subQuery, joinCondition, cols, notDeleted),
+		sd.buildInsertQuery(tableID, subQuery, joinCondition, primaryKeys[0], cols, notDeleted),
+	}
+
+	if containsHardDeletes {
+		parts = append(parts, fmt.Sprintf(`DELETE FROM %s AS %s WHERE EXISTS (SELECT 1 FROM %s AS %s WHERE %s AND %s = true);`,
+			tableID.FullyQualifiedName(), constants.TargetAlias,
+			subQuery, constants.StagingAlias, joinCondition, sql.QuotedDeleteColumnMarker(constants.StagingAlias, sd),
+		))
+	}
+
+	return parts, nil
+}
+
+// buildUpdateQuery - https://www.sqlite.org/lang_update.html#update_from
+func (sd SQLiteDialect) buildUpdateQuery(tableID sql.TableIdentifier, subQuery string, joinCondition string, cols []columns.Column, condition string) string {
+	return fmt.Sprintf(`UPDATE %s AS %s SET %s FROM %s AS %s WHERE %s AND %s;`,
+		tableID.FullyQualifiedName(), constants.TargetAlias, sql.BuildColumnsUpdateFragment(cols, constants.StagingAlias, constants.TargetAlias, sd),
+		subQuery, constants.StagingAlias, joinCondition, condition,
+	)
+}
+
+func (sd SQLiteDialect) buildInsertQuery(tableID sql.TableIdentifier, subQuery string, joinCondition string, primaryKey columns.Column, cols []columns.Column, condition string) string {
+	whereClause := fmt.Sprintf("%s IS NULL", sql.QuoteTableAliasColumn(constants.TargetAlias, primaryKey, sd))
+	if condition != "" {
+		whereClause += " AND " + condition
+	}
+
+	return fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM %s AS %s LEFT JOIN %s AS %s ON %s WHERE %s;`,
+		tableID.FullyQualifiedName(), strings.Join(sql.QuoteColumns(cols, sd), ","),
+		strings.Join(sql.QuoteTableAliasColumns(constants.StagingAlias, cols, sd), ","), subQuery, constants.StagingAlias,
+		tableID.FullyQualifiedName(), constants.TargetAlias, joinCondition,
+		whereClause,
+	)
+}
+
+func (SQLiteDialect) BuildSweepQuery() (string, []any) {
+	return `SELECT name FROM sqlite_master WHERE type = 'table' AND name LIKE ?`, []any{"%" + constants.ArtiePrefix + "%"}
+}
@@ -0,0 +1,55 @@
+package dialect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func TestSQLiteDialect_BuildMergeQueries(t *testing.T) {
+	tableID := NewTableIdentifier("orders")
+	primaryKeys := []columns.Column{columns.NewColumn("id", typing.Integer)}
+	cols := []columns.Column{
+		columns.NewColumn("id", typing.Integer),
+		columns.NewColumn("name", typing.String),
+		columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean),
+		columns.NewColumn(constants.OnlySetDeleteColumnMarker, typing.Boolean),
+	}
+	{
+		// Regular merge without hard deletes
+		queries, err := SQLiteDialect{}.BuildMergeQueries(tableID, `"staging"`, primaryKeys, nil, cols, false, false, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			`UPDATE "orders" AS tgt SET "id"=stg."id","name"=stg."name" FROM "staging" AS stg WHERE tgt."id" = stg."id" AND COALESCE(stg."__artie_delete", false) = false;`,
+			`INSERT INTO "orders" ("id","name") SELECT stg."id",stg."name" FROM "staging" AS stg LEFT JOIN "orders" AS tgt ON tgt."id" = stg."id" WHERE tgt."id" IS NULL AND COALESCE(stg."__artie_delete", false) = false;`,
+		}, queries)
+	}
+	{
+		// Regular merge with hard deletes and null safe equality
+		queries, err := SQLiteDialect{}.BuildMergeQueries(tableID, `"staging"`, primaryKeys, nil, cols, false, true, true)
+		assert.NoError(t, err)
+		assert.Len(t, queries, 3)
+		assert.Equal(t, `DELETE FROM "orders" AS tgt WHERE EXISTS (SELECT 1 FROM "staging" AS stg WHERE tgt."id" IS stg."id" AND stg."__artie_delete" = true);`, queries[2])
+	}
+	{
+		// Soft delete
+		queries, err := SQLiteDialect{}.BuildMergeQueries(tableID, `"staging"`, primaryKeys, nil, cols, true, false, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			`UPDATE "orders" AS tgt SET "id"=stg."id","name"=stg."name","__artie_delete"=stg."__artie_delete" FROM "staging" AS stg WHERE tgt."id" = stg."id" AND COALESCE(stg."__artie_only_set_delete", false) = false;`,
+			`UPDATE "orders" AS tgt SET "__artie_delete"=stg."__artie_delete" FROM "staging" AS stg WHERE tgt."id" = stg."id" AND COALESCE(stg."__artie_only_set_delete", false) = true;`,
+			`INSERT INTO "orders" ("id","name","__artie_delete") SELECT stg."id",stg."name",stg."__artie_delete" FROM "staging" AS stg LEFT JOIN "orders" AS tgt ON tgt."id" = stg."id" WHERE tgt."id" IS NULL;`,
+		}, queries)
+	}
+}
+
+func TestSQLiteDialect_BuildIsNotToastValueExpression(t *testing.T) {
+	assert.Equal(t,
+		`COALESCE(tgt."name", '') NOT LIKE '%__debezium_unavailable_value%'`,
+		SQLiteDialect{}.BuildIsNotToastValueExpression(constants.TargetAlias, columns.NewColumn("name", typing.String)),
+	)
+}
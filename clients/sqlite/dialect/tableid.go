@@ -0,0 +1,48 @@
+package dialect
+
+import (
+	"github.com/artie-labs/transfer/lib/sql"
+)
+
+var _dialect = SQLiteDialect{}
+
+// mainSchema is the name SQLite gives to the database that was opened, SQLite does not have schemas so every table lives in here.
+const mainSchema = "main"
+
+type TableIdentifier struct {
+	table          string
+	temporaryTable bool
+}
+
+func NewTableIdentifier(table string) TableIdentifier {
+	return TableIdentifier{table: table}
+}
+
+func (ti TableIdentifier) Schema() string {
+	return mainSchema
+}
+
+func (ti TableIdentifier) EscapedTable() string {
+	return _dialect.QuoteIdentifier(ti.table)
+}
+
+func (ti TableIdentifier) Table() string {
+	return ti.table
+}
+
+func (ti TableIdentifier) WithTable(table string) sql.TableIdentifier {
+	return NewTableIdentifier(table)
+}
+
+func (ti TableIdentifier) FullyQualifiedName() string {
+	return ti.EscapedTable()
+}
+
+func (ti TableIdentifier) WithTemporaryTable(temp bool) sql.TableIdentifier {
+	ti.temporaryTable = temp
+	return ti
+}
+
+func (ti TableIdentifier) TemporaryTable() bool {
+	return ti.temporaryTable
+}
@@ -0,0 +1,125 @@
+package dialect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+// SQLite will accept any declared type and only uses it to pick a type affinity (https://www.sqlite.org/datatype3.html).
+// We declare descriptive type names so that they can be mapped back to the same kind when we read the table's schema.
+
+func (SQLiteDialect) DataTypeForKind(kd typing.KindDetails, _ bool, _ config.SharedDestinationColumnSettings) (string, error) {
+	switch kd.Kind {
+	case typing.Float.Kind:
+		return "DOUBLE", nil
+	case typing.Integer.Kind:
+		if kd.OptionalIntegerKind != nil {
+			switch *kd.OptionalIntegerKind {
+			case typing.SmallIntegerKind:
+				return "SMALLINT", nil
+			case typing.IntegerKind:
+				return "INTEGER", nil
+			}
+		}
+
+		return "BIGINT", nil
+	case typing.Boolean.Kind:
+		return "BOOLEAN", nil
+	case typing.String.Kind:
+		if kd.OptionalStringPrecision != nil {
+			return fmt.Sprintf("VARCHAR(%d)", *kd.OptionalStringPrecision), nil
+		}
+
+		return "TEXT", nil
+	case typing.Struct.Kind:
+		return "JSON", nil
+	case typing.Array.Kind:
+		return "ARRAY", nil
+	case typing.Bytes.Kind:
+		return "BLOB", nil
+	case typing.UUID.Kind:
+		return "UUID", nil
+	case typing.Interval.Kind:
+		return "INTERVAL", nil
+	case typing.Date.Kind:
+		return "DATE", nil
+	case typing.TimeKindDetails.Kind:
+		return "TIME", nil
+	case typing.TimestampNTZ.Kind:
+		return "TIMESTAMP", nil
+	case typing.TimestampTZ.Kind:
+		return "TIMESTAMPTZ", nil
+	case typing.EDecimal.Kind:
+		if kd.ExtendedDecimalDetails == nil {
+			return "", fmt.Errorf("expected extended decimal details to be set for %q", kd.Kind)
+		}
+
+		return kd.ExtendedDecimalDetails.PostgresKind(), nil
+	default:
+		return "", fmt.Errorf("unsupported kind: %q", kd.Kind)
+	}
+}
+
+var dataTypeMap = map[string]typing.KindDetails{
+	"double":      typing.Float,
+	"real":        typing.Float,
+	"float":       typing.Float,
+	"smallint":    typing.BuildIntegerKind(typing.SmallIntegerKind),
+	"integer":     typing.BuildIntegerKind(typing.IntegerKind),
+	"int":         typing.BuildIntegerKind(typing.IntegerKind),
+	"bigint":      typing.BuildIntegerKind(typing.BigIntegerKind),
+	"boolean":     typing.Boolean,
+	"text":        typing.String,
+	"json":        typing.Struct,
+	"array":       typing.Array,
+	"blob":        typing.Bytes,
+	"uuid":        typing.UUID,
+	"interval":    typing.Interval,
+	"date":        typing.Date,
+	"time":        typing.TimeKindDetails,
+	"timestamp":   typing.TimestampNTZ,
+	"datetime":    typing.TimestampNTZ,
+	"timestamptz": typing.TimestampTZ,
+}
+
+func (SQLiteDialect) KindForDataType(rawType string) (typing.KindDetails, error) {
+	dataType, parameters, err := sql.ParseDataTypeDefinition(strings.ToLower(rawType))
+	if err != nil {
+		return typing.Invalid, err
+	}
+
+	if kind, ok := dataTypeMap[dataType]; ok {
+		return kind, nil
+	}
+
+	switch dataType {
+	case "numeric", "decimal":
+		if len(parameters) == 0 {
+			return typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(decimal.PrecisionNotSpecified, decimal.DefaultScale)), nil
+		}
+
+		return typing.ParseNumeric(parameters)
+	case "varchar", "character varying":
+		if len(parameters) != 1 {
+			return typing.String, nil
+		}
+
+		precision, err := strconv.ParseInt(parameters[0], 10, 32)
+		if err != nil {
+			return typing.Invalid, fmt.Errorf("failed to parse string precision: %q, err: %w", parameters[0], err)
+		}
+
+		return typing.KindDetails{
+			Kind:                    typing.String.Kind,
+			OptionalStringPrecision: typing.ToPtr(int32(precision)),
+		}, nil
+	default:
+		return typing.Invalid, fmt.Errorf("unsupported data type: %q", rawType)
+	}
+}
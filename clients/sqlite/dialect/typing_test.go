@@ -0,0 +1,61 @@
+package dialect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+func TestSQLiteDialect_DataTypeForKind(t *testing.T) {
+	// Every kind should map back to itself so that we don't keep altering the table.
+	for _, kd := range []typing.KindDetails{
+		typing.Float,
+		typing.BuildIntegerKind(typing.SmallIntegerKind),
+		typing.BuildIntegerKind(typing.IntegerKind),
+		typing.BuildIntegerKind(typing.BigIntegerKind),
+		typing.Boolean,
+		typing.String,
+		{Kind: typing.String.Kind, OptionalStringPrecision: typing.ToPtr(int32(255))},
+		typing.Struct,
+		typing.Array,
+		typing.Bytes,
+		typing.UUID,
+		typing.Interval,
+		typing.Date,
+		typing.TimeKindDetails,
+		typing.TimestampNTZ,
+		typing.TimestampTZ,
+		typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(10, 2)),
+	} {
+		dataType, err := SQLiteDialect{}.DataTypeForKind(kd, false, config.SharedDestinationColumnSettings{})
+		assert.NoError(t, err, kd.Kind)
+
+		kind, err := SQLiteDialect{}.KindForDataType(dataType)
+		assert.NoError(t, err, dataType)
+		assert.Equal(t, kd, kind, dataType)
+	}
+	{
+		// Unspecified integers are created as BIGINT
+		dataType, err := SQLiteDialect{}.DataTypeForKind(typing.Integer, false, config.SharedDestinationColumnSettings{})
+		assert.NoError(t, err)
+		assert.Equal(t, "BIGINT", dataType)
+	}
+}
+
+func TestSQLiteDialect_KindForDataType(t *testing.T) {
+	{
+		// Case insensitive
+		kd, err := SQLiteDialect{}.KindForDataType("Double")
+		assert.NoError(t, err)
+		assert.Equal(t, typing.Float, kd)
+	}
+	{
+		// Unsupported
+		_, err := SQLiteDialect{}.KindForDataType("geometry")
+		assert.ErrorContains(t, err, `unsupported data type: "geometry"`)
+	}
+}
@@ -0,0 +1,112 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/artie-labs/transfer/clients/shared"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/optimization"
+	libsql "github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func (s *Store) LoadDataIntoTable(ctx context.Context, tableData *optimization.TableData, dwh *types.DestinationTableConfig, tableID, _ libsql.TableIdentifier, opts types.AdditionalSettings, createTempTable bool) error {
+	if createTempTable {
+		if err := shared.CreateTempTable(ctx, s, tableData, dwh, opts.ColumnSettings, tableID); err != nil {
+			return err
+		}
+	}
+
+	cols := tableData.ReadOnlyInMemoryCols().ValidColumns()
+	if len(cols) == 0 {
+		return fmt.Errorf("no columns to insert")
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		tableID.FullyQualifiedName(),
+		strings.Join(libsql.QuoteColumns(cols, s.dialect()), ","),
+		strings.Repeat("?, ", len(cols)-1)+"?",
+	)
+
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// SQLite runs in-process, so inserting the rows one by one through a prepared statement is as fast as batching them.
+	return db.CommitOrRollback(tx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		defer stmt.Close()
+
+		for row, err := range tableData.AllRows() {
+			if err != nil {
+				return fmt.Errorf("failed to read row: %w", err)
+			}
+
+			values := make([]any, len(cols))
+			for i, col := range cols {
+				value, _ := row.GetValue(col.Name())
+				if values[i], err = parseValue(value, col); err != nil {
+					return fmt.Errorf("failed to parse value for column %q: %w", col.Name(), err)
+				}
+			}
+
+			if _, err = stmt.ExecContext(ctx, values...); err != nil {
+				return fmt.Errorf("failed to insert row: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// parseValue converts [value] into something the driver can store, dates and times are stored as text since SQLite does not have native types for them.
+func parseValue(value any, col columns.Column) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if col.KindDetails.Kind == typing.Bytes.Kind {
+		castedValue, err := typing.AssertType[string](value)
+		if err != nil {
+			return nil, err
+		}
+
+		if castedValue == constants.ToastUnavailableValuePlaceholder {
+			return []byte(castedValue), nil
+		}
+
+		return base64.StdEncoding.DecodeString(castedValue)
+	}
+
+	parsedValue, err := shared.ParseValue(value, col)
+	if err != nil {
+		return nil, err
+	}
+
+	if _time, ok := parsedValue.(time.Time); ok {
+		switch col.KindDetails.Kind {
+		case typing.Date.Kind:
+			return _time.Format(time.DateOnly), nil
+		case typing.TimeKindDetails.Kind:
+			return _time.Format(typing.PostgresTimeFormatNoTZ), nil
+		case typing.TimestampNTZ.Kind:
+			return _time.Format(typing.RFC3339NoTZ), nil
+		case typing.TimestampTZ.Kind:
+			return _time.Format(time.RFC3339Nano), nil
+		}
+	}
+
+	return parsedValue, nil
+}
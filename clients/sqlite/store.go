@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/artie-labs/transfer/clients/shared"
+	"github.com/artie-labs/transfer/clients/sqlite/dialect"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/destination/ddl"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/webhooks"
+)
+
+type Store struct {
+	configMap *types.DestinationTableConfigMap
+	config    config.Config
+	db.Store
+}
+
+func (s Store) Label() constants.DestinationKind {
+	return s.config.Output
+}
+
+func (s Store) GetConfig() config.Config {
+	return s.config
+}
+
+func (s Store) IsOLTP() bool {
+	return true
+}
+
+func (s *Store) DropTable(ctx context.Context, tableID sql.TableIdentifier) error {
+	return shared.DropTemporaryTable(ctx, s, tableID, s.configMap)
+}
+
+func (s *Store) Dialect() sql.Dialect {
+	return s.dialect()
+}
+
+func (s *Store) dialect() dialect.SQLiteDialect {
+	return dialect.SQLiteDialect{}
+}
+
+func (s *Store) Merge(ctx context.Context, tableData *optimization.TableData, whClient *webhooks.Client) (bool, error) {
+	if err := shared.Merge(ctx, s, tableData, types.MergeOpts{}, whClient); err != nil {
+		return false, fmt.Errorf("failed to merge: %w", err)
+	}
+
+	return true, nil
+}
+
+func (s *Store) Append(ctx context.Context, tableData *optimization.TableData, whClient *webhooks.Client, _ bool) error {
+	return shared.Append(ctx, s, tableData, whClient, types.AdditionalSettings{})
+}
+
+// IdentifierFor returns a generic [sql.TableIdentifier] interface for a [TopicConfig] + table name.
+// SQLite does not have schemas, so the database and schema are not part of the identifier.
+func (s *Store) IdentifierFor(_ kafkalib.DatabaseAndSchemaPair, table string) sql.TableIdentifier {
+	return dialect.NewTableIdentifier(table)
+}
+
+// SweepTemporaryTables - SQLite only has one database, so we don't need to go through every topic like [shared.Sweep] does.
+// The table names are also read upfront since SQLite will not let us drop a table while a statement is still reading from sqlite_master.
+func (s *Store) SweepTemporaryTables(ctx context.Context) error {
+	slog.Info("Looking to see if there are any dangling artie temporary tables to delete...")
+	query, args := s.dialect().BuildSweepQuery()
+	rows, err := s.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	var tableNames []string
+	for rows.Next() {
+		var tableName string
+		if err = rows.Scan(&tableName); err != nil {
+			_ = rows.Close()
+			return err
+		}
+
+		tableNames = append(tableNames, tableName)
+	}
+
+	if err = rows.Close(); err != nil {
+		return fmt.Errorf("failed to close rows: %w", err)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over rows: %w", err)
+	}
+
+	for _, tableName := range tableNames {
+		if ddl.ShouldDeleteFromName(tableName) {
+			if err = ddl.DropTemporaryTable(ctx, s, dialect.NewTableIdentifier(tableName), true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) Dedupe(_ context.Context, _ sql.TableIdentifier, _ kafkalib.DatabaseAndSchemaPair, _ []string, _ bool) error {
+	return fmt.Errorf("dedupe is not supported for SQLite: %w", errors.ErrUnsupported)
+}
+
+func (s *Store) GetTableConfig(ctx context.Context, tableID sql.TableIdentifier, dropDeletedColumns bool) (*types.DestinationTableConfig, error) {
+	return shared.GetTableCfgArgs{
+		Destination:           s,
+		TableID:               tableID,
+		ConfigMap:             s.configMap,
+		ColumnNameForName:     "column_name",
+		ColumnNameForDataType: "data_type",
+		DropDeletedColumns:    dropDeletedColumns,
+	}.GetTableConfig(ctx)
+}
+
+func LoadStore(_ context.Context, cfg config.Config) (*Store, error) {
+	if err := cfg.SQLite.Validate(); err != nil {
+		return nil, err
+	}
+
+	store, err := db.Open("sqlite", cfg.SQLite.DSN())
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only allows one writer at a time, this also keeps an in-memory database alive since it only exists for the lifetime of its connection.
+	store.GetDatabase().SetMaxOpenConns(1)
+	return &Store{
+		Store:     store,
+		configMap: &types.DestinationTableConfigMap{},
+		config:    cfg,
+	}, nil
+}
+
+// LoadReadOnlyStore opens an existing database file in read-only mode, unlike [LoadStore] it won't create the file.
+func LoadReadOnlyStore(_ context.Context, cfg config.Config) (*Store, error) {
+	if err := cfg.SQLite.Validate(); err != nil {
+		return nil, err
+	}
+
+	store, err := db.Open("sqlite", cfg.SQLite.ReadOnlyDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		Store:     store,
+		configMap: &types.DestinationTableConfigMap{},
+		config:    cfg,
+	}, nil
+}
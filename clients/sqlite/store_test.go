@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/clients/sqlite/dialect"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func newStore(t *testing.T) *Store {
+	store, err := LoadStore(t.Context(), config.Config{
+		Output: constants.SQLite,
+		SQLite: &config.SQLiteSettings{Path: filepath.Join(t.TempDir(), "artie.db")},
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, store.Close()) })
+	return store
+}
+
+func newTableData(t *testing.T, topicConfig kafkalib.TopicConfig, colNames []string, rows ...map[string]any) *optimization.TableData {
+	cols := columns.NewColumns(nil)
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	for _, colName := range colNames {
+		col := columns.NewColumn(colName, typing.String)
+		col.ToastColumn = true
+		cols.AddColumn(col)
+	}
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+	cols.AddColumn(columns.NewColumn(constants.OnlySetDeleteColumnMarker, typing.Boolean))
+
+	tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, topicConfig, "orders")
+	tableData.SetLatestTimestamp(time.Now())
+	for _, row := range rows {
+		deleted, _ := row[constants.DeleteColumnMarker].(bool)
+		assert.NoError(t, tableData.InsertRow(fmt.Sprint(row["id"]), row, deleted))
+	}
+
+	return tableData
+}
+
+func merge(t *testing.T, store *Store, tableData *optimization.TableData) {
+	commit, err := store.Merge(t.Context(), tableData, nil)
+	assert.NoError(t, err)
+	assert.True(t, commit)
+}
+
+func queryRows(t *testing.T, store *Store, query string) [][]any {
+	rows, err := store.QueryContext(t.Context(), query)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	assert.NoError(t, err)
+
+	var out [][]any
+	for rows.Next() {
+		values := make([]any, len(cols))
+		pointers := make([]any, len(cols))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		assert.NoError(t, rows.Scan(pointers...))
+		out = append(out, values)
+	}
+
+	assert.NoError(t, rows.Err())
+	return out
+}
+
+func TestStore_Merge(t *testing.T) {
+	store := newStore(t)
+	topicConfig := kafkalib.TopicConfig{Database: "shop", Schema: "public", DropDeletedColumns: true}
+	{
+		// Initial load creates the table
+		merge(t, store, newTableData(t, topicConfig, []string{"name"},
+			map[string]any{"id": int64(1), "name": "foo", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(2), "name": "bar", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(3), "name": "baz", constants.DeleteColumnMarker: false},
+		))
+		assert.Equal(t, [][]any{{int64(1), "foo"}, {int64(2), "bar"}, {int64(3), "baz"}}, queryRows(t, store, `SELECT "id", "name" FROM "orders" ORDER BY "id"`))
+	}
+	{
+		// Update, delete, TOAST and a new column
+		merge(t, store, newTableData(t, topicConfig, []string{"name", "email"},
+			map[string]any{"id": int64(1), "name": "updated", "email": "foo@artie.com", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(2), constants.DeleteColumnMarker: true},
+			map[string]any{"id": int64(3), "name": constants.ToastUnavailableValuePlaceholder, "email": "baz@artie.com", constants.DeleteColumnMarker: false},
+			map[string]any{"id": int64(4), "name": "new", constants.DeleteColumnMarker: false},
+		))
+		assert.Equal(t, [][]any{
+			{int64(1), "updated", "foo@artie.com"},
+			{int64(3), "baz", "baz@artie.com"},
+			{int64(4), "new", nil},
+		}, queryRows(t, store, `SELECT "id", "name", "email" FROM "orders" ORDER BY "id"`))
+	}
+	{
+		// The table config is read back through PRAGMA table_info
+		store.configMap.RemoveTable(store.IdentifierFor(topicConfig.BuildDatabaseAndSchemaPair(), "orders"))
+		tableConfig, err := store.GetTableConfig(t.Context(), store.IdentifierFor(topicConfig.BuildDatabaseAndSchemaPair(), "orders"), true)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"id", "name", "email"}, columns.ColumnNames(tableConfig.GetColumns()))
+		assert.Equal(t, typing.BuildIntegerKind(typing.BigIntegerKind), tableConfig.GetColumns()[0].KindDetails)
+	}
+	{
+		// Columns are dropped once they have been missing for long enough
+		for range 2 {
+			tableData := newTableData(t, topicConfig, []string{"name"}, map[string]any{"id": int64(5), "name": "qux", constants.DeleteColumnMarker: false})
+			tableData.SetLatestTimestamp(time.Now().Add(365 * 24 * time.Hour))
+			merge(t, store, tableData)
+		}
+
+		assert.Equal(t, [][]any{{"id"}, {"name"}}, queryRows(t, store, `SELECT name FROM pragma_table_info('orders')`))
+	}
+	{
+		// Temporary tables are dropped after every merge
+		assert.Empty(t, queryRows(t, store, `SELECT name FROM sqlite_master WHERE name LIKE '%__artie%'`))
+	}
+}
+
+func TestStore_Merge_SoftDelete(t *testing.T) {
+	store := newStore(t)
+	topicConfig := kafkalib.TopicConfig{Database: "shop", Schema: "public", SoftDelete: true}
+	merge(t, store, newTableData(t, topicConfig, []string{"name"},
+		map[string]any{"id": int64(1), "name": "foo", constants.DeleteColumnMarker: false},
+		map[string]any{"id": int64(2), "name": "bar", constants.DeleteColumnMarker: false},
+	))
+	merge(t, store, newTableData(t, topicConfig, []string{"name"},
+		map[string]any{"id": int64(1), constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true},
+		map[string]any{"id": int64(3), "name": "baz", constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true},
+	))
+
+	assert.Equal(t, [][]any{
+		{int64(1), "foo", int64(1)},
+		{int64(2), "bar", int64(0)},
+		{int64(3), "baz", int64(1)},
+	}, queryRows(t, store, `SELECT "id", "name", "__artie_delete" FROM "orders" ORDER BY "id"`))
+}
+
+func TestStore_SweepTemporaryTables(t *testing.T) {
+	store := newStore(t)
+	expired := fmt.Sprintf("orders_%s_abcde_%d", constants.ArtiePrefix, time.Now().Add(-time.Hour).Unix())
+	active := fmt.Sprintf("orders_%s_fghij_%d", constants.ArtiePrefix, time.Now().Add(time.Hour).Unix())
+	for _, tableName := range []string{"orders", expired, active} {
+		_, err := store.ExecContext(t.Context(), fmt.Sprintf("CREATE TABLE %s (id BIGINT)", dialect.NewTableIdentifier(tableName).FullyQualifiedName()))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, store.SweepTemporaryTables(t.Context()))
+	assert.Equal(t, [][]any{{"orders"}, {active}}, queryRows(t, store, `SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name`))
+}
+
+func TestStore_Dedupe(t *testing.T) {
+	store := newStore(t)
+	err := store.Dedupe(t.Context(), dialect.NewTableIdentifier("orders"), kafkalib.DatabaseAndSchemaPair{}, []string{"id"}, false)
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+}
+
+func TestLoadReadOnlyStore(t *testing.T) {
+	cfg := config.Config{Output: constants.SQLite, SQLite: &config.SQLiteSettings{Path: filepath.Join(t.TempDir(), "artie.db")}}
+	{
+		// The database file has to exist already
+		_, err := LoadReadOnlyStore(t.Context(), cfg)
+		assert.Error(t, err)
+		assert.NoFileExists(t, cfg.SQLite.Path)
+	}
+	{
+		// Nothing can be written
+		store, err := LoadStore(t.Context(), cfg)
+		assert.NoError(t, err)
+		assert.NoError(t, store.Close())
+
+		readOnlyStore, err := LoadReadOnlyStore(t.Context(), cfg)
+		assert.NoError(t, err)
+		t.Cleanup(func() { assert.NoError(t, readOnlyStore.Close()) })
+		_, err = readOnlyStore.ExecContext(t.Context(), "CREATE TABLE orders (id BIGINT)")
+		assert.ErrorContains(t, err, "readonly")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"slices"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
@@ -17,6 +16,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/smithy-go"
 
+	"github.com/artie-labs/transfer/lib/batch"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/db"
@@ -52,6 +52,21 @@ func (s *Store) Validate() error {
 	return s.config.SQS.Validate()
 }
 
+// CheckConnectivity fetches the queue's attributes in single queue mode, otherwise it lists the queues since they're only known per table.
+func (s *Store) CheckConnectivity(ctx context.Context) error {
+	if s.config.SQS.IsSingleQueueMode() {
+		if _, err := s.sqsClient.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{QueueUrl: aws.String(s.config.SQS.QueueURL)}); err != nil {
+			return fmt.Errorf("failed to get queue attributes: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := s.sqsClient.ListQueues(ctx, &sqs.ListQueuesInput{MaxResults: aws.Int32(1)}); err != nil {
+		return fmt.Errorf("failed to list queues: %w", err)
+	}
+	return nil
+}
+
 func (s *Store) IdentifierFor(topicConfig kafkalib.DatabaseAndSchemaPair, table string) sqllib.TableIdentifier {
 	return NewTableIdentifier(topicConfig.Database, topicConfig.Schema, table)
 }
@@ -101,12 +116,12 @@ func (s *Store) Merge(ctx context.Context, tableData *optimization.TableData, _
 	}
 
 	var totalSent int
-	for batch := range slices.Chunk(tableData.Rows(), maxBatchSize) {
+	err = batch.ByCount(tableData.AllRows(), maxBatchSize, func(rows []optimization.Row) error {
 		var entries []types.SendMessageBatchRequestEntry
-		for i, row := range batch {
+		for i, row := range rows {
 			jsonData, err := json.Marshal(row.GetData())
 			if err != nil {
-				return false, fmt.Errorf("failed to marshal row data: %w", err)
+				return fmt.Errorf("failed to marshal row data: %w", err)
 			}
 
 			entry := types.SendMessageBatchRequestEntry{
@@ -124,19 +139,23 @@ func (s *Store) Merge(ctx context.Context, tableData *optimization.TableData, _
 
 		output, err := s.sqsClient.SendMessageBatch(ctx, input)
 		if err != nil {
-			return false, fmt.Errorf("failed to send message batch to SQS: %w", err)
+			return fmt.Errorf("failed to send message batch to SQS: %w", err)
 		}
 
 		// Check for partial failures
 		if len(output.Failed) > 0 {
-			return false, fmt.Errorf("failed to send %d messages: %q", len(output.Failed), firstError(output.Failed))
+			return fmt.Errorf("failed to send %d messages: %q", len(output.Failed), firstError(output.Failed))
 		}
 
 		totalSent += len(output.Successful)
+		return nil
+	})
+	if err != nil {
+		return false, err
 	}
 
-	if len(tableData.Rows()) != totalSent {
-		return false, fmt.Errorf("expected %d messages to be sent, got %d", len(tableData.Rows()), totalSent)
+	if expectedRows := int(tableData.NumberOfRows()); expectedRows != totalSent {
+		return false, fmt.Errorf("expected %d messages to be sent, got %d", expectedRows, totalSent)
 	}
 
 	return true, nil
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/artie-labs/transfer/clients/shared"
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/utils"
+	"github.com/artie-labs/transfer/lib/filelib"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/logger"
+	"github.com/artie-labs/transfer/lib/natslib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/webhooks"
+	"github.com/artie-labs/transfer/processes/consumer"
+)
+
+const connectivityTimeout = 30 * time.Second
+
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{
+		name:        "run",
+		description: "Replicate data from the message queue into the destination (default)",
+		run: func(_ context.Context, args []string) error {
+			run(args)
+			return nil
+		},
+	},
+	{name: "validate", description: "Validate the config and test connectivity to the destination and message queue", run: validateCommand},
+	{name: "dedupe", description: "Remove duplicate rows from a table, keeping the latest row per primary key", run: dedupeCommand},
+	{name: "sweep", description: "Drop temporary tables left behind by previous runs", run: sweepCommand},
+	{name: "describe", description: "Print the columns of a table next to the schema Transfer expects", run: describeCommand},
+	{name: "plan", description: "Print the statements Transfer would run for a sample of messages, without executing them", run: planCommand},
+	{name: "drop-table", description: "Drop a table from the destination", run: dropTableCommand},
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+
+	return command{}, false
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: transfer [command] --config <path> [options]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.description)
+	}
+	tw.Flush()
+}
+
+// tableOptions are shared by the commands that operate on a single table.
+type tableOptions struct {
+	Table string `long:"table" description:"name of the table in the destination" required:"true"`
+	Topic string `long:"topic" description:"topic of the topic config to use, only needed if the topic config does not specify a table name"`
+}
+
+// topicConfig returns the topic config that writes to [tableOptions.Table], or the one for [tableOptions.Topic] if it is set.
+func (t tableOptions) topicConfig(cfg config.Config) (kafkalib.TopicConfig, error) {
+	for _, tc := range cfg.TopicConfigs() {
+		if t.Topic != "" {
+			if tc.Topic == t.Topic {
+				return *tc, nil
+			}
+		} else if tc.TableName == t.Table {
+			return *tc, nil
+		}
+	}
+
+	if t.Topic != "" {
+		return kafkalib.TopicConfig{}, fmt.Errorf("no topic config found for topic %q", t.Topic)
+	}
+	return kafkalib.TopicConfig{}, fmt.Errorf("no topic config found for table %q, pass --topic if the topic config does not specify a table name", t.Table)
+}
+
+// loadSettings loads and validates the config and sets up the default logger for a command.
+func loadSettings(args []string, options any) (*config.Settings, error) {
+	settings, err := config.LoadSettingsWithOptions(args, true, options)
+	if err != nil {
+		return nil, err
+	}
+
+	_logger, _ := logger.NewLogger(settings.VerboseLogging, nil, version)
+	slog.SetDefault(_logger)
+	return settings, nil
+}
+
+func validateCommand(ctx context.Context, args []string) error {
+	settings, err := loadSettings(args, nil)
+	if err != nil {
+		return err
+	}
+	slog.Info("Config is valid", slog.String("destination", string(settings.Config.Output)), slog.String("queue", string(settings.Config.Queue)))
+
+	ctx, cancel := context.WithTimeout(ctx, connectivityTimeout)
+	defer cancel()
+
+	if err = utils.CheckConnectivity(ctx, settings.Config); err != nil {
+		return fmt.Errorf("failed to connect to destination: %w", err)
+	}
+	slog.Info("Connected to destination")
+
+	switch settings.Config.Queue {
+	case constants.Kafka:
+		err = kafkalib.CheckConnectivity(ctx, settings.Config.Kafka)
+	case constants.NATS:
+		err = natslib.CheckConnectivity(ctx, settings.Config.NATS)
+	case constants.File:
+		_, err = filelib.ReadFiles(settings.Config.File.Paths...)
+	default:
+		err = fmt.Errorf("message queue %q not supported", settings.Config.Queue)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", settings.Config.Queue, err)
+	}
+	slog.Info("Connected to message queue", slog.Any("topics", settings.Config.Topics()))
+	return nil
+}
+
+func dedupeCommand(ctx context.Context, args []string) error {
+	var opts struct {
+		tableOptions
+		PrimaryKeys []string `long:"pk" description:"primary key column, can be repeated (default: the topic config's primaryKeysOverride)"`
+	}
+
+	settings, err := loadSettings(args, &opts)
+	if err != nil {
+		return err
+	}
+
+	tc, err := opts.topicConfig(settings.Config)
+	if err != nil {
+		return err
+	}
+
+	primaryKeys := opts.PrimaryKeys
+	if len(primaryKeys) == 0 {
+		primaryKeys = tc.PrimaryKeysOverride
+	}
+	if len(primaryKeys) == 0 {
+		return fmt.Errorf("no primary keys provided, pass --pk or set primaryKeysOverride on the topic config")
+	}
+
+	dest, err := utils.LoadSQLDestination(ctx, settings.Config)
+	if err != nil {
+		return fmt.Errorf("failed to load destination: %w", err)
+	}
+
+	whClient, err := webhooks.NewClient(settings.Config.WebhookSettings, webhooks.Transfer, version)
+	if err != nil {
+		return fmt.Errorf("failed to initialize webhooks client: %w", err)
+	}
+
+	tableID := dest.IdentifierFor(tc.BuildDatabaseAndSchemaPair(), opts.Table)
+	props := webhooks.EventProperties{
+		Table:  tableID.Table(),
+		Schema: tableID.Schema(),
+	}
+
+	slog.Info("Running dedupe", slog.String("table", tableID.FullyQualifiedName()), slog.Any("primaryKeys", primaryKeys), slog.Bool("includeArtieUpdatedAt", tc.IncludeArtieUpdatedAt))
+	whClient.SendEvent(ctx, webhooks.EventDedupeStarted, props)
+	start := time.Now()
+	if err = dest.Dedupe(ctx, tableID, tc.BuildDatabaseAndSchemaPair(), primaryKeys, tc.IncludeArtieUpdatedAt); err != nil {
+		props.Error = err.Error()
+		props.DurationSeconds = time.Since(start).Seconds()
+		whClient.SendEvent(ctx, webhooks.EventDedupeFailed, props)
+		return fmt.Errorf("failed to dedupe %q: %w", tableID.FullyQualifiedName(), err)
+	}
+
+	props.DurationSeconds = time.Since(start).Seconds()
+	whClient.SendEvent(ctx, webhooks.EventDedupeCompleted, props)
+	slog.Info("Deduped", slog.String("table", tableID.FullyQualifiedName()), slog.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func sweepCommand(ctx context.Context, args []string) error {
+	settings, err := loadSettings(args, nil)
+	if err != nil {
+		return err
+	}
+
+	dest, err := utils.LoadSQLDestination(ctx, settings.Config)
+	if err != nil {
+		return fmt.Errorf("failed to load destination: %w", err)
+	}
+
+	if err = dest.SweepTemporaryTables(ctx); err != nil {
+		return fmt.Errorf("failed to clean up temporary tables: %w", err)
+	}
+
+	slog.Info("Swept temporary tables")
+	return nil
+}
+
+func describeCommand(ctx context.Context, args []string) error {
+	var opts tableOptions
+	settings, err := loadSettings(args, &opts)
+	if err != nil {
+		return err
+	}
+
+	tc, err := opts.topicConfig(settings.Config)
+	if err != nil {
+		return err
+	}
+
+	dest, err := utils.LoadSQLDestination(ctx, settings.Config)
+	if err != nil {
+		return fmt.Errorf("failed to load destination: %w", err)
+	}
+
+	tableID := dest.IdentifierFor(tc.BuildDatabaseAndSchemaPair(), opts.Table)
+	tableConfig, err := dest.GetTableConfig(ctx, tableID, tc.DropDeletedColumns)
+	if err != nil {
+		return fmt.Errorf("failed to describe %q: %w", tableID.FullyQualifiedName(), err)
+	}
+
+	if tableConfig.CreateTable() {
+		fmt.Printf("Table %s does not exist, Transfer will create it on the first flush.\n", tableID.FullyQualifiedName())
+		return nil
+	}
+
+	return printTableDescription(os.Stdout, dest, settings.Config, tc, tableConfig.GetColumns())
+}
+
+// printTableDescription prints every destination column with the data type Transfer would create for its kind,
+// followed by any columns the topic config expects that are missing from the destination.
+func printTableDescription(w io.Writer, dest destination.SQLDestination, cfg config.Config, tc kafkalib.TopicConfig, destCols []columns.Column) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLUMN\tKIND\tEXPECTED TYPE\tNOTE")
+
+	existing := make(map[string]bool)
+	for _, col := range destCols {
+		existing[col.Name()] = true
+		expectedType, err := dest.Dialect().DataTypeForKind(col.KindDetails, col.PrimaryKey(), cfg.SharedDestinationSettings.ColumnSettings)
+		if err != nil {
+			expectedType = fmt.Sprintf("error: %v", err)
+		}
+
+		var note string
+		switch {
+		case slices.Contains(tc.ColumnsToExclude, col.Name()):
+			note = "excluded by columnsToExclude"
+		case col.PrimaryKey():
+			note = "primary key"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", col.Name(), col.KindDetails.Kind, expectedType, note)
+	}
+
+	for _, name := range expectedColumns(cfg, tc) {
+		if !existing[name] {
+			fmt.Fprintf(tw, "%s\t-\t-\tmissing\n", name)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// expectedColumns returns the columns that the topic config asks Transfer to write, regardless of the source schema.
+func expectedColumns(cfg config.Config, tc kafkalib.TopicConfig) []string {
+	expected := optimization.NewTableData(nil, cfg.Mode, tc.PrimaryKeysOverride, tc, "").BuildColumnsToKeep()
+	expected = append(expected, tc.PrimaryKeysOverride...)
+	expected = append(expected, tc.ColumnsToInclude...)
+	for _, staticColumn := range tc.StaticColumns {
+		expected = append(expected, staticColumn.Name)
+	}
+
+	slices.Sort(expected)
+	return slices.Compact(expected)
+}
+
+func planCommand(ctx context.Context, args []string) error {
+	var opts struct {
+		Limit   int      `long:"limit" description:"number of messages to sample from the start of each topic" default:"100"`
+		Samples []string `long:"sample" description:"NDJSON file to read messages from instead of the message queue, can be repeated"`
+	}
+
+	settings, err := loadSettings(args, &opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Limit <= 0 {
+		return fmt.Errorf("--limit must be greater than 0")
+	}
+
+	dest, err := utils.LoadReadOnlySQLDestination(ctx, settings.Config)
+	if err != nil {
+		return fmt.Errorf("failed to load destination: %w", err)
+	}
+
+	msgs, err := fetchPlanMessages(ctx, settings.Config, opts.Samples, opts.Limit)
+	if err != nil {
+		return err
+	}
+
+	plans, err := consumer.BuildPlans(ctx, settings.Config, dest, msgs)
+	if err != nil {
+		return fmt.Errorf("failed to build plans: %w", err)
+	}
+
+	printPlans(os.Stdout, plans)
+	return nil
+}
+
+// fetchPlanMessages reads every message from [samples], or up to [limit] messages per topic from the message queue if there are none.
+// Every record is read for the file queue, since the files are already a sample.
+func fetchPlanMessages(ctx context.Context, cfg config.Config, samples []string, limit int) ([]artie.Message, error) {
+	if len(samples) > 0 {
+		return filelib.ReadFiles(samples...)
+	}
+
+	if cfg.Queue == constants.File {
+		msgs, err := filelib.ReadFiles(cfg.File.Paths...)
+		if err != nil {
+			return nil, err
+		}
+
+		// The file queue skips records for topics without a topic config.
+		return slices.DeleteFunc(msgs, func(msg artie.Message) bool {
+			return !slices.Contains(cfg.Topics(), msg.Topic())
+		}), nil
+	}
+
+	var msgs []artie.Message
+	for _, topic := range cfg.Topics() {
+		var topicMsgs []artie.Message
+		var err error
+		switch cfg.Queue {
+		case constants.Kafka:
+			topicMsgs, err = kafkalib.FetchSample(ctx, cfg.Kafka, topic, limit)
+		case constants.NATS:
+			topicMsgs, err = natslib.FetchSample(ctx, cfg.NATS, topic, limit)
+		default:
+			err = fmt.Errorf("message queue %q not supported", cfg.Queue)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample messages for topic %q: %w", topic, err)
+		}
+
+		slog.Info("Sampled messages", slog.String("topic", topic), slog.Int("count", len(topicMsgs)))
+		msgs = append(msgs, topicMsgs...)
+	}
+
+	return msgs, nil
+}
+
+func printPlans(w io.Writer, plans []shared.TablePlan) {
+	if len(plans) == 0 {
+		fmt.Fprintln(w, "-- No rows were sampled, there is nothing to plan.")
+		return
+	}
+
+	for i, plan := range plans {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		action := "table exists"
+		if plan.CreateTable {
+			action = "table will be created"
+		}
+		fmt.Fprintf(w, "-- %s (%d rows, %s)\n", plan.TableID.FullyQualifiedName(), plan.Rows, action)
+		printStatements(w, "Schema changes", plan.DDL)
+		printStatements(w, "Columns dropped after the deletion grace period", plan.DropColumns)
+		printStatements(w, "Staging", plan.Staging)
+		printStatements(w, "Merge", plan.Merge)
+	}
+}
+
+func printStatements(w io.Writer, header string, statements []string) {
+	if len(statements) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "-- %s\n", header)
+	for _, statement := range statements {
+		fmt.Fprintln(w, strings.TrimSuffix(statement, ";")+";")
+	}
+}
+
+func dropTableCommand(ctx context.Context, args []string) error {
+	var opts struct {
+		tableOptions
+		Yes bool `long:"yes" description:"confirm that the table should be dropped"`
+	}
+
+	settings, err := loadSettings(args, &opts)
+	if err != nil {
+		return err
+	}
+
+	tc, err := opts.topicConfig(settings.Config)
+	if err != nil {
+		return err
+	}
+
+	dest, err := utils.Load(ctx, settings.Config)
+	if err != nil {
+		return fmt.Errorf("failed to load destination: %w", err)
+	}
+
+	tableID := dest.IdentifierFor(tc.BuildDatabaseAndSchemaPair(), opts.Table)
+	if !opts.Yes {
+		return fmt.Errorf("refusing to drop %q without --yes", tableID.FullyQualifiedName())
+	}
+
+	// DropTable only drops temporary tables to protect against accidents, this command is the explicit opt-in.
+	if err = dest.DropTable(ctx, tableID.WithTemporaryTable(true)); err != nil {
+		return fmt.Errorf("failed to drop %q: %w", tableID.FullyQualifiedName(), err)
+	}
+
+	slog.Info("Dropped table", slog.String("table", tableID.FullyQualifiedName()))
+	return nil
+}
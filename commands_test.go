@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/clients/shared"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/filelib"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks"
+)
+
+func TestTableOptions_TopicConfig(t *testing.T) {
+	orders := &kafkalib.TopicConfig{Topic: "dbserver1.public.orders", TableName: "orders", Schema: "public"}
+	customers := &kafkalib.TopicConfig{Topic: "dbserver1.public.customers", Schema: "public"}
+	cfg := config.Config{Queue: constants.Kafka, Kafka: &kafkalib.Kafka{TopicConfigs: []*kafkalib.TopicConfig{orders, customers}}}
+
+	testCases := []struct {
+		name          string
+		opts          tableOptions
+		cfg           config.Config
+		expected      kafkalib.TopicConfig
+		expectedError string
+	}{
+		{
+			name:     "matches on the table name",
+			opts:     tableOptions{Table: "orders"},
+			cfg:      cfg,
+			expected: *orders,
+		},
+		{
+			name:     "matches on the topic",
+			opts:     tableOptions{Table: "customers", Topic: "dbserver1.public.customers"},
+			cfg:      cfg,
+			expected: *customers,
+		},
+		{
+			name:          "topic takes precedence over the table name",
+			opts:          tableOptions{Table: "orders", Topic: "dbserver1.public.missing"},
+			cfg:           cfg,
+			expectedError: `no topic config found for topic "dbserver1.public.missing"`,
+		},
+		{
+			name:          "topic config does not specify a table name",
+			opts:          tableOptions{Table: "customers"},
+			cfg:           cfg,
+			expectedError: `no topic config found for table "customers", pass --topic if the topic config does not specify a table name`,
+		},
+		{
+			name:     "file queue",
+			opts:     tableOptions{Table: "orders"},
+			cfg:      config.Config{Queue: constants.File, File: &filelib.File{TopicConfigs: []*kafkalib.TopicConfig{orders}}},
+			expected: *orders,
+		},
+		{
+			name:          "no topic configs",
+			opts:          tableOptions{Table: "orders"},
+			cfg:           config.Config{Queue: constants.Kafka},
+			expectedError: `no topic config found for table "orders"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := testCase.opts.topicConfig(testCase.cfg)
+		if testCase.expectedError != "" {
+			assert.ErrorContains(t, err, testCase.expectedError, testCase.name)
+			continue
+		}
+
+		assert.NoError(t, err, testCase.name)
+		assert.Equal(t, testCase.expected, actual, testCase.name)
+	}
+}
+
+func TestExpectedColumns(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mode     config.Mode
+		tc       kafkalib.TopicConfig
+		expected []string
+	}{
+		{
+			name:     "primary keys and columns to include",
+			mode:     config.Replication,
+			tc:       kafkalib.TopicConfig{PrimaryKeysOverride: []string{"id"}, ColumnsToInclude: []string{"name", "id"}},
+			expected: []string{"id", "name"},
+		},
+		{
+			name: "artie columns and static columns",
+			mode: config.Replication,
+			tc: kafkalib.TopicConfig{
+				SoftDelete:            true,
+				IncludeArtieUpdatedAt: true,
+				StaticColumns:         []kafkalib.StaticColumn{{Name: "region", Value: "us"}},
+			},
+			expected: []string{constants.DeleteColumnMarker, constants.UpdateColumnMarker, "region"},
+		},
+		{
+			name:     "history mode includes the operation column",
+			mode:     config.History,
+			tc:       kafkalib.TopicConfig{IncludeArtieOperation: true},
+			expected: []string{constants.OperationColumnMarker},
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, expectedColumns(config.Config{Mode: testCase.mode}, testCase.tc), testCase.name)
+	}
+}
+
+func TestPrintPlans(t *testing.T) {
+	ordersID := &mocks.FakeTableIdentifier{}
+	ordersID.FullyQualifiedNameReturns(`"public"."orders"`)
+	customersID := &mocks.FakeTableIdentifier{}
+	customersID.FullyQualifiedNameReturns(`"public"."customers"`)
+
+	testCases := []struct {
+		name     string
+		plans    []shared.TablePlan
+		expected string
+	}{
+		{
+			name:     "no plans",
+			expected: "-- No rows were sampled, there is nothing to plan.\n",
+		},
+		{
+			name: "new table",
+			plans: []shared.TablePlan{{
+				TableID:     ordersID,
+				Rows:        2,
+				CreateTable: true,
+				DDL:         []string{`CREATE TABLE "public"."orders" ("id" bigint)`},
+				Merge:       []string{`INSERT INTO "public"."orders" VALUES (1);`},
+			}},
+			expected: `-- "public"."orders" (2 rows, table will be created)
+-- Schema changes
+CREATE TABLE "public"."orders" ("id" bigint);
+-- Merge
+INSERT INTO "public"."orders" VALUES (1);
+`,
+		},
+		{
+			name: "multiple tables",
+			plans: []shared.TablePlan{
+				{
+					TableID:     ordersID,
+					Rows:        1,
+					DropColumns: []string{`ALTER TABLE "public"."orders" DROP COLUMN "name"`},
+					Staging:     []string{`CREATE TABLE "public"."orders_staging" ("id" bigint)`},
+				},
+				{TableID: customersID, Rows: 3},
+			},
+			expected: `-- "public"."orders" (1 rows, table exists)
+-- Columns dropped after the deletion grace period
+ALTER TABLE "public"."orders" DROP COLUMN "name";
+-- Staging
+CREATE TABLE "public"."orders_staging" ("id" bigint);
+
+-- "public"."customers" (3 rows, table exists)
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var buf bytes.Buffer
+		printPlans(&buf, testCase.plans)
+		assert.Equal(t, testCase.expected, buf.String(), testCase.name)
+	}
+}
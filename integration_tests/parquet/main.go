@@ -203,6 +203,6 @@ func main() {
 			logger.Fatal("Failed to write parquet file", slog.Any("error", err))
 		}
 
-		slog.Info("Wrote comprehensive parquet file", slog.String("path", parquetPath), slog.Int("rows", len(tableData.Rows())))
+		slog.Info("Wrote comprehensive parquet file", slog.String("path", parquetPath), slog.Int("rows", int(tableData.NumberOfRows())))
 	}
 }
@@ -15,6 +15,12 @@ type Message interface {
 	Key() []byte
 	Value() []byte
 	HighWaterMark() int64
+	Headers() []Header
+}
+
+type Header struct {
+	Key   string
+	Value []byte
 }
 
 func BuildLogFields(msg Message) []any {
@@ -66,3 +72,11 @@ func (m FranzGoMessage) Value() []byte {
 func (m FranzGoMessage) HighWaterMark() int64 {
 	return m.highWatermark
 }
+
+func (m FranzGoMessage) Headers() []Header {
+	headers := make([]Header, 0, len(m.message.Headers))
+	for _, header := range m.message.Headers {
+		headers = append(headers, Header{Key: header.Key, Value: header.Value})
+	}
+	return headers
+}
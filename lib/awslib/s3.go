@@ -3,6 +3,7 @@ package awslib
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -52,6 +53,29 @@ func (s S3Client) UploadLocalFileToS3(ctx context.Context, bucket, prefix, filep
 	return fmt.Sprintf("s3://%s/%s", bucket, objectKey), nil
 }
 
+// PutObject uploads [body] to [bucket] under [objectKey] and returns the S3 URI of the object.
+func (s S3Client) PutObject(ctx context.Context, bucket, objectKey string, body io.Reader) (string, error) {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(objectKey),
+		Body:   body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload object to s3: %w", err)
+	}
+
+	return fmt.Sprintf("s3://%s/%s", bucket, objectKey), nil
+}
+
+// HeadBucket checks that the bucket exists and that we have access to it.
+func (s S3Client) HeadBucket(ctx context.Context, bucket string) error {
+	if _, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)}); err != nil {
+		return fmt.Errorf("failed to access bucket %q: %w", bucket, err)
+	}
+
+	return nil
+}
+
 // DeleteFolder - Folders in S3 are virtual, so we need to list all the objects in the folder and then delete them
 func (s S3Client) DeleteFolder(ctx context.Context, bucket, folder string) error {
 	var continuationToken *string
@@ -2,6 +2,7 @@ package batch
 
 import (
 	"fmt"
+	"iter"
 	"log/slog"
 )
 
@@ -20,12 +21,32 @@ func hasKeyFunction[T any](item T) (KeyFunction, bool) {
 // BySize takes a series of elements [in], encodes them using [encode], groups them into batches of bytes that sum to at
 // most [maxSizeBytes], and then passes each batch to the [yield] function.
 func BySize[T any](in []T, maxSizeBytes int, failIfRowExceedsMaxSizeBytes bool, encode func(T) ([]byte, error), yield func([][]byte, []T) error) (int, error) {
+	seq := func(yield func(T, error) bool) {
+		for _, item := range in {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+
+	return BySizeSeq(seq, maxSizeBytes, failIfRowExceedsMaxSizeBytes, encode, yield)
+}
+
+// BySizeSeq is the same as [BySize], but reads the elements from an iterator so that they don't all have to be in memory.
+func BySizeSeq[T any](in iter.Seq2[T, error], maxSizeBytes int, failIfRowExceedsMaxSizeBytes bool, encode func(T) ([]byte, error), yield func([][]byte, []T) error) (int, error) {
 	var buffer [][]byte
 	var rows []T
 	var currentSizeBytes int
 	var skipped int
+	var count int
+
+	for item, err := range in {
+		i := count
+		count++
+		if err != nil {
+			return 0, fmt.Errorf("failed to read item %d: %w", i, err)
+		}
 
-	for i, item := range in {
 		bytes, err := encode(item)
 		if err != nil {
 			return 0, fmt.Errorf("failed to encode item %d: %w", i, err)
@@ -77,3 +98,29 @@ func BySize[T any](in []T, maxSizeBytes int, failIfRowExceedsMaxSizeBytes bool,
 
 	return skipped, nil
 }
+
+// ByCount groups the elements of [in] into batches of at most [size] elements and passes each batch to the [yield] function.
+// This stops at the first error returned by either [in] or [yield].
+func ByCount[T any](in iter.Seq2[T, error], size int, yield func([]T) error) error {
+	var buffer []T
+	for item, err := range in {
+		if err != nil {
+			return err
+		}
+
+		buffer = append(buffer, item)
+		if len(buffer) == size {
+			if err = yield(buffer); err != nil {
+				return err
+			}
+
+			buffer = nil
+		}
+	}
+
+	if len(buffer) > 0 {
+		return yield(buffer)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package batch
 
 import (
 	"fmt"
+	"iter"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -157,3 +158,59 @@ func TestBySize(t *testing.T) {
 		assert.Equal(t, []string{"foo", "bar", "baz", "qux"}, items[0])
 	}
 }
+
+func TestByCount(t *testing.T) {
+	seq := func(values ...string) iter.Seq2[string, error] {
+		return func(yield func(string, error) bool) {
+			for _, value := range values {
+				if !yield(value, nil) {
+					return
+				}
+			}
+		}
+	}
+
+	testByCount := func(in iter.Seq2[string, error], size int) ([][]string, error) {
+		var batches [][]string
+		err := ByCount(in, size, func(batch []string) error {
+			batches = append(batches, batch)
+			return nil
+		})
+		return batches, err
+	}
+	{
+		// Empty
+		batches, err := testByCount(seq(), 2)
+		assert.NoError(t, err)
+		assert.Empty(t, batches)
+	}
+	{
+		// Last batch is partial
+		batches, err := testByCount(seq("a", "b", "c", "d", "e"), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, batches)
+	}
+	{
+		// Batches line up with the size
+		batches, err := testByCount(seq("a", "b", "c", "d"), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}}, batches)
+	}
+	{
+		// Iterator error
+		in := func(yield func(string, error) bool) {
+			if yield("a", nil) {
+				yield("", fmt.Errorf("failed to read"))
+			}
+		}
+		_, err := testByCount(in, 5)
+		assert.ErrorContains(t, err, "failed to read")
+	}
+	{
+		// Yield error
+		err := ByCount(seq("a", "b", "c"), 2, func(batch []string) error {
+			return fmt.Errorf("yield failed for %v", batch)
+		})
+		assert.ErrorContains(t, err, "yield failed for [a b]")
+	}
+}
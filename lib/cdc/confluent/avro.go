@@ -0,0 +1,226 @@
+package confluent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/cdc/util"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+)
+
+// Avro parses Debezium events that were serialized with `io.confluent.connect.avro.AvroConverter`.
+type Avro struct {
+	registry *schemaregistry.Client
+
+	mu      sync.RWMutex
+	schemas map[int]avro.Schema
+}
+
+func NewAvro(registry *schemaregistry.Client) *Avro {
+	return &Avro{registry: registry, schemas: make(map[int]avro.Schema)}
+}
+
+func (a *Avro) Labels() []string {
+	return []string{constants.DBZRelationalAvroFormat}
+}
+
+func (a *Avro) GetPrimaryKey(ctx context.Context, key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error) {
+	if tc.CDCKeyFormat != kafkalib.AvroKeyFmt {
+		return debezium.ParsePartitionKey(key, tc.CDCKeyFormat, reservedColumns)
+	}
+
+	schema, value, err := a.decode(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key: %w", err)
+	}
+
+	recordSchema, ok := schema.(*avro.RecordSchema)
+	if !ok {
+		return nil, fmt.Errorf("expected key schema to be a record, got %q", schema.Type())
+	}
+
+	payload, err := avroRecordToPrimaryKeyPayload(recordSchema, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return debezium.ParsePrimaryKeyPayload(payload, reservedColumns)
+}
+
+func (a *Avro) GetEventFromBytes(ctx context.Context, bytes []byte) (cdc.Event, error) {
+	if len(bytes) == 0 {
+		return nil, fmt.Errorf("empty message")
+	}
+
+	schema, value, err := a.decode(ctx, bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	envelopeSchema, ok := schema.(*avro.RecordSchema)
+	if !ok {
+		return nil, fmt.Errorf("expected envelope schema to be a record, got %q", schema.Type())
+	}
+
+	envelope, err := avroToValue(envelopeSchema, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert envelope: %w", err)
+	}
+
+	var rowSchema *avro.RecordSchema
+	for _, field := range envelopeSchema.Fields() {
+		if field.Name() != string(debezium.After) && field.Name() != string(debezium.Before) {
+			continue
+		}
+
+		fieldSchema, _, err := unwrapAvroSchema(field.Type())
+		if err != nil {
+			return nil, fmt.Errorf("failed to unwrap %q schema: %w", field.Name(), err)
+		}
+
+		if rowSchema, ok = fieldSchema.(*avro.RecordSchema); ok {
+			break
+		}
+	}
+
+	if rowSchema == nil {
+		return nil, fmt.Errorf("envelope schema %q does not contain a before or after record", envelopeSchema.FullName())
+	}
+
+	fields, err := avroRecordToFields(rowSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSchemaEventPayload(envelope.(map[string]any), fields)
+}
+
+// decode strips the wire format header, looks up the writer schema and decodes the payload.
+func (a *Avro) decode(ctx context.Context, data []byte) (avro.Schema, any, error) {
+	schemaID, payload, err := schemaregistry.ParseWireFormat(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schema, err := a.getSchema(ctx, schemaID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var value any
+	if err = avro.Unmarshal(schema, payload, &value); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal avro payload with schema %d: %w", schemaID, err)
+	}
+
+	return schema, value, nil
+}
+
+func (a *Avro) getSchema(ctx context.Context, schemaID int) (avro.Schema, error) {
+	a.mu.RLock()
+	schema, ok := a.schemas[schemaID]
+	a.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	registrySchema, err := a.registry.GetSchemaByID(ctx, schemaID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", cdc.ErrSchemaRegistry, err)
+	}
+
+	if registrySchema.Type() != schemaregistry.Avro {
+		return nil, fmt.Errorf("schema %d is not an avro schema: %q", schemaID, registrySchema.Type())
+	}
+
+	references, err := a.registry.ResolveReferences(ctx, registrySchema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", cdc.ErrSchemaRegistry, err)
+	}
+
+	// Referenced named types need to be registered within the cache before the schema that uses them is parsed.
+	cache := &avro.SchemaCache{}
+	for name, reference := range references {
+		if _, err = avro.ParseWithCache(reference.Schema, "", cache); err != nil {
+			return nil, fmt.Errorf("failed to parse referenced schema %q: %w", name, err)
+		}
+	}
+
+	schema, err = avro.ParseWithCache(registrySchema.Schema, "", cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema %d: %w", schemaID, err)
+	}
+
+	a.mu.Lock()
+	a.schemas[schemaID] = schema
+	a.mu.Unlock()
+	return schema, nil
+}
+
+func avroRecordToPrimaryKeyPayload(schema *avro.RecordSchema, value any) (debezium.PrimaryKeyPayload, error) {
+	fields, err := avroRecordToFields(schema)
+	if err != nil {
+		return debezium.PrimaryKeyPayload{}, err
+	}
+
+	payload, err := avroToValue(schema, value)
+	if err != nil {
+		return debezium.PrimaryKeyPayload{}, fmt.Errorf("failed to convert key: %w", err)
+	}
+
+	return debezium.PrimaryKeyPayload{
+		Schema:  debezium.FieldsObject{FieldObjectType: string(debezium.Struct), Fields: fields},
+		Payload: payload.(map[string]any),
+	}, nil
+}
+
+// buildSchemaEventPayload assembles the same [util.SchemaEventPayload] that the JSON converter produces, so that the rest of the pipeline is unchanged.
+func buildSchemaEventPayload(envelope map[string]any, fields []debezium.Field) (*util.SchemaEventPayload, error) {
+	var source util.Source
+	if sourceValue, ok := envelope[string(debezium.Source)]; ok && sourceValue != nil {
+		sourceBytes, err := json.Marshal(sourceValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal source: %w", err)
+		}
+
+		if err = json.Unmarshal(sourceBytes, &source); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal source: %w", err)
+		}
+	}
+
+	operation, ok := envelope[string(debezium.Op)].(string)
+	if !ok {
+		return nil, fmt.Errorf("envelope is missing the %q field", debezium.Op)
+	}
+
+	event := &util.SchemaEventPayload{
+		Schema: debezium.Schema{
+			SchemaType: string(debezium.Struct),
+			FieldsObject: []debezium.FieldsObject{
+				{FieldObjectType: string(debezium.Struct), Fields: fields, Optional: true, FieldLabel: debezium.Before},
+				{FieldObjectType: string(debezium.Struct), Fields: fields, Optional: true, FieldLabel: debezium.After},
+			},
+		},
+		Payload: util.Payload{
+			Source:    source,
+			Operation: constants.Operation(operation),
+		},
+	}
+
+	if before, ok := envelope[string(debezium.Before)].(map[string]any); ok {
+		event.Payload.Before = before
+	}
+
+	if after, ok := envelope[string(debezium.After)].(map[string]any); ok {
+		event.Payload.After = after
+	}
+
+	return event, nil
+}
@@ -0,0 +1,278 @@
+package confluent
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/hamba/avro/v2"
+
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/debezium/converters"
+)
+
+const (
+	connectNameProp       = "connect.name"
+	connectTypeProp       = "connect.type"
+	connectParametersProp = "connect.parameters"
+)
+
+// unwrapAvroSchema resolves references and nullable unions, Debezium only emits unions in the form of [null, T].
+func unwrapAvroSchema(schema avro.Schema) (avro.Schema, bool, error) {
+	switch castedSchema := schema.(type) {
+	case *avro.RefSchema:
+		return unwrapAvroSchema(castedSchema.Schema())
+	case *avro.UnionSchema:
+		if !castedSchema.Nullable() {
+			return nil, false, fmt.Errorf("unsupported union: %s", castedSchema.String())
+		}
+
+		_, typeIdx := castedSchema.Indices()
+		inner, _, err := unwrapAvroSchema(castedSchema.Types()[typeIdx])
+		if err != nil {
+			return nil, false, err
+		}
+
+		return inner, true, nil
+	}
+
+	return schema, false, nil
+}
+
+// unwrapAvroValue strips the union wrapper that named types are decoded with, e.g. {"dbserver1.inventory.customers.Value": {...}}.
+func unwrapAvroValue(schema avro.Schema, value any) any {
+	namedSchema, ok := schema.(avro.NamedSchema)
+	if !ok {
+		return value
+	}
+
+	castedValue, ok := value.(map[string]any)
+	if !ok || len(castedValue) != 1 {
+		return value
+	}
+
+	if inner, ok := castedValue[namedSchema.FullName()]; ok {
+		return inner
+	}
+
+	return value
+}
+
+func avroLogicalType(schema avro.Schema) avro.LogicalType {
+	if logicalSchema, ok := schema.(avro.LogicalTypeSchema); ok && logicalSchema.Logical() != nil {
+		return logicalSchema.Logical().Type()
+	}
+
+	return ""
+}
+
+func avroProp(schema avro.Schema, key string) any {
+	if propertySchema, ok := schema.(avro.PropertySchema); ok {
+		return propertySchema.Prop(key)
+	}
+
+	return nil
+}
+
+func avroRecordToFields(schema *avro.RecordSchema) ([]debezium.Field, error) {
+	fields := make([]debezium.Field, 0, len(schema.Fields()))
+	for _, avroField := range schema.Fields() {
+		field, err := avroSchemaToField(avroField.Name(), avroField.Type())
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %q: %w", avroField.Name(), err)
+		}
+
+		// Defaults for bytes and logical types are encoded differently from the JSON converter, so we'll skip them.
+		if avroField.HasDefault() && field.Type != debezium.Bytes && field.DebeziumType == "" {
+			field.Default = avroField.Default()
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// avroSchemaToField maps an Avro schema onto the equivalent [debezium.Field] that the JSON converter would have emitted.
+func avroSchemaToField(name string, schema avro.Schema) (debezium.Field, error) {
+	schema, optional, err := unwrapAvroSchema(schema)
+	if err != nil {
+		return debezium.Field{}, err
+	}
+
+	field := debezium.Field{FieldName: name, Optional: optional}
+	switch schema.Type() {
+	case avro.Boolean:
+		field.Type = debezium.Boolean
+	case avro.Int:
+		field.Type = debezium.Int32
+		switch avroLogicalType(schema) {
+		case avro.Date:
+			field.DebeziumType = debezium.Date
+		case avro.TimeMillis:
+			field.DebeziumType = debezium.Time
+		}
+	case avro.Long:
+		field.Type = debezium.Int64
+		switch avroLogicalType(schema) {
+		case avro.TimeMicros:
+			field.DebeziumType = debezium.MicroTime
+		case avro.TimestampMillis, avro.LocalTimestampMillis:
+			field.DebeziumType = debezium.Timestamp
+		case avro.TimestampMicros, avro.LocalTimestampMicros:
+			field.DebeziumType = debezium.MicroTimestamp
+		}
+	case avro.Float:
+		field.Type = debezium.Float
+	case avro.Double:
+		field.Type = debezium.Double
+	case avro.String:
+		field.Type = debezium.String
+		if avroLogicalType(schema) == avro.UUID {
+			field.DebeziumType = debezium.UUID
+		}
+	case avro.Enum:
+		field.Type = debezium.String
+	case avro.Bytes, avro.Fixed:
+		field.Type = debezium.Bytes
+		if logicalSchema, ok := schema.(avro.LogicalTypeSchema); ok {
+			if decimalSchema, ok := logicalSchema.Logical().(*avro.DecimalLogicalSchema); ok {
+				field.DebeziumType = debezium.KafkaDecimalType
+				field.Parameters = map[string]any{
+					"scale":                           decimalSchema.Scale(),
+					debezium.KafkaDecimalPrecisionKey: decimalSchema.Precision(),
+				}
+			}
+		}
+	case avro.Record:
+		field.Type = debezium.Struct
+	case avro.Map:
+		field.Type = debezium.Map
+	case avro.Array:
+		items, err := avroSchemaToField("", schema.(*avro.ArraySchema).Items())
+		if err != nil {
+			return debezium.Field{}, fmt.Errorf("failed to convert array items: %w", err)
+		}
+
+		field.Type = debezium.Array
+		field.ItemsMetadata = &items
+	default:
+		return debezium.Field{}, fmt.Errorf("unsupported avro type %q", schema.Type())
+	}
+
+	// Kafka Connect preserves the original schema name and parameters as properties, these take precedence.
+	if connectName, ok := avroProp(schema, connectNameProp).(string); ok {
+		field.DebeziumType = debezium.SupportedDebeziumType(connectName)
+	}
+
+	if connectType, ok := avroProp(schema, connectTypeProp).(string); ok {
+		switch connectType {
+		case "int8":
+			field.Type = debezium.Int8
+		case "int16":
+			field.Type = debezium.Int16
+		}
+	}
+
+	if parameters, ok := avroProp(schema, connectParametersProp).(map[string]any); ok {
+		if field.Parameters == nil {
+			field.Parameters = make(map[string]any)
+		}
+
+		for key, value := range parameters {
+			field.Parameters[key] = value
+		}
+	}
+
+	return field, nil
+}
+
+// avroToValue converts a decoded Avro value into what the JSON converter would have emitted, so that it can be parsed by [debezium.Field.ParseValue].
+func avroToValue(schema avro.Schema, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	schema, _, err := unwrapAvroSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	value = unwrapAvroValue(schema, value)
+	switch castedValue := value.(type) {
+	case int:
+		return int64(castedValue), nil
+	case float32:
+		return float64(castedValue), nil
+	case time.Time:
+		switch avroLogicalType(schema) {
+		case avro.Date:
+			return int64(castedValue.Unix() / int64((24 * time.Hour).Seconds())), nil
+		case avro.TimestampMillis, avro.LocalTimestampMillis:
+			return castedValue.UnixMilli(), nil
+		default:
+			return castedValue.UnixMicro(), nil
+		}
+	case time.Duration:
+		if avroLogicalType(schema) == avro.TimeMillis {
+			return castedValue.Milliseconds(), nil
+		}
+		return castedValue.Microseconds(), nil
+	case *big.Rat:
+		decimalSchema, ok := schema.(avro.LogicalTypeSchema).Logical().(*avro.DecimalLogicalSchema)
+		if !ok {
+			return nil, fmt.Errorf("expected decimal logical type for %T", value)
+		}
+
+		return encodeRat(castedValue, decimalSchema.Scale()), nil
+	case map[string]any:
+		switch castedSchema := schema.(type) {
+		case *avro.RecordSchema:
+			out := make(map[string]any, len(castedValue))
+			for _, field := range castedSchema.Fields() {
+				fieldValue, err := avroToValue(field.Type(), castedValue[field.Name()])
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert field %q: %w", field.Name(), err)
+				}
+				out[field.Name()] = fieldValue
+			}
+			return out, nil
+		case *avro.MapSchema:
+			out := make(map[string]any, len(castedValue))
+			for key, mapValue := range castedValue {
+				convertedValue, err := avroToValue(castedSchema.Values(), mapValue)
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert map value %q: %w", key, err)
+				}
+				out[key] = convertedValue
+			}
+			return out, nil
+		}
+	case []any:
+		arraySchema, ok := schema.(*avro.ArraySchema)
+		if !ok {
+			return nil, fmt.Errorf("expected array schema, got %q", schema.Type())
+		}
+
+		out := make([]any, len(castedValue))
+		for i, item := range castedValue {
+			convertedItem, err := avroToValue(arraySchema.Items(), item)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert array item %d: %w", i, err)
+			}
+			out[i] = convertedItem
+		}
+		return out, nil
+	}
+
+	return value, nil
+}
+
+// encodeRat returns the unscaled two's-complement representation that `org.apache.kafka.connect.data.Decimal` uses.
+func encodeRat(value *big.Rat, scale int) []byte {
+	unscaled := new(big.Int).Mul(value.Num(), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+	unscaled.Quo(unscaled, value.Denom())
+	bytes, _ := converters.EncodeDecimal(apd.NewWithBigInt(new(apd.BigInt).SetMathBigInt(unscaled), int32(-scale)))
+	return bytes
+}
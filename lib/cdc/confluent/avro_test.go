@@ -0,0 +1,233 @@
+package confluent
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+const (
+	avroKeySchema = `{
+		"type": "record", "name": "Key", "namespace": "dbserver1.inventory.customers",
+		"fields": [{"name": "id", "type": "int"}]
+	}`
+	avroValueSchema = `{
+		"type": "record", "name": "Envelope", "namespace": "dbserver1.inventory.customers",
+		"fields": [
+			{"name": "before", "type": ["null", {
+				"type": "record", "name": "Value",
+				"fields": [
+					{"name": "id", "type": "int"},
+					{"name": "email", "type": ["null", "string"], "default": null},
+					{"name": "balance", "type": ["null", {"type": "bytes", "scale": 2, "precision": 10, "connect.version": 1, "connect.parameters": {"scale": "2", "connect.decimal.precision": "10"}, "connect.name": "org.apache.kafka.connect.data.Decimal", "logicalType": "decimal"}], "default": null},
+					{"name": "created_at", "type": {"type": "long", "connect.version": 1, "connect.name": "io.debezium.time.MicroTimestamp"}},
+					{"name": "updated_at", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+					{"name": "birthday", "type": ["null", {"type": "int", "connect.version": 1, "connect.name": "io.debezium.time.Date"}], "default": null},
+					{"name": "tags", "type": {"type": "array", "items": "string"}},
+					{"name": "active", "type": "boolean", "default": true}
+				]
+			}], "default": null},
+			{"name": "after", "type": ["null", "Value"], "default": null},
+			{"name": "source", "type": {
+				"type": "record", "name": "Source", "namespace": "io.debezium.connector.postgresql",
+				"fields": [
+					{"name": "connector", "type": "string"},
+					{"name": "ts_ms", "type": "long"},
+					{"name": "db", "type": "string"},
+					{"name": "schema", "type": "string"},
+					{"name": "table", "type": "string"},
+					{"name": "lsn", "type": ["null", "long"], "default": null}
+				]
+			}},
+			{"name": "op", "type": "string"},
+			{"name": "ts_ms", "type": ["null", "long"], "default": null}
+		]
+	}`
+)
+
+func newTestRegistry(t *testing.T, schemas map[int]schemaregistry.Schema) *schemaregistry.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for id, schema := range schemas {
+			if r.URL.Path == fmt.Sprintf("/schemas/ids/%d", id) {
+				assert.NoError(t, json.NewEncoder(w).Encode(schema))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	return schemaregistry.NewClient(server.URL, "", "")
+}
+
+func toWireFormat(schemaID int, payload []byte) []byte {
+	header := make([]byte, 5)
+	binary.BigEndian.PutUint32(header[1:], uint32(schemaID))
+	return append(header, payload...)
+}
+
+func marshalAvro(t *testing.T, schema string, value any) []byte {
+	out, err := avro.Marshal(avro.MustParse(schema), value)
+	assert.NoError(t, err)
+	return out
+}
+
+func TestAvro_GetPrimaryKey(t *testing.T) {
+	format := NewAvro(newTestRegistry(t, map[int]schemaregistry.Schema{1: {Schema: avroKeySchema}}))
+	{
+		// Avro key
+		key := toWireFormat(1, marshalAvro(t, avroKeySchema, map[string]any{"id": 1001}))
+		pk, err := format.GetPrimaryKey(t.Context(), key, kafkalib.TopicConfig{CDCKeyFormat: kafkalib.AvroKeyFmt}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": int64(1001)}, pk)
+	}
+	{
+		// String key
+		pk, err := format.GetPrimaryKey(t.Context(), []byte("Struct{id=1001}"), kafkalib.TopicConfig{CDCKeyFormat: kafkalib.StringKeyFmt}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": "1001"}, pk)
+	}
+	{
+		// Schema does not exist
+		_, err := format.GetPrimaryKey(t.Context(), toWireFormat(2, nil), kafkalib.TopicConfig{CDCKeyFormat: kafkalib.AvroKeyFmt}, nil)
+		assert.ErrorContains(t, err, "failed to fetch schema 2")
+	}
+}
+
+func TestAvro_RegistryUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	format := NewAvro(schemaregistry.NewClient(server.URL, "", ""))
+	{
+		// Key
+		_, err := format.GetPrimaryKey(t.Context(), toWireFormat(1, []byte{0}), kafkalib.TopicConfig{CDCKeyFormat: kafkalib.AvroKeyFmt}, nil)
+		assert.ErrorIs(t, err, cdc.ErrSchemaRegistry)
+		assert.ErrorContains(t, err, "unexpected status code 503")
+	}
+	{
+		// Value
+		_, err := format.GetEventFromBytes(t.Context(), toWireFormat(2, []byte{0}))
+		assert.ErrorIs(t, err, cdc.ErrSchemaRegistry)
+	}
+	{
+		// Not in the wire format, this is an issue with the message and not the registry.
+		_, err := format.GetEventFromBytes(t.Context(), []byte{1})
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, cdc.ErrSchemaRegistry)
+	}
+}
+
+func TestAvro_GetEventFromBytes(t *testing.T) {
+	format := NewAvro(newTestRegistry(t, map[int]schemaregistry.Schema{
+		1: {Schema: avroKeySchema},
+		2: {Schema: avroValueSchema},
+	}))
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	row := map[string]any{
+		"id":         1001,
+		"email":      "sally@example.com",
+		"balance":    big.NewRat(12345, 100),
+		"created_at": createdAt.UnixMicro(),
+		"updated_at": createdAt,
+		"birthday":   int32(19000),
+		"tags":       []any{"a", "b"},
+		"active":     true,
+	}
+
+	value := toWireFormat(2, marshalAvro(t, avroValueSchema, map[string]any{
+		"before": nil,
+		"after":  map[string]any{"dbserver1.inventory.customers.Value": row},
+		"source": map[string]any{"connector": "postgresql", "ts_ms": int64(1704164645000), "db": "postgres", "schema": "inventory", "table": "customers", "lsn": int64(123)},
+		"op":     "c",
+		"ts_ms":  int64(1704164645001),
+	}))
+
+	{
+		// Empty
+		_, err := format.GetEventFromBytes(t.Context(), nil)
+		assert.ErrorContains(t, err, "empty message")
+	}
+	{
+		// Not in the wire format
+		_, err := format.GetEventFromBytes(t.Context(), []byte(`{"payload": {}}`))
+		assert.ErrorContains(t, err, "unexpected magic byte")
+	}
+	{
+		// Key schema is not an envelope
+		_, err := format.GetEventFromBytes(t.Context(), toWireFormat(1, marshalAvro(t, avroKeySchema, map[string]any{"id": 1})))
+		assert.ErrorContains(t, err, `envelope schema "dbserver1.inventory.customers.Key" does not contain a before or after record`)
+	}
+	{
+		// Create
+		evt, err := format.GetEventFromBytes(t.Context(), value)
+		assert.NoError(t, err)
+
+		assert.Equal(t, constants.Create, evt.Operation())
+		assert.Equal(t, "customers", evt.GetTableName())
+		assert.Equal(t, "postgres.inventory.customers", evt.GetFullTableName())
+		assert.Equal(t, time.UnixMilli(1704164645000).UTC(), evt.GetExecutionTime())
+
+		data, err := evt.GetData(kafkalib.TopicConfig{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1001), data["id"])
+		assert.Equal(t, "sally@example.com", data["email"])
+		assert.Equal(t, "123.45", data["balance"].(*decimal.Decimal).String())
+		assert.Equal(t, createdAt, data["created_at"])
+		assert.Equal(t, createdAt, data["updated_at"])
+		assert.Equal(t, "2022-01-08", data["birthday"])
+		assert.Equal(t, []any{"a", "b"}, data["tags"])
+		assert.Equal(t, true, data["active"])
+		assert.Equal(t, false, data[constants.DeleteColumnMarker])
+
+		schema, err := evt.GetOptionalSchema(config.SharedDestinationSettings{})
+		assert.NoError(t, err)
+		assert.Equal(t, typing.Integer, schema["id"])
+		assert.Equal(t, typing.String, schema["email"])
+		assert.Equal(t, typing.NewDecimalDetailsFromTemplate(typing.EDecimal, decimal.NewDetails(10, 2)), schema["balance"])
+		assert.Equal(t, typing.TimestampNTZ, schema["created_at"])
+		assert.Equal(t, typing.TimestampNTZ, schema["updated_at"])
+		assert.Equal(t, typing.Date, schema["birthday"])
+		assert.Equal(t, typing.Array, schema["tags"])
+		assert.Equal(t, typing.Boolean, schema["active"])
+
+		cols, err := evt.GetColumns(nil)
+		assert.NoError(t, err)
+		assert.Len(t, cols, 8)
+		assert.Equal(t, true, cols[7].DefaultValue())
+	}
+	{
+		// Delete
+		evt, err := format.GetEventFromBytes(t.Context(), toWireFormat(2, marshalAvro(t, avroValueSchema, map[string]any{
+			"before": map[string]any{"dbserver1.inventory.customers.Value": row},
+			"after":  nil,
+			"source": map[string]any{"connector": "postgresql", "ts_ms": int64(1704164645000), "db": "postgres", "schema": "inventory", "table": "customers"},
+			"op":     "d",
+		})))
+		assert.NoError(t, err)
+		assert.True(t, evt.DeletePayload())
+
+		data, err := evt.GetData(kafkalib.TopicConfig{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1001), data["id"])
+		assert.Equal(t, true, data[constants.DeleteColumnMarker])
+	}
+}
@@ -0,0 +1,190 @@
+package confluent
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+)
+
+// Protobuf parses Debezium events that were serialized with `io.confluent.connect.protobuf.ProtobufConverter`.
+type Protobuf struct {
+	registry *schemaregistry.Client
+
+	mu    sync.RWMutex
+	files map[int]protoreflect.FileDescriptor
+}
+
+func NewProtobuf(registry *schemaregistry.Client) *Protobuf {
+	return &Protobuf{registry: registry, files: make(map[int]protoreflect.FileDescriptor)}
+}
+
+func (p *Protobuf) Labels() []string {
+	return []string{constants.DBZRelationalProtobufFormat}
+}
+
+func (p *Protobuf) GetPrimaryKey(ctx context.Context, key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error) {
+	if tc.CDCKeyFormat != kafkalib.ProtobufKeyFmt {
+		return debezium.ParsePartitionKey(key, tc.CDCKeyFormat, reservedColumns)
+	}
+
+	msg, err := p.decode(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key: %w", err)
+	}
+
+	fields, err := protobufMessageToFields(msg.Descriptor())
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := protobufMessageToValue(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert key: %w", err)
+	}
+
+	return debezium.ParsePrimaryKeyPayload(debezium.PrimaryKeyPayload{
+		Schema:  debezium.FieldsObject{FieldObjectType: string(debezium.Struct), Fields: fields},
+		Payload: payload,
+	}, reservedColumns)
+}
+
+func (p *Protobuf) GetEventFromBytes(ctx context.Context, bytes []byte) (cdc.Event, error) {
+	if len(bytes) == 0 {
+		return nil, fmt.Errorf("empty message")
+	}
+
+	msg, err := p.decode(ctx, bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	envelope, err := protobufMessageToValue(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert envelope: %w", err)
+	}
+
+	var rowDescriptor protoreflect.MessageDescriptor
+	for _, label := range []debezium.FieldLabelKind{debezium.After, debezium.Before} {
+		if fd := msg.Descriptor().Fields().ByName(protoreflect.Name(label)); fd != nil && fd.Message() != nil {
+			rowDescriptor = fd.Message()
+			break
+		}
+	}
+
+	if rowDescriptor == nil {
+		return nil, fmt.Errorf("envelope message %q does not contain a before or after message", msg.Descriptor().FullName())
+	}
+
+	fields, err := protobufMessageToFields(rowDescriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSchemaEventPayload(envelope, fields)
+}
+
+// decode strips the wire format header and message indexes, looks up the writer schema and decodes the payload.
+func (p *Protobuf) decode(ctx context.Context, data []byte) (*dynamicpb.Message, error) {
+	schemaID, payload, err := schemaregistry.ParseWireFormat(data)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, payload, err := schemaregistry.ParseMessageIndexes(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := p.getFile(ctx, schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor, err := findMessageDescriptor(file, indexes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find message in schema %d: %w", schemaID, err)
+	}
+
+	msg := dynamicpb.NewMessage(descriptor)
+	if err = proto.Unmarshal(payload, msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal protobuf payload with schema %d: %w", schemaID, err)
+	}
+
+	return msg, nil
+}
+
+func (p *Protobuf) getFile(ctx context.Context, schemaID int) (protoreflect.FileDescriptor, error) {
+	p.mu.RLock()
+	file, ok := p.files[schemaID]
+	p.mu.RUnlock()
+	if ok {
+		return file, nil
+	}
+
+	registrySchema, err := p.registry.GetSchemaByID(ctx, schemaID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", cdc.ErrSchemaRegistry, err)
+	}
+
+	if registrySchema.Type() != schemaregistry.Protobuf {
+		return nil, fmt.Errorf("schema %d is not a protobuf schema: %q", schemaID, registrySchema.Type())
+	}
+
+	references, err := p.registry.ResolveReferences(ctx, registrySchema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", cdc.ErrSchemaRegistry, err)
+	}
+
+	fileName := fmt.Sprintf("schema_%d.proto", schemaID)
+	sources := maps.Clone(wellKnownProtobufImports)
+	for name, reference := range references {
+		sources[name] = reference.Schema
+	}
+	sources[fileName] = registrySchema.Schema
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{Accessor: protocompile.SourceAccessorFromMap(sources)}),
+	}
+
+	files, err := compiler.Compile(ctx, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema %d: %w", schemaID, err)
+	}
+
+	file = files[0]
+	p.mu.Lock()
+	p.files[schemaID] = file
+	p.mu.Unlock()
+	return file, nil
+}
+
+func findMessageDescriptor(file protoreflect.FileDescriptor, indexes []int) (protoreflect.MessageDescriptor, error) {
+	messages := file.Messages()
+	var descriptor protoreflect.MessageDescriptor
+	for _, index := range indexes {
+		if index < 0 || index >= messages.Len() {
+			return nil, fmt.Errorf("message index %d is out of range", index)
+		}
+
+		descriptor = messages.Get(index)
+		messages = descriptor.Messages()
+	}
+
+	if descriptor == nil {
+		return nil, fmt.Errorf("no message indexes were provided")
+	}
+
+	return descriptor, nil
+}
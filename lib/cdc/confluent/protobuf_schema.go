@@ -0,0 +1,328 @@
+package confluent
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/artie-labs/transfer/lib/debezium"
+)
+
+const (
+	timestampMessage = "google.protobuf.Timestamp"
+	dateMessage      = "google.type.Date"
+	timeOfDayMessage = "google.type.TimeOfDay"
+	decimalMessage   = "confluent.type.Decimal"
+
+	// fieldMetaNumber is the extension number of `confluent.field_meta`, which is where Kafka Connect stores the original schema name and parameters.
+	fieldMetaNumber protowire.Number = 1088
+)
+
+var wrapperMessages = map[protoreflect.FullName]bool{
+	"google.protobuf.BoolValue":   true,
+	"google.protobuf.BytesValue":  true,
+	"google.protobuf.DoubleValue": true,
+	"google.protobuf.FloatValue":  true,
+	"google.protobuf.Int32Value":  true,
+	"google.protobuf.Int64Value":  true,
+	"google.protobuf.StringValue": true,
+	"google.protobuf.UInt32Value": true,
+	"google.protobuf.UInt64Value": true,
+}
+
+func protobufMessageToFields(descriptor protoreflect.MessageDescriptor) ([]debezium.Field, error) {
+	fields := make([]debezium.Field, 0, descriptor.Fields().Len())
+	for i := range descriptor.Fields().Len() {
+		fd := descriptor.Fields().Get(i)
+		field, err := protobufFieldToField(fd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %q: %w", fd.Name(), err)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// protobufFieldToField maps a Protobuf field onto the equivalent [debezium.Field] that the JSON converter would have emitted.
+func protobufFieldToField(fd protoreflect.FieldDescriptor) (debezium.Field, error) {
+	switch {
+	case fd.IsMap():
+		return debezium.Field{FieldName: string(fd.Name()), Type: debezium.Map, Optional: true}, nil
+	case fd.IsList():
+		items, err := protobufKindToField("", fd)
+		if err != nil {
+			return debezium.Field{}, fmt.Errorf("failed to convert list items: %w", err)
+		}
+
+		return debezium.Field{FieldName: string(fd.Name()), Type: debezium.Array, Optional: true, ItemsMetadata: &items}, nil
+	}
+
+	return protobufKindToField(string(fd.Name()), fd)
+}
+
+func protobufKindToField(name string, fd protoreflect.FieldDescriptor) (debezium.Field, error) {
+	field := debezium.Field{FieldName: name, Optional: fd.HasPresence()}
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		field.Type = debezium.Boolean
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		field.Type = debezium.Int32
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Int64Kind, protoreflect.Sint64Kind,
+		protoreflect.Sfixed64Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		field.Type = debezium.Int64
+	case protoreflect.FloatKind:
+		field.Type = debezium.Float
+	case protoreflect.DoubleKind:
+		field.Type = debezium.Double
+	case protoreflect.StringKind, protoreflect.EnumKind:
+		field.Type = debezium.String
+	case protoreflect.BytesKind:
+		field.Type = debezium.Bytes
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		switch messageName := fd.Message().FullName(); {
+		case messageName == timestampMessage:
+			field.Type = debezium.Int64
+			field.DebeziumType = debezium.MicroTimestamp
+			return field, nil
+		case messageName == dateMessage:
+			field.Type = debezium.Int32
+			field.DebeziumType = debezium.Date
+			return field, nil
+		case messageName == timeOfDayMessage:
+			field.Type = debezium.Int64
+			field.DebeziumType = debezium.MicroTime
+			return field, nil
+		case messageName == decimalMessage:
+			field.Type = debezium.Struct
+			field.DebeziumType = debezium.KafkaVariableNumericType
+			return field, nil
+		case wrapperMessages[messageName]:
+			wrapped, err := protobufKindToField(name, fd.Message().Fields().ByName("value"))
+			if err != nil {
+				return debezium.Field{}, err
+			}
+
+			wrapped.Optional = true
+			return wrapped, nil
+		default:
+			field.Type = debezium.Struct
+		}
+	default:
+		return debezium.Field{}, fmt.Errorf("unsupported protobuf kind %q", fd.Kind())
+	}
+
+	// Kafka Connect preserves the original schema name and parameters as field options.
+	for key, value := range protobufFieldParams(fd) {
+		if key == connectNameProp {
+			field.DebeziumType = debezium.SupportedDebeziumType(value)
+			continue
+		}
+
+		if field.Parameters == nil {
+			field.Parameters = make(map[string]any)
+		}
+		field.Parameters[key] = value
+	}
+
+	return field, nil
+}
+
+// protobufFieldParams returns the params from the `confluent.field_meta` option, if set.
+func protobufFieldParams(fd protoreflect.FieldDescriptor) map[string]string {
+	options := fd.Options()
+	if options == nil {
+		return nil
+	}
+
+	optionBytes, err := proto.Marshal(options)
+	if err != nil {
+		return nil
+	}
+
+	var params map[string]string
+	for len(optionBytes) > 0 {
+		number, wireType, n := protowire.ConsumeTag(optionBytes)
+		if n < 0 {
+			return params
+		}
+		optionBytes = optionBytes[n:]
+
+		if number == fieldMetaNumber && wireType == protowire.BytesType {
+			meta, n := protowire.ConsumeBytes(optionBytes)
+			if n < 0 {
+				return params
+			}
+
+			params = parseMetaParams(meta)
+			optionBytes = optionBytes[n:]
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(number, wireType, optionBytes)
+		if n < 0 {
+			return params
+		}
+		optionBytes = optionBytes[n:]
+	}
+
+	return params
+}
+
+// parseMetaParams reads field 2 (map<string, string> params) out of an encoded `confluent.Meta` message.
+func parseMetaParams(data []byte) map[string]string {
+	params := make(map[string]string)
+	for _, entry := range consumeBytesFields(data, 2) {
+		keys := consumeBytesFields(entry, 1)
+		values := consumeBytesFields(entry, 2)
+		if len(keys) == 1 && len(values) == 1 {
+			params[string(keys[0])] = string(values[0])
+		}
+	}
+
+	return params
+}
+
+func consumeBytesFields(data []byte, fieldNumber protowire.Number) [][]byte {
+	var out [][]byte
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return out
+		}
+		data = data[n:]
+
+		if number == fieldNumber && wireType == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return out
+			}
+			out = append(out, value)
+			data = data[n:]
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(number, wireType, data)
+		if n < 0 {
+			return out
+		}
+		data = data[n:]
+	}
+
+	return out
+}
+
+func protobufMessageToValue(msg protoreflect.Message) (map[string]any, error) {
+	out := make(map[string]any)
+	fields := msg.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if fd.HasPresence() && !msg.Has(fd) {
+			out[string(fd.Name())] = nil
+			continue
+		}
+
+		value, err := protobufFieldToValue(fd, msg.Get(fd))
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %q: %w", fd.Name(), err)
+		}
+
+		out[string(fd.Name())] = value
+	}
+
+	return out, nil
+}
+
+// protobufFieldToValue converts a Protobuf value into what the JSON converter would have emitted, so that it can be parsed by [debezium.Field.ParseValue].
+func protobufFieldToValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) (any, error) {
+	switch {
+	case fd.IsMap():
+		out := make(map[string]any)
+		var rangeErr error
+		value.Map().Range(func(key protoreflect.MapKey, mapValue protoreflect.Value) bool {
+			convertedValue, err := protobufSingularToValue(fd.MapValue(), mapValue)
+			if err != nil {
+				rangeErr = fmt.Errorf("failed to convert map value %q: %w", key.String(), err)
+				return false
+			}
+
+			out[key.String()] = convertedValue
+			return true
+		})
+		return out, rangeErr
+	case fd.IsList():
+		list := value.List()
+		out := make([]any, list.Len())
+		for i := range list.Len() {
+			item, err := protobufSingularToValue(fd, list.Get(i))
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert list item %d: %w", i, err)
+			}
+			out[i] = item
+		}
+		return out, nil
+	}
+
+	return protobufSingularToValue(fd, value)
+}
+
+func protobufSingularToValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) (any, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return value.Bool(), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return value.Int(), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64(value.Uint()), nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return value.Float(), nil
+	case protoreflect.StringKind:
+		return value.String(), nil
+	case protoreflect.BytesKind:
+		return value.Bytes(), nil
+	case protoreflect.EnumKind:
+		if enumValue := fd.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name()), nil
+		}
+		return strconv.Itoa(int(value.Enum())), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return protobufWellKnownToValue(value.Message())
+	}
+
+	return nil, fmt.Errorf("unsupported protobuf kind %q", fd.Kind())
+}
+
+func protobufWellKnownToValue(msg protoreflect.Message) (any, error) {
+	fields := msg.Descriptor().Fields()
+	getInt := func(name protoreflect.Name) int64 {
+		return msg.Get(fields.ByName(name)).Int()
+	}
+
+	switch messageName := msg.Descriptor().FullName(); {
+	case messageName == timestampMessage:
+		return time.Unix(getInt("seconds"), getInt("nanos")).UnixMicro(), nil
+	case messageName == dateMessage:
+		date := time.Date(int(getInt("year")), time.Month(getInt("month")), int(getInt("day")), 0, 0, 0, 0, time.UTC)
+		return date.Unix() / int64((24 * time.Hour).Seconds()), nil
+	case messageName == timeOfDayMessage:
+		duration := time.Duration(getInt("hours"))*time.Hour + time.Duration(getInt("minutes"))*time.Minute +
+			time.Duration(getInt("seconds"))*time.Second + time.Duration(getInt("nanos"))
+		return duration.Microseconds(), nil
+	case messageName == decimalMessage:
+		return map[string]any{
+			"scale": getInt("scale"),
+			"value": msg.Get(fields.ByName("value")).Bytes(),
+		}, nil
+	case wrapperMessages[messageName]:
+		fd := fields.ByName("value")
+		return protobufSingularToValue(fd, msg.Get(fd))
+	}
+
+	return protobufMessageToValue(msg)
+}
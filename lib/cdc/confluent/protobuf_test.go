@@ -0,0 +1,191 @@
+package confluent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+const (
+	protobufKeySchema = `syntax = "proto3";
+package dbserver1.inventory.customers;
+
+message Key {
+  int32 id = 1;
+}
+`
+	protobufValueSchema = `syntax = "proto3";
+package dbserver1.inventory.customers;
+
+import "confluent/meta.proto";
+import "confluent/type/decimal.proto";
+import "google/protobuf/timestamp.proto";
+import "google/protobuf/wrappers.proto";
+import "google/type/date.proto";
+
+message Envelope {
+  Value before = 1;
+  Value after = 2;
+  Source source = 3;
+  string op = 4;
+  google.protobuf.Int64Value ts_ms = 5;
+
+  message Value {
+    int32 id = 1;
+    google.protobuf.StringValue email = 2;
+    confluent.type.Decimal balance = 3;
+    int64 created_at = 4 [(confluent.field_meta) = { params: [{ key: "connect.name", value: "io.debezium.time.MicroTimestamp" }] }];
+    google.protobuf.Timestamp updated_at = 5;
+    google.type.Date birthday = 6;
+    repeated string tags = 7;
+    bool active = 8;
+  }
+
+  message Source {
+    string connector = 1;
+    int64 ts_ms = 2;
+    string db = 3;
+    string schema = 4;
+    string table = 5;
+  }
+}
+`
+)
+
+func setField(msg *dynamicpb.Message, name string, value any) {
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	msg.Set(fd, protoreflect.ValueOf(value))
+}
+
+func newMessage(msg *dynamicpb.Message, name string) *dynamicpb.Message {
+	return dynamicpb.NewMessage(msg.Descriptor().Fields().ByName(protoreflect.Name(name)).Message())
+}
+
+func marshalProtobuf(t *testing.T, schemaID int, msg proto.Message) []byte {
+	out, err := proto.Marshal(msg)
+	assert.NoError(t, err)
+	// Message indexes are [0], which is encoded as a single 0 byte.
+	return toWireFormat(schemaID, append([]byte{0}, out...))
+}
+
+func TestProtobufFieldToField(t *testing.T) {
+	format := NewProtobuf(newTestRegistry(t, map[int]schemaregistry.Schema{2: {Schema: protobufValueSchema, SchemaType: schemaregistry.Protobuf}}))
+	file, err := format.getFile(t.Context(), 2)
+	assert.NoError(t, err)
+
+	fields, err := protobufMessageToFields(file.Messages().Get(0).Messages().ByName("Value"))
+	assert.NoError(t, err)
+	assert.Equal(t, []debezium.Field{
+		{FieldName: "id", Type: debezium.Int32},
+		{FieldName: "email", Type: debezium.String, Optional: true},
+		{FieldName: "balance", Type: debezium.Struct, Optional: true, DebeziumType: debezium.KafkaVariableNumericType},
+		{FieldName: "created_at", Type: debezium.Int64, DebeziumType: debezium.MicroTimestamp},
+		{FieldName: "updated_at", Type: debezium.Int64, Optional: true, DebeziumType: debezium.MicroTimestamp},
+		{FieldName: "birthday", Type: debezium.Int32, Optional: true, DebeziumType: debezium.Date},
+		{FieldName: "tags", Type: debezium.Array, Optional: true, ItemsMetadata: &debezium.Field{Type: debezium.String}},
+		{FieldName: "active", Type: debezium.Boolean},
+	}, fields)
+}
+
+func TestProtobuf_GetPrimaryKey(t *testing.T) {
+	format := NewProtobuf(newTestRegistry(t, map[int]schemaregistry.Schema{1: {Schema: protobufKeySchema, SchemaType: schemaregistry.Protobuf}}))
+	file, err := format.getFile(t.Context(), 1)
+	assert.NoError(t, err)
+
+	key := dynamicpb.NewMessage(file.Messages().Get(0))
+	setField(key, "id", int32(1001))
+
+	pk, err := format.GetPrimaryKey(t.Context(), marshalProtobuf(t, 1, key), kafkalib.TopicConfig{CDCKeyFormat: kafkalib.ProtobufKeyFmt}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": int64(1001)}, pk)
+}
+
+func TestProtobuf_GetEventFromBytes(t *testing.T) {
+	format := NewProtobuf(newTestRegistry(t, map[int]schemaregistry.Schema{
+		1: {Schema: avroKeySchema},
+		2: {Schema: protobufValueSchema, SchemaType: schemaregistry.Protobuf},
+	}))
+	{
+		// Not a protobuf schema
+		_, err := format.GetEventFromBytes(t.Context(), toWireFormat(1, []byte{0}))
+		assert.ErrorContains(t, err, `schema 1 is not a protobuf schema: "AVRO"`)
+	}
+
+	file, err := format.getFile(t.Context(), 2)
+	assert.NoError(t, err)
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	envelope := dynamicpb.NewMessage(file.Messages().Get(0))
+
+	after := newMessage(envelope, "after")
+	setField(after, "id", int32(1001))
+	email := newMessage(after, "email")
+	setField(email, "value", "sally@example.com")
+	setField(after, "email", email)
+	balance := newMessage(after, "balance")
+	setField(balance, "value", []byte{0x30, 0x39})
+	setField(balance, "scale", int32(2))
+	setField(after, "balance", balance)
+	setField(after, "created_at", createdAt.UnixMicro())
+	updatedAt := newMessage(after, "updated_at")
+	setField(updatedAt, "seconds", createdAt.Unix())
+	setField(updatedAt, "nanos", int32(createdAt.Nanosecond()))
+	setField(after, "updated_at", updatedAt)
+	birthday := newMessage(after, "birthday")
+	setField(birthday, "year", int32(2022))
+	setField(birthday, "month", int32(1))
+	setField(birthday, "day", int32(8))
+	setField(after, "birthday", birthday)
+	tags := after.NewField(after.Descriptor().Fields().ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("a"))
+	tags.Append(protoreflect.ValueOfString("b"))
+	after.Set(after.Descriptor().Fields().ByName("tags"), protoreflect.ValueOfList(tags))
+	setField(after, "active", true)
+	setField(envelope, "after", after)
+
+	source := newMessage(envelope, "source")
+	setField(source, "connector", "postgresql")
+	setField(source, "ts_ms", int64(1704164645000))
+	setField(source, "db", "postgres")
+	setField(source, "schema", "inventory")
+	setField(source, "table", "customers")
+	setField(envelope, "source", source)
+	setField(envelope, "op", "c")
+
+	evt, err := format.GetEventFromBytes(t.Context(), marshalProtobuf(t, 2, envelope))
+	assert.NoError(t, err)
+	assert.Equal(t, constants.Create, evt.Operation())
+	assert.Equal(t, "postgres.inventory.customers", evt.GetFullTableName())
+	assert.Equal(t, time.UnixMilli(1704164645000).UTC(), evt.GetExecutionTime())
+
+	data, err := evt.GetData(kafkalib.TopicConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1001), data["id"])
+	assert.Equal(t, "sally@example.com", data["email"])
+	assert.Equal(t, "123.45", data["balance"].(*decimal.Decimal).String())
+	assert.Equal(t, createdAt, data["created_at"])
+	assert.Equal(t, createdAt, data["updated_at"])
+	assert.Equal(t, "2022-01-08", data["birthday"])
+	assert.Equal(t, []any{"a", "b"}, data["tags"])
+	assert.Equal(t, true, data["active"])
+
+	schema, err := evt.GetOptionalSchema(config.SharedDestinationSettings{})
+	assert.NoError(t, err)
+	assert.Equal(t, typing.Integer, schema["id"])
+	assert.Equal(t, typing.String, schema["email"])
+	assert.Equal(t, typing.EDecimal.Kind, schema["balance"].Kind)
+	assert.Equal(t, typing.TimestampNTZ, schema["created_at"])
+	assert.Equal(t, typing.Date, schema["birthday"])
+}
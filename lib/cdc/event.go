@@ -1,6 +1,8 @@
 package cdc
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,10 +13,20 @@ import (
 	"github.com/artie-labs/transfer/lib/typing/columns"
 )
 
+// ErrSchemaRegistry is returned by a [Format] when the schema for a message could not be fetched from the schema registry.
+// The message itself may be fine, so it should be retried instead of being treated as unparseable.
+var ErrSchemaRegistry = errors.New("failed to fetch schema from the schema registry")
+
 type Format interface {
 	Labels() []string // Labels() to return a list of strings to maintain backward compatibility.
-	GetPrimaryKey(key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error)
-	GetEventFromBytes(bytes []byte) (Event, error)
+	GetPrimaryKey(ctx context.Context, key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error)
+	GetEventFromBytes(ctx context.Context, bytes []byte) (Event, error)
+}
+
+// ExecutionTimeFallback is implemented by events that did not have a source timestamp (e.g. a flattened row without the field).
+// The consumer sets the message's publish time as the execution time before the event is used.
+type ExecutionTimeFallback interface {
+	SetExecutionTimeFallback(ts time.Time)
 }
 
 type Event interface {
@@ -1,6 +1,7 @@
 package eventtracking
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/artie-labs/transfer/lib/cdc"
@@ -12,7 +13,7 @@ import (
 
 type Format struct{}
 
-func (Format) GetEventFromBytes(bytes []byte) (cdc.Event, error) {
+func (Format) GetEventFromBytes(_ context.Context, bytes []byte) (cdc.Event, error) {
 	if len(bytes) == 0 {
 		return nil, fmt.Errorf("empty message")
 	}
@@ -43,7 +44,7 @@ func (Format) Labels() []string {
 	return []string{constants.EventTrackingFormat}
 }
 
-func (Format) GetPrimaryKey(key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error) {
+func (Format) GetPrimaryKey(_ context.Context, key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error) {
 	return map[string]any{
 		columns.EscapeName("id", reservedColumns): string(key),
 	}, nil
@@ -0,0 +1,193 @@
+package flattened
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/cdc/util"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/jsonutil"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+// Format parses Debezium events that have been unwrapped by the ExtractNewRecordState SMT.
+// Instead of a before / after envelope, the row is emitted as-is with the metadata (operation, delete flag, source timestamp) added as extra fields.
+type Format struct {
+	settings kafkalib.FlattenedSettings
+	// warnMissingTsMs is used to only log once when rows do not have a source timestamp.
+	warnMissingTsMs *sync.Once
+}
+
+func NewFormat(settings kafkalib.FlattenedSettings) Format {
+	return Format{settings: settings, warnMissingTsMs: &sync.Once{}}
+}
+
+// eventWithoutTsMs is returned for rows that do not have a source timestamp, so that the message's timestamp can be used instead of the Unix epoch.
+type eventWithoutTsMs struct {
+	*util.SchemaEventPayload
+}
+
+func (e eventWithoutTsMs) SetExecutionTimeFallback(ts time.Time) {
+	e.Payload.Source.TsMs = ts.UnixMilli()
+}
+
+func (Format) Labels() []string {
+	return []string{constants.DBZRelationalFlattenedFormat}
+}
+
+func (Format) GetPrimaryKey(_ context.Context, key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error) {
+	return debezium.ParsePartitionKey(key, tc.CDCKeyFormat, reservedColumns)
+}
+
+// connectSchema is the struct schema that the JSON converter emits when `schemas.enable` is true.
+type connectSchema struct {
+	Type   string           `json:"type"`
+	Fields []debezium.Field `json:"fields"`
+}
+
+// splitSchemaAndPayload returns the schema fields and the row if [bytes] is a JSON converter envelope, else the row is [bytes].
+func splitSchemaAndPayload(bytes []byte) ([]debezium.Field, []byte) {
+	var envelope map[string]json.RawMessage
+	if err := jsonutil.Unmarshal(bytes, &envelope); err != nil || len(envelope) != 2 {
+		return nil, bytes
+	}
+
+	schemaBytes, hasSchema := envelope["schema"]
+	payloadBytes, hasPayload := envelope["payload"]
+	if !hasSchema || !hasPayload {
+		return nil, bytes
+	}
+
+	var schema connectSchema
+	if err := jsonutil.Unmarshal(schemaBytes, &schema); err != nil || schema.Type != string(debezium.Struct) {
+		return nil, bytes
+	}
+
+	return schema.Fields, payloadBytes
+}
+
+func (f Format) GetEventFromBytes(_ context.Context, bytes []byte) (cdc.Event, error) {
+	if len(bytes) == 0 {
+		return nil, fmt.Errorf("empty message")
+	}
+
+	fields, rowBytes := splitSchemaAndPayload(bytes)
+	var row map[string]any
+	if err := jsonutil.Unmarshal(rowBytes, &row); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal row: %w", err)
+	}
+
+	if row == nil {
+		return nil, fmt.Errorf("row is null")
+	}
+
+	operation := constants.Create
+	if value, ok := row[f.settings.GetOperationField()]; ok && value != nil {
+		op, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected %q to be a string, got: %T", f.settings.GetOperationField(), value)
+		}
+		operation = constants.Operation(op)
+	}
+
+	deleted, err := parseDeleted(row[f.settings.GetDeletedField()])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", f.settings.GetDeletedField(), err)
+	}
+
+	if deleted {
+		operation = constants.Delete
+	}
+
+	missingTsMs := row[f.settings.GetSourceTsMsField()] == nil
+	tsMs, err := parseTsMs(row[f.settings.GetSourceTsMsField()])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", f.settings.GetSourceTsMsField(), err)
+	}
+
+	source := util.Source{
+		TsMs:     tsMs,
+		Database: stringValue(row[f.settings.GetDatabaseField()]),
+		Schema:   stringValue(row[f.settings.GetSchemaField()]),
+		Table:    stringValue(row[f.settings.GetTableField()]),
+	}
+
+	metadataFields := f.settings.MetadataFields()
+	for _, field := range metadataFields {
+		delete(row, field)
+	}
+
+	fields = slices.DeleteFunc(slices.Clone(fields), func(field debezium.Field) bool {
+		return slices.Contains(metadataFields, field.FieldName)
+	})
+
+	event := &util.SchemaEventPayload{Payload: util.Payload{Source: source, Operation: operation}}
+	if len(fields) > 0 {
+		event.Schema = debezium.Schema{
+			SchemaType: string(debezium.Struct),
+			FieldsObject: []debezium.FieldsObject{
+				{FieldObjectType: string(debezium.Struct), Fields: fields, Optional: true, FieldLabel: debezium.Before},
+				{FieldObjectType: string(debezium.Struct), Fields: fields, Optional: true, FieldLabel: debezium.After},
+			},
+		}
+	}
+
+	// With `delete.handling.mode=rewrite`, the row for a delete has the values from before the delete.
+	if operation == constants.Delete {
+		event.Payload.Before = row
+	} else {
+		event.Payload.After = row
+	}
+
+	if missingTsMs {
+		f.warnMissingTsMs.Do(func() {
+			slog.Warn("Row does not have a source timestamp, the message's timestamp will be used as the execution time instead", slog.String("field", f.settings.GetSourceTsMsField()))
+		})
+		return eventWithoutTsMs{event}, nil
+	}
+
+	return event, nil
+}
+
+func parseDeleted(value any) (bool, error) {
+	switch castedValue := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return castedValue, nil
+	case string:
+		// The SMT writes this out as a string.
+		return strconv.ParseBool(castedValue)
+	default:
+		return false, fmt.Errorf("unexpected type %T", value)
+	}
+}
+
+func parseTsMs(value any) (int64, error) {
+	switch castedValue := value.(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		return castedValue.Int64()
+	case string:
+		return strconv.ParseInt(castedValue, 10, 64)
+	default:
+		return 0, fmt.Errorf("unexpected type %T", value)
+	}
+}
+
+func stringValue(value any) string {
+	if castedValue, ok := value.(string); ok {
+		return castedValue
+	}
+
+	return ""
+}
@@ -0,0 +1,153 @@
+package flattened
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/typing"
+)
+
+func TestFormat_GetEventFromBytes(t *testing.T) {
+	format := NewFormat(kafkalib.FlattenedSettings{})
+	{
+		// Empty message
+		_, err := format.GetEventFromBytes(t.Context(), nil)
+		assert.ErrorContains(t, err, "empty message")
+	}
+	{
+		// Null row
+		_, err := format.GetEventFromBytes(t.Context(), []byte("null"))
+		assert.ErrorContains(t, err, "row is null")
+	}
+	{
+		// Invalid delete flag
+		_, err := format.GetEventFromBytes(t.Context(), []byte(`{"id": 1, "__deleted": "maybe"}`))
+		assert.ErrorContains(t, err, `failed to parse "__deleted"`)
+	}
+	{
+		// Without a schema
+		evt, err := format.GetEventFromBytes(t.Context(), []byte(`{"id": 1, "name": "dusty", "__op": "u", "__deleted": "false", "__source_ts_ms": 1704164645000, "__db": "postgres", "__schema": "public", "__table": "customers"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, constants.Update, evt.Operation())
+		assert.False(t, evt.DeletePayload())
+		assert.Equal(t, time.UnixMilli(1704164645000).UTC(), evt.GetExecutionTime())
+		assert.Equal(t, "customers", evt.GetTableName())
+		assert.Equal(t, "postgres.public.customers", evt.GetFullTableName())
+
+		data, err := evt.GetData(kafkalib.TopicConfig{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"id":                                json.Number("1"),
+			"name":                              "dusty",
+			constants.DeleteColumnMarker:        false,
+			constants.OnlySetDeleteColumnMarker: false,
+		}, data)
+
+		schema, err := evt.GetOptionalSchema(config.SharedDestinationSettings{})
+		assert.NoError(t, err)
+		assert.Nil(t, schema)
+	}
+	{
+		// Rewritten delete
+		evt, err := format.GetEventFromBytes(t.Context(), []byte(`{"id": 1, "name": "dusty", "__deleted": "true", "__source_ts_ms": 1704164645000}`))
+		assert.NoError(t, err)
+		assert.Equal(t, constants.Delete, evt.Operation())
+		assert.True(t, evt.DeletePayload())
+
+		data, err := evt.GetData(kafkalib.TopicConfig{})
+		assert.NoError(t, err)
+		assert.Equal(t, "dusty", data["name"])
+		assert.Equal(t, true, data[constants.DeleteColumnMarker])
+	}
+	{
+		// No metadata, defaults to a create
+		evt, err := format.GetEventFromBytes(t.Context(), []byte(`{"id": 1}`))
+		assert.NoError(t, err)
+		assert.Equal(t, constants.Create, evt.Operation())
+		assert.Equal(t, time.UnixMilli(0).UTC(), evt.GetExecutionTime())
+	}
+}
+
+func TestFormat_GetEventFromBytes_Schema(t *testing.T) {
+	payload := []byte(`{
+	"schema": {
+		"type": "struct",
+		"name": "postgres.public.customers.Value",
+		"fields": [
+			{"type": "int32", "optional": false, "field": "id"},
+			{"type": "int64", "optional": true, "name": "io.debezium.time.MicroTimestamp", "field": "created_at"},
+			{"type": "int32", "optional": true, "name": "io.debezium.time.Date", "field": "birthday"},
+			{"type": "string", "optional": true, "field": "__op"},
+			{"type": "string", "optional": true, "field": "__deleted"},
+			{"type": "int64", "optional": true, "field": "__source_ts_ms"}
+		]
+	},
+	"payload": {"id": 1001, "created_at": 1704164645123456, "birthday": 19000, "__op": "c", "__deleted": "false", "__source_ts_ms": 1704164645000}
+}`)
+
+	evt, err := NewFormat(kafkalib.FlattenedSettings{}).GetEventFromBytes(t.Context(), payload)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.Create, evt.Operation())
+
+	data, err := evt.GetData(kafkalib.TopicConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1001), data["id"])
+	assert.Equal(t, time.UnixMicro(1704164645123456).UTC(), data["created_at"])
+	assert.Equal(t, "2022-01-08", data["birthday"])
+	assert.NotContains(t, data, "__op")
+	assert.NotContains(t, data, "__source_ts_ms")
+
+	schema, err := evt.GetOptionalSchema(config.SharedDestinationSettings{})
+	assert.NoError(t, err)
+	assert.Len(t, schema, 3)
+	assert.Equal(t, typing.Integer, schema["id"])
+	assert.Equal(t, typing.TimestampNTZ, schema["created_at"])
+	assert.Equal(t, typing.Date, schema["birthday"])
+
+	cols, err := evt.GetColumns(nil)
+	assert.NoError(t, err)
+	assert.Len(t, cols, 3)
+}
+
+func TestFormat_CustomFields(t *testing.T) {
+	format := NewFormat(kafkalib.FlattenedSettings{OperationField: "_op", DeletedField: "_is_deleted", SourceTsMsField: "_ts", TableField: "_tbl"})
+	evt, err := format.GetEventFromBytes(t.Context(), []byte(`{"id": 1, "_op": "d", "_is_deleted": true, "_ts": "1704164645000", "_tbl": "orders", "__op": "c"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, constants.Delete, evt.Operation())
+	assert.Equal(t, time.UnixMilli(1704164645000).UTC(), evt.GetExecutionTime())
+	assert.Equal(t, "orders", evt.GetTableName())
+
+	data, err := evt.GetData(kafkalib.TopicConfig{})
+	assert.NoError(t, err)
+	assert.NotContains(t, data, "_op")
+	assert.NotContains(t, data, "_tbl")
+	// This isn't one of the configured metadata fields, so it's kept.
+	assert.Equal(t, "c", data["__op"])
+}
+
+func TestFormat_MissingSourceTsMs(t *testing.T) {
+	format := NewFormat(kafkalib.FlattenedSettings{})
+	{
+		// The message's timestamp is used as the execution time
+		evt, err := format.GetEventFromBytes(t.Context(), []byte(`{"id": 1}`))
+		assert.NoError(t, err)
+
+		fallback, ok := evt.(cdc.ExecutionTimeFallback)
+		assert.True(t, ok)
+		fallback.SetExecutionTimeFallback(time.UnixMilli(1704164645000))
+		assert.Equal(t, time.UnixMilli(1704164645000).UTC(), evt.GetExecutionTime())
+	}
+	{
+		// Rows with a source timestamp keep it
+		evt, err := format.GetEventFromBytes(t.Context(), []byte(`{"id": 1, "__source_ts_ms": 1704164645000}`))
+		assert.NoError(t, err)
+		assert.NotImplements(t, (*cdc.ExecutionTimeFallback)(nil), evt)
+	}
+}
@@ -4,19 +4,30 @@ import (
 	"log/slog"
 
 	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/cdc/confluent"
 	"github.com/artie-labs/transfer/lib/cdc/eventtracking"
+	"github.com/artie-labs/transfer/lib/cdc/flattened"
 	"github.com/artie-labs/transfer/lib/cdc/mongo"
 	"github.com/artie-labs/transfer/lib/cdc/relational"
+	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/logger"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
 )
 
-func GetFormatParser(label, topic string) cdc.Format {
-	for _, validFormat := range []cdc.Format{relational.Debezium{}, mongo.Debezium{}, eventtracking.Format{}} {
+// GetFormatParser returns the parser for [tc]'s CDC format, [registry] is only required for the Avro and Protobuf formats.
+func GetFormatParser(tc kafkalib.TopicConfig, registry *schemaregistry.Client) cdc.Format {
+	label := tc.CDCFormat
+	validFormats := []cdc.Format{relational.Debezium{}, mongo.Debezium{}, eventtracking.Format{}, flattened.NewFormat(tc.GetFlattenedSettings())}
+	if registry != nil {
+		validFormats = append(validFormats, confluent.NewAvro(registry), confluent.NewProtobuf(registry))
+	}
+
+	for _, validFormat := range validFormats {
 		for _, fmtLabel := range validFormat.Labels() {
 			if fmtLabel == label {
 				slog.Info("Loaded CDC Format parser...",
 					slog.String("label", label),
-					slog.String("topic", topic),
+					slog.String("topic", tc.Topic),
 				)
 				return validFormat
 			}
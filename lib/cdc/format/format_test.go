@@ -7,9 +7,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/artie-labs/transfer/lib/cdc/confluent"
+	"github.com/artie-labs/transfer/lib/cdc/flattened"
 	"github.com/artie-labs/transfer/lib/cdc/mongo"
 	"github.com/artie-labs/transfer/lib/cdc/relational"
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
 	"github.com/artie-labs/transfer/lib/typing"
 )
 
@@ -17,7 +21,7 @@ func TestGetFormatParser(t *testing.T) {
 	{
 		// Relational
 		for _, format := range []string{constants.DBZPostgresAltFormat, constants.DBZPostgresFormat} {
-			formatParser := GetFormatParser(format, "topicA")
+			formatParser := GetFormatParser(kafkalib.TopicConfig{CDCFormat: format, Topic: "topicA"}, nil)
 			assert.NotNil(t, formatParser)
 
 			_, err := typing.AssertType[relational.Debezium](formatParser)
@@ -26,12 +30,27 @@ func TestGetFormatParser(t *testing.T) {
 	}
 	{
 		// Mongo
-		formatParser := GetFormatParser(constants.DBZMongoFormat, "topicA")
+		formatParser := GetFormatParser(kafkalib.TopicConfig{CDCFormat: constants.DBZMongoFormat, Topic: "topicA"}, nil)
 		assert.NotNil(t, formatParser)
 
 		_, err := typing.AssertType[mongo.Debezium](formatParser)
 		assert.NoError(t, err)
 	}
+	{
+		// Flattened
+		formatParser := GetFormatParser(kafkalib.TopicConfig{CDCFormat: constants.DBZRelationalFlattenedFormat, Topic: "topicA"}, nil)
+		_, err := typing.AssertType[flattened.Format](formatParser)
+		assert.NoError(t, err)
+	}
+	{
+		// Schema Registry
+		registry := schemaregistry.NewClient("http://localhost:8081", "", "")
+		_, err := typing.AssertType[*confluent.Avro](GetFormatParser(kafkalib.TopicConfig{CDCFormat: constants.DBZRelationalAvroFormat, Topic: "topicA"}, registry))
+		assert.NoError(t, err)
+
+		_, err = typing.AssertType[*confluent.Protobuf](GetFormatParser(kafkalib.TopicConfig{CDCFormat: constants.DBZRelationalProtobufFormat, Topic: "topicA"}, registry))
+		assert.NoError(t, err)
+	}
 }
 
 func testOsExit(t *testing.T, testFunc func(*testing.T)) {
@@ -53,6 +72,6 @@ func testOsExit(t *testing.T, testFunc func(*testing.T)) {
 func TestGetFormatParserFatal(t *testing.T) {
 	// This test cannot be iterated because it forks a separate process to do `go test -test.run=...`
 	testOsExit(t, func(t *testing.T) {
-		GetFormatParser("foo", "topicB")
+		GetFormatParser(kafkalib.TopicConfig{CDCFormat: "foo", Topic: "topicB"}, nil)
 	})
 }
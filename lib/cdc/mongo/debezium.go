@@ -1,6 +1,7 @@
 package mongo
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -21,7 +22,7 @@ var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
 type Debezium struct{}
 
-func (Debezium) GetEventFromBytes(bytes []byte) (cdc.Event, error) {
+func (Debezium) GetEventFromBytes(_ context.Context, bytes []byte) (cdc.Event, error) {
 	if len(bytes) == 0 {
 		return nil, fmt.Errorf("empty message")
 	}
@@ -57,7 +58,7 @@ func (Debezium) Labels() []string {
 	return []string{constants.DBZMongoFormat}
 }
 
-func (Debezium) GetPrimaryKey(key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error) {
+func (Debezium) GetPrimaryKey(_ context.Context, key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error) {
 	kvMap, err := debezium.ParsePartitionKey(key, tc.CDCKeyFormat, reservedColumns)
 	if err != nil {
 		return nil, err
@@ -15,7 +15,7 @@ import (
 func TestGetPrimaryKey(t *testing.T) {
 	{
 		// Test JSON key format with numeric ID
-		pkMap, err := Debezium{}.GetPrimaryKey([]byte(`{"id": 1001}`), kafkalib.TopicConfig{CDCKeyFormat: kafkalib.JSONKeyFmt}, nil)
+		pkMap, err := Debezium{}.GetPrimaryKey(t.Context(), []byte(`{"id": 1001}`), kafkalib.TopicConfig{CDCKeyFormat: kafkalib.JSONKeyFmt}, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, int32(1001), pkMap["_id"])
 
@@ -25,7 +25,7 @@ func TestGetPrimaryKey(t *testing.T) {
 	}
 	{
 		// Test string key format with numeric ID
-		pkMap, err := Debezium{}.GetPrimaryKey([]byte(`Struct{id=1001}`), kafkalib.TopicConfig{CDCKeyFormat: kafkalib.StringKeyFmt}, nil)
+		pkMap, err := Debezium{}.GetPrimaryKey(t.Context(), []byte(`Struct{id=1001}`), kafkalib.TopicConfig{CDCKeyFormat: kafkalib.StringKeyFmt}, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, "1001", pkMap["_id"])
 
@@ -35,7 +35,7 @@ func TestGetPrimaryKey(t *testing.T) {
 	}
 	{
 		// Test JSON key format with ObjectId
-		pkMap, err := Debezium{}.GetPrimaryKey([]byte(`{"schema":{"type":"struct","fields":[{"type":"string","optional":false,"field":"id"}],"optional":false,"name":"1a75f632-29d2-419b-9ffe-d18fa12d74d5.38d5d2db-870a-4a38-a76c-9891b0e5122d.myFirstDatabase.stock.Key"},"payload":{"id":"{\"$oid\": \"63e3a3bf314a4076d249e203\"}"}}`), kafkalib.TopicConfig{
+		pkMap, err := Debezium{}.GetPrimaryKey(t.Context(), []byte(`{"schema":{"type":"struct","fields":[{"type":"string","optional":false,"field":"id"}],"optional":false,"name":"1a75f632-29d2-419b-9ffe-d18fa12d74d5.38d5d2db-870a-4a38-a76c-9891b0e5122d.myFirstDatabase.stock.Key"},"payload":{"id":"{\"$oid\": \"63e3a3bf314a4076d249e203\"}"}}`), kafkalib.TopicConfig{
 			CDCKeyFormat: kafkalib.JSONKeyFmt,
 		}, nil)
 		assert.NoError(t, err)
@@ -47,7 +47,7 @@ func TestGetPrimaryKey(t *testing.T) {
 	}
 	{
 		// Test string key format with ObjectId
-		pkMap, err := Debezium{}.GetPrimaryKey([]byte(`Struct{id={"$oid": "65566afbfefeb3c639deaf5d"}}`), kafkalib.TopicConfig{
+		pkMap, err := Debezium{}.GetPrimaryKey(t.Context(), []byte(`Struct{id={"$oid": "65566afbfefeb3c639deaf5d"}}`), kafkalib.TopicConfig{
 			CDCKeyFormat: kafkalib.StringKeyFmt,
 		}, nil)
 		assert.NoError(t, err)
@@ -106,7 +106,7 @@ func TestMongoDBEventOrder(t *testing.T) {
 }
 `
 
-	evt, err := Debezium{}.GetEventFromBytes([]byte(payload))
+	evt, err := Debezium{}.GetEventFromBytes(t.Context(), []byte(payload))
 	assert.NoError(t, err)
 
 	schemaEvt, ok := evt.(*SchemaEventPayload)
@@ -118,7 +118,7 @@ func TestMongoDBEventOrder(t *testing.T) {
 
 func TestMongoDBEvent_DeletedRow(t *testing.T) {
 	payload := `{"schema":{"type":"","fields":null},"payload":{"before":"{\"_id\":\"abc\"}","after":"{\"_id\":\"abc\"}","source":{"connector":"","ts_ms":1728784382733,"db":"foo","collection":"bar"},"op":"d"}}`
-	evt, err := Debezium{}.GetEventFromBytes([]byte(payload))
+	evt, err := Debezium{}.GetEventFromBytes(t.Context(), []byte(payload))
 	assert.NoError(t, err)
 	evtData, err := evt.GetData(kafkalib.TopicConfig{})
 	assert.NoError(t, err)
@@ -155,7 +155,7 @@ func TestMongoDBEventCustomer(t *testing.T) {
 	}
 }
 `
-	evt, err := Debezium{}.GetEventFromBytes([]byte(payload))
+	evt, err := Debezium{}.GetEventFromBytes(t.Context(), []byte(payload))
 	assert.NoError(t, err)
 	evtData, err := evt.GetData(kafkalib.TopicConfig{})
 	assert.NoError(t, err)
@@ -220,7 +220,7 @@ func TestMongoDBEventCustomerBefore_NoData(t *testing.T) {
 	}
 }
 `
-	evt, err := Debezium{}.GetEventFromBytes([]byte(payload))
+	evt, err := Debezium{}.GetEventFromBytes(t.Context(), []byte(payload))
 	assert.NoError(t, err)
 	{
 		// Making sure the `before` payload is set.
@@ -269,7 +269,7 @@ func TestMongoDBEventCustomerBefore(t *testing.T) {
 	}
 }
 `
-	evt, err := Debezium{}.GetEventFromBytes([]byte(payload))
+	evt, err := Debezium{}.GetEventFromBytes(t.Context(), []byte(payload))
 	assert.NoError(t, err)
 	{
 		// Making sure the `before` payload is set.
@@ -306,7 +306,7 @@ func TestMongoDBEventCustomerBefore(t *testing.T) {
 }
 
 func TestGetEventFromBytesTombstone(t *testing.T) {
-	_, err := Debezium{}.GetEventFromBytes(nil)
+	_, err := Debezium{}.GetEventFromBytes(t.Context(), nil)
 	assert.ErrorContains(t, err, "empty message")
 }
 
@@ -496,7 +496,7 @@ func TestMongoDBEventWithSchema(t *testing.T) {
 	}
 }
 `
-	evt, err := Debezium{}.GetEventFromBytes([]byte(payload))
+	evt, err := Debezium{}.GetEventFromBytes(t.Context(), []byte(payload))
 	assert.NoError(t, err)
 	schemaEvt, ok := evt.(*SchemaEventPayload)
 	assert.True(t, ok)
@@ -1,6 +1,7 @@
 package relational
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/artie-labs/transfer/lib/cdc"
@@ -13,7 +14,7 @@ import (
 
 type Debezium struct{}
 
-func (Debezium) GetEventFromBytes(bytes []byte) (cdc.Event, error) {
+func (Debezium) GetEventFromBytes(_ context.Context, bytes []byte) (cdc.Event, error) {
 	if len(bytes) == 0 {
 		return nil, fmt.Errorf("empty message")
 	}
@@ -35,6 +36,6 @@ func (Debezium) Labels() []string {
 	}
 }
 
-func (Debezium) GetPrimaryKey(key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error) {
+func (Debezium) GetPrimaryKey(_ context.Context, key []byte, tc kafkalib.TopicConfig, reservedColumns map[string]bool) (map[string]any, error) {
 	return debezium.ParsePartitionKey(key, tc.CDCKeyFormat, reservedColumns)
 }
@@ -21,13 +21,13 @@ var validTc = kafkalib.TopicConfig{
 }
 
 func (r *RelationTestSuite) TestGetEventFromBytesTombstone() {
-	_, err := r.GetEventFromBytes(nil)
+	_, err := r.GetEventFromBytes(r.T().Context(), nil)
 	assert.ErrorContains(r.T(), err, "empty message")
 }
 
 func (r *RelationTestSuite) TestGetPrimaryKey() {
 	valString := `{"id": 47}`
-	pkMap, err := r.GetPrimaryKey([]byte(valString), validTc, nil)
+	pkMap, err := r.GetPrimaryKey(r.T().Context(), []byte(valString), validTc, nil)
 	assert.NoError(r.T(), err)
 
 	val, ok := pkMap["id"]
@@ -38,7 +38,7 @@ func (r *RelationTestSuite) TestGetPrimaryKey() {
 
 func (r *RelationTestSuite) TestGetPrimaryKeyUUID() {
 	valString := `{"uuid": "ca0cefe9-45cf-44fa-a2ab-ec5e7e5522a3"}`
-	pkMap, err := r.GetPrimaryKey([]byte(valString), validTc, nil)
+	pkMap, err := r.GetPrimaryKey(r.T().Context(), []byte(valString), validTc, nil)
 	val, ok := pkMap["uuid"]
 	assert.True(r.T(), ok)
 	assert.Equal(r.T(), val, "ca0cefe9-45cf-44fa-a2ab-ec5e7e5522a3")
@@ -85,7 +85,7 @@ func (r *RelationTestSuite) TestPostgresEvent() {
 	}
 }
 `
-	evt, err := r.Debezium.GetEventFromBytes([]byte(payload))
+	evt, err := r.Debezium.GetEventFromBytes(r.T().Context(), []byte(payload))
 	assert.NoError(r.T(), err)
 	assert.False(r.T(), evt.DeletePayload())
 
@@ -196,7 +196,7 @@ func (r *RelationTestSuite) TestPostgresEventWithSchemaAndTimestampNoTZ() {
 	}
 }
 `
-	evt, err := r.Debezium.GetEventFromBytes([]byte(payload))
+	evt, err := r.Debezium.GetEventFromBytes(r.T().Context(), []byte(payload))
 	assert.NoError(r.T(), err)
 	assert.False(r.T(), evt.DeletePayload())
 
@@ -523,7 +523,7 @@ func (r *RelationTestSuite) TestGetEventFromBytes_MySQL() {
 		"transaction": null
 	}
 }`
-	evt, err := r.Debezium.GetEventFromBytes([]byte(payload))
+	evt, err := r.Debezium.GetEventFromBytes(r.T().Context(), []byte(payload))
 	assert.NoError(r.T(), err)
 	assert.Equal(r.T(), time.Date(2023, time.March, 13, 19, 19, 24, 0, time.UTC), evt.GetExecutionTime())
 	assert.Equal(r.T(), "customers", evt.GetTableName())
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 
 	"gopkg.in/yaml.v3"
 
@@ -44,6 +45,14 @@ func (s *S3Settings) Validate() error {
 		return fmt.Errorf("invalid s3 output format %q", s.OutputFormat)
 	}
 
+	if err := s.FormatOptions.Validate(); err != nil {
+		return fmt.Errorf("invalid s3 format options: %w", err)
+	}
+
+	if err := s.Rollup.Validate(); err != nil {
+		return fmt.Errorf("invalid s3 rollup settings: %w", err)
+	}
+
 	return nil
 }
 
@@ -60,11 +69,67 @@ func (g *GCSSettings) Validate() error {
 		return fmt.Errorf("invalid gcs output format %q", g.OutputFormat)
 	}
 
+	if err := g.FormatOptions.Validate(); err != nil {
+		return fmt.Errorf("invalid gcs format options: %w", err)
+	}
+
+	if err := g.Rollup.Validate(); err != nil {
+		return fmt.Errorf("invalid gcs rollup settings: %w", err)
+	}
+
 	return nil
 }
 
+// TopicConfigs returns the topic configs for the configured queue.
 func (c Config) TopicConfigs() []*kafkalib.TopicConfig {
-	return c.Kafka.TopicConfigs
+	switch c.Queue {
+	case constants.NATS:
+		if c.NATS != nil {
+			return c.NATS.TopicConfigs
+		}
+	case constants.File:
+		if c.File != nil {
+			return c.File.TopicConfigs
+		}
+	default:
+		if c.Kafka != nil {
+			return c.Kafka.TopicConfigs
+		}
+	}
+
+	return nil
+}
+
+func (c Config) Topics() []string {
+	var out []string
+	for _, topicConfig := range c.TopicConfigs() {
+		out = append(out, topicConfig.Topic)
+	}
+
+	return out
+}
+
+// EqualExceptTopicConfigs returns true if [c] and [other] only differ by their topic configs.
+func (c Config) EqualExceptTopicConfigs(other Config) bool {
+	if c.Kafka != nil && other.Kafka != nil {
+		kafka, otherKafka := *c.Kafka, *other.Kafka
+		kafka.TopicConfigs, otherKafka.TopicConfigs = nil, nil
+		c.Kafka, other.Kafka = &kafka, &otherKafka
+	}
+
+	if c.NATS != nil && other.NATS != nil {
+		nats, otherNATS := *c.NATS, *other.NATS
+		nats.TopicConfigs, otherNATS.TopicConfigs = nil, nil
+		c.NATS, other.NATS = &nats, &otherNATS
+	}
+
+	if c.File != nil && other.File != nil {
+		file, otherFile := *c.File, *other.File
+		file.TopicConfigs, otherFile.TopicConfigs = nil, nil
+		c.File, other.File = &file, &otherFile
+	}
+
+	return reflect.DeepEqual(c, other)
 }
 
 func (m Mode) String() string {
@@ -281,6 +346,26 @@ func (c Config) Validate() error {
 		if err := c.ValidateSQS(); err != nil {
 			return err
 		}
+	case constants.KafkaSink:
+		if err := c.KafkaSink.Validate(); err != nil {
+			return err
+		}
+	case constants.HTTPSink:
+		if err := c.HTTPSink.Validate(); err != nil {
+			return err
+		}
+	case constants.LocalFile:
+		if err := c.LocalFile.Validate(); err != nil {
+			return err
+		}
+	case constants.SQLite:
+		if err := c.SQLite.Validate(); err != nil {
+			return err
+		}
+	case constants.DuckDB:
+		if err := c.DuckDB.Validate(); err != nil {
+			return err
+		}
 	}
 
 	switch c.Queue {
@@ -293,6 +378,20 @@ func (c Config) Validate() error {
 		if stringutil.Empty(c.Kafka.GroupID, c.Kafka.BootstrapServer) {
 			return fmt.Errorf("kafka group or bootstrap server is empty")
 		}
+
+		if c.Kafka.SchemaRegistry != nil {
+			if err := c.Kafka.SchemaRegistry.Validate(); err != nil {
+				return err
+			}
+		}
+	case constants.NATS:
+		if err := c.NATS.Validate(); err != nil {
+			return err
+		}
+	case constants.File:
+		if err := c.File.Validate(); err != nil {
+			return err
+		}
 	}
 
 	tcs := c.TopicConfigs()
@@ -310,6 +409,13 @@ func (c Config) Validate() error {
 			return fmt.Errorf("failed to validate topic config: %w", err)
 		}
 
+		switch topicConfig.CDCFormat {
+		case constants.DBZRelationalAvroFormat, constants.DBZRelationalProtobufFormat:
+			if c.Kafka == nil || c.Kafka.SchemaRegistry == nil {
+				return fmt.Errorf("schema registry is required for cdc format %q, topic: %s", topicConfig.CDCFormat, topicConfig.String())
+			}
+		}
+
 		if len(topicConfig.ColumnsToEncrypt) > 0 || topicConfig.EncryptJSONBColumns {
 			hasColumnsToEncrypt = true
 		}
@@ -355,5 +461,29 @@ func (c Config) Validate() error {
 		}
 	}
 
+	if tracing := c.Telemetry.Tracing; tracing != nil && tracing.Enabled {
+		if err := tracing.Validate(); err != nil {
+			return fmt.Errorf("invalid tracing settings: %w", err)
+		}
+	}
+
+	if spill := c.Spill; spill != nil && spill.Enabled {
+		if err := spill.Validate(); err != nil {
+			return fmt.Errorf("invalid spill settings: %w", err)
+		}
+	}
+
+	if memoryBudget := c.MemoryBudget; memoryBudget != nil {
+		if err := memoryBudget.Validate(); err != nil {
+			return fmt.Errorf("invalid memory budget settings: %w", err)
+		}
+	}
+
+	if gracefulShutdown := c.GracefulShutdown; gracefulShutdown != nil {
+		if err := gracefulShutdown.Validate(); err != nil {
+			return fmt.Errorf("invalid graceful shutdown settings: %w", err)
+		}
+	}
+
 	return nil
 }
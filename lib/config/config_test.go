@@ -506,3 +506,33 @@ func TestConfig_Validate(t *testing.T) {
 		assert.ErrorContains(t, cfg.Validate(), "flush size pool has to be a positive number")
 	}
 }
+
+func TestConfig_EqualExceptTopicConfigs(t *testing.T) {
+	newConfig := func(flushIntervalSeconds int, topics ...string) Config {
+		cfg := Config{FlushIntervalSeconds: flushIntervalSeconds, Kafka: &kafkalib.Kafka{BootstrapServer: "localhost:9092", GroupID: "group"}}
+		for _, topic := range topics {
+			cfg.Kafka.TopicConfigs = append(cfg.Kafka.TopicConfigs, &kafkalib.TopicConfig{Topic: topic})
+		}
+		return cfg
+	}
+
+	cfg := newConfig(10, "orders")
+	{
+		// Same
+		assert.True(t, cfg.EqualExceptTopicConfigs(newConfig(10, "orders")))
+	}
+	{
+		// Only topic configs changed
+		assert.True(t, cfg.EqualExceptTopicConfigs(newConfig(10, "orders", "customers")))
+		// This should not have modified the original config
+		assert.Len(t, cfg.Kafka.TopicConfigs, 1)
+	}
+	{
+		// Other settings changed
+		assert.False(t, cfg.EqualExceptTopicConfigs(newConfig(60, "orders")))
+
+		other := newConfig(10, "orders")
+		other.Kafka.GroupID = "other-group"
+		assert.False(t, cfg.EqualExceptTopicConfigs(other))
+	}
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -90,6 +93,26 @@ func TestS3Settings_Validate(t *testing.T) {
 		}
 		assert.NoError(t, s3.Validate())
 	}
+	{
+		// csv with an invalid delimiter
+		s3 := &S3Settings{
+			Bucket:        "bucket",
+			RoleARN:       "arn:aws:iam::123456789:role/my-role",
+			OutputFormat:  constants.CSVFormat,
+			FormatOptions: FileFormatOptions{Delimiter: "||"},
+		}
+		assert.ErrorContains(t, s3.Validate(), `invalid s3 format options: delimiter must be a single character, got "||"`)
+	}
+	{
+		// valid jsonl
+		s3 := &S3Settings{
+			Bucket:        "bucket",
+			RoleARN:       "arn:aws:iam::123456789:role/my-role",
+			OutputFormat:  constants.JSONLFormat,
+			FormatOptions: FileFormatOptions{Compression: NoCompression},
+		}
+		assert.NoError(t, s3.Validate())
+	}
 }
 
 func TestGCSSettings_Validate(t *testing.T) {
@@ -449,3 +472,80 @@ func TestCfg_ValidateRedshift(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestTracingSettings_Validate(t *testing.T) {
+	{
+		// Default exporter
+		assert.NoError(t, TracingSettings{Enabled: true}.Validate())
+		assert.Equal(t, OTLPTracingExporter, TracingSettings{}.GetExporter())
+	}
+	{
+		// File exporter without a file path
+		assert.ErrorContains(t, TracingSettings{Exporter: FileTracingExporter}.Validate(), "filePath is required for the file tracing exporter")
+	}
+	{
+		// File exporter
+		assert.NoError(t, TracingSettings{Exporter: FileTracingExporter, FilePath: "/tmp/traces.jsonl"}.Validate())
+	}
+	{
+		// Unsupported exporter
+		assert.ErrorContains(t, TracingSettings{Exporter: "zipkin"}.Validate(), `unsupported tracing exporter "zipkin"`)
+	}
+	{
+		// Sample ratio
+		assert.Equal(t, 1.0, TracingSettings{}.GetSampleRatio())
+		assert.Equal(t, 1.0, TracingSettings{SampleRatio: 2}.GetSampleRatio())
+		assert.Equal(t, 0.25, TracingSettings{SampleRatio: 0.25}.GetSampleRatio())
+	}
+}
+
+func TestSpillSettings_Validate(t *testing.T) {
+	{
+		// Missing threshold
+		assert.ErrorContains(t, SpillSettings{Enabled: true}.Validate(), "memoryThresholdKb must be greater than 0")
+	}
+	{
+		// Default directory
+		assert.NoError(t, SpillSettings{Enabled: true, MemoryThresholdKb: 1024}.Validate())
+		assert.Equal(t, os.TempDir(), SpillSettings{}.GetDirectory())
+	}
+	{
+		// Directory does not exist
+		assert.ErrorContains(t, SpillSettings{MemoryThresholdKb: 1024, Directory: "/does/not/exist"}.Validate(), `failed to stat directory "/does/not/exist"`)
+	}
+	{
+		// Directory is a file
+		fp := filepath.Join(t.TempDir(), "file")
+		assert.NoError(t, os.WriteFile(fp, []byte("hi"), 0o644))
+		assert.ErrorContains(t, SpillSettings{MemoryThresholdKb: 1024, Directory: fp}.Validate(), "is not a directory")
+	}
+	{
+		// Valid directory
+		assert.NoError(t, SpillSettings{MemoryThresholdKb: 1024, Directory: t.TempDir()}.Validate())
+	}
+}
+
+func TestMemoryBudgetSettings_Validate(t *testing.T) {
+	{
+		// Missing limit
+		assert.ErrorContains(t, MemoryBudgetSettings{}.Validate(), "limitMb must be greater than 0")
+	}
+	{
+		// Invalid resume percent
+		assert.ErrorContains(t, MemoryBudgetSettings{LimitMb: 1024, ResumePercent: 101}.Validate(), "resumePercent must be between 0 and 100, got: 101")
+	}
+	{
+		// Valid
+		assert.NoError(t, MemoryBudgetSettings{LimitMb: 1024}.Validate())
+		assert.Equal(t, int64(1024*1024*1024), MemoryBudgetSettings{LimitMb: 1024}.LimitBytes())
+		assert.Equal(t, int64(768*1024*1024), MemoryBudgetSettings{LimitMb: 1024}.ResumeBytes())
+		assert.Equal(t, int64(512*1024*1024), MemoryBudgetSettings{LimitMb: 1024, ResumePercent: 50}.ResumeBytes())
+	}
+}
+
+func TestGracefulShutdownSettings_Validate(t *testing.T) {
+	assert.NoError(t, GracefulShutdownSettings{}.Validate())
+	assert.Equal(t, time.Minute, GracefulShutdownSettings{}.GetTimeout())
+	assert.Equal(t, 30*time.Second, GracefulShutdownSettings{TimeoutSeconds: 30}.GetTimeout())
+	assert.ErrorContains(t, GracefulShutdownSettings{TimeoutSeconds: -1}.Validate(), "timeoutSeconds must be greater than or equal to 0, got: -1")
+}
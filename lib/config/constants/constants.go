@@ -48,6 +48,11 @@ const (
 	DBZMySQLFormat = "debezium.mysql"
 
 	DBZRelationalFormat = "debezium.relational"
+	// DBZRelationalAvroFormat and DBZRelationalProtobufFormat are for connectors using Confluent's converters with a Schema Registry.
+	DBZRelationalAvroFormat     = "debezium.relational.avro"
+	DBZRelationalProtobufFormat = "debezium.relational.protobuf"
+	// DBZRelationalFlattenedFormat is for connectors using the ExtractNewRecordState SMT, where rows are emitted without the before / after envelope.
+	DBZRelationalFlattenedFormat = "debezium.relational.flattened"
 
 	EventTrackingFormat = "artie.trackevents"
 
@@ -68,7 +73,10 @@ var ArtieColumns = []string{
 // ExporterKind is used for the Telemetry package
 type ExporterKind string
 
-const Datadog ExporterKind = "datadog"
+const (
+	Datadog    ExporterKind = "datadog"
+	Prometheus ExporterKind = "prometheus"
+)
 
 // ColumnOperation is a type used for DDL operations
 type ColumnOperation string
@@ -82,6 +90,10 @@ type QueueKind string
 
 const (
 	Kafka QueueKind = "kafka"
+	// NATS consumes from NATS JetStream, every topic is a subject with its own durable consumer.
+	NATS QueueKind = "nats"
+	// File replays records from NDJSON files, offsets are only committed in memory.
+	File QueueKind = "file"
 	// Reader - This is when Reader is directly importing code from Transfer and skipping Kafka.
 	Reader QueueKind = "reader"
 )
@@ -103,6 +115,13 @@ const (
 	Snowflake  DestinationKind = "snowflake"
 	Redis      DestinationKind = "redis"
 	SQS        DestinationKind = "sqs"
+	// KafkaSink re-publishes the flushed rows to Kafka, it is named differently so that it doesn't clash with the [Kafka] queue.
+	KafkaSink DestinationKind = "kafka"
+	HTTPSink  DestinationKind = "http"
+	LocalFile DestinationKind = "file"
+	SQLite    DestinationKind = "sqlite"
+	// DuckDB opens a local DuckDB database in-process, use [MotherDuck] to write to MotherDuck instead.
+	DuckDB DestinationKind = "duckdb"
 )
 
 var ValidDestinations = []DestinationKind{
@@ -120,6 +139,11 @@ var ValidDestinations = []DestinationKind{
 	Snowflake,
 	Redis,
 	SQS,
+	KafkaSink,
+	HTTPSink,
+	LocalFile,
+	SQLite,
+	DuckDB,
 }
 
 func IsValidDestination(destination DestinationKind) bool {
@@ -138,10 +162,19 @@ type ColComment struct {
 
 type S3OutputFormat string
 
-const ParquetFormat S3OutputFormat = "parquet"
+const (
+	ParquetFormat S3OutputFormat = "parquet"
+	CSVFormat     S3OutputFormat = "csv"
+	JSONLFormat   S3OutputFormat = "jsonl"
+)
 
 func IsValidS3OutputFormat(format S3OutputFormat) bool {
-	return format == ParquetFormat
+	switch format {
+	case ParquetFormat, CSVFormat, JSONLFormat:
+		return true
+	default:
+		return false
+	}
 }
 
 type TableAlias string
@@ -3,8 +3,14 @@ package config
 import (
 	"cmp"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
 )
 
 type BigQuery struct {
@@ -85,9 +91,95 @@ type S3Settings struct {
 	RoleARN            string                   `yaml:"roleARN,omitempty"`
 	ExternalID         string                   `yaml:"externalID,omitempty"`
 	OutputFormat       constants.S3OutputFormat `yaml:"outputFormat"`
+	FormatOptions      FileFormatOptions        `yaml:"formatOptions,omitempty"`
+	Rollup             FileRollupSettings       `yaml:"rollup,omitempty"`
 	TableNameSeparator string                   `yaml:"tableNameSeparator"`
 }
 
+type FileCompression string
+
+const (
+	GzipCompression FileCompression = "gzip"
+	NoCompression   FileCompression = "none"
+)
+
+// FileFormatOptions are used by the csv and jsonl output formats, parquet files are always written with gzip compression.
+type FileFormatOptions struct {
+	// CSV only, [Delimiter] defaults to a comma and [NullToken] defaults to an empty string.
+	Delimiter string `yaml:"delimiter,omitempty"`
+	Header    bool   `yaml:"header,omitempty"`
+	NullToken string `yaml:"nullToken,omitempty"`
+
+	// [Compression] - Defaults to gzip.
+	Compression FileCompression `yaml:"compression,omitempty"`
+}
+
+func (f FileFormatOptions) GetDelimiter() rune {
+	if f.Delimiter == "" {
+		return ','
+	}
+
+	delimiter, _ := utf8.DecodeRuneInString(f.Delimiter)
+	return delimiter
+}
+
+func (f FileFormatOptions) GetCompression() FileCompression {
+	return cmp.Or(f.Compression, GzipCompression)
+}
+
+func (f FileFormatOptions) Validate() error {
+	if f.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(f.Delimiter)
+		if size != len(f.Delimiter) || delimiter == utf8.RuneError {
+			return fmt.Errorf("delimiter must be a single character, got %q", f.Delimiter)
+		}
+
+		if delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+			return fmt.Errorf("delimiter cannot be %q", f.Delimiter)
+		}
+	}
+
+	switch f.GetCompression() {
+	case GzipCompression, NoCompression:
+	default:
+		return fmt.Errorf("invalid compression: %q", f.Compression)
+	}
+
+	return nil
+}
+
+const DefaultRollupMaxWaitSeconds = 15 * 60
+
+// FileRollupSettings holds back time-based flushes until enough rows have been buffered, this avoids writing lots of tiny files.
+// Flushes that are triggered by [Config.BufferRows], [Config.FlushSizeKb], memory pressure or shutdown are never held back.
+type FileRollupSettings struct {
+	// [TargetFileSizeKb] - If set, time-based flushes are skipped until the buffered table reaches this size.
+	TargetFileSizeKb int `yaml:"targetFileSizeKb,omitempty"`
+	// [MaxWaitSeconds] - How long rows can be held back since the last flush, defaults to 15 minutes.
+	MaxWaitSeconds int `yaml:"maxWaitSeconds,omitempty"`
+}
+
+func (f FileRollupSettings) GetMaxWait() time.Duration {
+	return time.Duration(cmp.Or(f.MaxWaitSeconds, DefaultRollupMaxWaitSeconds)) * time.Second
+}
+
+// ShouldDefer returns true if a table of [approxSize] bytes that was last flushed at [lastFlushTime] should keep buffering.
+func (f FileRollupSettings) ShouldDefer(approxSize int, lastFlushTime time.Time) bool {
+	if f.TargetFileSizeKb <= 0 {
+		return false
+	}
+
+	return approxSize < f.TargetFileSizeKb*1024 && time.Since(lastFlushTime) < f.GetMaxWait()
+}
+
+func (f FileRollupSettings) Validate() error {
+	if f.TargetFileSizeKb < 0 || f.MaxWaitSeconds < 0 {
+		return fmt.Errorf("rollup targetFileSizeKb and maxWaitSeconds cannot be negative")
+	}
+
+	return nil
+}
+
 type GCSSettings struct {
 	FolderName string `yaml:"folderName"`
 	Bucket     string `yaml:"bucket"`
@@ -96,6 +188,8 @@ type GCSSettings struct {
 	PathToCredentials  string                   `yaml:"pathToCredentials"`
 	ProjectID          string                   `yaml:"projectID"`
 	OutputFormat       constants.S3OutputFormat `yaml:"outputFormat"`
+	FormatOptions      FileFormatOptions        `yaml:"formatOptions,omitempty"`
+	Rollup             FileRollupSettings       `yaml:"rollup,omitempty"`
 	TableNameSeparator string                   `yaml:"tableNameSeparator"`
 }
 
@@ -161,7 +255,19 @@ type ExternalStage struct {
 	CredentialsClause string `yaml:"credentialsClause,omitempty"`
 }
 
+type IcebergWriter string
+
+const (
+	// IcebergWriterApacheLivy runs Spark SQL statements through Apache Livy, this is the default.
+	IcebergWriterApacheLivy IcebergWriter = "apacheLivy"
+	// IcebergWriterNative writes Parquet data files with iceberg-go and commits snapshots through the REST catalog.
+	IcebergWriterNative IcebergWriter = "native"
+)
+
 type Iceberg struct {
+	// [Writer] - Which writer to use, defaults to [IcebergWriterApacheLivy].
+	Writer IcebergWriter `yaml:"writer,omitempty"`
+
 	ApacheLivyURL                   string `yaml:"apacheLivyURL"`
 	SessionHeartbeatTimeoutInSecond int    `yaml:"sessionHeartbeatTimeoutInSecond"`
 	SessionDriverMemory             string `yaml:"sessionDriverMemory"`
@@ -235,6 +341,20 @@ func (r RestCatalog) Validate() error {
 	return nil
 }
 
+// ValidateNativeWriter - The native writer does not stage files for Spark, so only the catalog settings are required.
+// Authentication and AWS credentials are optional so that it can be used against a local REST catalog with a filesystem warehouse.
+func (r RestCatalog) ValidateNativeWriter() error {
+	if r.URI == "" {
+		return fmt.Errorf("rest catalog uri is required")
+	}
+
+	if r.Warehouse == "" {
+		return fmt.Errorf("rest catalog warehouse is required")
+	}
+
+	return nil
+}
+
 func (r RestCatalog) CatalogName() string {
 	return r.Warehouse
 }
@@ -340,6 +460,24 @@ type MotherDuck struct {
 	Token       string `yaml:"token"`
 }
 
+// DuckDBSettings is for a local DuckDB database that is opened in-process, see [MotherDuck] for the hosted version.
+type DuckDBSettings struct {
+	// [Path] - Path to the database file, it will be created if it doesn't exist.
+	Path string `yaml:"path"`
+}
+
+func (d *DuckDBSettings) Validate() error {
+	if d == nil {
+		return fmt.Errorf("duckdb settings are nil")
+	}
+
+	if d.Path == "" {
+		return fmt.Errorf("duckdb path is required")
+	}
+
+	return nil
+}
+
 type Clickhouse struct {
 	Addresses  []string `json:"addresses" yaml:"addresses"`
 	Username   string   `json:"username" yaml:"username"`
@@ -383,3 +521,302 @@ func (s *SQSSettings) Validate() error {
 func (s *SQSSettings) IsSingleQueueMode() bool {
 	return s.QueueURL != ""
 }
+
+type KafkaSinkOutputFormat string
+
+const (
+	KafkaSinkOutputJSON KafkaSinkOutputFormat = "json"
+	KafkaSinkOutputAvro KafkaSinkOutputFormat = "avro"
+)
+
+const DefaultKafkaSinkTopicTemplate = "{database}.{schema}.{table}"
+
+// placeholderRegex matches the placeholders in topic and URL templates, e.g. {table}.
+var placeholderRegex = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// validatePlaceholders makes sure [template] only uses {database}, {schema}, {table} and {topic}.
+func validatePlaceholders(template, field string) error {
+	for _, match := range placeholderRegex.FindAllStringSubmatch(template, -1) {
+		switch match[1] {
+		case "database", "schema", "table", "topic":
+		default:
+			return fmt.Errorf("invalid placeholder %q in %s", match[0], field)
+		}
+	}
+
+	return nil
+}
+
+type KafkaSinkSettings struct {
+	// Connection settings, these follow the same semantics as the Kafka queue settings.
+	BootstrapServer string `yaml:"bootstrapServer"`
+	Username        string `yaml:"username,omitempty"`
+	Password        string `yaml:"password,omitempty"`
+	EnableAWSMSKIAM bool   `yaml:"enableAWSMKSIAM,omitempty"`
+	DisableTLS      bool   `yaml:"disableTLS,omitempty"`
+
+	// [TopicTemplate] - The topic to write each table to, supports {database}, {schema}, {table} and {topic} (the source topic).
+	// A template without placeholders will write every table to the same topic. Defaults to [DefaultKafkaSinkTopicTemplate].
+	TopicTemplate string `yaml:"topicTemplate,omitempty"`
+	// [AllowAutoTopicCreation] - If enabled, the brokers will create the topics if they do not exist yet.
+	AllowAutoTopicCreation bool `yaml:"allowAutoTopicCreation,omitempty"`
+
+	// [OutputFormat] - Defaults to json. Avro values are serialized with the Confluent wire format and require [SchemaRegistry].
+	// Message keys are always the JSON-encoded primary key.
+	OutputFormat   KafkaSinkOutputFormat    `yaml:"outputFormat,omitempty"`
+	SchemaRegistry *kafkalib.SchemaRegistry `yaml:"schemaRegistry,omitempty"`
+}
+
+func (k *KafkaSinkSettings) GetOutputFormat() KafkaSinkOutputFormat {
+	if k.OutputFormat == "" {
+		return KafkaSinkOutputJSON
+	}
+
+	return k.OutputFormat
+}
+
+func (k *KafkaSinkSettings) GetTopicTemplate() string {
+	if k.TopicTemplate == "" {
+		return DefaultKafkaSinkTopicTemplate
+	}
+
+	return k.TopicTemplate
+}
+
+// TopicName renders [GetTopicTemplate], empty database or schema values are dropped along with the separator that follows them.
+func (k *KafkaSinkSettings) TopicName(database, schema, table, sourceTopic string) string {
+	values := map[string]string{
+		"database": database,
+		"schema":   schema,
+		"table":    table,
+		"topic":    sourceTopic,
+	}
+
+	template := k.GetTopicTemplate()
+	var out strings.Builder
+	var skipSeparator bool
+	for len(template) > 0 {
+		loc := placeholderRegex.FindStringIndex(template)
+		if loc == nil {
+			out.WriteString(template)
+			break
+		}
+
+		literal := template[:loc[0]]
+		if skipSeparator && len(literal) > 0 {
+			literal = literal[1:]
+		}
+		out.WriteString(literal)
+
+		value := values[template[loc[0]+1:loc[1]-1]]
+		out.WriteString(value)
+		skipSeparator = value == ""
+		template = template[loc[1]:]
+	}
+
+	return out.String()
+}
+
+func (k *KafkaSinkSettings) Validate() error {
+	if k == nil {
+		return fmt.Errorf("kafka sink settings are nil")
+	}
+
+	if k.BootstrapServer == "" {
+		return fmt.Errorf("kafka sink bootstrapServer is required")
+	}
+
+	if err := validatePlaceholders(k.GetTopicTemplate(), "kafka sink topicTemplate"); err != nil {
+		return err
+	}
+
+	switch k.GetOutputFormat() {
+	case KafkaSinkOutputJSON:
+	case KafkaSinkOutputAvro:
+		if k.SchemaRegistry == nil {
+			return fmt.Errorf("schemaRegistry is required for the avro output format")
+		}
+
+		if err := k.SchemaRegistry.Validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid kafka sink outputFormat: %q", k.OutputFormat)
+	}
+
+	return nil
+}
+
+type HTTPSinkFormat string
+
+const (
+	HTTPSinkFormatJSON   HTTPSinkFormat = "json"
+	HTTPSinkFormatNDJSON HTTPSinkFormat = "ndjson"
+)
+
+const (
+	DefaultHTTPSinkMaxRowsPerRequest  = 500
+	DefaultHTTPSinkMaxBytesPerRequest = 5 * 1024 * 1024
+	DefaultHTTPSinkSignatureHeader    = "X-Artie-Signature"
+	DefaultHTTPSinkTimeoutSeconds     = 30
+	DefaultHTTPSinkMaxAttempts        = 5
+)
+
+type HTTPSinkSettings struct {
+	// [URLTemplate] - The URL to POST the rows to, supports {database}, {schema}, {table} and {topic} (the source topic).
+	// Placeholder values are path escaped.
+	URLTemplate string `yaml:"urlTemplate"`
+	// [Format] - Either json (an array of rows) or ndjson (one row per line), defaults to json.
+	Format HTTPSinkFormat `yaml:"format,omitempty"`
+
+	// Auth, [Headers] are added to every request, e.g. an API key header.
+	BearerToken string            `yaml:"bearerToken,omitempty"`
+	Headers     map[string]string `yaml:"headers,omitempty"`
+
+	// [SigningSecret] - If set, every request will include a HMAC-SHA256 signature of "<timestamp>.<body>" in [SignatureHeader].
+	SigningSecret   string `yaml:"signingSecret,omitempty"`
+	SignatureHeader string `yaml:"signatureHeader,omitempty"`
+
+	// Limits, a table with more rows than this will be sent over multiple requests.
+	MaxRowsPerRequest  int `yaml:"maxRowsPerRequest,omitempty"`
+	MaxBytesPerRequest int `yaml:"maxBytesPerRequest,omitempty"`
+	TimeoutSeconds     int `yaml:"timeoutSeconds,omitempty"`
+	// [MaxAttempts] - The number of attempts for each request, only 429s, 5xxs and network errors are retried.
+	MaxAttempts int `yaml:"maxAttempts,omitempty"`
+}
+
+func (h *HTTPSinkSettings) GetFormat() HTTPSinkFormat {
+	if h.Format == "" {
+		return HTTPSinkFormatJSON
+	}
+
+	return h.Format
+}
+
+func (h *HTTPSinkSettings) GetSignatureHeader() string {
+	return cmp.Or(h.SignatureHeader, DefaultHTTPSinkSignatureHeader)
+}
+
+func (h *HTTPSinkSettings) GetMaxRowsPerRequest() int {
+	return cmp.Or(h.MaxRowsPerRequest, DefaultHTTPSinkMaxRowsPerRequest)
+}
+
+func (h *HTTPSinkSettings) GetMaxBytesPerRequest() int {
+	return cmp.Or(h.MaxBytesPerRequest, DefaultHTTPSinkMaxBytesPerRequest)
+}
+
+func (h *HTTPSinkSettings) GetTimeout() time.Duration {
+	return time.Duration(cmp.Or(h.TimeoutSeconds, DefaultHTTPSinkTimeoutSeconds)) * time.Second
+}
+
+func (h *HTTPSinkSettings) GetMaxAttempts() int {
+	return cmp.Or(h.MaxAttempts, DefaultHTTPSinkMaxAttempts)
+}
+
+// URL renders [URLTemplate].
+func (h *HTTPSinkSettings) URL(database, schema, table, sourceTopic string) string {
+	return strings.NewReplacer(
+		"{database}", url.PathEscape(database),
+		"{schema}", url.PathEscape(schema),
+		"{table}", url.PathEscape(table),
+		"{topic}", url.PathEscape(sourceTopic),
+	).Replace(h.URLTemplate)
+}
+
+func (h *HTTPSinkSettings) Validate() error {
+	if h == nil {
+		return fmt.Errorf("http sink settings are nil")
+	}
+
+	if h.URLTemplate == "" {
+		return fmt.Errorf("http sink urlTemplate is required")
+	}
+
+	if err := validatePlaceholders(h.URLTemplate, "http sink urlTemplate"); err != nil {
+		return err
+	}
+
+	if _, err := url.Parse(h.URL("database", "schema", "table", "topic")); err != nil {
+		return fmt.Errorf("invalid http sink urlTemplate: %w", err)
+	}
+
+	switch h.GetFormat() {
+	case HTTPSinkFormatJSON, HTTPSinkFormatNDJSON:
+	default:
+		return fmt.Errorf("invalid http sink format: %q", h.Format)
+	}
+
+	if h.MaxRowsPerRequest < 0 || h.MaxBytesPerRequest < 0 || h.TimeoutSeconds < 0 || h.MaxAttempts < 0 {
+		return fmt.Errorf("http sink limits cannot be negative")
+	}
+
+	return nil
+}
+
+type LocalFileSettings struct {
+	// [Directory] - Files are written under this directory, it will be created if it doesn't exist.
+	Directory string `yaml:"directory"`
+	// [OutputFormat] - Defaults to parquet.
+	OutputFormat  constants.S3OutputFormat `yaml:"outputFormat,omitempty"`
+	FormatOptions FileFormatOptions        `yaml:"formatOptions,omitempty"`
+	// [Compact] - If enabled, merges will keep a single compacted file per table instead of writing a new file for every flush.
+	// History mode and append-only topics always write new files.
+	Compact            bool   `yaml:"compact,omitempty"`
+	TableNameSeparator string `yaml:"tableNameSeparator,omitempty"`
+}
+
+func (l LocalFileSettings) GetOutputFormat() constants.S3OutputFormat {
+	return cmp.Or(l.OutputFormat, constants.ParquetFormat)
+}
+
+func (l *LocalFileSettings) Validate() error {
+	if l == nil {
+		return fmt.Errorf("local file settings are nil")
+	}
+
+	if l.Directory == "" {
+		return fmt.Errorf("local file directory is required")
+	}
+
+	if !constants.IsValidS3OutputFormat(l.GetOutputFormat()) {
+		return fmt.Errorf("invalid local file output format %q", l.OutputFormat)
+	}
+
+	if err := l.FormatOptions.Validate(); err != nil {
+		return fmt.Errorf("invalid local file format options: %w", err)
+	}
+
+	return nil
+}
+
+type SQLiteSettings struct {
+	// [Path] - Path to the database file, it will be created if it doesn't exist. Use ":memory:" for an in-memory database.
+	Path string `yaml:"path"`
+	// [BusyTimeoutMs] - How long a write will wait for a lock held by another process, defaults to 5 seconds.
+	BusyTimeoutMs int `yaml:"busyTimeoutMs,omitempty"`
+}
+
+func (s SQLiteSettings) DSN() string {
+	return fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)", s.Path, cmp.Or(s.BusyTimeoutMs, 5_000))
+}
+
+// ReadOnlyDSN opens an existing database file without creating it or changing its journal mode.
+func (s SQLiteSettings) ReadOnlyDSN() string {
+	return fmt.Sprintf("file:%s?mode=ro&_pragma=busy_timeout(%d)", s.Path, cmp.Or(s.BusyTimeoutMs, 5_000))
+}
+
+func (s *SQLiteSettings) Validate() error {
+	if s == nil {
+		return fmt.Errorf("sqlite settings are nil")
+	}
+
+	if s.Path == "" {
+		return fmt.Errorf("sqlite path is required")
+	}
+
+	if s.BusyTimeoutMs < 0 {
+		return fmt.Errorf("sqlite busyTimeoutMs cannot be negative")
+	}
+
+	return nil
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
 )
 
 func TestDatabricks_Validate(t *testing.T) {
@@ -91,3 +94,204 @@ func TestMSSQL_DSN(t *testing.T) {
 		assert.Contains(t, dsn, "db.example.com:1433")
 	}
 }
+
+func TestKafkaSinkSettings_Validate(t *testing.T) {
+	{
+		// Nil
+		var k *KafkaSinkSettings
+		assert.ErrorContains(t, k.Validate(), "kafka sink settings are nil")
+	}
+	{
+		// Missing bootstrap server
+		k := &KafkaSinkSettings{}
+		assert.ErrorContains(t, k.Validate(), "kafka sink bootstrapServer is required")
+	}
+	{
+		// Invalid placeholder
+		k := &KafkaSinkSettings{BootstrapServer: "localhost:9092", TopicTemplate: "cdc.{db}.{table}"}
+		assert.ErrorContains(t, k.Validate(), `invalid placeholder "{db}" in kafka sink topicTemplate`)
+	}
+	{
+		// Avro without a schema registry
+		k := &KafkaSinkSettings{BootstrapServer: "localhost:9092", OutputFormat: KafkaSinkOutputAvro}
+		assert.ErrorContains(t, k.Validate(), "schemaRegistry is required for the avro output format")
+	}
+	{
+		// Invalid output format
+		k := &KafkaSinkSettings{BootstrapServer: "localhost:9092", OutputFormat: "xml"}
+		assert.ErrorContains(t, k.Validate(), `invalid kafka sink outputFormat: "xml"`)
+	}
+	{
+		// Valid
+		k := &KafkaSinkSettings{BootstrapServer: "localhost:9092"}
+		assert.NoError(t, k.Validate())
+		assert.Equal(t, KafkaSinkOutputJSON, k.GetOutputFormat())
+	}
+}
+
+func TestKafkaSinkSettings_TopicName(t *testing.T) {
+	{
+		// Default template
+		k := &KafkaSinkSettings{}
+		assert.Equal(t, "shop.public.orders", k.TopicName("shop", "public", "orders", "dbserver1.public.orders"))
+		assert.Equal(t, "shop.orders", k.TopicName("shop", "", "orders", "dbserver1.orders"))
+		assert.Equal(t, "orders", k.TopicName("", "", "orders", "orders"))
+	}
+	{
+		// Source topic
+		k := &KafkaSinkSettings{TopicTemplate: "{topic}.clean"}
+		assert.Equal(t, "dbserver1.public.orders.clean", k.TopicName("shop", "public", "orders", "dbserver1.public.orders"))
+	}
+	{
+		// Single topic
+		k := &KafkaSinkSettings{TopicTemplate: "cdc"}
+		assert.Equal(t, "cdc", k.TopicName("shop", "public", "orders", "dbserver1.public.orders"))
+	}
+}
+
+func TestHTTPSinkSettings_Validate(t *testing.T) {
+	{
+		// Missing URL
+		h := &HTTPSinkSettings{}
+		assert.ErrorContains(t, h.Validate(), "http sink urlTemplate is required")
+	}
+	{
+		// Invalid placeholder
+		h := &HTTPSinkSettings{URLTemplate: "https://example.com/{db}"}
+		assert.ErrorContains(t, h.Validate(), `invalid placeholder "{db}" in http sink urlTemplate`)
+	}
+	{
+		// Invalid format
+		h := &HTTPSinkSettings{URLTemplate: "https://example.com", Format: "csv"}
+		assert.ErrorContains(t, h.Validate(), `invalid http sink format: "csv"`)
+	}
+	{
+		// Negative limit
+		h := &HTTPSinkSettings{URLTemplate: "https://example.com", MaxRowsPerRequest: -1}
+		assert.ErrorContains(t, h.Validate(), "http sink limits cannot be negative")
+	}
+	{
+		// Valid
+		h := &HTTPSinkSettings{URLTemplate: "https://example.com/{schema}/{table}"}
+		assert.NoError(t, h.Validate())
+		assert.Equal(t, "https://example.com/public/order%20items", h.URL("shop", "public", "order items", "orders"))
+		assert.Equal(t, DefaultHTTPSinkMaxRowsPerRequest, h.GetMaxRowsPerRequest())
+		assert.Equal(t, DefaultHTTPSinkSignatureHeader, h.GetSignatureHeader())
+	}
+}
+
+func TestFileFormatOptions(t *testing.T) {
+	{
+		// Defaults
+		var opts FileFormatOptions
+		assert.NoError(t, opts.Validate())
+		assert.Equal(t, ',', opts.GetDelimiter())
+		assert.Equal(t, GzipCompression, opts.GetCompression())
+	}
+	{
+		// Tab delimiter without compression
+		opts := FileFormatOptions{Delimiter: "\t", Compression: NoCompression}
+		assert.NoError(t, opts.Validate())
+		assert.Equal(t, '\t', opts.GetDelimiter())
+		assert.Equal(t, NoCompression, opts.GetCompression())
+	}
+	{
+		// Invalid delimiters
+		assert.ErrorContains(t, FileFormatOptions{Delimiter: "ab"}.Validate(), `delimiter must be a single character, got "ab"`)
+		assert.ErrorContains(t, FileFormatOptions{Delimiter: `"`}.Validate(), `delimiter cannot be "\""`)
+		assert.ErrorContains(t, FileFormatOptions{Delimiter: "\n"}.Validate(), `delimiter cannot be "\n"`)
+	}
+	{
+		// Invalid compression
+		assert.ErrorContains(t, FileFormatOptions{Compression: "zstd"}.Validate(), `invalid compression: "zstd"`)
+	}
+}
+
+func TestFileRollupSettings(t *testing.T) {
+	{
+		// Disabled
+		var rollup FileRollupSettings
+		assert.NoError(t, rollup.Validate())
+		assert.False(t, rollup.ShouldDefer(0, time.Now()))
+		assert.Equal(t, 15*time.Minute, rollup.GetMaxWait())
+	}
+	{
+		// Enabled
+		rollup := FileRollupSettings{TargetFileSizeKb: 1024, MaxWaitSeconds: 60}
+		assert.NoError(t, rollup.Validate())
+		assert.True(t, rollup.ShouldDefer(1024, time.Now()))
+		// Reached the target size
+		assert.False(t, rollup.ShouldDefer(1024*1024, time.Now()))
+		// Waited for too long
+		assert.False(t, rollup.ShouldDefer(1024, time.Now().Add(-2*time.Minute)))
+	}
+	{
+		// Invalid
+		assert.ErrorContains(t, FileRollupSettings{TargetFileSizeKb: -1}.Validate(), "rollup targetFileSizeKb and maxWaitSeconds cannot be negative")
+	}
+}
+
+func TestLocalFileSettings_Validate(t *testing.T) {
+	{
+		// Nil
+		var l *LocalFileSettings
+		assert.ErrorContains(t, l.Validate(), "local file settings are nil")
+	}
+	{
+		// Missing directory
+		l := &LocalFileSettings{}
+		assert.ErrorContains(t, l.Validate(), "local file directory is required")
+	}
+	{
+		// Invalid output format
+		l := &LocalFileSettings{Directory: "/tmp/artie", OutputFormat: "avro"}
+		assert.ErrorContains(t, l.Validate(), `invalid local file output format "avro"`)
+	}
+	{
+		// Valid, defaults to parquet
+		l := &LocalFileSettings{Directory: "/tmp/artie"}
+		assert.NoError(t, l.Validate())
+		assert.Equal(t, constants.ParquetFormat, l.GetOutputFormat())
+	}
+}
+
+func TestSQLiteSettings(t *testing.T) {
+	{
+		// Nil
+		var s *SQLiteSettings
+		assert.ErrorContains(t, s.Validate(), "sqlite settings are nil")
+	}
+	{
+		// Missing path
+		s := &SQLiteSettings{}
+		assert.ErrorContains(t, s.Validate(), "sqlite path is required")
+	}
+	{
+		// Negative busy timeout
+		s := &SQLiteSettings{Path: "artie.db", BusyTimeoutMs: -1}
+		assert.ErrorContains(t, s.Validate(), "sqlite busyTimeoutMs cannot be negative")
+	}
+	{
+		// Valid
+		s := &SQLiteSettings{Path: "/tmp/artie.db"}
+		assert.NoError(t, s.Validate())
+		assert.Equal(t, "file:/tmp/artie.db?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", s.DSN())
+		assert.Equal(t, "file:/tmp/artie.db?mode=ro&_pragma=busy_timeout(5000)", s.ReadOnlyDSN())
+	}
+}
+
+func TestDuckDBSettings_Validate(t *testing.T) {
+	{
+		// Nil
+		var d *DuckDBSettings
+		assert.ErrorContains(t, d.Validate(), "duckdb settings are nil")
+	}
+	{
+		// Missing path
+		assert.ErrorContains(t, (&DuckDBSettings{}).Validate(), "duckdb path is required")
+	}
+	{
+		// Valid
+		assert.NoError(t, (&DuckDBSettings{Path: "/tmp/artie.duckdb"}).Validate())
+	}
+}
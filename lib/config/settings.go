@@ -8,32 +8,43 @@ import (
 
 type Settings struct {
 	Config         Config
+	ConfigFilePath string
 	VerboseLogging bool
 }
 
 // LoadSettings will take the flags and then parse, loadConfig is optional for testing purposes.
 func LoadSettings(args []string, loadConfig bool) (*Settings, error) {
+	return LoadSettingsWithOptions(args, loadConfig, nil)
+}
+
+// LoadSettingsWithOptions is the same as [LoadSettings], but will also parse any command specific flags into [options].
+// [options] must be a pointer to a struct tagged for go-flags.
+func LoadSettingsWithOptions(args []string, loadConfig bool, options any) (*Settings, error) {
 	var opts struct {
 		ConfigFilePath string `short:"c" long:"config" description:"path to the config file"`
 		Verbose        bool   `short:"v" long:"verbose" description:"debug logging" optional:"true"`
 	}
 
-	if _, err := flags.ParseArgs(&opts, args); err != nil {
+	parser := flags.NewParser(&opts, flags.Default)
+	if options != nil {
+		if _, err := parser.AddGroup("Command Options", "", options); err != nil {
+			return nil, fmt.Errorf("failed to add command options: %w", err)
+		}
+	}
+
+	if _, err := parser.ParseArgs(args); err != nil {
 		return nil, fmt.Errorf("failed to parse args: %w", err)
 	}
 
 	settings := &Settings{
+		ConfigFilePath: opts.ConfigFilePath,
 		VerboseLogging: opts.Verbose,
 	}
 
 	if loadConfig {
-		config, err := readFileToConfig(opts.ConfigFilePath)
+		config, err := ReadConfig(opts.ConfigFilePath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse config file: %w", err)
-		}
-
-		if err = config.Validate(); err != nil {
-			return nil, fmt.Errorf("failed to validate config: %w", err)
+			return nil, err
 		}
 
 		settings.Config = *config
@@ -41,3 +52,17 @@ func LoadSettings(args []string, loadConfig bool) (*Settings, error) {
 
 	return settings, nil
 }
+
+// ReadConfig reads and validates the config file at [pathToConfig].
+func ReadConfig(pathToConfig string) (*Config, error) {
+	config, err := readFileToConfig(pathToConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if err = config.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to validate config: %w", err)
+	}
+
+	return config, nil
+}
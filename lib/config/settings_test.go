@@ -15,3 +15,28 @@ func TestParseArgs(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, settings.VerboseLogging, true)
 }
+
+func TestLoadSettingsWithOptions(t *testing.T) {
+	var opts struct {
+		Table       string   `long:"table" required:"true"`
+		PrimaryKeys []string `long:"pk"`
+	}
+	{
+		// Command options are parsed alongside the shared flags.
+		settings, err := LoadSettingsWithOptions([]string{"-v", "--table", "orders", "--pk", "id", "--pk", "tenant_id"}, false, &opts)
+		assert.NoError(t, err)
+		assert.True(t, settings.VerboseLogging)
+		assert.Equal(t, "orders", opts.Table)
+		assert.Equal(t, []string{"id", "tenant_id"}, opts.PrimaryKeys)
+	}
+	{
+		// Missing required option
+		_, err := LoadSettingsWithOptions([]string{"-v"}, false, &opts)
+		assert.ErrorContains(t, err, "the required flag `--table' was not specified")
+	}
+	{
+		// Unknown flags are still rejected without command options.
+		_, err := LoadSettings([]string{"--table", "orders"}, false)
+		assert.ErrorContains(t, err, "unknown flag `table'")
+	}
+}
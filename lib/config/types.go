@@ -1,8 +1,11 @@
 package config
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
@@ -13,7 +16,9 @@ import (
 	"github.com/artie-labs/transfer/lib/awslib"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/cryptography"
+	"github.com/artie-labs/transfer/lib/filelib"
 	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/natslib"
 	"github.com/artie-labs/transfer/lib/stringutil"
 )
 
@@ -55,8 +60,11 @@ type SharedDestinationSettings struct {
 	TruncateExceededValues bool `yaml:"truncateExceededValues,omitempty"`
 	// ExpandStringPrecision - This will expand the string precision if the incoming data has a higher precision than the destination table.
 	// This is only supported by Redshift at the moment.
-	ExpandStringPrecision bool                            `yaml:"expandStringPrecision,omitempty"`
-	ColumnSettings        SharedDestinationColumnSettings `yaml:"columnSettings"`
+	ExpandStringPrecision bool `yaml:"expandStringPrecision,omitempty"`
+	// [WidenColumnTypes] - If enabled, we will widen destination columns (e.g. INT to BIGINT, VARCHAR(50) to TEXT, NUMERIC(10, 2) to NUMERIC(20, 4))
+	// when the incoming column is wider than the destination column. This is only used by destinations that go through [shared.Merge].
+	WidenColumnTypes bool                            `yaml:"widenColumnTypes,omitempty"`
+	ColumnSettings   SharedDestinationColumnSettings `yaml:"columnSettings"`
 	// TODO: Standardize on this method.
 	UseNewStringMethod bool `yaml:"useNewStringMethod,omitempty"`
 	// [EnableMergeAssertion] - This will enable the merge assertion checks for the destination.
@@ -192,6 +200,56 @@ type Reporting struct {
 	EmitDBExecutionTime bool    `yaml:"emitDBExecutionTime"`
 }
 
+type TracingExporter string
+
+const (
+	OTLPTracingExporter TracingExporter = "otlp"
+	FileTracingExporter TracingExporter = "file"
+)
+
+type TracingSettings struct {
+	Enabled bool `yaml:"enabled"`
+	// Exporter defaults to [OTLPTracingExporter].
+	Exporter TracingExporter `yaml:"exporter,omitempty"`
+	// Endpoint is the OTLP/HTTP collector (host:port), if this is not set, the OTEL_EXPORTER_OTLP_* environment variables are used.
+	Endpoint string `yaml:"endpoint,omitempty"`
+	Insecure bool   `yaml:"insecure,omitempty"`
+	// FilePath is where spans are written to as JSON when using [FileTracingExporter].
+	FilePath string `yaml:"filePath,omitempty"`
+	// SampleRatio is the fraction of root spans that are sampled, defaults to 1.
+	SampleRatio float64 `yaml:"sampleRatio,omitempty"`
+}
+
+func (t TracingSettings) GetExporter() TracingExporter {
+	if t.Exporter == "" {
+		return OTLPTracingExporter
+	}
+
+	return t.Exporter
+}
+
+func (t TracingSettings) GetSampleRatio() float64 {
+	if t.SampleRatio <= 0 || t.SampleRatio > 1 {
+		return 1
+	}
+
+	return t.SampleRatio
+}
+
+func (t TracingSettings) Validate() error {
+	switch t.GetExporter() {
+	case OTLPTracingExporter:
+	case FileTracingExporter:
+		if t.FilePath == "" {
+			return fmt.Errorf("filePath is required for the file tracing exporter")
+		}
+	default:
+		return fmt.Errorf("unsupported tracing exporter %q", t.Exporter)
+	}
+
+	return nil
+}
+
 type Config struct {
 	KafkaClient KafkaClient               `yaml:"kafkaClient,omitempty"`
 	Mode        Mode                      `yaml:"mode"`
@@ -205,22 +263,29 @@ type Config struct {
 
 	// Supported message queues
 	Kafka *kafkalib.Kafka `yaml:"kafka,omitempty"`
+	NATS  *natslib.NATS   `yaml:"nats,omitempty"`
+	File  *filelib.File   `yaml:"file,omitempty"`
 
 	// Supported destinations
-	BigQuery   *BigQuery    `yaml:"bigquery,omitempty"`
-	Databricks *Databricks  `yaml:"databricks,omitempty"`
-	MSSQL      *MSSQL       `yaml:"mssql,omitempty"`
-	MySQL      *MySQL       `yaml:"mysql,omitempty"`
-	Postgres   *Postgres    `yaml:"postgres,omitempty"`
-	Snowflake  *Snowflake   `yaml:"snowflake,omitempty"`
-	Redshift   *Redshift    `yaml:"redshift,omitempty"`
-	S3         *S3Settings  `yaml:"s3,omitempty"`
-	GCS        *GCSSettings `yaml:"gcs,omitempty"`
-	Iceberg    *Iceberg     `yaml:"iceberg,omitempty"`
-	MotherDuck *MotherDuck  `yaml:"motherduck,omitempty"`
-	Redis      *Redis       `yaml:"redis,omitempty"`
-	Clickhouse *Clickhouse  `yaml:"clickhouse,omitempty"`
-	SQS        *SQSSettings `yaml:"sqs,omitempty"`
+	BigQuery   *BigQuery          `yaml:"bigquery,omitempty"`
+	Databricks *Databricks        `yaml:"databricks,omitempty"`
+	MSSQL      *MSSQL             `yaml:"mssql,omitempty"`
+	MySQL      *MySQL             `yaml:"mysql,omitempty"`
+	Postgres   *Postgres          `yaml:"postgres,omitempty"`
+	Snowflake  *Snowflake         `yaml:"snowflake,omitempty"`
+	Redshift   *Redshift          `yaml:"redshift,omitempty"`
+	S3         *S3Settings        `yaml:"s3,omitempty"`
+	GCS        *GCSSettings       `yaml:"gcs,omitempty"`
+	Iceberg    *Iceberg           `yaml:"iceberg,omitempty"`
+	MotherDuck *MotherDuck        `yaml:"motherduck,omitempty"`
+	Redis      *Redis             `yaml:"redis,omitempty"`
+	Clickhouse *Clickhouse        `yaml:"clickhouse,omitempty"`
+	SQS        *SQSSettings       `yaml:"sqs,omitempty"`
+	KafkaSink  *KafkaSinkSettings `yaml:"kafkaSink,omitempty"`
+	HTTPSink   *HTTPSinkSettings  `yaml:"httpSink,omitempty"`
+	LocalFile  *LocalFileSettings `yaml:"localFile,omitempty"`
+	SQLite     *SQLiteSettings    `yaml:"sqlite,omitempty"`
+	DuckDB     *DuckDBSettings    `yaml:"duckdb,omitempty"`
 
 	SharedDestinationSettings SharedDestinationSettings `yaml:"sharedDestinationSettings"`
 	StagingTableReuse         *StagingTableReuseConfig  `yaml:"stagingTableReuse,omitempty"`
@@ -230,10 +295,128 @@ type Config struct {
 			Provider constants.ExporterKind `yaml:"provider"`
 			Settings map[string]any         `yaml:"settings,omitempty"`
 		}
+		Tracing *TracingSettings `yaml:"tracing,omitempty"`
 	}
 
 	// [WebhookSettings] - This will enable the webhook settings for the transfer.
 	WebhookSettings *WebhookSettings `yaml:"webhookSettings,omitempty"`
+
+	// [AdminServer] - If set, Transfer will serve the health, readiness and admin endpoints.
+	AdminServer *AdminServerSettings `yaml:"adminServer,omitempty"`
+
+	// [Spill] - If enabled, buffered rows past the memory threshold will be written to local files until the table is flushed.
+	Spill *SpillSettings `yaml:"spill,omitempty"`
+
+	// [MemoryBudget] - If set, this caps how much can be buffered in memory across all the topics.
+	MemoryBudget *MemoryBudgetSettings `yaml:"memoryBudget,omitempty"`
+
+	// [GracefulShutdown] - If set, Transfer will flush all the buffered rows and commit the offsets before shutting down.
+	GracefulShutdown *GracefulShutdownSettings `yaml:"gracefulShutdown,omitempty"`
+}
+
+const DefaultGracefulShutdownTimeout = time.Minute
+
+type GracefulShutdownSettings struct {
+	// [TimeoutSeconds] - How long to wait for the buffers to be flushed, defaults to 60 seconds.
+	// This should be lower than the orchestrator's termination grace period, else the process will be killed before it can leave the consumer group.
+	TimeoutSeconds int `yaml:"timeoutSeconds,omitempty"`
+}
+
+func (g GracefulShutdownSettings) GetTimeout() time.Duration {
+	if g.TimeoutSeconds == 0 {
+		return DefaultGracefulShutdownTimeout
+	}
+
+	return time.Duration(g.TimeoutSeconds) * time.Second
+}
+
+func (g GracefulShutdownSettings) Validate() error {
+	if g.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeoutSeconds must be greater than or equal to 0, got: %d", g.TimeoutSeconds)
+	}
+
+	return nil
+}
+
+const DefaultMemoryBudgetResumePercent = 75
+
+type MemoryBudgetSettings struct {
+	// [LimitMb] - Once the buffered rows across all the tables exceed this, fetching is paused and the largest tables are flushed.
+	LimitMb int `yaml:"limitMb"`
+	// [ResumePercent] - Tables will be flushed until the usage drops below this percentage of [LimitMb], defaults to 75.
+	ResumePercent int `yaml:"resumePercent,omitempty"`
+}
+
+func (m MemoryBudgetSettings) LimitBytes() int64 {
+	return int64(m.LimitMb) * 1024 * 1024
+}
+
+func (m MemoryBudgetSettings) ResumeBytes() int64 {
+	return m.LimitBytes() * int64(cmp.Or(m.ResumePercent, DefaultMemoryBudgetResumePercent)) / 100
+}
+
+func (m MemoryBudgetSettings) Validate() error {
+	if m.LimitMb <= 0 {
+		return fmt.Errorf("limitMb must be greater than 0")
+	}
+
+	if m.ResumePercent < 0 || m.ResumePercent > 100 {
+		return fmt.Errorf("resumePercent must be between 0 and 100, got: %d", m.ResumePercent)
+	}
+
+	return nil
+}
+
+type SpillSettings struct {
+	Enabled bool `yaml:"enabled"`
+	// [Directory] - Where the spill files are written to, defaults to [os.TempDir].
+	Directory string `yaml:"directory,omitempty"`
+	// [MemoryThresholdKb] - Once a table has buffered this much in memory, the rows will be moved to disk.
+	MemoryThresholdKb int `yaml:"memoryThresholdKb"`
+}
+
+func (s SpillSettings) GetDirectory() string {
+	if s.Directory == "" {
+		return os.TempDir()
+	}
+
+	return s.Directory
+}
+
+func (s SpillSettings) Validate() error {
+	if s.MemoryThresholdKb <= 0 {
+		return fmt.Errorf("memoryThresholdKb must be greater than 0")
+	}
+
+	if s.Directory != "" {
+		info, err := os.Stat(s.Directory)
+		if err != nil {
+			return fmt.Errorf("failed to stat directory %q: %w", s.Directory, err)
+		}
+
+		if !info.IsDir() {
+			return fmt.Errorf("%q is not a directory", s.Directory)
+		}
+	}
+
+	return nil
+}
+
+// DefaultAdminServerAddr only listens on loopback, set [AdminServerSettings.Addr] to expose the admin server.
+const DefaultAdminServerAddr = "127.0.0.1:8080"
+
+type AdminServerSettings struct {
+	Addr string `yaml:"addr,omitempty"`
+	// [BearerToken] - If set, endpoints that mutate state (e.g. POST /flush) require an `Authorization: Bearer <token>` header.
+	BearerToken string `yaml:"bearerToken,omitempty"`
+}
+
+func (a AdminServerSettings) GetAddr() string {
+	if a.Addr == "" {
+		return DefaultAdminServerAddr
+	}
+
+	return a.Addr
 }
 
 type WebhookSettings struct {
@@ -3,50 +3,67 @@ package csvwriter
 import (
 	"compress/gzip"
 	"encoding/csv"
+	"io"
 	"os"
 	"path/filepath"
 )
 
-type GzipWriter struct {
+// GzipWriter writes tab separated, gzip compressed CSV files.
+type GzipWriter = Writer
+
+func NewGzipWriter(fp string) (*GzipWriter, error) {
+	return NewWriter(fp, '\t', true)
+}
+
+// Writer writes CSV rows to a file, if [compress] is set the file will be gzip compressed.
+type Writer struct {
 	file   *os.File
 	gzip   *gzip.Writer
 	writer *csv.Writer
 }
 
-func NewGzipWriter(fp string) (*GzipWriter, error) {
+func NewWriter(fp string, comma rune, compress bool) (*Writer, error) {
 	file, err := os.Create(fp)
 	if err != nil {
 		return nil, err
 	}
 
-	gzipWriter := gzip.NewWriter(file)
-	csvWriter := csv.NewWriter(gzipWriter)
-	csvWriter.Comma = '\t'
-	return &GzipWriter{
+	var out io.Writer = file
+	var gzipWriter *gzip.Writer
+	if compress {
+		gzipWriter = gzip.NewWriter(file)
+		out = gzipWriter
+	}
+
+	csvWriter := csv.NewWriter(out)
+	csvWriter.Comma = comma
+	return &Writer{
 		file:   file,
 		gzip:   gzipWriter,
 		writer: csvWriter,
 	}, nil
 }
 
-func (g *GzipWriter) FileName() string {
-	return filepath.Base(g.file.Name())
+func (w *Writer) FileName() string {
+	return filepath.Base(w.file.Name())
 }
 
-func (g *GzipWriter) Write(row []string) error {
-	return g.writer.Write(row)
+func (w *Writer) Write(row []string) error {
+	return w.writer.Write(row)
 }
 
-func (g *GzipWriter) Flush() error {
-	g.writer.Flush()
-	return g.writer.Error()
+func (w *Writer) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
 }
 
-func (g *GzipWriter) Close() error {
-	if err := g.gzip.Close(); err != nil {
-		// If closing the gzip writer fails, we should still try to close the file.
-		_ = g.file.Close()
-		return err
+func (w *Writer) Close() error {
+	if w.gzip != nil {
+		if err := w.gzip.Close(); err != nil {
+			// If closing the gzip writer fails, we should still try to close the file.
+			_ = w.file.Close()
+			return err
+		}
 	}
-	return g.file.Close()
+	return w.file.Close()
 }
@@ -92,3 +92,18 @@ func TestGzipWriterLargeData(t *testing.T) {
 		}
 	}
 }
+
+func TestWriter_Uncompressed(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "test.csv")
+	writer, err := NewWriter(filePath, ',', false)
+	assert.NoError(t, err)
+
+	assert.NoError(t, writer.Write([]string{"id", "name"}))
+	assert.NoError(t, writer.Write([]string{"1", "hello,dusty"}))
+	assert.NoError(t, writer.Flush())
+	assert.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "id,name\n1,\"hello,dusty\"\n", string(data))
+}
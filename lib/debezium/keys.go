@@ -120,6 +120,11 @@ func parsePartitionKeyStruct(keyBytes []byte, reservedColumns map[string]bool) (
 		return nil, fmt.Errorf("failed to json unmarshal into PrimaryKeyPayload: %w", err)
 	}
 
+	return ParsePrimaryKeyPayload(primaryKeyPayload, reservedColumns)
+}
+
+// ParsePrimaryKeyPayload is used when the key has already been decoded alongside its schema, e.g. from Avro or Protobuf.
+func ParsePrimaryKeyPayload(primaryKeyPayload PrimaryKeyPayload, reservedColumns map[string]bool) (map[string]any, error) {
 	keys, err := primaryKeyPayload.parseAndReturnPayload()
 	if err != nil {
 		return nil, err
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
@@ -47,6 +48,18 @@ type Destination interface {
 	IsOLTP() bool
 }
 
+// FlushDeferrer can be implemented by destinations that would rather keep buffering than write small batches (e.g. object storage).
+// It is only consulted for time-based flushes.
+type FlushDeferrer interface {
+	ShouldDeferFlush(tableData *optimization.TableData, lastFlushTime time.Time) bool
+}
+
+// ConnectivityChecker can be implemented by destinations that don't connect when they're loaded (e.g. object storage).
+// It is used by the validate command, so it must not write anything to the destination.
+type ConnectivityChecker interface {
+	CheckConnectivity(ctx context.Context) error
+}
+
 // ExecContextStatements executes one or more statements against a [SQLDestination].
 // If there is more than one statement, the statements will be executed inside of a transaction.
 func ExecContextStatements(ctx context.Context, dest SQLDestination, statements []string) ([]sql.Result, error) {
@@ -7,8 +7,12 @@ import (
 	"github.com/artie-labs/transfer/clients/bigquery"
 	"github.com/artie-labs/transfer/clients/clickhouse"
 	"github.com/artie-labs/transfer/clients/databricks"
+	"github.com/artie-labs/transfer/clients/duckdb"
 	"github.com/artie-labs/transfer/clients/gcs"
+	"github.com/artie-labs/transfer/clients/httpsink"
 	"github.com/artie-labs/transfer/clients/iceberg"
+	"github.com/artie-labs/transfer/clients/kafka"
+	"github.com/artie-labs/transfer/clients/localfile"
 	"github.com/artie-labs/transfer/clients/motherduck"
 	"github.com/artie-labs/transfer/clients/mssql"
 	"github.com/artie-labs/transfer/clients/mysql"
@@ -17,6 +21,7 @@ import (
 	"github.com/artie-labs/transfer/clients/redshift"
 	"github.com/artie-labs/transfer/clients/s3"
 	"github.com/artie-labs/transfer/clients/snowflake"
+	"github.com/artie-labs/transfer/clients/sqlite"
 	"github.com/artie-labs/transfer/clients/sqs"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
@@ -44,8 +49,12 @@ func Load(ctx context.Context, cfg config.Config) (destination.Destination, erro
 		return redshift.LoadStore(ctx, cfg, nil)
 	case constants.MotherDuck:
 		return motherduck.LoadStore(ctx, cfg)
+	case constants.DuckDB:
+		return duckdb.LoadStore(ctx, cfg)
 	case constants.Clickhouse:
 		return clickhouse.LoadStore(ctx, cfg, nil)
+	case constants.SQLite:
+		return sqlite.LoadStore(ctx, cfg)
 
 	// Object storage destinations
 	case constants.S3:
@@ -53,17 +62,27 @@ func Load(ctx context.Context, cfg config.Config) (destination.Destination, erro
 	case constants.GCS:
 		return gcs.LoadStore(ctx, cfg)
 	case constants.Iceberg:
+		if cfg.Iceberg != nil && cfg.Iceberg.Writer == config.IcebergWriterNative {
+			return iceberg.LoadNativeStore(ctx, cfg)
+		}
+
 		store, err := iceberg.LoadStore(ctx, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return store, nil
+	case constants.LocalFile:
+		return localfile.LoadStore(ctx, cfg)
 
 	// Streaming destinations
 	case constants.Redis:
 		return redis.LoadStore(ctx, cfg)
 	case constants.SQS:
 		return sqs.LoadStore(ctx, cfg)
+	case constants.KafkaSink:
+		return kafka.LoadStore(ctx, cfg)
+	case constants.HTTPSink:
+		return httpsink.LoadStore(ctx, cfg)
 	}
 
 	return nil, fmt.Errorf("invalid destination: %q", cfg.Output)
@@ -84,3 +103,51 @@ func LoadSQLDestination(ctx context.Context, cfg config.Config) (destination.SQL
 
 	return sqlDest, nil
 }
+
+// LoadReadOnlySQLDestination is like [LoadSQLDestination], but it skips any set up that writes to the destination (e.g. creating
+// schemas, stages or database files). This is used by commands like plan that must not change anything.
+func LoadReadOnlySQLDestination(ctx context.Context, cfg config.Config) (destination.SQLDestination, error) {
+	switch cfg.Output {
+	case constants.Snowflake:
+		return snowflake.LoadReadOnlyStore(cfg)
+	case constants.DuckDB:
+		return duckdb.LoadReadOnlyStore(ctx, cfg)
+	case constants.SQLite:
+		return sqlite.LoadReadOnlyStore(ctx, cfg)
+	case constants.BigQuery, constants.Databricks, constants.MSSQL, constants.MySQL, constants.Postgres, constants.Redshift, constants.MotherDuck, constants.Clickhouse:
+		// These only connect to the destination when they're loaded.
+		return LoadSQLDestination(ctx, cfg)
+	}
+
+	return nil, fmt.Errorf("destination %q is not a SQL destination", cfg.Output)
+}
+
+// CheckConnectivity loads the destination without any set up that writes to it and checks that we can reach it.
+// SQL destinations are checked when they're loaded, the others are checked with [destination.ConnectivityChecker].
+func CheckConnectivity(ctx context.Context, cfg config.Config) error {
+	var dest destination.Destination
+	var err error
+	switch cfg.Output {
+	case constants.Iceberg:
+		if cfg.Iceberg != nil && cfg.Iceberg.Writer == config.IcebergWriterNative {
+			dest, err = iceberg.LoadReadOnlyNativeStore(ctx, cfg)
+		} else {
+			dest, err = iceberg.LoadReadOnlyStore(ctx, cfg)
+		}
+	case constants.LocalFile:
+		dest, err = localfile.LoadReadOnlyStore(cfg)
+	case constants.S3, constants.GCS, constants.Redis, constants.SQS, constants.KafkaSink, constants.HTTPSink:
+		// These don't write anything when they're loaded.
+		dest, err = Load(ctx, cfg)
+	default:
+		dest, err = LoadReadOnlySQLDestination(ctx, cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if checker, ok := dest.(destination.ConnectivityChecker); ok {
+		return checker.CheckConnectivity(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package dlq
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker keeps track of failures within a rolling window.
+type CircuitBreaker struct {
+	mu          sync.Mutex
+	maxFailures int
+	window      time.Duration
+	failures    []time.Time
+	now         func() time.Time
+}
+
+func NewCircuitBreaker(maxFailures int, window time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		maxFailures: maxFailures,
+		window:      window,
+		now:         time.Now,
+	}
+}
+
+// Record adds a failure and returns an error if there have been more than [maxFailures] within the window.
+func (c *CircuitBreaker) Record() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	cutoff := now.Add(-c.window)
+
+	var idx int
+	for idx < len(c.failures) && !c.failures[idx].After(cutoff) {
+		idx++
+	}
+
+	c.failures = append(c.failures[idx:], now)
+	if len(c.failures) > c.maxFailures {
+		return fmt.Errorf("dead-letter queue circuit breaker tripped: %d failures within %s, max: %d", len(c.failures), c.window, c.maxFailures)
+	}
+
+	return nil
+}
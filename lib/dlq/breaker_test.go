@@ -0,0 +1,23 @@
+package dlq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_Record(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	assert.NoError(t, breaker.Record())
+	assert.NoError(t, breaker.Record())
+	assert.ErrorContains(t, breaker.Record(), "circuit breaker tripped: 3 failures within 1m0s, max: 2")
+
+	// Once the window has passed, the older failures should be evicted.
+	now = now.Add(61 * time.Second)
+	assert.NoError(t, breaker.Record())
+	assert.Len(t, breaker.failures, 1)
+}
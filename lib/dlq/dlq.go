@@ -0,0 +1,100 @@
+package dlq
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+// Record is what gets written to the dead-letter queue for every message that failed processing.
+type Record struct {
+	Topic     string         `json:"topic"`
+	Partition int            `json:"partition"`
+	Offset    int64          `json:"offset"`
+	Key       []byte         `json:"key"`
+	Value     []byte         `json:"value"`
+	Headers   []artie.Header `json:"headers,omitempty"`
+	Error     string         `json:"error"`
+	// [PublishTime] - The timestamp of the original message.
+	PublishTime time.Time `json:"publishTime"`
+	FailedAt    time.Time `json:"failedAt"`
+}
+
+func NewRecord(msg artie.Message, err error) Record {
+	return Record{
+		Topic:       msg.Topic(),
+		Partition:   msg.Partition(),
+		Offset:      msg.Offset(),
+		Key:         msg.Key(),
+		Value:       msg.Value(),
+		Headers:     msg.Headers(),
+		Error:       err.Error(),
+		PublishTime: msg.PublishTime(),
+		FailedAt:    time.Now().UTC(),
+	}
+}
+
+type Sink interface {
+	Write(ctx context.Context, record Record) error
+	Close() error
+}
+
+// Policy wraps a [Sink] with a circuit breaker, so that a flood of bad messages will still stop the consumer.
+type Policy struct {
+	sink    Sink
+	breaker *CircuitBreaker
+}
+
+func NewPolicy(sink Sink, breaker *CircuitBreaker) *Policy {
+	return &Policy{sink: sink, breaker: breaker}
+}
+
+// LoadPolicy returns nil if the dead-letter queue has not been enabled for this topic.
+func LoadPolicy(ctx context.Context, kafkaCfg *kafkalib.Kafka, settings *kafkalib.DeadLetterQueueSettings) (*Policy, error) {
+	if settings == nil || !settings.Enabled {
+		return nil, nil
+	}
+
+	var sink Sink
+	var err error
+	switch settings.Kind {
+	case kafkalib.DeadLetterQueueKafka:
+		sink, err = NewKafkaSink(ctx, kafkaCfg, settings.Topic)
+	case kafkalib.DeadLetterQueueFile:
+		sink, err = NewFileSink(settings.Directory)
+	case kafkalib.DeadLetterQueueS3:
+		sink, err = NewS3Sink(ctx, *settings)
+	default:
+		return nil, fmt.Errorf("invalid dead-letter queue kind: %q", settings.Kind)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %q dead-letter queue sink: %w", settings.Kind, err)
+	}
+
+	return NewPolicy(sink, NewCircuitBreaker(settings.GetMaxFailures(), settings.GetWindow())), nil
+}
+
+// Handle will write the failed message to the dead-letter queue.
+// An error is returned if the message could not be written or if the circuit breaker has tripped, callers should treat this as fatal.
+func (p *Policy) Handle(ctx context.Context, msg artie.Message, processErr error) error {
+	if err := p.breaker.Record(); err != nil {
+		return err
+	}
+
+	if err := p.sink.Write(ctx, NewRecord(msg, processErr)); err != nil {
+		return fmt.Errorf("failed to write to dead-letter queue: %w", err)
+	}
+
+	slog.Warn("Message sent to dead-letter queue", slog.String("topic", msg.Topic()), slog.Int("partition", msg.Partition()),
+		slog.Int64("offset", msg.Offset()), slog.Any("err", processErr))
+	return nil
+}
+
+func (p *Policy) Close() error {
+	return p.sink.Close()
+}
@@ -0,0 +1,96 @@
+package dlq
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/artie-labs/transfer/lib/artie"
+)
+
+func newTestMessage(offset int64) artie.Message {
+	return artie.NewFranzGoMessage(kgo.Record{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    offset,
+		Key:       []byte(`{"id": 1}`),
+		Value:     []byte(`not json`),
+		Headers:   []kgo.RecordHeader{{Key: "traceparent", Value: []byte("abc")}},
+		Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, 0)
+}
+
+func TestNewRecord(t *testing.T) {
+	record := NewRecord(newTestMessage(5), fmt.Errorf("cannot unmarshal event"))
+	assert.Equal(t, "orders", record.Topic)
+	assert.Equal(t, 2, record.Partition)
+	assert.Equal(t, int64(5), record.Offset)
+	assert.Equal(t, `{"id": 1}`, string(record.Key))
+	assert.Equal(t, "not json", string(record.Value))
+	assert.Equal(t, []artie.Header{{Key: "traceparent", Value: []byte("abc")}}, record.Headers)
+	assert.Equal(t, "cannot unmarshal event", record.Error)
+	assert.False(t, record.FailedAt.IsZero())
+}
+
+func TestBuildKafkaRecord(t *testing.T) {
+	kafkaRecord := BuildKafkaRecord("orders.dlq", NewRecord(newTestMessage(5), fmt.Errorf("boom")))
+	assert.Equal(t, "orders.dlq", kafkaRecord.Topic)
+	assert.Equal(t, `{"id": 1}`, string(kafkaRecord.Key))
+	assert.Equal(t, "not json", string(kafkaRecord.Value))
+
+	headers := make(map[string]string)
+	for _, header := range kafkaRecord.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+
+	assert.Equal(t, map[string]string{
+		"traceparent":   "abc",
+		HeaderTopic:     "orders",
+		HeaderPartition: "2",
+		HeaderOffset:    "5",
+		HeaderError:     "boom",
+	}, headers)
+}
+
+func TestS3Sink_ObjectKey(t *testing.T) {
+	sink := S3Sink{prefix: "dlq"}
+	assert.Equal(t, "dlq/orders/partition=2/5.ndjson", sink.ObjectKey(NewRecord(newTestMessage(5), fmt.Errorf("boom"))))
+}
+
+func TestPolicy_Handle(t *testing.T) {
+	sink, err := NewFileSink(t.TempDir())
+	assert.NoError(t, err)
+
+	policy := NewPolicy(sink, NewCircuitBreaker(2, time.Minute))
+	ctx := context.Background()
+	assert.NoError(t, policy.Handle(ctx, newTestMessage(1), fmt.Errorf("first")))
+	assert.NoError(t, policy.Handle(ctx, newTestMessage(2), fmt.Errorf("second")))
+	assert.ErrorContains(t, policy.Handle(ctx, newTestMessage(3), fmt.Errorf("third")), "circuit breaker tripped")
+	assert.NoError(t, policy.Close())
+
+	file, err := os.Open(sink.FilePath("orders"))
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Record
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+
+	// The third message should not have been written since the breaker tripped.
+	assert.Len(t, records, 2)
+	assert.Equal(t, int64(1), records[0].Offset)
+	assert.Equal(t, "first", records[0].Error)
+	assert.Equal(t, "not json", string(records[0].Value))
+	assert.Equal(t, int64(2), records[1].Offset)
+}
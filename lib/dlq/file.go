@@ -0,0 +1,70 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSink appends records as NDJSON into a file per topic inside [directory].
+type FileSink struct {
+	mu        sync.Mutex
+	directory string
+	files     map[string]*os.File
+}
+
+func NewFileSink(directory string) (*FileSink, error) {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %q: %w", directory, err)
+	}
+
+	return &FileSink{directory: directory, files: make(map[string]*os.File)}, nil
+}
+
+func (f *FileSink) FilePath(topic string) string {
+	return filepath.Join(f.directory, fmt.Sprintf("%s.ndjson", topic))
+}
+
+func (f *FileSink) Write(_ context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, ok := f.files[record.Topic]
+	if !ok {
+		file, err = os.OpenFile(f.FilePath(record.Topic), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
+		}
+
+		f.files[record.Topic] = file
+	}
+
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+
+	return file.Sync()
+}
+
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var closeErr error
+	for topic, file := range f.files {
+		if err := file.Close(); err != nil {
+			closeErr = fmt.Errorf("failed to close file for topic %q: %w", topic, err)
+		}
+		delete(f.files, topic)
+	}
+
+	return closeErr
+}
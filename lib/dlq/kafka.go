@@ -0,0 +1,77 @@
+package dlq
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+const (
+	HeaderTopic     = "artie.dlq.topic"
+	HeaderPartition = "artie.dlq.partition"
+	HeaderOffset    = "artie.dlq.offset"
+	HeaderError     = "artie.dlq.error"
+)
+
+// KafkaSink re-publishes the original key and value to [topic], the failure details are attached as headers.
+type KafkaSink struct {
+	topic  string
+	client *kgo.Client
+}
+
+func NewKafkaSink(ctx context.Context, cfg *kafkalib.Kafka, topic string) (*KafkaSink, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("kafka config is nil")
+	}
+
+	kafkaConn := kafkalib.NewConnection(cfg.EnableAWSMSKIAM, cfg.DisableTLS, cfg.Username, cfg.Password, kafkalib.DefaultTimeout)
+	clientOpts, err := kafkaConn.ClientOptions(ctx, cfg.BootstrapServers(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client options: %w", err)
+	}
+
+	client, err := kgo.NewClient(append(clientOpts, kgo.DefaultProduceTopic(topic))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+
+	return &KafkaSink{topic: topic, client: client}, nil
+}
+
+func BuildKafkaRecord(topic string, record Record) *kgo.Record {
+	headers := make([]kgo.RecordHeader, 0, len(record.Headers)+4)
+	for _, header := range record.Headers {
+		headers = append(headers, kgo.RecordHeader{Key: header.Key, Value: header.Value})
+	}
+
+	headers = append(headers,
+		kgo.RecordHeader{Key: HeaderTopic, Value: []byte(record.Topic)},
+		kgo.RecordHeader{Key: HeaderPartition, Value: []byte(strconv.Itoa(record.Partition))},
+		kgo.RecordHeader{Key: HeaderOffset, Value: []byte(strconv.FormatInt(record.Offset, 10))},
+		kgo.RecordHeader{Key: HeaderError, Value: []byte(record.Error)},
+	)
+
+	return &kgo.Record{
+		Topic:   topic,
+		Key:     record.Key,
+		Value:   record.Value,
+		Headers: headers,
+	}
+}
+
+func (k *KafkaSink) Write(ctx context.Context, record Record) error {
+	if err := k.client.ProduceSync(ctx, BuildKafkaRecord(k.topic, record)).FirstErr(); err != nil {
+		return fmt.Errorf("failed to produce to %q: %w", k.topic, err)
+	}
+
+	return nil
+}
+
+func (k *KafkaSink) Close() error {
+	k.client.Close()
+	return nil
+}
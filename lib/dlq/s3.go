@@ -0,0 +1,62 @@
+package dlq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+
+	"github.com/artie-labs/transfer/lib/awslib"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+// S3Sink writes every record as its own NDJSON object, so that nothing is lost if the process crashes.
+type S3Sink struct {
+	bucket string
+	prefix string
+	client awslib.S3Client
+}
+
+func NewS3Sink(ctx context.Context, settings kafkalib.DeadLetterQueueSettings) (*S3Sink, error) {
+	awsCfg, err := awslib.NewDefaultConfig(ctx, settings.AwsRegion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	if settings.AwsAccessKeyID != "" && settings.AwsSecretAccessKey != "" {
+		awsCfg = awslib.NewConfigWithCredentialsAndRegion(
+			credentials.NewStaticCredentialsProvider(settings.AwsAccessKeyID, settings.AwsSecretAccessKey, ""),
+			settings.AwsRegion,
+		)
+	}
+
+	return &S3Sink{
+		bucket: settings.Bucket,
+		prefix: settings.Prefix,
+		client: awslib.NewS3Client(awsCfg),
+	}, nil
+}
+
+func (s *S3Sink) ObjectKey(record Record) string {
+	return path.Join(s.prefix, record.Topic, fmt.Sprintf("partition=%d", record.Partition), fmt.Sprintf("%d.ndjson", record.Offset))
+}
+
+func (s *S3Sink) Write(ctx context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	if _, err = s.client.PutObject(ctx, s.bucket, s.ObjectKey(record), bytes.NewReader(append(line, '\n'))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *S3Sink) Close() error {
+	return nil
+}
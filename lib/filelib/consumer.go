@@ -0,0 +1,195 @@
+package filelib
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+// groupID is reported in metrics and logs in place of the Kafka group ID.
+const groupID = "file"
+
+// Consumer replays the records for a single topic ordered by partition and offset.
+// Offsets are committed in memory, so replaying the same files always starts from the first record.
+type Consumer struct {
+	mu       sync.Mutex
+	topic    string
+	msgs     []artie.Message
+	position int
+	paused   atomic.Bool
+	// exhausted is set once every record has been processed.
+	exhausted atomic.Bool
+	// skipped is the number of records that were skipped because a record with the same or a later offset had already been processed.
+	skipped int
+
+	partitionToAppliedOffset map[int]artie.Message
+	committedOffsets         map[int]int64
+}
+
+func newConsumer(topic string, msgs []artie.Message) *Consumer {
+	return &Consumer{
+		topic:                    topic,
+		msgs:                     msgs,
+		partitionToAppliedOffset: make(map[int]artie.Message),
+		committedOffsets:         make(map[int]int64),
+	}
+}
+
+// NewConsumer reads the records for [topicConfig] from every file in [cfg].
+func NewConsumer(cfg *File, topicConfig kafkalib.TopicConfig) (*Consumer, error) {
+	msgs, err := ReadFiles(cfg.Paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	var topicMsgs []artie.Message
+	for _, msg := range msgs {
+		if msg.Topic() == topicConfig.Topic {
+			topicMsgs = append(topicMsgs, msg)
+		}
+	}
+
+	return newConsumer(topicConfig.Topic, topicMsgs), nil
+}
+
+// InjectConsumersIntoContext reads every file once and creates a consumer for each topic config.
+func InjectConsumersIntoContext(ctx context.Context, cfg *File) (context.Context, error) {
+	msgs, err := ReadFiles(cfg.Paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	topicToMsgs := make(map[string][]artie.Message)
+	for _, msg := range msgs {
+		topicToMsgs[msg.Topic()] = append(topicToMsgs[msg.Topic()], msg)
+	}
+
+	registry := kafkalib.NewConsumerRegistry()
+	for _, topicConfig := range cfg.TopicConfigs {
+		registry.Add(newConsumer(topicConfig.Topic, topicToMsgs[topicConfig.Topic]))
+		slog.Info("Created file consumer for topic", slog.String("topic", topicConfig.Topic), slog.Int("records", len(topicToMsgs[topicConfig.Topic])))
+		delete(topicToMsgs, topicConfig.Topic)
+	}
+
+	for _, topic := range slices.Sorted(maps.Keys(topicToMsgs)) {
+		slog.Warn("Skipping records for topic without a topic config", slog.String("topic", topic), slog.Int("records", len(topicToMsgs[topic])))
+	}
+
+	return kafkalib.InjectConsumerRegistryIntoContext(ctx, registry), nil
+}
+
+func (c *Consumer) Topic() string {
+	return c.topic
+}
+
+func (c *Consumer) GetGroupID() string {
+	return groupID
+}
+
+// AssignedPartitions returns every partition that has records, all of them are read by this consumer.
+func (c *Consumer) AssignedPartitions() ([]int32, bool) {
+	var partitions []int32
+	for _, msg := range c.msgs {
+		if !slices.Contains(partitions, int32(msg.Partition())) {
+			partitions = append(partitions, int32(msg.Partition()))
+		}
+	}
+
+	slices.Sort(partitions)
+	return partitions, true
+}
+
+func (c *Consumer) PauseFetching() {
+	c.paused.Store(true)
+}
+
+func (c *Consumer) ResumeFetching() {
+	c.paused.Store(false)
+}
+
+// WaitForTopic is a no-op, the files have already been read.
+func (c *Consumer) WaitForTopic(_ context.Context) error {
+	return nil
+}
+
+func (c *Consumer) Close() error {
+	return nil
+}
+
+// Exhausted returns true once every record has been processed, the rows may still be buffered in memory.
+func (c *Consumer) Exhausted() bool {
+	return c.exhausted.Load()
+}
+
+// FetchMessageAndProcess returns [kafkalib.ErrNoMessages] once every record has been processed.
+func (c *Consumer) FetchMessageAndProcess(_ context.Context, do func(artie.Message) error) error {
+	if c.paused.Load() {
+		return kafkalib.NewFetchMessageError(kafkalib.ErrNoMessages)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.position >= len(c.msgs) {
+		if !c.exhausted.Swap(true) && c.skipped > 0 {
+			slog.Warn("Skipped records that overlap with records that were already processed", slog.String("topic", c.topic), slog.Int("skipped", c.skipped))
+		}
+		return kafkalib.NewFetchMessageError(kafkalib.ErrNoMessages)
+	}
+
+	msg := c.msgs[c.position]
+	if appliedMsg, ok := c.partitionToAppliedOffset[msg.Partition()]; ok && appliedMsg.Offset() >= msg.Offset() {
+		// Dumps can overlap, skip records that have already been processed.
+		c.position++
+		c.skipped++
+		return nil
+	}
+
+	if err := do(msg); err != nil {
+		return fmt.Errorf("failed to process message: %w", err)
+	}
+
+	c.position++
+	c.partitionToAppliedOffset[msg.Partition()] = msg
+	return nil
+}
+
+func (c *Consumer) LockAndProcess(_ context.Context, lock bool, do func() error) error {
+	if lock {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+
+	if err := do(); err != nil {
+		return fmt.Errorf("failed to process: %w", err)
+	}
+
+	return nil
+}
+
+// CommitMessage records the next offset to read for every partition, mirroring what Kafka would commit.
+func (c *Consumer) CommitMessage(_ context.Context) error {
+	for partition, msg := range c.partitionToAppliedOffset {
+		c.committedOffsets[partition] = msg.Offset() + 1
+	}
+
+	slog.Info("Committed messages", slog.String("topic", c.topic), slog.Any("partitionToOffset", c.committedOffsets))
+	return nil
+}
+
+// CommittedOffsets returns the committed offset for every partition, this is the offset of the next record to read.
+func (c *Consumer) CommittedOffsets() map[int]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return maps.Clone(c.committedOffsets)
+}
+
+var _ kafkalib.QueueConsumer = (*Consumer)(nil)
@@ -0,0 +1,123 @@
+package filelib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+func writeDump(t *testing.T) *File {
+	path := filepath.Join(t.TempDir(), "dump.ndjson")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"topic": "orders", "partition": 0, "offset": 0, "value": "a"}
+{"topic": "orders", "partition": 1, "offset": 0, "value": "b"}
+{"topic": "orders", "partition": 0, "offset": 1, "value": "c"}
+{"topic": "orders", "partition": 0, "offset": 1, "value": "c"}
+{"topic": "customers", "partition": 0, "offset": 0, "value": "d"}
+{"topic": "unknown", "partition": 0, "offset": 0, "value": "e"}
+`), 0o644))
+
+	return &File{
+		Paths:        []string{path},
+		TopicConfigs: []*kafkalib.TopicConfig{{Topic: "orders"}, {Topic: "customers"}},
+	}
+}
+
+func fetch(t *testing.T, consumer *Consumer) []artie.Message {
+	var msgs []artie.Message
+	for {
+		err := consumer.FetchMessageAndProcess(t.Context(), func(msg artie.Message) error {
+			msgs = append(msgs, msg)
+			return nil
+		})
+		if err != nil {
+			assert.ErrorIs(t, err, kafkalib.ErrNoMessages)
+			return msgs
+		}
+	}
+}
+
+func TestFile_Validate(t *testing.T) {
+	{
+		// Nil
+		var cfg *File
+		assert.ErrorContains(t, cfg.Validate(), "file config is nil")
+	}
+	{
+		// Missing paths
+		assert.ErrorContains(t, (&File{}).Validate(), "file paths are empty")
+	}
+	{
+		// Valid
+		assert.NoError(t, (&File{Paths: []string{"dump.ndjson"}}).Validate())
+	}
+}
+
+func TestConsumer(t *testing.T) {
+	consumer, err := NewConsumer(writeDump(t), kafkalib.TopicConfig{Topic: "orders"})
+	assert.NoError(t, err)
+	assert.Equal(t, "orders", consumer.Topic())
+	assert.Equal(t, "file", consumer.GetGroupID())
+
+	partitions, joinedGroup := consumer.AssignedPartitions()
+	assert.Equal(t, []int32{0, 1}, partitions)
+	assert.True(t, joinedGroup)
+	{
+		// Paused
+		consumer.PauseFetching()
+		assert.Empty(t, fetch(t, consumer))
+		assert.False(t, consumer.Exhausted())
+		consumer.ResumeFetching()
+	}
+	{
+		// Duplicate records are skipped
+		msgs := fetch(t, consumer)
+		assert.Len(t, msgs, 3)
+		assert.Equal(t, "a", string(msgs[0].Value()))
+		assert.Equal(t, "c", string(msgs[1].Value()))
+		assert.Equal(t, "b", string(msgs[2].Value()))
+		assert.Equal(t, 1, consumer.skipped)
+		assert.True(t, consumer.Exhausted())
+	}
+	{
+		// Offsets are only committed once the topic has been flushed
+		assert.Empty(t, consumer.CommittedOffsets())
+		assert.NoError(t, consumer.CommitMessage(t.Context()))
+		assert.Equal(t, map[int]int64{0: 2, 1: 1}, consumer.CommittedOffsets())
+	}
+}
+
+func TestConsumer_RetriesFailedRecords(t *testing.T) {
+	consumer, err := NewConsumer(writeDump(t), kafkalib.TopicConfig{Topic: "customers"})
+	assert.NoError(t, err)
+
+	err = consumer.FetchMessageAndProcess(t.Context(), func(msg artie.Message) error {
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	msgs := fetch(t, consumer)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, "d", string(msgs[0].Value()))
+}
+
+func TestInjectConsumersIntoContext(t *testing.T) {
+	ctx, err := InjectConsumersIntoContext(t.Context(), writeDump(t))
+	assert.NoError(t, err)
+
+	topics, ok := kafkalib.GetTopicsFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"customers", "orders"}, topics)
+
+	queueConsumer, err := kafkalib.GetConsumerFromContext(ctx, "orders")
+	assert.NoError(t, err)
+	assert.Len(t, fetch(t, queueConsumer.(*Consumer)), 3)
+
+	// The files have to exist
+	_, err = InjectConsumersIntoContext(t.Context(), &File{Paths: []string{filepath.Join(t.TempDir(), "missing.ndjson")}})
+	assert.ErrorContains(t, err, "failed to open")
+}
@@ -0,0 +1,36 @@
+package filelib
+
+import (
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+// File replays records from NDJSON files instead of consuming from a message queue, see [Record] for the format.
+// This is used to reproduce incidents and as regression fixtures, Transfer shuts down once every record has been flushed.
+type File struct {
+	// Paths are read in order, records for topics that do not have a topic config are skipped.
+	Paths        []string                `yaml:"paths"`
+	TopicConfigs []*kafkalib.TopicConfig `yaml:"topicConfigs"`
+}
+
+func (f *File) Validate() error {
+	if f == nil {
+		return fmt.Errorf("file config is nil")
+	}
+
+	if len(f.Paths) == 0 {
+		return fmt.Errorf("file paths are empty")
+	}
+
+	return nil
+}
+
+func (f *File) Topics() []string {
+	var out []string
+	for _, config := range f.TopicConfigs {
+		out = append(out, config.Topic)
+	}
+
+	return out
+}
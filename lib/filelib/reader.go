@@ -0,0 +1,179 @@
+package filelib
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+// Record is a single message in an NDJSON dump. Both our own format and the output of `kcat -C -J` are supported:
+//
+//	{"topic": "orders", "partition": 0, "offset": 10, "key": "...", "value": {...}, "timestamp": 1700000000000}
+//	{"topic": "orders", "partition": 0, "offset": 10, "key": "...", "payload": "...", "ts": 1700000000000}
+//
+// Keys and values that are JSON strings are used as is, anything else (e.g. an object) is used as raw JSON.
+// The offset is required since it is used to order the records and to skip records that show up in more than one dump.
+type Record struct {
+	Topic     string          `json:"topic"`
+	Partition int32           `json:"partition"`
+	Offset    *int64          `json:"offset"`
+	Key       json.RawMessage `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	// Timestamp is in milliseconds since the epoch.
+	Timestamp *int64 `json:"timestamp"`
+
+	// Payload and Ts are the field names that kcat uses.
+	Payload json.RawMessage `json:"payload"`
+	Ts      *int64          `json:"ts"`
+}
+
+func toBytes(raw json.RawMessage) ([]byte, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	if raw[0] == '"' {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+		return []byte(value), nil
+	}
+
+	return raw, nil
+}
+
+func (r Record) toKgoRecord() (kgo.Record, error) {
+	if r.Topic == "" {
+		return kgo.Record{}, fmt.Errorf("topic is required")
+	}
+
+	if r.Offset == nil {
+		return kgo.Record{}, fmt.Errorf("offset is required")
+	}
+
+	key, err := toBytes(r.Key)
+	if err != nil {
+		return kgo.Record{}, fmt.Errorf("failed to parse key: %w", err)
+	}
+
+	rawValue := r.Value
+	if len(rawValue) == 0 {
+		rawValue = r.Payload
+	}
+
+	value, err := toBytes(rawValue)
+	if err != nil {
+		return kgo.Record{}, fmt.Errorf("failed to parse value: %w", err)
+	}
+
+	record := kgo.Record{
+		Topic:     r.Topic,
+		Partition: r.Partition,
+		Offset:    *r.Offset,
+		Key:       key,
+		Value:     value,
+	}
+
+	if ts := r.Timestamp; ts != nil {
+		record.Timestamp = time.UnixMilli(*ts).UTC()
+	} else if ts := r.Ts; ts != nil {
+		record.Timestamp = time.UnixMilli(*ts).UTC()
+	}
+
+	return record, nil
+}
+
+func readRecords(r io.Reader) ([]kgo.Record, error) {
+	var records []kgo.Record
+	decoder := json.NewDecoder(r)
+	for {
+		var record Record
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+			return nil, fmt.Errorf("failed to decode record %d: %w", len(records)+1, err)
+		}
+
+		kgoRecord, err := record.toKgoRecord()
+		if err != nil {
+			return nil, fmt.Errorf("invalid record %d: %w", len(records)+1, err)
+		}
+		records = append(records, kgoRecord)
+	}
+}
+
+// toMessages sorts the records by topic, partition and offset so that dumps can be passed in any order.
+// The high watermark of each message is set to the offset after the last record for its topic and partition, as if the records were the whole topic.
+func toMessages(records []kgo.Record) []artie.Message {
+	slices.SortStableFunc(records, func(a, b kgo.Record) int {
+		return cmp.Or(
+			cmp.Compare(a.Topic, b.Topic),
+			cmp.Compare(a.Partition, b.Partition),
+			cmp.Compare(a.Offset, b.Offset),
+		)
+	})
+
+	highWatermarks := make(map[string]int64)
+	for _, record := range records {
+		key := kafkalib.GetHighWatermarkMapKey(record.Topic, record.Partition)
+		highWatermarks[key] = max(highWatermarks[key], record.Offset+1)
+	}
+
+	msgs := make([]artie.Message, 0, len(records))
+	for _, record := range records {
+		msgs = append(msgs, artie.NewFranzGoMessage(record, highWatermarks[kafkalib.GetHighWatermarkMapKey(record.Topic, record.Partition)]))
+	}
+
+	return msgs
+}
+
+// ReadMessages reads every record from [r], see [toMessages] for the order they are returned in.
+func ReadMessages(r io.Reader) ([]artie.Message, error) {
+	records, err := readRecords(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return toMessages(records), nil
+}
+
+// ReadFiles reads every record from the NDJSON files at [paths], see [ReadMessages].
+func ReadFiles(paths ...string) ([]artie.Message, error) {
+	var records []kgo.Record
+	for _, path := range paths {
+		fileRecords, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, fileRecords...)
+	}
+
+	return toMessages(records), nil
+}
+
+func readFile(path string) ([]kgo.Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer file.Close()
+
+	records, err := readRecords(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,101 @@
+package filelib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadMessages(t *testing.T) {
+	{
+		// Our own format, values can be objects
+		msgs, err := ReadMessages(strings.NewReader(`{"topic": "orders", "partition": 1, "offset": 10, "key": "{\"id\": 1}", "value": {"payload": {"id": 1}}, "timestamp": 1700000000000}
+{"topic": "orders", "partition": 1, "offset": 11, "key": "{\"id\": 2}", "value": null}
+
+{"topic": "customers", "partition": 0, "offset": 3, "key": null, "value": "hello"}
+`))
+		assert.NoError(t, err)
+		assert.Len(t, msgs, 3)
+
+		// Records are sorted by topic, partition and offset
+		assert.Equal(t, "customers", msgs[0].Topic())
+		assert.Nil(t, msgs[0].Key())
+		assert.Equal(t, "hello", string(msgs[0].Value()))
+		assert.True(t, msgs[0].PublishTime().IsZero())
+		assert.Equal(t, int64(4), msgs[0].HighWaterMark())
+
+		assert.Equal(t, "orders", msgs[1].Topic())
+		assert.Equal(t, 1, msgs[1].Partition())
+		assert.Equal(t, int64(10), msgs[1].Offset())
+		assert.Equal(t, `{"id": 1}`, string(msgs[1].Key()))
+		assert.Equal(t, `{"payload": {"id": 1}}`, string(msgs[1].Value()))
+		assert.Equal(t, time.UnixMilli(1700000000000).UTC(), msgs[1].PublishTime())
+		assert.Equal(t, int64(12), msgs[1].HighWaterMark())
+
+		// Tombstones
+		assert.Nil(t, msgs[2].Value())
+		assert.Equal(t, int64(12), msgs[2].HighWaterMark())
+	}
+	{
+		// Out of order records
+		msgs, err := ReadMessages(strings.NewReader(`{"topic": "orders", "partition": 0, "offset": 2, "value": "c"}
+{"topic": "orders", "partition": 0, "offset": 0, "value": "a"}
+{"topic": "orders", "partition": 0, "offset": 1, "value": "b"}
+`))
+		assert.NoError(t, err)
+		assert.Len(t, msgs, 3)
+		for i, value := range []string{"a", "b", "c"} {
+			assert.Equal(t, int64(i), msgs[i].Offset())
+			assert.Equal(t, value, string(msgs[i].Value()))
+		}
+	}
+	{
+		// kcat format
+		msgs, err := ReadMessages(strings.NewReader(`{"topic":"orders","partition":0,"offset":5,"tstype":"create","ts":1700000000000,"broker":1,"key":"1","payload":"{\"id\":1}"}`))
+		assert.NoError(t, err)
+		assert.Len(t, msgs, 1)
+		assert.Equal(t, "1", string(msgs[0].Key()))
+		assert.Equal(t, `{"id":1}`, string(msgs[0].Value()))
+		assert.Equal(t, time.UnixMilli(1700000000000).UTC(), msgs[0].PublishTime())
+	}
+	{
+		// Missing topic
+		_, err := ReadMessages(strings.NewReader(`{"partition": 0, "offset": 1, "value": "hello"}`))
+		assert.ErrorContains(t, err, "invalid record 1: topic is required")
+	}
+	{
+		// Missing offset
+		_, err := ReadMessages(strings.NewReader(`{"topic": "orders", "partition": 0, "offset": 0, "value": "a"}
+{"topic": "orders", "partition": 0, "value": "b"}`))
+		assert.ErrorContains(t, err, "invalid record 2: offset is required")
+	}
+	{
+		// Invalid JSON
+		_, err := ReadMessages(strings.NewReader(`{"topic": "orders", "offset": 1}
+{"topic": `))
+		assert.ErrorContains(t, err, "failed to decode record 2")
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.ndjson"), filepath.Join(dir, "second.ndjson")
+	assert.NoError(t, os.WriteFile(first, []byte(`{"topic": "orders", "offset": 1, "value": "hello"}`), 0o644))
+	assert.NoError(t, os.WriteFile(second, []byte(`{"topic": "orders", "offset": 2, "value": "world"}`), 0o644))
+
+	// Files can be passed in any order
+	msgs, err := ReadFiles(second, first)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "hello", string(msgs[0].Value()))
+	assert.Equal(t, "world", string(msgs[1].Value()))
+	// The high watermark spans every file
+	assert.Equal(t, int64(3), msgs[0].HighWaterMark())
+
+	_, err = ReadFiles(first, filepath.Join(dir, "missing.ndjson"))
+	assert.ErrorContains(t, err, "failed to open")
+}
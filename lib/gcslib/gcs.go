@@ -50,6 +50,15 @@ func (g GCSClient) UploadLocalFileToGCS(ctx context.Context, bucket, prefix, fil
 	return fmt.Sprintf("gs://%s/%s", bucket, objectKey), nil
 }
 
+// BucketAttrs checks that the bucket exists and that we have access to it.
+func (g GCSClient) BucketAttrs(ctx context.Context, bucket string) error {
+	if _, err := g.client.Bucket(bucket).Attrs(ctx); err != nil {
+		return fmt.Errorf("failed to access bucket %q: %w", bucket, err)
+	}
+
+	return nil
+}
+
 // DeleteFolder - Folders in GCS are virtual, so we need to list all the objects in the folder and then delete them
 func (g GCSClient) DeleteFolder(ctx context.Context, bucket, folder string) error {
 	bkt := g.client.Bucket(bucket)
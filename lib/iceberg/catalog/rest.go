@@ -26,6 +26,8 @@ type Config struct {
 	Scope     string
 	Warehouse string
 	Prefix    string
+	// Properties are passed through to the catalog, this is used to configure the FileIO (e.g. s3.access-key-id) for tables that we load.
+	Properties map[string]string
 }
 
 type RestCatalog struct {
@@ -57,6 +59,10 @@ func NewRESTCatalog(ctx context.Context, cfg Config) (*RestCatalog, error) {
 		opts = append(opts, rest.WithPrefix(cfg.Prefix))
 	}
 
+	if len(cfg.Properties) > 0 {
+		opts = append(opts, rest.WithAdditionalProps(cfg.Properties))
+	}
+
 	cat, err := rest.NewCatalog(ctx, "iceberg", cfg.URI, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create REST catalog: %w", err)
@@ -156,6 +162,33 @@ func (r *RestCatalog) DropTable(ctx context.Context, namespace, name string) err
 	return nil
 }
 
+// LoadTable loads the table so that data files can be written and committed with iceberg-go.
+func (r *RestCatalog) LoadTable(ctx context.Context, namespace, name string) (*table.Table, error) {
+	tbl, err := r.catalog.LoadTable(ctx, buildTableIdentifier(namespace, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load table: %w", err)
+	}
+	return tbl, nil
+}
+
+// CreateTable creates an unpartitioned table with the given schema.
+func (r *RestCatalog) CreateTable(ctx context.Context, namespace, name string, schema *iceberg.Schema) (*table.Table, error) {
+	tbl, err := r.catalog.CreateTable(ctx, buildTableIdentifier(namespace, name), schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create table: %w", err)
+	}
+	return tbl, nil
+}
+
+// CommitTable commits metadata updates (e.g. schema changes) that are not exposed through an iceberg-go transaction.
+func (r *RestCatalog) CommitTable(ctx context.Context, identifier table.Identifier, requirements []table.Requirement, updates []table.Update) (table.Metadata, string, error) {
+	metadata, location, err := r.catalog.CommitTable(ctx, identifier, requirements, updates)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to commit table: %w", err)
+	}
+	return metadata, location, nil
+}
+
 // buildNamespaceIdentifier converts a namespace string to a table.Identifier.
 // Namespace parts separated by "." are split into individual components (e.g., "db.schema" → ["db", "schema"]).
 func buildNamespaceIdentifier(namespace string) table.Identifier {
@@ -2,8 +2,11 @@ package iceberg
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
+
+	"github.com/apache/iceberg-go/catalog"
 )
 
 type IcebergCatalog interface {
@@ -43,5 +46,5 @@ func NamespaceNotFoundError(err error) bool {
 		return false
 	}
 
-	return strings.Contains(err.Error(), "NoSuchNamespaceException: Namespace")
+	return errors.Is(err, catalog.ErrNoSuchNamespace) || strings.Contains(err.Error(), "NoSuchNamespaceException: Namespace")
 }
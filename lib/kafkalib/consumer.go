@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sync"
 	"time"
 
@@ -18,6 +19,8 @@ import (
 const (
 	FetchMessageTimeout     = 5 * time.Minute
 	TopicExistsPollInterval = 5 * time.Minute
+	// sampleFetchTimeout is how long [FetchSample] waits for more messages before returning what it has.
+	sampleFetchTimeout = 10 * time.Second
 )
 
 var ErrNoMessages = errors.New("no messages found")
@@ -160,6 +163,85 @@ func WaitForTopicToExist(ctx context.Context, client *kgo.Client, topic string)
 	}
 }
 
+// CheckConnectivity connects to the brokers in [cfg] and verifies that every configured topic exists.
+// Missing topics are tolerated when [Kafka.WaitForTopics] is enabled since the consumer will wait for them.
+func CheckConnectivity(ctx context.Context, cfg *Kafka) error {
+	kafkaConn := NewConnection(cfg.EnableAWSMSKIAM, cfg.DisableTLS, cfg.Username, cfg.Password, DefaultTimeout)
+	clientOpts, err := kafkaConn.ClientOptions(ctx, cfg.BootstrapServers(true))
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka client options: %w", err)
+	}
+
+	client, err := kgo.NewClient(clientOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+	defer client.Close()
+
+	if err = client.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to reach Kafka brokers: %w", err)
+	}
+
+	for _, topic := range cfg.Topics() {
+		exists, err := TopicExists(ctx, client, topic)
+		if err != nil {
+			return fmt.Errorf("failed to check topic existence: %w", err)
+		}
+		if !exists && !cfg.WaitForTopics {
+			return fmt.Errorf("topic %q does not exist", topic)
+		}
+	}
+
+	return nil
+}
+
+// FetchSample reads up to [limit] messages for [topic] from the start of every partition.
+// The client does not join the consumer group, so no offsets are committed and the group's consumers are not rebalanced.
+func FetchSample(ctx context.Context, cfg *Kafka, topic string, limit int) ([]artie.Message, error) {
+	kafkaConn := NewConnection(cfg.EnableAWSMSKIAM, cfg.DisableTLS, cfg.Username, cfg.Password, DefaultTimeout)
+	clientOpts, err := kafkaConn.ClientOptions(ctx, cfg.BootstrapServers(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client options: %w", err)
+	}
+
+	clientOpts = append(clientOpts,
+		kgo.ConsumeTopics(topic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+
+	client, err := kgo.NewClient(clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+	defer client.Close()
+
+	var msgs []artie.Message
+	for len(msgs) < limit {
+		// Stop once a poll comes back empty, the topic has either been read to the end or nothing is being produced.
+		pollCtx, cancel := context.WithTimeout(ctx, sampleFetchTimeout)
+		fetches := client.PollRecords(pollCtx, limit-len(msgs))
+		cancel()
+
+		for _, fetchErr := range fetches.Errors() {
+			if !errors.Is(fetchErr.Err, context.DeadlineExceeded) {
+				return nil, fmt.Errorf("failed to fetch messages for topic %q: %w", topic, fetchErr.Err)
+			}
+		}
+
+		if fetches.NumRecords() == 0 {
+			break
+		}
+
+		fetches.EachPartition(func(partition kgo.FetchTopicPartition) {
+			for _, record := range partition.Records {
+				msgs = append(msgs, artie.NewFranzGoMessage(*record, partition.HighWatermark))
+			}
+		})
+	}
+
+	return msgs, nil
+}
+
 func (f *FranzGoConsumer) CommitMessages(ctx context.Context, msgs ...artie.Message) error {
 	offsetsToCommit := make(map[string]map[int32]kgo.EpochOffset)
 
@@ -232,9 +314,68 @@ type ConsumerProvider struct {
 	partitionToAppliedOffset map[int]artie.Message
 	client                   *kgo.Client // For FranzGo consumers
 
+	// This is guarded by its own mutex since [mu] is held for the entire flush and we don't want to block rebalances.
+	partitionsMu       sync.RWMutex
+	joinedGroup        bool
+	assignedPartitions map[int32]bool
+
 	Consumer
 }
 
+func (c *ConsumerProvider) assignPartitions(partitions []int32) {
+	c.partitionsMu.Lock()
+	defer c.partitionsMu.Unlock()
+
+	c.joinedGroup = true
+	if c.assignedPartitions == nil {
+		c.assignedPartitions = make(map[int32]bool)
+	}
+
+	for _, partition := range partitions {
+		c.assignedPartitions[partition] = true
+	}
+}
+
+func (c *ConsumerProvider) revokePartitions(partitions []int32) {
+	c.partitionsMu.Lock()
+	defer c.partitionsMu.Unlock()
+
+	for _, partition := range partitions {
+		delete(c.assignedPartitions, partition)
+	}
+}
+
+// AssignedPartitions returns the partitions that are currently assigned to this consumer.
+// [joinedGroup] is false until the consumer has received its first assignment, note that the assignment may be empty if there are more consumers than partitions.
+func (c *ConsumerProvider) AssignedPartitions() (partitions []int32, joinedGroup bool) {
+	c.partitionsMu.RLock()
+	defer c.partitionsMu.RUnlock()
+
+	for partition := range c.assignedPartitions {
+		partitions = append(partitions, partition)
+	}
+	slices.Sort(partitions)
+	return partitions, c.joinedGroup
+}
+
+// PauseFetching stops the client from fetching any more messages until [ResumeFetching] is called.
+// Messages that have already been fetched will still be returned. Only supported for FranzGo consumers.
+func (c *ConsumerProvider) PauseFetching() {
+	if c.client == nil {
+		return
+	}
+
+	c.client.PauseFetchTopics(c.topic)
+}
+
+func (c *ConsumerProvider) ResumeFetching() {
+	if c.client == nil {
+		return
+	}
+
+	c.client.ResumeFetchTopics(c.topic)
+}
+
 // WaitForTopic waits for the topic to exist. Only supported for FranzGo consumers.
 func (c *ConsumerProvider) WaitForTopic(ctx context.Context) error {
 	if c.client == nil {
@@ -258,102 +399,109 @@ func NewConsumerProviderForTest(consumer Consumer, topic, groupID string) *Consu
 	}
 }
 
-func InjectFranzGoConsumerProvidersIntoContext(ctx context.Context, cfg *Kafka) (context.Context, error) {
+// NewFranzGoConsumerProvider creates a consumer with its own client for [topicConfig], the client will join the consumer group in the background.
+func NewFranzGoConsumerProvider(ctx context.Context, cfg *Kafka, topicConfig TopicConfig) (*ConsumerProvider, error) {
 	kafkaConn := NewConnection(cfg.EnableAWSMSKIAM, cfg.DisableTLS, cfg.Username, cfg.Password, DefaultTimeout)
 	brokers := cfg.BootstrapServers(true)
 
-	var createdClients []*kgo.Client
-	closeClients := func() {
-		for _, c := range createdClients {
-			c.Close()
-		}
+	clientOpts, err := kafkaConn.ClientOptions(ctx, brokers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client options for topic %s: %w", topicConfig.Topic, err)
 	}
 
-	// Create separate clients for each topic
-	for _, topicConfig := range cfg.TopicConfigs {
-		clientOpts, err := kafkaConn.ClientOptions(ctx, brokers)
-		if err != nil {
-			closeClients()
-			return nil, fmt.Errorf("failed to create Kafka client options for topic %s: %w", topicConfig.Topic, err)
-		}
+	provider := &ConsumerProvider{
+		topic:                    topicConfig.Topic,
+		groupID:                  cfg.GroupID,
+		partitionToAppliedOffset: make(map[int]artie.Message),
+	}
 
-		clientOpts = append(clientOpts,
-			kgo.ConsumerGroup(cfg.GroupID),
-			kgo.ConsumeTopics(topicConfig.Topic), // Consume only this specific topic
-			kgo.DisableAutoCommit(),
-			// Set session timeout for consumer group heartbeats
-			kgo.SessionTimeout(30*time.Second),
-			// Set heartbeat interval
-			kgo.HeartbeatInterval(3*time.Second),
-			// Ensure we allow time for rebalancing
-			kgo.RebalanceTimeout(30*time.Second),
-			// Consumer group lifecycle callbacks with detailed logging
-			kgo.OnPartitionsAssigned(func(ctx context.Context, c *kgo.Client, assigned map[string][]int32) {
-				for topic, partitions := range assigned {
-					// Check group metadata during assignment for debugging
-					actualGroupID, generation := c.GroupMetadata()
-					slog.Info("Partitions assigned",
-						slog.String("topic", topic),
-						slog.Any("partitions", partitions),
-						slog.String("expectedGroupID", cfg.GroupID),
-						slog.String("actualGroupID", actualGroupID),
-						slog.Int("generation", int(generation)))
-				}
-			}),
-			kgo.OnPartitionsRevoked(func(ctx context.Context, c *kgo.Client, revoked map[string][]int32) {
-				for topic, partitions := range revoked {
-					slog.Info("Partitions revoked",
-						slog.String("topic", topic),
-						slog.Any("partitions", partitions),
-						slog.String("groupID", cfg.GroupID))
-				}
-			}),
-			kgo.OnPartitionsLost(func(ctx context.Context, c *kgo.Client, lost map[string][]int32) {
-				for topic, partitions := range lost {
-					slog.Warn("Partitions lost",
-						slog.String("topic", topic),
-						slog.Any("partitions", partitions),
-						slog.String("groupID", cfg.GroupID))
-				}
-			}),
-		)
+	clientOpts = append(clientOpts,
+		kgo.ConsumerGroup(cfg.GroupID),
+		kgo.ConsumeTopics(topicConfig.Topic), // Consume only this specific topic
+		kgo.DisableAutoCommit(),
+		// Set session timeout for consumer group heartbeats
+		kgo.SessionTimeout(30*time.Second),
+		// Set heartbeat interval
+		kgo.HeartbeatInterval(3*time.Second),
+		// Ensure we allow time for rebalancing
+		kgo.RebalanceTimeout(30*time.Second),
+		// Consumer group lifecycle callbacks with detailed logging
+		kgo.OnPartitionsAssigned(func(ctx context.Context, c *kgo.Client, assigned map[string][]int32) {
+			provider.assignPartitions(assigned[topicConfig.Topic])
+			for topic, partitions := range assigned {
+				// Check group metadata during assignment for debugging
+				actualGroupID, generation := c.GroupMetadata()
+				slog.Info("Partitions assigned",
+					slog.String("topic", topic),
+					slog.Any("partitions", partitions),
+					slog.String("expectedGroupID", cfg.GroupID),
+					slog.String("actualGroupID", actualGroupID),
+					slog.Int("generation", int(generation)))
+			}
+		}),
+		kgo.OnPartitionsRevoked(func(ctx context.Context, c *kgo.Client, revoked map[string][]int32) {
+			provider.revokePartitions(revoked[topicConfig.Topic])
+			for topic, partitions := range revoked {
+				slog.Info("Partitions revoked",
+					slog.String("topic", topic),
+					slog.Any("partitions", partitions),
+					slog.String("groupID", cfg.GroupID))
+			}
+		}),
+		kgo.OnPartitionsLost(func(ctx context.Context, c *kgo.Client, lost map[string][]int32) {
+			provider.revokePartitions(lost[topicConfig.Topic])
+			for topic, partitions := range lost {
+				slog.Warn("Partitions lost",
+					slog.String("topic", topic),
+					slog.Any("partitions", partitions),
+					slog.String("groupID", cfg.GroupID))
+			}
+		}),
+	)
 
-		// Apply optional fetch tuning settings if configured
-		if cfg.FetchMaxBytes > 0 {
-			clientOpts = append(clientOpts, kgo.FetchMaxBytes(cfg.FetchMaxBytes))
-		}
-		if cfg.FetchMaxPartitionBytes > 0 {
-			clientOpts = append(clientOpts, kgo.FetchMaxPartitionBytes(cfg.FetchMaxPartitionBytes))
-		}
-		if cfg.FetchMinBytes > 0 {
-			clientOpts = append(clientOpts, kgo.FetchMinBytes(cfg.FetchMinBytes))
-		}
-		if cfg.FetchMaxWaitMs > 0 {
-			clientOpts = append(clientOpts, kgo.FetchMaxWait(time.Duration(cfg.FetchMaxWaitMs)*time.Millisecond))
-		}
+	// Apply optional fetch tuning settings if configured
+	if cfg.FetchMaxBytes > 0 {
+		clientOpts = append(clientOpts, kgo.FetchMaxBytes(cfg.FetchMaxBytes))
+	}
+	if cfg.FetchMaxPartitionBytes > 0 {
+		clientOpts = append(clientOpts, kgo.FetchMaxPartitionBytes(cfg.FetchMaxPartitionBytes))
+	}
+	if cfg.FetchMinBytes > 0 {
+		clientOpts = append(clientOpts, kgo.FetchMinBytes(cfg.FetchMinBytes))
+	}
+	if cfg.FetchMaxWaitMs > 0 {
+		clientOpts = append(clientOpts, kgo.FetchMaxWait(time.Duration(cfg.FetchMaxWaitMs)*time.Millisecond))
+	}
+
+	client, err := kgo.NewClient(clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client for topic %s: %w", topicConfig.Topic, err)
+	}
+
+	slog.Info("Created Kafka consumer for topic",
+		slog.String("topic", topicConfig.Topic),
+		slog.String("groupID", cfg.GroupID),
+		slog.Any("brokers", brokers))
 
-		client, err := kgo.NewClient(clientOpts...)
+	provider.Consumer = NewFranzGoConsumer(client, cfg.GroupID, topicConfig.Topic)
+	provider.client = client
+	return provider, nil
+}
+
+func InjectFranzGoConsumerProvidersIntoContext(ctx context.Context, cfg *Kafka) (context.Context, error) {
+	registry := NewConsumerRegistry()
+	// Create separate clients for each topic
+	for _, topicConfig := range cfg.TopicConfigs {
+		provider, err := NewFranzGoConsumerProvider(ctx, cfg, *topicConfig)
 		if err != nil {
-			closeClients()
-			return nil, fmt.Errorf("failed to create Kafka client for topic %s: %w", topicConfig.Topic, err)
+			registry.CloseAll()
+			return nil, err
 		}
-		createdClients = append(createdClients, client)
-
-		slog.Info("Created Kafka consumer for topic",
-			slog.String("topic", topicConfig.Topic),
-			slog.String("groupID", cfg.GroupID),
-			slog.Any("brokers", brokers))
-
-		ctx = context.WithValue(ctx, BuildContextKey(topicConfig.Topic), &ConsumerProvider{
-			Consumer:                 NewFranzGoConsumer(client, cfg.GroupID, topicConfig.Topic),
-			topic:                    topicConfig.Topic,
-			groupID:                  cfg.GroupID,
-			partitionToAppliedOffset: make(map[int]artie.Message),
-			client:                   client,
-		})
+
+		registry.Add(provider)
 	}
 
-	return ctx, nil
+	return InjectConsumerRegistryIntoContext(ctx, registry), nil
 }
 
 func (c *ConsumerProvider) LockAndProcess(ctx context.Context, lock bool, do func() error) error {
@@ -396,9 +544,15 @@ func (c *ConsumerProvider) FetchMessageAndProcess(ctx context.Context, do func(a
 	return nil
 }
 
-func GetConsumerFromContext(ctx context.Context, topic string) (*ConsumerProvider, error) {
+func GetConsumerFromContext(ctx context.Context, topic string) (QueueConsumer, error) {
+	if registry, ok := GetConsumerRegistryFromContext(ctx); ok {
+		if provider, ok := registry.Get(topic); ok {
+			return provider, nil
+		}
+	}
+
 	value := ctx.Value(BuildContextKey(topic))
-	consumer, ok := value.(*ConsumerProvider)
+	consumer, ok := value.(QueueConsumer)
 	if !ok {
 		return nil, fmt.Errorf("consumer not found for topic %q, got: %T", topic, value)
 	}
@@ -425,6 +579,10 @@ func (c *ConsumerProvider) CommitMessage(ctx context.Context) error {
 	return nil
 }
 
+func (c *ConsumerProvider) Topic() string {
+	return c.topic
+}
+
 func (c *ConsumerProvider) GetGroupID() string {
 	return c.groupID
 }
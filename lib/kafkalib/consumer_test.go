@@ -0,0 +1,35 @@
+package kafkalib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerProvider_AssignedPartitions(t *testing.T) {
+	provider := NewConsumerProviderForTest(nil, "topic", "group")
+	{
+		// Has not joined the group yet
+		partitions, joinedGroup := provider.AssignedPartitions()
+		assert.Empty(t, partitions)
+		assert.False(t, joinedGroup)
+	}
+	{
+		// Empty assignment
+		provider.assignPartitions(nil)
+		partitions, joinedGroup := provider.AssignedPartitions()
+		assert.Empty(t, partitions)
+		assert.True(t, joinedGroup)
+	}
+	{
+		// Assigned, then revoked
+		provider.assignPartitions([]int32{2, 0, 1})
+		partitions, _ := provider.AssignedPartitions()
+		assert.Equal(t, []int32{0, 1, 2}, partitions)
+
+		provider.revokePartitions([]int32{1})
+		partitions, joinedGroup := provider.AssignedPartitions()
+		assert.Equal(t, []int32{0, 2}, partitions)
+		assert.True(t, joinedGroup)
+	}
+}
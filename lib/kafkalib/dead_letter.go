@@ -0,0 +1,98 @@
+package kafkalib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/stringutil"
+)
+
+type DeadLetterQueueKind string
+
+const (
+	DeadLetterQueueKafka DeadLetterQueueKind = "kafka"
+	DeadLetterQueueFile  DeadLetterQueueKind = "file"
+	DeadLetterQueueS3    DeadLetterQueueKind = "s3"
+)
+
+const (
+	defaultDeadLetterMaxFailures   = 100
+	defaultDeadLetterWindowSeconds = 60
+)
+
+type DeadLetterQueueSettings struct {
+	Enabled bool                `yaml:"enabled"`
+	Kind    DeadLetterQueueKind `yaml:"kind"`
+
+	// [Topic] - The Kafka topic to publish failed messages to, this is only used when [Kind] is kafka.
+	// The topic will be produced to with the same Kafka connection settings as the consumer.
+	Topic string `yaml:"topic,omitempty"`
+
+	// [Directory] - Local directory where NDJSON files will be written to, this is only used when [Kind] is file.
+	Directory string `yaml:"directory,omitempty"`
+
+	// S3 settings, these are only used when [Kind] is s3.
+	Bucket             string `yaml:"bucket,omitempty"`
+	Prefix             string `yaml:"prefix,omitempty"`
+	AwsRegion          string `yaml:"awsRegion,omitempty"`
+	AwsAccessKeyID     string `yaml:"awsAccessKeyID,omitempty"`
+	AwsSecretAccessKey string `yaml:"awsSecretAccessKey,omitempty"`
+
+	// [MaxFailures] - The maximum number of messages that can be dead-lettered within [WindowSeconds].
+	// Once this is exceeded, the circuit breaker trips and Transfer will stop processing the topic.
+	MaxFailures   int `yaml:"maxFailures,omitempty"`
+	WindowSeconds int `yaml:"windowSeconds,omitempty"`
+}
+
+func (d DeadLetterQueueSettings) GetMaxFailures() int {
+	if d.MaxFailures > 0 {
+		return d.MaxFailures
+	}
+
+	return defaultDeadLetterMaxFailures
+}
+
+func (d DeadLetterQueueSettings) GetWindow() time.Duration {
+	if d.WindowSeconds > 0 {
+		return time.Duration(d.WindowSeconds) * time.Second
+	}
+
+	return defaultDeadLetterWindowSeconds * time.Second
+}
+
+func (d DeadLetterQueueSettings) Validate() error {
+	if !d.Enabled {
+		return nil
+	}
+
+	if d.MaxFailures < 0 {
+		return fmt.Errorf("maxFailures cannot be negative")
+	}
+
+	if d.WindowSeconds < 0 {
+		return fmt.Errorf("windowSeconds cannot be negative")
+	}
+
+	switch d.Kind {
+	case DeadLetterQueueKafka:
+		if stringutil.Empty(d.Topic) {
+			return fmt.Errorf("topic is required for a kafka dead-letter queue")
+		}
+	case DeadLetterQueueFile:
+		if stringutil.Empty(d.Directory) {
+			return fmt.Errorf("directory is required for a file dead-letter queue")
+		}
+	case DeadLetterQueueS3:
+		if stringutil.Empty(d.Bucket, d.AwsRegion) {
+			return fmt.Errorf("bucket and awsRegion are required for a s3 dead-letter queue")
+		}
+
+		if (d.AwsAccessKeyID == "") != (d.AwsSecretAccessKey == "") {
+			return fmt.Errorf("both awsAccessKeyID and awsSecretAccessKey must be provided together")
+		}
+	default:
+		return fmt.Errorf("invalid dead-letter queue kind: %q", d.Kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package kafkalib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetterQueueSettings_Validate(t *testing.T) {
+	{
+		// Not enabled
+		assert.NoError(t, DeadLetterQueueSettings{}.Validate())
+	}
+	{
+		// Invalid kind
+		assert.ErrorContains(t, DeadLetterQueueSettings{Enabled: true, Kind: "foo"}.Validate(), `invalid dead-letter queue kind: "foo"`)
+	}
+	{
+		// Kafka without a topic
+		assert.ErrorContains(t, DeadLetterQueueSettings{Enabled: true, Kind: DeadLetterQueueKafka}.Validate(), "topic is required")
+		assert.NoError(t, DeadLetterQueueSettings{Enabled: true, Kind: DeadLetterQueueKafka, Topic: "dlq"}.Validate())
+	}
+	{
+		// File without a directory
+		assert.ErrorContains(t, DeadLetterQueueSettings{Enabled: true, Kind: DeadLetterQueueFile}.Validate(), "directory is required")
+		assert.NoError(t, DeadLetterQueueSettings{Enabled: true, Kind: DeadLetterQueueFile, Directory: "/tmp/dlq"}.Validate())
+	}
+	{
+		// S3
+		assert.ErrorContains(t, DeadLetterQueueSettings{Enabled: true, Kind: DeadLetterQueueS3, Bucket: "bucket"}.Validate(), "bucket and awsRegion are required")
+		assert.ErrorContains(t, DeadLetterQueueSettings{Enabled: true, Kind: DeadLetterQueueS3, Bucket: "bucket", AwsRegion: "us-east-1", AwsAccessKeyID: "key"}.Validate(), "must be provided together")
+		assert.NoError(t, DeadLetterQueueSettings{Enabled: true, Kind: DeadLetterQueueS3, Bucket: "bucket", AwsRegion: "us-east-1"}.Validate())
+	}
+	{
+		// Negative values
+		assert.ErrorContains(t, DeadLetterQueueSettings{Enabled: true, Kind: DeadLetterQueueKafka, Topic: "dlq", MaxFailures: -1}.Validate(), "maxFailures cannot be negative")
+		assert.ErrorContains(t, DeadLetterQueueSettings{Enabled: true, Kind: DeadLetterQueueKafka, Topic: "dlq", WindowSeconds: -1}.Validate(), "windowSeconds cannot be negative")
+	}
+}
+
+func TestDeadLetterQueueSettings_Defaults(t *testing.T) {
+	{
+		// Defaults
+		assert.Equal(t, defaultDeadLetterMaxFailures, DeadLetterQueueSettings{}.GetMaxFailures())
+		assert.Equal(t, time.Minute, DeadLetterQueueSettings{}.GetWindow())
+	}
+	{
+		// Overrides
+		settings := DeadLetterQueueSettings{MaxFailures: 5, WindowSeconds: 10}
+		assert.Equal(t, 5, settings.GetMaxFailures())
+		assert.Equal(t, 10*time.Second, settings.GetWindow())
+	}
+}
@@ -0,0 +1,52 @@
+package kafkalib
+
+import "cmp"
+
+const (
+	DefaultFlattenedOperationField  = "__op"
+	DefaultFlattenedDeletedField    = "__deleted"
+	DefaultFlattenedSourceTsMsField = "__source_ts_ms"
+	DefaultFlattenedDatabaseField   = "__db"
+	DefaultFlattenedSchemaField     = "__schema"
+	DefaultFlattenedTableField      = "__table"
+)
+
+// FlattenedSettings configures which fields carry the Debezium metadata for events that have been unwrapped by the ExtractNewRecordState SMT.
+// The defaults line up with `add.fields=op,db,schema,table,source.ts_ms` and `delete.handling.mode=rewrite`.
+type FlattenedSettings struct {
+	OperationField  string `yaml:"operationField,omitempty"`
+	DeletedField    string `yaml:"deletedField,omitempty"`
+	SourceTsMsField string `yaml:"sourceTsMsField,omitempty"`
+	DatabaseField   string `yaml:"databaseField,omitempty"`
+	SchemaField     string `yaml:"schemaField,omitempty"`
+	TableField      string `yaml:"tableField,omitempty"`
+}
+
+func (f FlattenedSettings) GetOperationField() string {
+	return cmp.Or(f.OperationField, DefaultFlattenedOperationField)
+}
+
+func (f FlattenedSettings) GetDeletedField() string {
+	return cmp.Or(f.DeletedField, DefaultFlattenedDeletedField)
+}
+
+func (f FlattenedSettings) GetSourceTsMsField() string {
+	return cmp.Or(f.SourceTsMsField, DefaultFlattenedSourceTsMsField)
+}
+
+func (f FlattenedSettings) GetDatabaseField() string {
+	return cmp.Or(f.DatabaseField, DefaultFlattenedDatabaseField)
+}
+
+func (f FlattenedSettings) GetSchemaField() string {
+	return cmp.Or(f.SchemaField, DefaultFlattenedSchemaField)
+}
+
+func (f FlattenedSettings) GetTableField() string {
+	return cmp.Or(f.TableField, DefaultFlattenedTableField)
+}
+
+// MetadataFields returns all the metadata fields, these are stripped out of the row.
+func (f FlattenedSettings) MetadataFields() []string {
+	return []string{f.GetOperationField(), f.GetDeletedField(), f.GetSourceTsMsField(), f.GetDatabaseField(), f.GetSchemaField(), f.GetTableField()}
+}
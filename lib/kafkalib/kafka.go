@@ -20,6 +20,9 @@ type Kafka struct {
 	EnableAWSMSKIAM bool   `yaml:"enableAWSMKSIAM,omitempty"`
 	DisableTLS      bool   `yaml:"disableTLS,omitempty"`
 
+	// SchemaRegistry is required for topics that are serialized with Confluent's Avro or Protobuf converters.
+	SchemaRegistry *SchemaRegistry `yaml:"schemaRegistry,omitempty"`
+
 	// WaitForTopics - if true, polls until topics exist before consuming.
 	// This prevents relying on broker auto-creation and allows graceful startup
 	// when topics may not exist yet.
@@ -36,6 +39,21 @@ type Kafka struct {
 	FetchMaxWaitMs int32 `yaml:"fetchMaxWaitMs,omitempty"`
 }
 
+type SchemaRegistry struct {
+	URL string `yaml:"url"`
+	// Optional basic auth credentials, e.g. Confluent Cloud API key and secret.
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+func (s SchemaRegistry) Validate() error {
+	if s.URL == "" {
+		return fmt.Errorf("schema registry url is empty")
+	}
+
+	return nil
+}
+
 func (k *Kafka) Topics() []string {
 	var out []string
 	for _, config := range k.TopicConfigs {
@@ -40,3 +40,8 @@ func TestCfg_KafkaBootstrapServers(t *testing.T) {
 		assert.ElementsMatch(t, []string{"a:9092", "b:9093", "c:9094"}, kafkaWithMultipleBrokers.BootstrapServers(true))
 	}
 }
+
+func TestSchemaRegistry_Validate(t *testing.T) {
+	assert.ErrorContains(t, SchemaRegistry{}.Validate(), "schema registry url is empty")
+	assert.NoError(t, SchemaRegistry{URL: "http://localhost:8081"}.Validate())
+}
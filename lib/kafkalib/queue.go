@@ -0,0 +1,31 @@
+package kafkalib
+
+import (
+	"context"
+
+	"github.com/artie-labs/transfer/lib/artie"
+)
+
+// QueueConsumer is what the consumer process needs from a message queue, every topic has its own [QueueConsumer].
+// [ConsumerProvider] implements this for Kafka and the natslib package implements this for NATS JetStream.
+type QueueConsumer interface {
+	Topic() string
+	GetGroupID() string
+
+	// FetchMessageAndProcess fetches the next message and calls [do] while holding the lock, messages that have already been processed are skipped.
+	FetchMessageAndProcess(ctx context.Context, do func(artie.Message) error) error
+	// LockAndProcess calls [do] while holding the same lock that is used by [FetchMessageAndProcess].
+	LockAndProcess(ctx context.Context, lock bool, do func() error) error
+	// CommitMessage acknowledges every message that has been processed so far, this is called after the topic has been flushed.
+	CommitMessage(ctx context.Context) error
+
+	// AssignedPartitions returns the partitions that this consumer is reading from, [joinedGroup] is false until the consumer is ready.
+	AssignedPartitions() (partitions []int32, joinedGroup bool)
+	PauseFetching()
+	ResumeFetching()
+	WaitForTopic(ctx context.Context) error
+
+	Close() error
+}
+
+var _ QueueConsumer = (*ConsumerProvider)(nil)
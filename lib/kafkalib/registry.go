@@ -0,0 +1,87 @@
+package kafkalib
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+type registryCtxKey struct{}
+
+// ConsumerRegistry holds the consumer for each topic, consumers can be added and removed while Transfer is running.
+type ConsumerRegistry struct {
+	mu        sync.RWMutex
+	providers map[string]QueueConsumer
+}
+
+func NewConsumerRegistry() *ConsumerRegistry {
+	return &ConsumerRegistry{providers: make(map[string]QueueConsumer)}
+}
+
+func InjectConsumerRegistryIntoContext(ctx context.Context, registry *ConsumerRegistry) context.Context {
+	return context.WithValue(ctx, registryCtxKey{}, registry)
+}
+
+func GetConsumerRegistryFromContext(ctx context.Context) (*ConsumerRegistry, bool) {
+	registry, ok := ctx.Value(registryCtxKey{}).(*ConsumerRegistry)
+	return registry, ok
+}
+
+func (r *ConsumerRegistry) Add(provider QueueConsumer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[provider.Topic()] = provider
+}
+
+func (r *ConsumerRegistry) Get(topic string) (QueueConsumer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	provider, ok := r.providers[topic]
+	return provider, ok
+}
+
+// Remove removes and closes the consumer for [topic].
+func (r *ConsumerRegistry) Remove(topic string) error {
+	r.mu.Lock()
+	provider, ok := r.providers[topic]
+	delete(r.providers, topic)
+	r.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return provider.Close()
+}
+
+func (r *ConsumerRegistry) Topics() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var topics []string
+	for topic := range r.providers {
+		topics = append(topics, topic)
+	}
+
+	slices.Sort(topics)
+	return topics
+}
+
+func (r *ConsumerRegistry) CloseAll() {
+	for _, topic := range r.Topics() {
+		if err := r.Remove(topic); err != nil {
+			slog.Warn("Failed to close consumer", slog.Any("err", err), slog.String("topic", topic))
+		}
+	}
+}
+
+// GetTopicsFromContext returns the topics that currently have a consumer, [ok] is false if the consumers were not injected with a registry.
+func GetTopicsFromContext(ctx context.Context) (topics []string, ok bool) {
+	registry, ok := GetConsumerRegistryFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	return registry.Topics(), true
+}
@@ -0,0 +1,55 @@
+package kafkalib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCloser struct {
+	Consumer
+	closed bool
+}
+
+func (f *fakeCloser) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestConsumerRegistry(t *testing.T) {
+	registry := NewConsumerRegistry()
+	orders := &fakeCloser{}
+	registry.Add(NewConsumerProviderForTest(orders, "orders", "group"))
+	registry.Add(NewConsumerProviderForTest(&fakeCloser{}, "customers", "group"))
+	assert.Equal(t, []string{"customers", "orders"}, registry.Topics())
+
+	ctx := InjectConsumerRegistryIntoContext(t.Context(), registry)
+	{
+		// Lookup via the registry
+		provider, err := GetConsumerFromContext(ctx, "orders")
+		assert.NoError(t, err)
+		assert.Equal(t, "orders", provider.Topic())
+
+		topics, ok := GetTopicsFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"customers", "orders"}, topics)
+	}
+	{
+		// Remove
+		assert.NoError(t, registry.Remove("orders"))
+		assert.True(t, orders.closed)
+		assert.Equal(t, []string{"customers"}, registry.Topics())
+
+		_, err := GetConsumerFromContext(ctx, "orders")
+		assert.ErrorContains(t, err, `consumer not found for topic "orders"`)
+
+		// Removing it again is a no-op
+		assert.NoError(t, registry.Remove("orders"))
+	}
+	{
+		// Context without a registry
+		_, ok := GetTopicsFromContext(context.Background())
+		assert.False(t, ok)
+	}
+}
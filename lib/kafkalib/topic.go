@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/artie-labs/transfer/lib/kafkalib/partition"
+	"github.com/artie-labs/transfer/lib/objectkey"
+	"github.com/artie-labs/transfer/lib/rowfilter"
 	"github.com/artie-labs/transfer/lib/stringutil"
 )
 
@@ -224,6 +226,28 @@ type TopicConfig struct {
 
 	// [AppendOnly] - if true, data will always be appended instead of merged.
 	AppendOnly bool `yaml:"appendOnly,omitempty"`
+
+	// [DeadLetterQueue] - if enabled, messages that fail processing will be sent to the dead-letter queue instead of stopping the consumer.
+	DeadLetterQueue *DeadLetterQueueSettings `yaml:"deadLetterQueue,omitempty"`
+
+	// [FlattenedSettings] - This is only used by the flattened CDC format, if not specified, the defaults will be used.
+	FlattenedSettings *FlattenedSettings `yaml:"flattenedSettings,omitempty"`
+
+	// [ObjectKeyTemplate] - This is only used by object storage destinations (S3 and GCS) to build the object prefix, see [objectkey.Template].
+	// If not specified, objects are written under folderName/fullyQualifiedTableName/date=YYYY-MM-DD.
+	ObjectKeyTemplate string `yaml:"objectKeyTemplate,omitempty"`
+
+	// [RowFilter] - if specified, only rows that match this expression are written to the destination, see [rowfilter.Filter] for the syntax.
+	// The expression is evaluated against the typed row before columns are hashed, encrypted or excluded. Deletes are evaluated against the before image, which may only contain the primary keys.
+	RowFilter string `yaml:"rowFilter,omitempty"`
+}
+
+func (t TopicConfig) GetFlattenedSettings() FlattenedSettings {
+	if t.FlattenedSettings == nil {
+		return FlattenedSettings{}
+	}
+
+	return *t.FlattenedSettings
 }
 
 func (t TopicConfig) BuildDatabaseAndSchemaPair() DatabaseAndSchemaPair {
@@ -249,11 +273,13 @@ func (t TopicConfig) ReusableStagingTableNamePrefix() string {
 }
 
 const (
-	StringKeyFmt = "org.apache.kafka.connect.storage.StringConverter"
-	JSONKeyFmt   = "org.apache.kafka.connect.json.JsonConverter"
+	StringKeyFmt   = "org.apache.kafka.connect.storage.StringConverter"
+	JSONKeyFmt     = "org.apache.kafka.connect.json.JsonConverter"
+	AvroKeyFmt     = "io.confluent.connect.avro.AvroConverter"
+	ProtobufKeyFmt = "io.confluent.connect.protobuf.ProtobufConverter"
 )
 
-var validKeyFormats = []string{StringKeyFmt, JSONKeyFmt}
+var validKeyFormats = []string{StringKeyFmt, JSONKeyFmt, AvroKeyFmt, ProtobufKeyFmt}
 
 func (t TopicConfig) String() string {
 	var msmEnabled bool
@@ -294,6 +320,24 @@ func (t TopicConfig) Validate() error {
 		return fmt.Errorf("invalid soft partitioning configuration: %w", err)
 	}
 
+	if t.DeadLetterQueue != nil {
+		if err := t.DeadLetterQueue.Validate(); err != nil {
+			return fmt.Errorf("invalid dead-letter queue configuration: %w", err)
+		}
+	}
+
+	if t.ObjectKeyTemplate != "" {
+		if _, err := objectkey.Parse(t.ObjectKeyTemplate); err != nil {
+			return fmt.Errorf("invalid object key template: %w", err)
+		}
+	}
+
+	if t.RowFilter != "" {
+		if _, err := rowfilter.Parse(t.RowFilter); err != nil {
+			return fmt.Errorf("invalid row filter: %w", err)
+		}
+	}
+
 	if len(t.ColumnsToEncrypt) > 0 {
 		encryptSet := make(map[string]bool, len(t.ColumnsToEncrypt))
 		for _, col := range t.ColumnsToEncrypt {
@@ -336,6 +336,36 @@ func TestTopicConfig_Validate(t *testing.T) {
 		}
 		assert.NoError(t, tc.Validate())
 	}
+	{
+		// Invalid object key template
+		tc := TopicConfig{
+			Database:          "db",
+			Schema:            "schema",
+			Topic:             "topic",
+			CDCFormat:         "debezium",
+			CDCKeyFormat:      JSONKeyFmt,
+			ObjectKeyTemplate: "{fqTableName}/{date}",
+		}
+		assert.ErrorContains(t, tc.Validate(), `invalid object key template: invalid placeholder "{date}" in object key template`)
+
+		tc.ObjectKeyTemplate = "{fqTableName}/dt={yyyy}-{MM}-{dd}/hour={HH}"
+		assert.NoError(t, tc.Validate())
+	}
+	{
+		// Invalid row filter
+		tc := TopicConfig{
+			Database:     "db",
+			Schema:       "schema",
+			Topic:        "topic",
+			CDCFormat:    "debezium",
+			CDCKeyFormat: JSONKeyFmt,
+			RowFilter:    "tenant_id NOT IN ('test'",
+		}
+		assert.ErrorContains(t, tc.Validate(), `invalid row filter: expected ")", got end of expression`)
+
+		tc.RowFilter = "tenant_id NOT IN ('test') AND archived_at IS NULL"
+		assert.NoError(t, tc.Validate())
+	}
 }
 
 func TestMultiStepMergeSettings_Validate(t *testing.T) {
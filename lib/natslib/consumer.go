@@ -0,0 +1,293 @@
+package natslib
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+// defaultFetchMaxWait is how long a fetch waits for the batch to fill up before returning what it has.
+const defaultFetchMaxWait = 5 * time.Second
+
+// Consumer reads a single subject through a durable pull consumer with [jetstream.AckAllPolicy].
+// Messages are only acked once they have been flushed, acking the last applied message acks everything before it.
+type Consumer struct {
+	mu        sync.Mutex
+	topic     string
+	groupID   string
+	keyHeader string
+	batchSize int
+	paused    atomic.Bool
+	// fetchMaxWait is only overridden in tests.
+	fetchMaxWait time.Duration
+
+	conn     *nats.Conn
+	consumer jetstream.Consumer
+	batch    jetstream.MessageBatch
+
+	lastApplied   *Message
+	lastCommitted uint64
+}
+
+func connect(cfg *NATS) (*nats.Conn, error) {
+	opts := []nats.Option{nats.Name("artie-transfer")}
+	if cfg.Username != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, nats.Token(cfg.Token))
+	}
+
+	return nats.Connect(cfg.URL, opts...)
+}
+
+// CheckConnectivity connects to the server in [cfg] and verifies that the stream exists.
+func CheckConnectivity(ctx context.Context, cfg *NATS) error {
+	conn, err := connect(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	if _, err = js.Stream(ctx, cfg.Stream); err != nil {
+		return fmt.Errorf("failed to look up stream %q: %w", cfg.Stream, err)
+	}
+
+	return nil
+}
+
+// FetchSample reads up to [limit] messages for [topic] from the start of the stream.
+// An ephemeral ordered consumer is used so that nothing is acked and the durable consumer is left untouched.
+func FetchSample(ctx context.Context, cfg *NATS, topic string, limit int) ([]artie.Message, error) {
+	conn, err := connect(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	consumer, err := js.OrderedConsumer(ctx, cfg.Stream, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{topic},
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ordered consumer for subject %q: %w", topic, err)
+	}
+
+	batch, err := consumer.Fetch(limit, jetstream.FetchMaxWait(defaultFetchMaxWait))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch messages for subject %q: %w", topic, err)
+	}
+
+	var msgs []artie.Message
+	for msg := range batch.Messages() {
+		message, err := NewMessage(msg, topic, cfg.KeyHeader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message metadata: %w", err)
+		}
+		msgs = append(msgs, message)
+	}
+
+	if err = batch.Error(); err != nil {
+		return nil, fmt.Errorf("failed to fetch messages for subject %q: %w", topic, err)
+	}
+
+	return msgs, nil
+}
+
+// NewConsumer creates the durable consumer for [topicConfig] if it doesn't already exist, the stream must already exist.
+func NewConsumer(ctx context.Context, cfg *NATS, topicConfig kafkalib.TopicConfig) (*Consumer, error) {
+	conn, err := connect(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	durableName := cfg.DurableName(topicConfig.Topic)
+	consumer, err := js.CreateOrUpdateConsumer(ctx, cfg.Stream, jetstream.ConsumerConfig{
+		Durable:       durableName,
+		FilterSubject: topicConfig.Topic,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		AckPolicy:     jetstream.AckAllPolicy,
+		AckWait:       cfg.GetAckWait(),
+		// Nothing is acked until the topic is flushed, so the number of pending acks is bounded by the buffer instead.
+		MaxAckPending: -1,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create consumer %q for subject %q: %w", durableName, topicConfig.Topic, err)
+	}
+
+	slog.Info("Created NATS consumer for subject",
+		slog.String("subject", topicConfig.Topic),
+		slog.String("stream", cfg.Stream),
+		slog.String("durableName", durableName),
+	)
+
+	return &Consumer{
+		topic:        topicConfig.Topic,
+		groupID:      cfg.ConsumerName,
+		keyHeader:    cfg.KeyHeader,
+		batchSize:    cfg.GetFetchBatchSize(),
+		fetchMaxWait: defaultFetchMaxWait,
+		conn:         conn,
+		consumer:     consumer,
+	}, nil
+}
+
+func InjectConsumersIntoContext(ctx context.Context, cfg *NATS) (context.Context, error) {
+	registry := kafkalib.NewConsumerRegistry()
+	for _, topicConfig := range cfg.TopicConfigs {
+		consumer, err := NewConsumer(ctx, cfg, *topicConfig)
+		if err != nil {
+			registry.CloseAll()
+			return nil, err
+		}
+
+		registry.Add(consumer)
+	}
+
+	return kafkalib.InjectConsumerRegistryIntoContext(ctx, registry), nil
+}
+
+func (c *Consumer) Topic() string {
+	return c.topic
+}
+
+func (c *Consumer) GetGroupID() string {
+	return c.groupID
+}
+
+// AssignedPartitions always returns partition 0 since the durable consumer is created upfront and subjects are not partitioned.
+func (c *Consumer) AssignedPartitions() ([]int32, bool) {
+	return []int32{0}, true
+}
+
+// PauseFetching stops fetching new batches, messages from the current batch will still be returned.
+func (c *Consumer) PauseFetching() {
+	c.paused.Store(true)
+}
+
+func (c *Consumer) ResumeFetching() {
+	c.paused.Store(false)
+}
+
+// WaitForTopic is a no-op, the stream has to exist for [NewConsumer] to succeed.
+func (c *Consumer) WaitForTopic(_ context.Context) error {
+	return nil
+}
+
+func (c *Consumer) Close() error {
+	c.conn.Close()
+	return nil
+}
+
+func (c *Consumer) fetchMessage(ctx context.Context) (Message, error) {
+	if c.batch == nil {
+		if c.paused.Load() {
+			return Message{}, kafkalib.ErrNoMessages
+		}
+
+		batch, err := c.consumer.Fetch(c.batchSize, jetstream.FetchMaxWait(c.fetchMaxWait))
+		if err != nil {
+			return Message{}, err
+		}
+
+		c.batch = batch
+	}
+
+	select {
+	case <-ctx.Done():
+		return Message{}, ctx.Err()
+	case msg, ok := <-c.batch.Messages():
+		if !ok {
+			err := c.batch.Error()
+			c.batch = nil
+			if err != nil {
+				return Message{}, err
+			}
+
+			return Message{}, kafkalib.ErrNoMessages
+		}
+
+		return NewMessage(msg, c.topic, c.keyHeader)
+	}
+}
+
+func (c *Consumer) FetchMessageAndProcess(ctx context.Context, do func(artie.Message) error) error {
+	ctx, cancel := context.WithTimeout(ctx, kafkalib.FetchMessageTimeout)
+	defer cancel()
+
+	msg, err := c.fetchMessage(ctx)
+	if err != nil {
+		return kafkalib.NewFetchMessageError(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lastApplied != nil && c.lastApplied.Offset() >= msg.Offset() {
+		// This was redelivered after the ack wait expired, it has already been processed.
+		return nil
+	}
+
+	if err = do(msg); err != nil {
+		return fmt.Errorf("failed to process message: %w", err)
+	}
+
+	c.lastApplied = &msg
+	return nil
+}
+
+func (c *Consumer) LockAndProcess(_ context.Context, lock bool, do func() error) error {
+	if lock {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+
+	if err := do(); err != nil {
+		return fmt.Errorf("failed to process: %w", err)
+	}
+
+	return nil
+}
+
+// CommitMessage acks the last applied message and waits for the server to confirm it.
+func (c *Consumer) CommitMessage(ctx context.Context) error {
+	if c.lastApplied == nil || c.lastApplied.metadata.Sequence.Stream == c.lastCommitted {
+		return nil
+	}
+
+	if err := c.lastApplied.msg.DoubleAck(ctx); err != nil {
+		return fmt.Errorf("failed to ack messages: %w", err)
+	}
+
+	c.lastCommitted = c.lastApplied.metadata.Sequence.Stream
+	slog.Info("Acked messages", slog.String("subject", c.topic), slog.Uint64("streamSequence", c.lastCommitted))
+	return nil
+}
+
+var _ kafkalib.QueueConsumer = (*Consumer)(nil)
@@ -0,0 +1,175 @@
+package natslib
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+func runServer(t *testing.T) *NATS {
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoLog: true, NoSigs: true})
+	assert.NoError(t, err)
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+	assert.True(t, srv.ReadyForConnections(10*time.Second))
+
+	cfg := &NATS{URL: srv.ClientURL(), Stream: "cdc", ConsumerName: "transfer", KeyHeader: "key"}
+	conn, err := nats.Connect(cfg.URL)
+	assert.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	assert.NoError(t, err)
+	_, err = js.CreateStream(t.Context(), jetstream.StreamConfig{Name: cfg.Stream, Subjects: []string{"cdc.>"}})
+	assert.NoError(t, err)
+	for i := range 3 {
+		msg := nats.NewMsg("cdc.public.orders")
+		msg.Header.Set("key", fmt.Sprintf(`{"id": %d}`, i))
+		msg.Data = fmt.Appendf(nil, `{"payload": %d}`, i)
+		_, err = js.PublishMsg(t.Context(), msg)
+		assert.NoError(t, err)
+	}
+
+	return cfg
+}
+
+func newConsumer(t *testing.T, cfg *NATS) *Consumer {
+	consumer, err := NewConsumer(t.Context(), cfg, kafkalib.TopicConfig{Topic: "cdc.public.orders"})
+	assert.NoError(t, err)
+	consumer.fetchMaxWait = 100 * time.Millisecond
+	t.Cleanup(func() { assert.NoError(t, consumer.Close()) })
+	return consumer
+}
+
+func fetch(t *testing.T, consumer *Consumer) []artie.Message {
+	var msgs []artie.Message
+	for {
+		err := consumer.FetchMessageAndProcess(t.Context(), func(msg artie.Message) error {
+			msgs = append(msgs, msg)
+			return nil
+		})
+		if err != nil {
+			assert.ErrorIs(t, err, kafkalib.ErrNoMessages)
+			return msgs
+		}
+	}
+}
+
+func TestConsumer(t *testing.T) {
+	cfg := runServer(t)
+	{
+		// Fetch every message, nothing has been acked yet
+		consumer := newConsumer(t, cfg)
+		msgs := fetch(t, consumer)
+		assert.Len(t, msgs, 3)
+		for i, msg := range msgs {
+			assert.Equal(t, "cdc.public.orders", msg.Topic())
+			assert.Equal(t, 0, msg.Partition())
+			assert.Equal(t, int64(i+1), msg.Offset())
+			assert.Equal(t, int64(4), msg.HighWaterMark())
+			assert.Equal(t, fmt.Sprintf(`{"id": %d}`, i), string(msg.Key()))
+			assert.Equal(t, fmt.Sprintf(`{"payload": %d}`, i), string(msg.Value()))
+			assert.Equal(t, []artie.Header{{Key: "key", Value: msg.Key()}}, msg.Headers())
+		}
+
+		partitions, joinedGroup := consumer.AssignedPartitions()
+		assert.Equal(t, []int32{0}, partitions)
+		assert.True(t, joinedGroup)
+		assert.Equal(t, "transfer", consumer.GetGroupID())
+	}
+	{
+		// Nothing was acked, so a new consumer will receive the messages again once the ack wait expires
+		cfg.AckWaitSeconds = 1
+		consumer := newConsumer(t, cfg)
+		time.Sleep(time.Second)
+		msgs := fetch(t, consumer)
+		assert.Len(t, msgs, 3)
+
+		// Acking the last message acks everything before it
+		assert.NoError(t, consumer.CommitMessage(t.Context()))
+		assert.NoError(t, consumer.CommitMessage(t.Context()))
+
+		info, err := consumer.consumer.Info(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(3), info.AckFloor.Stream)
+		assert.Zero(t, info.NumAckPending)
+	}
+	{
+		// Pausing will stop fetching new batches
+		consumer := newConsumer(t, cfg)
+		consumer.PauseFetching()
+		err := consumer.FetchMessageAndProcess(t.Context(), func(artie.Message) error { return nil })
+		assert.ErrorIs(t, err, kafkalib.ErrNoMessages)
+		consumer.ResumeFetching()
+	}
+}
+
+func TestConsumer_SkipsRedeliveredMessages(t *testing.T) {
+	cfg := runServer(t)
+	cfg.AckWaitSeconds = 1
+	consumer := newConsumer(t, cfg)
+	assert.Len(t, fetch(t, consumer), 3)
+
+	// The messages are redelivered since they were not acked within the ack wait, they have already been processed so they are skipped.
+	time.Sleep(time.Second)
+	assert.Empty(t, fetch(t, consumer))
+}
+
+func TestInjectConsumersIntoContext(t *testing.T) {
+	cfg := runServer(t)
+	cfg.TopicConfigs = []*kafkalib.TopicConfig{{Topic: "cdc.public.orders"}, {Topic: "cdc.public.customers"}}
+	ctx, err := InjectConsumersIntoContext(t.Context(), cfg)
+	assert.NoError(t, err)
+
+	topics, ok := kafkalib.GetTopicsFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"cdc.public.customers", "cdc.public.orders"}, topics)
+
+	registry, _ := kafkalib.GetConsumerRegistryFromContext(ctx)
+	registry.CloseAll()
+
+	// The stream has to exist
+	cfg.Stream = "missing"
+	_, err = InjectConsumersIntoContext(context.Background(), cfg)
+	assert.ErrorContains(t, err, `failed to create consumer "transfer_cdc_public_orders" for subject "cdc.public.orders"`)
+}
+
+func TestCheckConnectivity(t *testing.T) {
+	cfg := runServer(t)
+	assert.NoError(t, CheckConnectivity(t.Context(), cfg))
+
+	// The stream has to exist
+	missingStream := *cfg
+	missingStream.Stream = "missing"
+	assert.ErrorContains(t, CheckConnectivity(t.Context(), &missingStream), `failed to look up stream "missing"`)
+
+	// The server has to be reachable
+	unreachable := *cfg
+	unreachable.URL = "nats://127.0.0.1:1"
+	assert.ErrorContains(t, CheckConnectivity(t.Context(), &unreachable), "failed to connect to NATS")
+}
+
+func TestFetchSample(t *testing.T) {
+	cfg := runServer(t)
+	{
+		msgs, err := FetchSample(t.Context(), cfg, "cdc.public.orders", 2)
+		assert.NoError(t, err)
+		assert.Len(t, msgs, 2)
+		assert.Equal(t, `{"id": 0}`, string(msgs[0].Key()))
+		assert.Equal(t, `{"payload": 1}`, string(msgs[1].Value()))
+	}
+	{
+		// Sampling should not create or advance the durable consumer
+		msgs := fetch(t, newConsumer(t, cfg))
+		assert.Len(t, msgs, 3)
+	}
+}
@@ -0,0 +1,76 @@
+package natslib
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/artie-labs/transfer/lib/artie"
+)
+
+// Message adapts a JetStream message into an [artie.Message], the stream sequence is used as the offset and everything is read from partition 0.
+type Message struct {
+	msg      jetstream.Msg
+	topic    string
+	key      []byte
+	metadata *jetstream.MsgMetadata
+}
+
+func NewMessage(msg jetstream.Msg, topic string, keyHeader string) (Message, error) {
+	metadata, err := msg.Metadata()
+	if err != nil {
+		return Message{}, err
+	}
+
+	var key []byte
+	if keyHeader != "" {
+		if value := msg.Headers().Get(keyHeader); value != "" {
+			key = []byte(value)
+		}
+	}
+
+	return Message{msg: msg, topic: topic, key: key, metadata: metadata}, nil
+}
+
+func (m Message) PublishTime() time.Time {
+	return m.metadata.Timestamp
+}
+
+// Topic returns the subject from the topic config, this may differ from the message's subject if the topic has wildcards.
+func (m Message) Topic() string {
+	return m.topic
+}
+
+func (m Message) Partition() int {
+	return 0
+}
+
+func (m Message) Offset() int64 {
+	return int64(m.metadata.Sequence.Stream)
+}
+
+func (m Message) Key() []byte {
+	return m.key
+}
+
+func (m Message) Value() []byte {
+	return m.msg.Data()
+}
+
+// HighWaterMark mirrors Kafka's definition, which is the offset of the next message that will be written.
+func (m Message) HighWaterMark() int64 {
+	return m.Offset() + int64(m.metadata.NumPending) + 1
+}
+
+func (m Message) Headers() []artie.Header {
+	var headers []artie.Header
+	for _, key := range slices.Sorted(maps.Keys(m.msg.Headers())) {
+		for _, value := range m.msg.Headers()[key] {
+			headers = append(headers, artie.Header{Key: key, Value: []byte(value)})
+		}
+	}
+
+	return headers
+}
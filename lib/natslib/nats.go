@@ -0,0 +1,90 @@
+package natslib
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/stringutil"
+)
+
+const (
+	defaultAckWait        = 10 * time.Minute
+	defaultFetchBatchSize = 500
+)
+
+type NATS struct {
+	// Comma-separated NATS servers, e.g. nats://host1:4222,nats://host2:4222
+	URL string `yaml:"url"`
+	// Stream is the JetStream stream that holds every subject in [TopicConfigs].
+	Stream string `yaml:"stream"`
+	// ConsumerName is used to name the durable consumer for each subject, this serves the same purpose as the Kafka group ID.
+	ConsumerName string `yaml:"consumerName"`
+	// TopicConfigs are keyed by subject, the topic is the subject that the durable consumer filters on.
+	TopicConfigs []*kafkalib.TopicConfig `yaml:"topicConfigs"`
+
+	// Optional parameters
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	Token    string `yaml:"token,omitempty"`
+	// KeyHeader is the message header that holds the message key, NATS messages do not have keys.
+	// If this is not set, every topic needs to set [kafkalib.TopicConfig.PrimaryKeysOverride].
+	KeyHeader string `yaml:"keyHeader,omitempty"`
+	// AckWaitSeconds is how long the server waits for an ack before redelivering, this should be longer than the flush interval.
+	AckWaitSeconds int `yaml:"ackWaitSeconds,omitempty"`
+	FetchBatchSize int `yaml:"fetchBatchSize,omitempty"`
+}
+
+func (n *NATS) Validate() error {
+	if n == nil {
+		return fmt.Errorf("nats config is nil")
+	}
+
+	if stringutil.Empty(n.URL, n.Stream, n.ConsumerName) {
+		return fmt.Errorf("nats url, stream or consumer name is empty")
+	}
+
+	if n.AckWaitSeconds < 0 {
+		return fmt.Errorf("nats ackWaitSeconds cannot be negative")
+	}
+
+	if n.FetchBatchSize < 0 {
+		return fmt.Errorf("nats fetchBatchSize cannot be negative")
+	}
+
+	return nil
+}
+
+func (n *NATS) GetAckWait() time.Duration {
+	if n.AckWaitSeconds > 0 {
+		return time.Duration(n.AckWaitSeconds) * time.Second
+	}
+
+	return defaultAckWait
+}
+
+func (n *NATS) GetFetchBatchSize() int {
+	return cmp.Or(n.FetchBatchSize, defaultFetchBatchSize)
+}
+
+func (n *NATS) Topics() []string {
+	var out []string
+	for _, config := range n.TopicConfigs {
+		out = append(out, config.Topic)
+	}
+
+	return out
+}
+
+func (n *NATS) String() string {
+	// Don't log credentials.
+	return fmt.Sprintf("url=%s, stream=%s, consumerName=%s, user_set=%v, pass_set=%v, token_set=%v",
+		n.URL, n.Stream, n.ConsumerName, n.Username != "", n.Password != "", n.Token != "")
+}
+
+// DurableName returns the name of the durable consumer for [subject], durable names cannot contain any of the subject tokens.
+func (n *NATS) DurableName(subject string) string {
+	return n.ConsumerName + "_" + strings.NewReplacer(".", "_", "*", "_", ">", "_").Replace(subject)
+}
@@ -0,0 +1,37 @@
+package natslib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNATS_Validate(t *testing.T) {
+	{
+		// Nil
+		var cfg *NATS
+		assert.ErrorContains(t, cfg.Validate(), "nats config is nil")
+	}
+	{
+		// Missing stream
+		assert.ErrorContains(t, (&NATS{URL: "nats://localhost:4222", ConsumerName: "transfer"}).Validate(), "nats url, stream or consumer name is empty")
+	}
+	{
+		// Negative ack wait
+		assert.ErrorContains(t, (&NATS{URL: "nats://localhost:4222", Stream: "cdc", ConsumerName: "transfer", AckWaitSeconds: -1}).Validate(), "nats ackWaitSeconds cannot be negative")
+	}
+	{
+		// Valid
+		cfg := &NATS{URL: "nats://localhost:4222", Stream: "cdc", ConsumerName: "transfer"}
+		assert.NoError(t, cfg.Validate())
+		assert.Equal(t, 10*time.Minute, cfg.GetAckWait())
+		assert.Equal(t, 500, cfg.GetFetchBatchSize())
+	}
+}
+
+func TestNATS_DurableName(t *testing.T) {
+	cfg := &NATS{ConsumerName: "transfer"}
+	assert.Equal(t, "transfer_cdc_public_orders", cfg.DurableName("cdc.public.orders"))
+	assert.Equal(t, "transfer_cdc____", cfg.DurableName("cdc.*.>"))
+}
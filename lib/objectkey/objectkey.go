@@ -0,0 +1,142 @@
+package objectkey
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HiveDefaultPartition is used in place of null column values, this matches what Hive uses for null partitions.
+const HiveDefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
+const columnPlaceholderPrefix = "column:"
+
+var placeholderRegex = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// Placeholders that are supported in addition to {column:<name>}.
+var placeholders = map[string]bool{
+	"folder":      true,
+	"database":    true,
+	"schema":      true,
+	"table":       true,
+	"fqTableName": true,
+	"yyyy":        true,
+	"MM":          true,
+	"dd":          true,
+	"HH":          true,
+	"operation":   true,
+	"partition":   true,
+}
+
+// Values are used to render a [Template] for a single row.
+type Values struct {
+	Folder                  string
+	Database                string
+	Schema                  string
+	Table                   string
+	FullyQualifiedTableName string
+	// ExecutionTime is the time the event was executed in the source database, the time placeholders are rendered in UTC.
+	ExecutionTime time.Time
+	Operation     string
+	Partition     int
+	// Column returns the string value of a column, nil values should return false.
+	Column func(name string) (string, bool, error)
+}
+
+// Template is a parsed object key template, e.g. "{folder}/{fqTableName}/year={yyyy}/month={MM}/day={dd}/hour={HH}".
+type Template struct {
+	template string
+}
+
+func Parse(template string) (Template, error) {
+	if strings.TrimSpace(template) == "" {
+		return Template{}, fmt.Errorf("object key template cannot be empty")
+	}
+
+	for _, match := range placeholderRegex.FindAllStringSubmatch(template, -1) {
+		name := match[1]
+		if column, ok := strings.CutPrefix(name, columnPlaceholderPrefix); ok {
+			if column == "" {
+				return Template{}, fmt.Errorf("column placeholder is missing a column name")
+			}
+			continue
+		}
+
+		if !placeholders[name] {
+			return Template{}, fmt.Errorf("invalid placeholder %q in object key template", match[0])
+		}
+	}
+
+	return Template{template: template}, nil
+}
+
+func (t Template) String() string {
+	return t.template
+}
+
+// Render returns the object prefix for [values], empty path segments are dropped and values are path escaped so they cannot add segments.
+func (t Template) Render(values Values) (string, error) {
+	executionTime := values.ExecutionTime.UTC()
+	var renderErr error
+	rendered := placeholderRegex.ReplaceAllStringFunc(t.template, func(match string) string {
+		name := match[1 : len(match)-1]
+		switch name {
+		case "folder":
+			// The folder is allowed to contain slashes.
+			return values.Folder
+		case "database":
+			return url.PathEscape(values.Database)
+		case "schema":
+			return url.PathEscape(values.Schema)
+		case "table":
+			return url.PathEscape(values.Table)
+		case "fqTableName":
+			return url.PathEscape(values.FullyQualifiedTableName)
+		case "yyyy":
+			return executionTime.Format("2006")
+		case "MM":
+			return executionTime.Format("01")
+		case "dd":
+			return executionTime.Format("02")
+		case "HH":
+			return executionTime.Format("15")
+		case "operation":
+			return url.PathEscape(values.Operation)
+		case "partition":
+			return strconv.Itoa(values.Partition)
+		}
+
+		column := strings.TrimPrefix(name, columnPlaceholderPrefix)
+		if values.Column == nil {
+			return HiveDefaultPartition
+		}
+
+		value, ok, err := values.Column(column)
+		if err != nil {
+			renderErr = fmt.Errorf("failed to get value for column %q: %w", column, err)
+			return ""
+		}
+
+		if !ok {
+			return HiveDefaultPartition
+		}
+
+		return url.PathEscape(value)
+	})
+
+	if renderErr != nil {
+		return "", renderErr
+	}
+
+	var segments []string
+	for segment := range strings.SplitSeq(rendered, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return strings.Join(segments, "/"), nil
+}
@@ -0,0 +1,86 @@
+package objectkey
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	{
+		// Empty
+		_, err := Parse(" ")
+		assert.ErrorContains(t, err, "object key template cannot be empty")
+	}
+	{
+		// Invalid placeholder
+		_, err := Parse("{folder}/{date}")
+		assert.ErrorContains(t, err, `invalid placeholder "{date}" in object key template`)
+	}
+	{
+		// Missing column name
+		_, err := Parse("{fqTableName}/{column:}")
+		assert.ErrorContains(t, err, "column placeholder is missing a column name")
+	}
+	{
+		// Valid
+		template, err := Parse("{folder}/{fqTableName}/year={yyyy}/month={MM}/day={dd}/hour={HH}/op={operation}/p={partition}/region={column:region}")
+		assert.NoError(t, err)
+		assert.Equal(t, "{folder}/{fqTableName}/year={yyyy}/month={MM}/day={dd}/hour={HH}/op={operation}/p={partition}/region={column:region}", template.String())
+	}
+}
+
+func TestTemplate_Render(t *testing.T) {
+	values := Values{
+		Folder:                  "exports/cdc",
+		Database:                "shop",
+		Schema:                  "public",
+		Table:                   "orders",
+		FullyQualifiedTableName: "shop.public.orders",
+		ExecutionTime:           time.Date(2025, 3, 4, 23, 30, 0, 0, time.FixedZone("PST", -8*60*60)),
+		Operation:               "u",
+		Partition:               7,
+		Column: func(name string) (string, bool, error) {
+			switch name {
+			case "region":
+				return "us/west", true, nil
+			case "broken":
+				return "", false, fmt.Errorf("boom")
+			}
+			return "", false, nil
+		},
+	}
+	{
+		// Time placeholders are rendered in UTC
+		template, err := Parse("{folder}/{fqTableName}/year={yyyy}/month={MM}/day={dd}/hour={HH}")
+		assert.NoError(t, err)
+		prefix, err := template.Render(values)
+		assert.NoError(t, err)
+		assert.Equal(t, "exports/cdc/shop.public.orders/year=2025/month=03/day=05/hour=07", prefix)
+	}
+	{
+		// Column values are escaped, null columns use the Hive default partition
+		template, err := Parse("{database}/{schema}/{table}/region={column:region}/tier={column:tier}/{operation}/{partition}")
+		assert.NoError(t, err)
+		prefix, err := template.Render(values)
+		assert.NoError(t, err)
+		assert.Equal(t, "shop/public/orders/region=us%2Fwest/tier=__HIVE_DEFAULT_PARTITION__/u/7", prefix)
+	}
+	{
+		// Empty segments are dropped
+		template, err := Parse("{folder}/{table}")
+		assert.NoError(t, err)
+		prefix, err := template.Render(Values{Table: "orders"})
+		assert.NoError(t, err)
+		assert.Equal(t, "orders", prefix)
+	}
+	{
+		// Column error
+		template, err := Parse("{table}/{column:broken}")
+		assert.NoError(t, err)
+		_, err = template.Render(values)
+		assert.ErrorContains(t, err, `failed to get value for column "broken": boom`)
+	}
+}
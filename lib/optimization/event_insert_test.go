@@ -110,7 +110,7 @@ func TestInsertRow_Toast(t *testing.T) {
 		}
 
 		var actualSize int
-		for _, rowData := range td.Rows() {
+		for _, rowData := range collectRows(t, td) {
 			actualSize += size.GetApproxSize(rowData.GetData())
 		}
 
@@ -1,9 +1,23 @@
 package optimization
 
-import "github.com/artie-labs/transfer/lib/size"
+import (
+	"time"
+
+	"github.com/artie-labs/transfer/lib/size"
+)
+
+// RowMetadata describes the event that produced the row, this is not written to the destination.
+type RowMetadata struct {
+	// ExecutionTime is when the event was executed in the source database.
+	ExecutionTime time.Time
+	Operation     string
+	// Partition is the Kafka partition the event was consumed from.
+	Partition int
+}
 
 type Row struct {
-	data map[string]any
+	data     map[string]any
+	metadata RowMetadata
 }
 
 func NewRow(data map[string]any) Row {
@@ -12,6 +26,13 @@ func NewRow(data map[string]any) Row {
 	}
 }
 
+func NewRowWithMetadata(data map[string]any, metadata RowMetadata) Row {
+	return Row{
+		data:     data,
+		metadata: metadata,
+	}
+}
+
 func (r Row) GetValue(key string) (any, bool) {
 	val, ok := r.data[key]
 	return val, ok
@@ -21,6 +42,10 @@ func (r Row) GetData() map[string]any {
 	return r.data
 }
 
+func (r Row) Metadata() RowMetadata {
+	return r.metadata
+}
+
 func (r Row) GetApproxSize() int {
 	return size.GetApproxSize(r.GetData())
 }
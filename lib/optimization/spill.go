@@ -0,0 +1,150 @@
+package optimization
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func init() {
+	// Rows are stored as map[string]any, so every concrete type that can show up as a value needs to be registered.
+	// Primitives and their slices are registered by gob already.
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+	gob.Register([]map[string]any{})
+	gob.Register(time.Time{})
+	gob.Register(json.Number(""))
+	gob.Register(&decimal.Decimal{})
+	gob.Register(ext.Time{})
+}
+
+// spilledRow is how a [Row] is encoded in the spill file.
+type spilledRow struct {
+	Data     map[string]any
+	Metadata RowMetadata
+}
+
+type spillLocation struct {
+	offset int64
+	length int
+	// approxSize is the approximate size of the row when it was in memory.
+	approxSize int
+}
+
+// spillFile stores rows that have been moved out of memory until the table is flushed.
+// Each row is gob encoded on its own so that it can be read back by its location, which allows replication mode to keep deduping by primary key.
+type spillFile struct {
+	file   *os.File
+	offset int64
+	// approxSize is the total approximate in-memory size of the live rows in this file.
+	approxSize int
+	// index maps the primary key to the location of its row, this is only used in replication mode.
+	index map[string]spillLocation
+	// locations preserves the insertion order, this is only used in history mode since rows are append only.
+	locations []spillLocation
+}
+
+func newSpillFile(directory string) (*spillFile, error) {
+	file, err := os.CreateTemp(directory, "transfer-spill-*.gob")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spill file: %w", err)
+	}
+
+	return &spillFile{file: file, index: make(map[string]spillLocation)}, nil
+}
+
+func (s *spillFile) write(row Row) (spillLocation, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(spilledRow{Data: row.GetData(), Metadata: row.Metadata()}); err != nil {
+		return spillLocation{}, fmt.Errorf("failed to encode row: %w", err)
+	}
+
+	if _, err := s.file.WriteAt(buf.Bytes(), s.offset); err != nil {
+		return spillLocation{}, fmt.Errorf("failed to write to spill file: %w", err)
+	}
+
+	location := spillLocation{offset: s.offset, length: buf.Len(), approxSize: row.GetApproxSize()}
+	s.offset += int64(buf.Len())
+	s.approxSize += location.approxSize
+	return location, nil
+}
+
+func (s *spillFile) read(location spillLocation) (Row, error) {
+	buf := make([]byte, location.length)
+	if _, err := s.file.ReadAt(buf, location.offset); err != nil {
+		return Row{}, fmt.Errorf("failed to read from spill file: %w", err)
+	}
+
+	var row spilledRow
+	if err := gob.NewDecoder(bytes.NewReader(buf)).Decode(&row); err != nil {
+		return Row{}, fmt.Errorf("failed to decode row: %w", err)
+	}
+
+	return NewRowWithMetadata(row.Data, row.Metadata), nil
+}
+
+// append is used for history mode.
+func (s *spillFile) append(row Row) error {
+	location, err := s.write(row)
+	if err != nil {
+		return err
+	}
+
+	s.locations = append(s.locations, location)
+	return nil
+}
+
+// put is used for replication mode, the previous row for [pk] (if any) should have been removed already.
+func (s *spillFile) put(pk string, row Row) error {
+	location, err := s.write(row)
+	if err != nil {
+		return err
+	}
+
+	s.index[pk] = location
+	return nil
+}
+
+// remove drops [pk] from the index and returns its row, the row is left in the file but will no longer be read.
+func (s *spillFile) remove(pk string) (Row, bool, error) {
+	location, ok := s.index[pk]
+	if !ok {
+		return Row{}, false, nil
+	}
+
+	row, err := s.read(location)
+	if err != nil {
+		return Row{}, false, err
+	}
+
+	delete(s.index, pk)
+	s.approxSize -= location.approxSize
+	return row, true, nil
+}
+
+func (s *spillFile) numberOfRows() int {
+	return len(s.index) + len(s.locations)
+}
+
+func (s *spillFile) sizeOnDisk() int64 {
+	return s.offset
+}
+
+// close closes and deletes the spill file.
+func (s *spillFile) close() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close spill file: %w", err)
+	}
+
+	if err := os.Remove(s.file.Name()); err != nil {
+		return fmt.Errorf("failed to remove spill file: %w", err)
+	}
+
+	return nil
+}
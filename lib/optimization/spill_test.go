@@ -0,0 +1,229 @@
+package optimization
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/numbers"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func newSpillingTableData(t *testing.T, mode config.Mode) *TableData {
+	td := NewTableData(nil, mode, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+	td.EnableSpill(config.SpillSettings{Enabled: true, Directory: t.TempDir(), MemoryThresholdKb: 1})
+	return td
+}
+
+func collectRows(t *testing.T, td *TableData) []Row {
+	var rows []Row
+	for row, err := range td.AllRows() {
+		assert.NoError(t, err)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestSpillFile_RoundTrip(t *testing.T) {
+	spill, err := newSpillFile(t.TempDir())
+	assert.NoError(t, err)
+	defer spill.close()
+
+	data := map[string]any{
+		"id":       1,
+		"name":     "dusty",
+		"price":    decimal.NewDecimalWithPrecision(numbers.MustParseDecimal("123.45"), 5),
+		"ts":       time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		"time":     ext.NewTime(time.Date(0, 1, 1, 3, 4, 5, 0, time.UTC)),
+		"tags":     []any{"a", "b"},
+		"metadata": map[string]any{"nested": true},
+		"missing":  nil,
+	}
+
+	assert.NoError(t, spill.put("1", NewRow(data)))
+	row, ok, err := spill.remove("1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, row.GetData()["id"])
+	assert.Equal(t, "dusty", row.GetData()["name"])
+	assert.Equal(t, "123.45", row.GetData()["price"].(*decimal.Decimal).String())
+	assert.Equal(t, data["ts"], row.GetData()["ts"])
+	assert.Equal(t, data["time"], row.GetData()["time"])
+	assert.Equal(t, data["tags"], row.GetData()["tags"])
+	assert.Equal(t, data["metadata"], row.GetData()["metadata"])
+	assert.Contains(t, row.GetData(), "missing")
+
+	{
+		// Removing it again is a no-op
+		_, ok, err = spill.remove("1")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+	{
+		// Row metadata is kept
+		metadata := RowMetadata{ExecutionTime: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Operation: "u", Partition: 3}
+		assert.NoError(t, spill.put("2", NewRowWithMetadata(map[string]any{"id": 2}, metadata)))
+		row, ok, err = spill.remove("2")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, metadata, row.Metadata())
+	}
+}
+
+func TestTableData_Spill_Replication(t *testing.T) {
+	td := newSpillingTableData(t, config.Replication)
+	payload := strings.Repeat("a", 2048)
+	{
+		// Every insert exceeds the threshold, so the rows should be spilled straight away
+		assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1, "payload": payload, "name": "dusty"}, false))
+		assert.NoError(t, td.InsertRow("2", map[string]any{"id": 2, "payload": payload, "name": "robin"}, false))
+		assert.Equal(t, 0, td.InMemorySize())
+		assert.Equal(t, 2, td.SpilledRows())
+		assert.Positive(t, td.SpilledBytes())
+		assert.Equal(t, uint(2), td.NumberOfRows())
+		assert.Positive(t, td.ApproxSize())
+	}
+	{
+		// Spill stats are reset after they've been read
+		assert.Equal(t, SpillStats{Files: 1, Rows: 2, Bytes: td.SpilledBytes()}, td.ResetSpillStats())
+		assert.Equal(t, SpillStats{}, td.ResetSpillStats())
+	}
+	{
+		// Updating a spilled row should dedupe and carry over TOAST columns
+		assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1, "payload": constants.ToastUnavailableValuePlaceholder, "name": "dusty2"}, false))
+		assert.Equal(t, uint(2), td.NumberOfRows())
+
+		rows := collectRows(t, td)
+		assert.Len(t, rows, 2)
+		for _, row := range rows {
+			if row.GetData()["id"] == 1 {
+				assert.Equal(t, "dusty2", row.GetData()["name"])
+				assert.Equal(t, payload, row.GetData()["payload"])
+			}
+		}
+	}
+	{
+		// Deleting a spilled row should keep the previous values
+		assert.NoError(t, td.InsertRow("2", map[string]any{"id": 2, "name": nil, constants.DeleteColumnMarker: true}, true))
+		assert.Equal(t, uint(2), td.NumberOfRows())
+		assert.True(t, td.ContainsHardDeletes())
+
+		for _, row := range collectRows(t, td) {
+			if row.GetData()["id"] == 2 {
+				assert.Equal(t, "robin", row.GetData()["name"])
+				assert.Equal(t, true, row.GetData()[constants.DeleteColumnMarker])
+			}
+		}
+	}
+	{
+		// Wiping should delete the spill file
+		fileName := td.spill.file.Name()
+		td.WipeData()
+		assert.NoFileExists(t, fileName)
+		assert.Equal(t, uint(0), td.NumberOfRows())
+		assert.Equal(t, 0, td.SpilledRows())
+		assert.Empty(t, collectRows(t, td))
+	}
+}
+
+func TestTableData_Spill_History(t *testing.T) {
+	td := newSpillingTableData(t, config.History)
+	assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1, "payload": strings.Repeat("a", 2048)}, false))
+	assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1, "payload": strings.Repeat("b", 2048)}, false))
+	assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1, "payload": "c"}, false))
+	assert.Equal(t, 2, td.SpilledRows())
+	assert.Equal(t, uint(3), td.NumberOfRows())
+
+	// History mode is append only, so the insertion order should be kept
+	rows := collectRows(t, td)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, strings.Repeat("a", 2048), rows[0].GetData()["payload"])
+	assert.Equal(t, strings.Repeat("b", 2048), rows[1].GetData()["payload"])
+	assert.Equal(t, "c", rows[2].GetData()["payload"])
+
+	fileName := td.spill.file.Name()
+	assert.NoError(t, td.Close())
+	assert.NoFileExists(t, fileName)
+}
+
+func TestTableData_GroupRows(t *testing.T) {
+	td := newSpillingTableData(t, config.History)
+	assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1, "region": "us", "payload": strings.Repeat("a", 2048)}, false))
+	assert.NoError(t, td.InsertRow("2", map[string]any{"id": 2, "region": "eu", "payload": strings.Repeat("b", 2048)}, false))
+	assert.NoError(t, td.InsertRow("3", map[string]any{"id": 3, "region": "us", "payload": "c"}, false))
+	assert.Equal(t, 2, td.SpilledRows())
+	defer td.Close()
+
+	var calls int
+	groups, err := td.GroupRows(func(row Row) (string, error) {
+		calls++
+		return row.GetData()["region"].(string), nil
+	})
+	assert.NoError(t, err)
+	// Every row is only read and keyed once
+	assert.Equal(t, 3, calls)
+	assert.Len(t, groups, 2)
+
+	collect := func(rows iter.Seq2[Row, error]) []any {
+		var ids []any
+		for row, err := range rows {
+			assert.NoError(t, err)
+			ids = append(ids, row.GetData()["id"])
+		}
+		return ids
+	}
+	{
+		// Spilled and in-memory rows are kept in order
+		assert.Equal(t, []any{1, 3}, collect(groups["us"]))
+		assert.Equal(t, []any{2}, collect(groups["eu"]))
+		// Groups can be iterated over more than once
+		assert.Equal(t, []any{1, 3}, collect(groups["us"]))
+	}
+	{
+		// Key error
+		_, err = td.GroupRows(func(_ Row) (string, error) {
+			return "", fmt.Errorf("bad key")
+		})
+		assert.ErrorContains(t, err, "bad key")
+	}
+}
+
+func TestTableData_Spill_ReadError(t *testing.T) {
+	td := newSpillingTableData(t, config.History)
+	assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1, "payload": strings.Repeat("a", 2048)}, false))
+	assert.NoError(t, td.spill.file.Close())
+
+	_, err := td.Rows()
+	assert.ErrorContains(t, err, "failed to read from spill file")
+	assert.NoError(t, os.Remove(td.spill.file.Name()))
+}
+
+func TestTableData_Spill_BelowThreshold(t *testing.T) {
+	td := newSpillingTableData(t, config.Replication)
+	assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1}, false))
+	assert.Nil(t, td.spill)
+	assert.Positive(t, td.InMemorySize())
+	assert.Equal(t, 0, td.SpilledRows())
+	assert.NoError(t, td.Close())
+}
+
+func TestTableData_Spill_InvalidDirectory(t *testing.T) {
+	td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+	td.EnableSpill(config.SpillSettings{Enabled: true, Directory: "/does/not/exist", MemoryThresholdKb: 1})
+
+	// Failing to spill should keep the rows in memory
+	assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1, "payload": strings.Repeat("a", 2048)}, false))
+	assert.Nil(t, td.spill)
+	assert.Nil(t, td.spillSettings)
+	assert.Equal(t, uint(1), td.NumberOfRows())
+	assert.Len(t, collectRows(t, td), 1)
+}
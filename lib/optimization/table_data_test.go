@@ -25,6 +25,23 @@ func TestTableData_WipeData(t *testing.T) {
 	assert.True(t, td.ContainsHardDeletes())
 }
 
+func TestMultiStepMergeSettings_PendingFlushCount(t *testing.T) {
+	{
+		// Disabled
+		assert.Equal(t, 0, MultiStepMergeSettings{TotalFlushCount: 3}.PendingFlushCount())
+	}
+	{
+		// Enabled
+		settings := MultiStepMergeSettings{Enabled: true, TotalFlushCount: 2}
+		assert.Equal(t, 3, settings.PendingFlushCount())
+		settings.Increment()
+		settings.Increment()
+		assert.Equal(t, 1, settings.PendingFlushCount())
+		settings.Increment()
+		assert.Equal(t, 0, settings.PendingFlushCount())
+	}
+}
+
 func TestTableData_ReadOnlyInMemoryCols(t *testing.T) {
 	// Making sure the columns are actually read only.
 	cols := columns.NewColumns(nil)
@@ -188,35 +205,35 @@ func TestTableData_InsertRowSoftDelete(t *testing.T) {
 
 	td.InsertRow("123", map[string]any{"id": "123", "name": "dana", constants.DeleteColumnMarker: false, constants.OnlySetDeleteColumnMarker: false}, false)
 	assert.Equal(t, 1, int(td.NumberOfRows()))
-	assert.Equal(t, "dana", td.Rows()[0].GetData()["name"])
+	assert.Equal(t, "dana", collectRows(t, td)[0].GetData()["name"])
 
 	td.InsertRow("123", map[string]any{"id": "123", "name": "dana2", constants.DeleteColumnMarker: false, constants.OnlySetDeleteColumnMarker: false}, false)
 	assert.Equal(t, 1, int(td.NumberOfRows()))
-	assert.Equal(t, "dana2", td.Rows()[0].GetData()["name"])
+	assert.Equal(t, "dana2", collectRows(t, td)[0].GetData()["name"])
 
 	td.InsertRow("123", map[string]any{"id": "123", constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true}, true)
 	assert.Equal(t, 1, int(td.NumberOfRows()))
 	// The previous value should be preserved, along with the delete marker
-	assert.Equal(t, "dana2", td.Rows()[0].GetData()["name"])
-	assert.Equal(t, true, td.Rows()[0].GetData()[constants.DeleteColumnMarker])
+	assert.Equal(t, "dana2", collectRows(t, td)[0].GetData()["name"])
+	assert.Equal(t, true, collectRows(t, td)[0].GetData()[constants.DeleteColumnMarker])
 	// OnlySetDeleteColumnMarker should be false because we want to set the previously received values that haven't been flushed yet
-	assert.Equal(t, false, td.Rows()[0].GetData()[constants.OnlySetDeleteColumnMarker])
+	assert.Equal(t, false, collectRows(t, td)[0].GetData()[constants.OnlySetDeleteColumnMarker])
 
 	// Ensure two deletes in a row are handled idempotently (in case the delete event is sent twice)
 	td.InsertRow("123", map[string]any{"id": "123", constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true}, true)
 	assert.Equal(t, 1, int(td.NumberOfRows()))
-	assert.Equal(t, "dana2", td.Rows()[0].GetData()["name"])
-	assert.Equal(t, true, td.Rows()[0].GetData()[constants.DeleteColumnMarker])
-	assert.Equal(t, false, td.Rows()[0].GetData()[constants.OnlySetDeleteColumnMarker])
+	assert.Equal(t, "dana2", collectRows(t, td)[0].GetData()["name"])
+	assert.Equal(t, true, collectRows(t, td)[0].GetData()[constants.DeleteColumnMarker])
+	assert.Equal(t, false, collectRows(t, td)[0].GetData()[constants.OnlySetDeleteColumnMarker])
 	{
 		// If deleting a row we don't have in memory, OnlySetDeleteColumnMarker should stay true
 		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{SoftDelete: true}, "foo")
 		assert.Equal(t, 0, int(td.NumberOfRows()))
 		td.InsertRow("123", map[string]any{"id": "123", constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true}, true)
-		assert.Equal(t, true, td.Rows()[0].GetData()[constants.OnlySetDeleteColumnMarker])
+		assert.Equal(t, true, collectRows(t, td)[0].GetData()[constants.OnlySetDeleteColumnMarker])
 		// Two deletes in a row; OnlySetDeleteColumnMarker should still be true because we don't have the other values in memory
 		td.InsertRow("123", map[string]any{"id": "123", constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true}, true)
-		assert.Equal(t, true, td.Rows()[0].GetData()[constants.OnlySetDeleteColumnMarker])
+		assert.Equal(t, true, collectRows(t, td)[0].GetData()[constants.OnlySetDeleteColumnMarker])
 	}
 	{
 		// If a row is created and deleted, then another row with the same primary key is created, the previous values should not be used
@@ -225,9 +242,9 @@ func TestTableData_InsertRowSoftDelete(t *testing.T) {
 		td.InsertRow("123", map[string]any{"id": "123", "name": "dana", "foo": "abc", constants.DeleteColumnMarker: false, constants.OnlySetDeleteColumnMarker: false}, false)
 		td.InsertRow("123", map[string]any{"id": "123", constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true}, true)
 		td.InsertRow("123", map[string]any{"id": "123", "name": "dana-new", constants.DeleteColumnMarker: false, constants.OnlySetDeleteColumnMarker: false}, false)
-		assert.Equal(t, "dana-new", td.Rows()[0].GetData()["name"])
-		assert.Nil(t, td.Rows()[0].GetData()["foo"])
-		assert.Equal(t, false, td.Rows()[0].GetData()[constants.DeleteColumnMarker])
+		assert.Equal(t, "dana-new", collectRows(t, td)[0].GetData()["name"])
+		assert.Nil(t, collectRows(t, td)[0].GetData()["foo"])
+		assert.Equal(t, false, collectRows(t, td)[0].GetData()[constants.DeleteColumnMarker])
 	}
 	{
 		// Update followed by a delete
@@ -239,7 +256,7 @@ func TestTableData_InsertRowSoftDelete(t *testing.T) {
 			td.InsertRow("123", map[string]any{"id": "123", constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true, constants.OperationColumnMarker: "d"}, true)
 			assert.Equal(t, 1, int(td.NumberOfRows()))
 
-			data := td.Rows()[0].GetData()
+			data := collectRows(t, td)[0].GetData()
 			assert.Equal(t, "dana", data["name"])
 			assert.Equal(t, "abc", data["foo"])
 			assert.Equal(t, "d", data[constants.OperationColumnMarker])
@@ -254,7 +271,7 @@ func TestTableData_InsertRowSoftDelete(t *testing.T) {
 			td.InsertRow("123", map[string]any{"id": "123", constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true, constants.OperationColumnMarker: "d", constants.DatabaseUpdatedColumnMarker: "b"}, true)
 			assert.Equal(t, 1, int(td.NumberOfRows()))
 
-			data := td.Rows()[0].GetData()
+			data := collectRows(t, td)[0].GetData()
 			assert.Equal(t, "dana", data["name"])
 			assert.Equal(t, "abc", data["foo"])
 			assert.Equal(t, "b", data[constants.DatabaseUpdatedColumnMarker])
@@ -293,7 +310,7 @@ func TestTableData_InsertRowSoftDelete(t *testing.T) {
 		}, true)
 		assert.Equal(t, 1, int(td.NumberOfRows()))
 
-		data := td.Rows()[0].GetData()
+		data := collectRows(t, td)[0].GetData()
 		// The previous row's actual values should be preserved, NOT the Debezium zero values
 		assert.Equal(t, "dana", data["name"], "name should be preserved from previous row, not Debezium zero value")
 		assert.Equal(t, 100, data["balance"], "balance should be preserved from previous row, not Debezium zero value")
@@ -0,0 +1,223 @@
+package rowfilter
+
+import (
+	"fmt"
+	"slices"
+)
+
+var comparisonOperators = []string{"=", "!=", "<>", "<", "<=", ">", ">="}
+
+// reservedKeywords cannot be used as bare column names, they need to be double quoted instead.
+var reservedKeywords = []string{"AND", "OR", "NOT", "IN", "IS", "NULL", "TRUE", "FALSE"}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+// acceptKeyword consumes the next token if it is [keyword].
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.peek().keyword() == keyword {
+		p.next()
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, description string) error {
+	if tok := p.next(); tok.kind != kind {
+		return fmt.Errorf("expected %s, got %s", description, tok)
+	}
+
+	return nil
+}
+
+// parseOr parses: and (OR and)*
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseAnd parses: not (AND not)*
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseNot parses: NOT not | '(' or ')' | predicate
+func (p *parser) parseNot() (node, error) {
+	if p.acceptKeyword("NOT") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return notNode{inner: inner}, nil
+	}
+
+	if p.peek().kind == tokenLeftParen {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if err = p.expect(tokenRightParen, `")"`); err != nil {
+			return nil, err
+		}
+
+		return inner, nil
+	}
+
+	return p.parsePredicate()
+}
+
+// parsePredicate parses: operand (comparison operand | [NOT] IN '(' literal, ... ')' | IS [NOT] NULL)
+func (p *parser) parsePredicate() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenOperator && slices.Contains(comparisonOperators, tok.value):
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		return comparisonNode{left: left, operator: tok.value, right: right}, nil
+	case tok.keyword() == "IS":
+		p.next()
+		negate := p.acceptKeyword("NOT")
+		if !p.acceptKeyword("NULL") {
+			return nil, fmt.Errorf("expected NULL, got %s", p.peek())
+		}
+
+		return isNullNode{operand: left, negate: negate}, nil
+	case tok.keyword() == "IN" || tok.keyword() == "NOT":
+		p.next()
+		negate := tok.keyword() == "NOT"
+		if negate && !p.acceptKeyword("IN") {
+			return nil, fmt.Errorf("expected IN, got %s", p.peek())
+		}
+
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+
+		return inNode{operand: left, values: values, negate: negate}, nil
+	default:
+		return nil, fmt.Errorf("expected a comparison operator, IN or IS, got %s", tok)
+	}
+}
+
+// parseList parses: '(' literal (, literal)* ')'
+func (p *parser) parseList() ([]literal, error) {
+	if err := p.expect(tokenLeftParen, `"("`); err != nil {
+		return nil, err
+	}
+
+	var values []literal
+	for {
+		next, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := next.(literal)
+		if !ok {
+			return nil, fmt.Errorf("IN only supports literal values, got column %q", next.(column).name)
+		}
+
+		values = append(values, value)
+		if p.peek().kind != tokenComma {
+			break
+		}
+		p.next()
+	}
+
+	if err := p.expect(tokenRightParen, `")"`); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenQuotedIdent:
+		if tok.value == "" {
+			return nil, fmt.Errorf("column name cannot be empty at position %d", tok.pos)
+		}
+		return column{name: tok.value}, nil
+	case tokenString:
+		return literal{value: tok.value}, nil
+	case tokenNumber:
+		value, ok := parseNumber(tok.value)
+		if !ok {
+			return nil, fmt.Errorf("invalid number %s", tok)
+		}
+		return literal{value: value}, nil
+	case tokenIdent:
+		switch tok.keyword() {
+		case "NULL":
+			return literal{value: nil}, nil
+		case "TRUE":
+			return literal{value: true}, nil
+		case "FALSE":
+			return literal{value: false}, nil
+		}
+
+		if slices.Contains(reservedKeywords, tok.keyword()) {
+			return nil, fmt.Errorf("unexpected keyword %s, column names that are keywords must be double quoted", tok)
+		}
+		return column{name: tok.value}, nil
+	default:
+		return nil, fmt.Errorf("expected a column or a value, got %s", tok)
+	}
+}
@@ -0,0 +1,243 @@
+package rowfilter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/artie-labs/transfer/lib/maputil"
+)
+
+// truth is the result of evaluating an expression, comparisons against NULL are unknown like they are in SQL.
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthUnknown
+	truthTrue
+)
+
+func toTruth(value bool) truth {
+	if value {
+		return truthTrue
+	}
+
+	return truthFalse
+}
+
+type node interface {
+	eval(row map[string]any) truth
+}
+
+type operand interface {
+	// resolve returns the normalized value, false is returned if the value cannot be compared.
+	resolve(row map[string]any) (any, bool)
+}
+
+type column struct {
+	name string
+}
+
+// resolve looks up the column case-insensitively, missing columns are treated as NULL.
+func (c column) resolve(row map[string]any) (any, bool) {
+	value, _, ok := maputil.GetCaseInsensitiveValue(row, c.name)
+	if !ok {
+		return nil, true
+	}
+
+	return normalize(value)
+}
+
+type literal struct {
+	value any
+}
+
+func (l literal) resolve(_ map[string]any) (any, bool) {
+	return l.value, true
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (o orNode) eval(row map[string]any) truth {
+	return max(o.left.eval(row), o.right.eval(row))
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (a andNode) eval(row map[string]any) truth {
+	return min(a.left.eval(row), a.right.eval(row))
+}
+
+type notNode struct {
+	inner node
+}
+
+func (n notNode) eval(row map[string]any) truth {
+	return truthTrue - n.inner.eval(row)
+}
+
+type comparisonNode struct {
+	left     operand
+	operator string
+	right    operand
+}
+
+func (c comparisonNode) eval(row map[string]any) truth {
+	left, ok := c.left.resolve(row)
+	if !ok || left == nil {
+		return truthUnknown
+	}
+
+	right, ok := c.right.resolve(row)
+	if !ok || right == nil {
+		return truthUnknown
+	}
+
+	cmp, ok := compare(left, right)
+	if !ok {
+		return truthUnknown
+	}
+
+	switch c.operator {
+	case "=":
+		return toTruth(cmp == 0)
+	case "!=", "<>":
+		return toTruth(cmp != 0)
+	case "<":
+		return toTruth(cmp < 0)
+	case "<=":
+		return toTruth(cmp <= 0)
+	case ">":
+		return toTruth(cmp > 0)
+	case ">=":
+		return toTruth(cmp >= 0)
+	default:
+		return truthUnknown
+	}
+}
+
+type inNode struct {
+	operand operand
+	values  []literal
+	negate  bool
+}
+
+func (i inNode) eval(row map[string]any) truth {
+	result := i.contains(row)
+	if i.negate {
+		return truthTrue - result
+	}
+
+	return result
+}
+
+// contains is true if any value matches, otherwise it is unknown if the operand or any of the values are NULL.
+func (i inNode) contains(row map[string]any) truth {
+	value, ok := i.operand.resolve(row)
+	if !ok || value == nil {
+		return truthUnknown
+	}
+
+	result := truthFalse
+	for _, item := range i.values {
+		if item.value == nil {
+			result = truthUnknown
+			continue
+		}
+
+		cmp, ok := compare(value, item.value)
+		if !ok {
+			result = truthUnknown
+			continue
+		}
+
+		if cmp == 0 {
+			return truthTrue
+		}
+	}
+
+	return result
+}
+
+type isNullNode struct {
+	operand operand
+	negate  bool
+}
+
+func (i isNullNode) eval(row map[string]any) truth {
+	value, ok := i.operand.resolve(row)
+	// Values that cannot be compared (e.g. structs) are still not NULL.
+	isNull := ok && value == nil
+	return toTruth(isNull != i.negate)
+}
+
+// Filter is a parsed row filter, e.g. "tenant_id NOT IN ('test', 'staging') AND deleted_at IS NULL".
+//
+// Expressions support the comparison operators =, !=, <>, <, <=, > and >=, [NOT] IN, IS [NOT] NULL, AND, OR, NOT and parentheses.
+// Strings are single quoted, column names can be double quoted and keywords are case-insensitive.
+// Like a SQL WHERE clause, a comparison against a NULL or missing column is unknown and only rows that evaluate to true are kept.
+type Filter struct {
+	expression string
+	root       node
+}
+
+func Parse(expression string) (*Filter, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("row filter cannot be empty")
+	}
+
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s", tok)
+	}
+
+	return &Filter{expression: expression, root: root}, nil
+}
+
+var (
+	cacheMu sync.RWMutex
+	cache   = make(map[string]*Filter)
+)
+
+// Get returns the parsed filter for [expression], filters are parsed once and then cached since they are evaluated for every row.
+func Get(expression string) (*Filter, error) {
+	cacheMu.RLock()
+	filter, ok := cache[expression]
+	cacheMu.RUnlock()
+	if ok {
+		return filter, nil
+	}
+
+	filter, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	cache[expression] = filter
+	cacheMu.Unlock()
+	return filter, nil
+}
+
+func (f *Filter) String() string {
+	return f.expression
+}
+
+// Match returns true if [row] should be kept.
+func (f *Filter) Match(row map[string]any) bool {
+	return f.root.eval(row) == truthTrue
+}
@@ -0,0 +1,186 @@
+package rowfilter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func TestParse(t *testing.T) {
+	{
+		// Empty
+		_, err := Parse(" ")
+		assert.ErrorContains(t, err, "row filter cannot be empty")
+	}
+	{
+		// Unterminated string
+		_, err := Parse("region = 'us")
+		assert.ErrorContains(t, err, "unterminated quote starting at position 9")
+	}
+	{
+		// Unexpected character
+		_, err := Parse("region ~ 'us'")
+		assert.ErrorContains(t, err, `unexpected character '~' at position 7`)
+	}
+	{
+		// Missing operator
+		_, err := Parse("region 'us'")
+		assert.ErrorContains(t, err, `expected a comparison operator, IN or IS, got "us" at position 7`)
+	}
+	{
+		// Missing closing parenthesis
+		_, err := Parse("(region = 'us' OR region = 'eu'")
+		assert.ErrorContains(t, err, `expected ")", got end of expression`)
+	}
+	{
+		// Trailing tokens
+		_, err := Parse("region = 'us' 'eu'")
+		assert.ErrorContains(t, err, `unexpected "eu" at position 14`)
+	}
+	{
+		// IS without NULL
+		_, err := Parse("deleted_at IS TRUE")
+		assert.ErrorContains(t, err, `expected NULL, got "TRUE" at position 14`)
+	}
+	{
+		// NOT without IN
+		_, err := Parse("region NOT ('us')")
+		assert.ErrorContains(t, err, `expected IN, got "(" at position 11`)
+	}
+	{
+		// IN with a column
+		_, err := Parse("region IN ('us', other_region)")
+		assert.ErrorContains(t, err, `IN only supports literal values, got column "other_region"`)
+	}
+	{
+		// Keywords as column names
+		_, err := Parse("in = 1")
+		assert.ErrorContains(t, err, `unexpected keyword "in" at position 0, column names that are keywords must be double quoted`)
+
+		_, err = Parse(`"in" = 1`)
+		assert.NoError(t, err)
+	}
+	{
+		// Valid
+		filter, err := Parse(`tenant_id NOT IN ('test', 'staging') and (deleted_at is null OR "Region" <> 'eu') AND NOT amount <= -1.5e2`)
+		assert.NoError(t, err)
+		assert.Equal(t, `tenant_id NOT IN ('test', 'staging') and (deleted_at is null OR "Region" <> 'eu') AND NOT amount <= -1.5e2`, filter.String())
+	}
+}
+
+func TestGet(t *testing.T) {
+	filter, err := Get("region = 'us'")
+	assert.NoError(t, err)
+
+	cached, err := Get("region = 'us'")
+	assert.NoError(t, err)
+	assert.Same(t, filter, cached)
+
+	_, err = Get("region =")
+	assert.ErrorContains(t, err, "expected a column or a value, got end of expression")
+}
+
+func match(t *testing.T, expression string, row map[string]any) bool {
+	filter, err := Parse(expression)
+	assert.NoError(t, err, expression)
+	return filter.Match(row)
+}
+
+func TestFilter_Match(t *testing.T) {
+	row := map[string]any{
+		"id":          int64(5),
+		"tenant_id":   "acme",
+		"Region":      "us",
+		"price":       decimal.NewDecimal(apd.New(1999, -2)),
+		"score":       float64(0.5),
+		"count":       json.Number("12"),
+		"active":      true,
+		"created_at":  time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC),
+		"opens_at":    ext.NewTime(time.Date(0, 1, 1, 9, 30, 0, 0, time.UTC)),
+		"deleted_at":  nil,
+		"metadata":    map[string]any{"foo": "bar"},
+		"quote's col": "it's",
+	}
+	{
+		// Comparisons
+		assert.True(t, match(t, "id = 5", row))
+		assert.True(t, match(t, "id != 6 AND id <> 6", row))
+		assert.True(t, match(t, "id > 4 AND id >= 5 AND id < 6 AND id <= 5", row))
+		assert.False(t, match(t, "id > 5", row))
+		assert.True(t, match(t, "5 = id", row))
+		assert.True(t, match(t, "id = '5'", row))
+	}
+	{
+		// Numbers are compared exactly across types
+		assert.True(t, match(t, "price = 19.99", row))
+		assert.True(t, match(t, "price < 20", row))
+		assert.True(t, match(t, "score = 0.5 AND score > 1e-1", row))
+		assert.True(t, match(t, "count = 12.0", row))
+	}
+	{
+		// Strings, column names are case-insensitive and quotes are escaped by doubling them
+		assert.True(t, match(t, "tenant_id = 'acme'", row))
+		assert.True(t, match(t, "region = 'us' AND REGION = 'us'", row))
+		assert.False(t, match(t, "tenant_id = 'ACME'", row))
+		assert.True(t, match(t, `"quote's col" = 'it''s'`, row))
+		assert.True(t, match(t, "tenant_id > 'abc'", row))
+	}
+	{
+		// Booleans
+		assert.True(t, match(t, "active = TRUE", row))
+		assert.False(t, match(t, "active = false", row))
+		assert.True(t, match(t, "active = 'true'", row))
+	}
+	{
+		// Timestamps and times are compared against string literals
+		assert.True(t, match(t, "created_at >= '2024-03-01'", row))
+		assert.True(t, match(t, "created_at < '2024-03-01T12:00:01Z'", row))
+		assert.True(t, match(t, "created_at = '2024-03-01 12:00:00'", row))
+		assert.False(t, match(t, "created_at < 'yesterday'", row))
+		assert.True(t, match(t, "opens_at >= '09:00:00'", row))
+	}
+	{
+		// IN
+		assert.True(t, match(t, "region IN ('us', 'ca')", row))
+		assert.False(t, match(t, "region NOT IN ('us', 'ca')", row))
+		assert.True(t, match(t, "id in (1, 5)", row))
+		assert.True(t, match(t, "region IN ('us', NULL)", row))
+		// A NULL in the list makes NOT IN unknown unless there is a match
+		assert.False(t, match(t, "region NOT IN ('eu', NULL)", row))
+	}
+	{
+		// IS NULL, missing columns are NULL
+		assert.True(t, match(t, "deleted_at IS NULL", row))
+		assert.True(t, match(t, "missing IS NULL", row))
+		assert.False(t, match(t, "deleted_at IS NOT NULL", row))
+		assert.True(t, match(t, "metadata IS NOT NULL", row))
+	}
+	{
+		// NULL and values that cannot be compared are unknown, so the row is not kept either way
+		assert.False(t, match(t, "deleted_at = NULL", row))
+		assert.False(t, match(t, "deleted_at > '2024-01-01'", row))
+		assert.False(t, match(t, "NOT deleted_at > '2024-01-01'", row))
+		assert.False(t, match(t, "missing != 'foo'", row))
+		assert.False(t, match(t, "metadata = 'foo'", row))
+		assert.False(t, match(t, "id = 'five'", row))
+	}
+	{
+		// AND, OR and NOT with unknown
+		assert.True(t, match(t, "missing = 1 OR id = 5", row))
+		assert.False(t, match(t, "missing = 1 AND id = 5", row))
+		assert.False(t, match(t, "NOT (missing = 1 OR id = 6)", row))
+		assert.True(t, match(t, "NOT (missing = 1 AND id = 6)", row))
+	}
+	{
+		// AND binds tighter than OR
+		assert.True(t, match(t, "id = 6 AND region = 'eu' OR tenant_id = 'acme'", row))
+		assert.False(t, match(t, "id = 6 AND (region = 'eu' OR tenant_id = 'acme')", row))
+		assert.True(t, match(t, "NOT NOT id = 5", row))
+	}
+}
@@ -0,0 +1,166 @@
+package rowfilter
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+// timeLayouts are the layouts that string literals are parsed with when they are compared against a timestamp or a date.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.DateOnly,
+}
+
+// normalize converts a typed column value into one of: nil, string, bool, *big.Rat or [time.Time].
+// Values that cannot be compared (e.g. structs and arrays) return false.
+func normalize(value any) (any, bool) {
+	switch v := value.(type) {
+	case nil, string, bool, time.Time:
+		return v, true
+	case ext.Time:
+		return v.String(), true
+	case *decimal.Decimal:
+		if v == nil {
+			return nil, true
+		}
+		return parseNumber(v.String())
+	case json.Number:
+		return parseNumber(v.String())
+	case int:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int8:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int16:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case uint:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint8:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint16:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint64:
+		return new(big.Rat).SetUint64(v), true
+	case float32:
+		return parseFloat(float64(v))
+	case float64:
+		return parseFloat(v)
+	default:
+		return nil, false
+	}
+}
+
+func parseNumber(value string) (*big.Rat, bool) {
+	return new(big.Rat).SetString(strings.TrimSpace(value))
+}
+
+func parseFloat(value float64) (*big.Rat, bool) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, false
+	}
+
+	return new(big.Rat).SetFloat64(value), true
+}
+
+func parseTime(value string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if ts, err := time.Parse(layout, value); err == nil {
+			return ts, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// compare returns -1, 0 or 1 if [left] is less than, equal to or greater than [right].
+// Both values must already be normalized, strings are coerced to the other side's type the same way a database would cast a literal.
+// If the values cannot be compared, false is returned so that the predicate evaluates to unknown.
+func compare(left, right any) (int, bool) {
+	switch l := left.(type) {
+	case string:
+		switch r := right.(type) {
+		case string:
+			return strings.Compare(l, r), true
+		case *big.Rat:
+			parsed, ok := parseNumber(l)
+			if !ok {
+				return 0, false
+			}
+			return parsed.Cmp(r), true
+		case time.Time:
+			parsed, ok := parseTime(l)
+			if !ok {
+				return 0, false
+			}
+			return parsed.Compare(r), true
+		case bool:
+			parsed, ok := parseBool(l)
+			if !ok {
+				return 0, false
+			}
+			return compareBools(parsed, r), true
+		}
+	case *big.Rat:
+		switch r := right.(type) {
+		case *big.Rat:
+			return l.Cmp(r), true
+		case string:
+			cmp, ok := compare(right, left)
+			return -cmp, ok
+		}
+	case time.Time:
+		switch r := right.(type) {
+		case time.Time:
+			return l.Compare(r), true
+		case string:
+			cmp, ok := compare(right, left)
+			return -cmp, ok
+		}
+	case bool:
+		switch r := right.(type) {
+		case bool:
+			return compareBools(l, r), true
+		case string:
+			cmp, ok := compare(right, left)
+			return -cmp, ok
+		}
+	}
+
+	return 0, false
+}
+
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "t", "1":
+		return true, true
+	case "false", "f", "0":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+func compareBools(left, right bool) int {
+	switch {
+	case left == right:
+		return 0
+	case !left:
+		return -1
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,218 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SchemaType string
+
+const (
+	Avro     SchemaType = "AVRO"
+	Protobuf SchemaType = "PROTOBUF"
+	JSON     SchemaType = "JSON"
+)
+
+type Reference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+type Schema struct {
+	ID         int         `json:"id,omitempty"`
+	Schema     string      `json:"schema"`
+	SchemaType SchemaType  `json:"schemaType,omitempty"`
+	References []Reference `json:"references,omitempty"`
+}
+
+// Type returns the schema type, the registry omits the type for Avro schemas.
+func (s Schema) Type() SchemaType {
+	if s.SchemaType == "" {
+		return Avro
+	}
+
+	return s.SchemaType
+}
+
+// Client is a Confluent Schema Registry client, schemas are immutable so they are cached forever.
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	mu          sync.RWMutex
+	schemasByID map[int]Schema
+	references  map[string]Schema
+	// registered maps a subject and schema to the ID that the registry assigned to it.
+	registered map[string]int
+}
+
+func NewClient(baseURL, username, password string) *Client {
+	return &Client{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		username:    username,
+		password:    password,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		schemasByID: make(map[int]Schema),
+		references:  make(map[string]Schema),
+		registered:  make(map[string]int),
+	}
+}
+
+// GetSchemaByID returns the schema registered under [id].
+func (c *Client) GetSchemaByID(ctx context.Context, id int) (Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.schemasByID[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	if err := c.get(ctx, fmt.Sprintf("/schemas/ids/%d", id), &schema); err != nil {
+		return Schema{}, fmt.Errorf("failed to fetch schema %d: %w", id, err)
+	}
+
+	schema.ID = id
+	c.mu.Lock()
+	c.schemasByID[id] = schema
+	c.mu.Unlock()
+	return schema, nil
+}
+
+// GetReference returns the schema that [reference] points to.
+func (c *Client) GetReference(ctx context.Context, reference Reference) (Schema, error) {
+	cacheKey := fmt.Sprintf("%s/%d", reference.Subject, reference.Version)
+	c.mu.RLock()
+	schema, ok := c.references[cacheKey]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	path := fmt.Sprintf("/subjects/%s/versions/%d", url.PathEscape(reference.Subject), reference.Version)
+	if err := c.get(ctx, path, &schema); err != nil {
+		return Schema{}, fmt.Errorf("failed to fetch reference %q: %w", reference.Name, err)
+	}
+
+	c.mu.Lock()
+	c.references[cacheKey] = schema
+	c.mu.Unlock()
+	return schema, nil
+}
+
+// ResolveReferences returns all the schemas [schema] depends on (directly or transitively), keyed by reference name.
+func (c *Client) ResolveReferences(ctx context.Context, schema Schema) (map[string]Schema, error) {
+	resolved := make(map[string]Schema)
+	if err := c.resolveReferences(ctx, schema, resolved); err != nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+func (c *Client) resolveReferences(ctx context.Context, schema Schema, resolved map[string]Schema) error {
+	for _, reference := range schema.References {
+		if _, ok := resolved[reference.Name]; ok {
+			continue
+		}
+
+		referencedSchema, err := c.GetReference(ctx, reference)
+		if err != nil {
+			return err
+		}
+
+		resolved[reference.Name] = referencedSchema
+		if err = c.resolveReferences(ctx, referencedSchema, resolved); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Register registers [schema] under [subject] and returns its ID, registering a schema that already exists is a no-op on the registry side.
+func (c *Client) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	cacheKey := subject + "/" + schema.Schema
+	c.mu.RLock()
+	id, ok := c.registered[cacheKey]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	var resp struct {
+		ID int `json:"id"`
+	}
+
+	path := fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject))
+	if err := c.do(ctx, http.MethodPost, path, schema, &resp); err != nil {
+		return 0, fmt.Errorf("failed to register schema for subject %q: %w", subject, err)
+	}
+
+	schema.ID = resp.ID
+	c.mu.Lock()
+	c.registered[cacheKey] = resp.ID
+	c.schemasByID[resp.ID] = schema
+	c.mu.Unlock()
+	return resp.ID, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in any, out any) error {
+	var reqBody io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err = json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_Type(t *testing.T) {
+	assert.Equal(t, Avro, Schema{}.Type())
+	assert.Equal(t, Protobuf, Schema{SchemaType: Protobuf}.Type())
+}
+
+func TestClient_GetSchemaByID(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "pass", password)
+
+		switch r.URL.Path {
+		case "/schemas/ids/1":
+			assert.NoError(t, json.NewEncoder(w).Encode(Schema{Schema: `"string"`}))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "user", "pass")
+	{
+		// Cache miss
+		schema, err := client.GetSchemaByID(t.Context(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, Schema{ID: 1, Schema: `"string"`}, schema)
+		assert.Equal(t, int32(1), requests.Load())
+	}
+	{
+		// Cache hit
+		schema, err := client.GetSchemaByID(t.Context(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, `"string"`, schema.Schema)
+		assert.Equal(t, int32(1), requests.Load())
+	}
+	{
+		// Not found
+		_, err := client.GetSchemaByID(t.Context(), 2)
+		assert.ErrorContains(t, err, "failed to fetch schema 2: unexpected status code 404")
+	}
+}
+
+func TestClient_ResolveReferences(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/subjects/common/versions/1":
+			assert.NoError(t, json.NewEncoder(w).Encode(Schema{
+				Schema:     `syntax = "proto3"; import "nested.proto";`,
+				SchemaType: Protobuf,
+				References: []Reference{{Name: "nested.proto", Subject: "nested", Version: 3}},
+			}))
+		case "/subjects/nested/versions/3":
+			assert.NoError(t, json.NewEncoder(w).Encode(Schema{Schema: `syntax = "proto3";`, SchemaType: Protobuf}))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "")
+	references, err := client.ResolveReferences(t.Context(), Schema{
+		SchemaType: Protobuf,
+		References: []Reference{{Name: "common.proto", Subject: "common", Version: 1}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, references, 2)
+	assert.Equal(t, `syntax = "proto3";`, references["nested.proto"].Schema)
+	assert.Contains(t, references["common.proto"].Schema, `import "nested.proto";`)
+}
+
+func TestClient_Register(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/vnd.schemaregistry.v1+json", r.Header.Get("Content-Type"))
+
+		var schema Schema
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&schema))
+
+		switch r.URL.Path {
+		case "/subjects/orders-value/versions":
+			assert.Equal(t, `"string"`, schema.Schema)
+			_, _ = w.Write([]byte(`{"id":7}`))
+		default:
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error_code":409,"message":"Schema being registered is incompatible"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "")
+	{
+		// Registers the schema
+		id, err := client.Register(t.Context(), "orders-value", Schema{Schema: `"string"`})
+		assert.NoError(t, err)
+		assert.Equal(t, 7, id)
+		assert.Equal(t, int32(1), requests.Load())
+	}
+	{
+		// Cache hit, the schema is also cached by ID
+		id, err := client.Register(t.Context(), "orders-value", Schema{Schema: `"string"`})
+		assert.NoError(t, err)
+		assert.Equal(t, 7, id)
+
+		schema, err := client.GetSchemaByID(t.Context(), 7)
+		assert.NoError(t, err)
+		assert.Equal(t, `"string"`, schema.Schema)
+		assert.Equal(t, int32(1), requests.Load())
+	}
+	{
+		// Incompatible
+		_, err := client.Register(t.Context(), "customers-value", Schema{Schema: `"long"`})
+		assert.ErrorContains(t, err, `failed to register schema for subject "customers-value": unexpected status code 409`)
+	}
+}
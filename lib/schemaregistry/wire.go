@@ -0,0 +1,64 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	magicByte  byte = 0
+	headerSize      = 5
+)
+
+// ParseWireFormat splits a message serialized with the Confluent wire format into its schema ID and payload.
+// The wire format is: magic byte (0) + 4 byte big-endian schema ID + payload.
+func ParseWireFormat(data []byte) (int, []byte, error) {
+	if len(data) < headerSize {
+		return 0, nil, fmt.Errorf("message is too short to be in the wire format, length: %d", len(data))
+	}
+
+	if data[0] != magicByte {
+		return 0, nil, fmt.Errorf("unexpected magic byte: %d", data[0])
+	}
+
+	return int(binary.BigEndian.Uint32(data[1:headerSize])), data[headerSize:], nil
+}
+
+// BuildWireFormat prefixes [payload] with the magic byte and [schemaID], this is the inverse of [ParseWireFormat].
+func BuildWireFormat(schemaID int, payload []byte) []byte {
+	out := make([]byte, headerSize, headerSize+len(payload))
+	out[0] = magicByte
+	binary.BigEndian.PutUint32(out[1:headerSize], uint32(schemaID))
+	return append(out, payload...)
+}
+
+// ParseMessageIndexes reads the message indexes that prefix Protobuf payloads, these point to the message type within the schema.
+// A single 0 is shorthand for the first message, which is the most common case.
+func ParseMessageIndexes(data []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(data)
+	if n <= 0 {
+		return nil, nil, fmt.Errorf("failed to read message index count")
+	}
+
+	data = data[n:]
+	if count == 0 {
+		return []int{0}, data, nil
+	}
+
+	if count < 0 || count > int64(len(data)) {
+		return nil, nil, fmt.Errorf("invalid message index count: %d", count)
+	}
+
+	indexes := make([]int, count)
+	for i := range indexes {
+		index, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, nil, fmt.Errorf("failed to read message index %d", i)
+		}
+
+		indexes[i] = int(index)
+		data = data[n:]
+	}
+
+	return indexes, data, nil
+}
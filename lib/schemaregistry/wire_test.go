@@ -0,0 +1,64 @@
+package schemaregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWireFormat(t *testing.T) {
+	{
+		// Too short
+		_, _, err := ParseWireFormat([]byte{0, 0, 1})
+		assert.ErrorContains(t, err, "message is too short to be in the wire format, length: 3")
+	}
+	{
+		// Wrong magic byte
+		_, _, err := ParseWireFormat([]byte(`{"foo":"bar"}`))
+		assert.ErrorContains(t, err, "unexpected magic byte: 123")
+	}
+	{
+		// Valid
+		id, payload, err := ParseWireFormat([]byte{0, 0, 0, 1, 2, 'h', 'i'})
+		assert.NoError(t, err)
+		assert.Equal(t, 258, id)
+		assert.Equal(t, []byte("hi"), payload)
+	}
+}
+
+func TestBuildWireFormat(t *testing.T) {
+	data := BuildWireFormat(258, []byte("hi"))
+	assert.Equal(t, []byte{0, 0, 0, 1, 2, 'h', 'i'}, data)
+
+	id, payload, err := ParseWireFormat(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 258, id)
+	assert.Equal(t, []byte("hi"), payload)
+}
+
+func TestParseMessageIndexes(t *testing.T) {
+	{
+		// Shorthand for the first message
+		indexes, payload, err := ParseMessageIndexes([]byte{0, 'h', 'i'})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0}, indexes)
+		assert.Equal(t, []byte("hi"), payload)
+	}
+	{
+		// Nested message, zig-zag encoded: count = 2, indexes = [1, 0]
+		indexes, payload, err := ParseMessageIndexes([]byte{4, 2, 0, 'h', 'i'})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 0}, indexes)
+		assert.Equal(t, []byte("hi"), payload)
+	}
+	{
+		// Empty
+		_, _, err := ParseMessageIndexes(nil)
+		assert.ErrorContains(t, err, "failed to read message index count")
+	}
+	{
+		// Count is larger than the remaining payload
+		_, _, err := ParseMessageIndexes([]byte{10, 2})
+		assert.ErrorContains(t, err, "invalid message index count: 5")
+	}
+}
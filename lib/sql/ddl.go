@@ -25,3 +25,14 @@ func DefaultBuildAddColumnQuery(tableID TableIdentifier, sqlPart string) string
 func DefaultBuildDropColumnQuery(tableID TableIdentifier, colName string) string {
 	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", tableID.FullyQualifiedName(), colName)
 }
+
+// DefaultBuildAlterColumnTypeQuery returns the standard ALTER TABLE ALTER COLUMN TYPE query.
+// Used by Postgres and Redshift.
+func DefaultBuildAlterColumnTypeQuery(tableID TableIdentifier, colName string, dataType string) string {
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s", tableID.FullyQualifiedName(), colName, dataType)
+}
+
+// DefaultBuildRenameColumnQuery returns the standard ALTER TABLE RENAME COLUMN query.
+func DefaultBuildRenameColumnQuery(tableID TableIdentifier, oldColName, newColName string) string {
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", tableID.FullyQualifiedName(), oldColName, newColName)
+}
@@ -66,3 +66,17 @@ type Dialect interface {
 	// Default values
 	GetDefaultValueStrategy() DefaultValueStrategy
 }
+
+// ColumnTypeAlterer is implemented by dialects that can change the data type of an existing column in place.
+// Dialects that do not implement this will have their columns widened by copying the values over to a new column instead.
+type ColumnTypeAlterer interface {
+	// BuildAlterColumnTypeQuery returns the query to change [col] to [dataType], [ok] is false if the dialect cannot do this in place for the column's kind.
+	// [col] is the destination column, so it carries the nullability and default value that need to be kept.
+	BuildAlterColumnTypeQuery(tableID TableIdentifier, col columns.Column, dataType string) (query string, ok bool)
+}
+
+// TransactionalDDL is implemented by dialects that can run DDL statements inside of a transaction.
+// Schema changes that take more than one statement (e.g. widening a column by copying it over) are then applied all at once or not at all.
+type TransactionalDDL interface {
+	SupportsTransactionalDDL() bool
+}
@@ -0,0 +1,27 @@
+package system
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ReloadHook calls [reload] every time the process receives a SIGHUP, until [ctx] is done.
+func ReloadHook(ctx context.Context, reload func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				slog.Info("Received reload signal, reloading config...")
+				reload()
+			}
+		}
+	}()
+}
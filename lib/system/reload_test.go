@@ -0,0 +1,34 @@
+//go:build unix
+
+package system
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadHook_callsReloadOnEachSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan struct{}, 2)
+	ReloadHook(ctx, func() { reloaded <- struct{}{} })
+
+	// Let the goroutine register with signal.Notify before we signal.
+	time.Sleep(50 * time.Millisecond)
+
+	for i := range 2 {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+			t.Fatalf("send SIGHUP: %v", err)
+		}
+
+		select {
+		case <-reloaded:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected reload %d to be called after signal", i+1)
+		}
+	}
+}
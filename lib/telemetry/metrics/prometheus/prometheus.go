@@ -0,0 +1,285 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/artie-labs/transfer/lib/maputil"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+)
+
+const (
+	Addr = "addr"
+	// DefaultAddr is the address that the `/metrics` endpoint will be served on.
+	DefaultAddr = ":9464"
+
+	Path        = "path"
+	DefaultPath = "/metrics"
+
+	Namespace        = "namespace"
+	DefaultNamespace = "transfer"
+
+	// MaxSeriesPerMetric guards against a tag with unbounded values (e.g. table names) from blowing up the number of series.
+	MaxSeriesPerMetric        = "maxSeriesPerMetric"
+	DefaultMaxSeriesPerMetric = 1000
+
+	// OverflowLabelValue is used for all the labels once a metric has reached [MaxSeriesPerMetric].
+	OverflowLabelValue = "_overflow"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// metricLabelNames declares the labels of every metric that we emit, since a Prometheus metric cannot change its labels once it's registered.
+// A metric is not always emitted with all of its tags (e.g. [process.message] has no table when the topic lookup fails), so the missing ones are left blank.
+// Metrics that are not declared here will have their labels fixed by their first observation, TestMetricLabelNames fails if an emitted metric is missing.
+var metricLabelNames = map[string][]string{
+	"buffer.memory_bytes":       {"database", "mode", "schema", "table"},
+	"buffer.spilled_bytes":      {"database", "mode", "schema", "table"},
+	"buffer.spilled_rows":       {"database", "mode", "schema", "table"},
+	"buffer.spill.files":        {"database", "mode", "schema", "table"},
+	"buffer.spill.rows":         {"database", "mode", "schema", "table"},
+	"buffer.spill.bytes":        {"database", "mode", "schema", "table"},
+	"dlq.message":               {"groupID", "topic"},
+	"flush":                     {"database", "mode", "reason", "schema", "table", "what"},
+	"ingestion.lag":             {"groupID", "mode", "schema", "table"},
+	"memory_budget.exceeded":    {},
+	"memory_budget.usage_bytes": {},
+	"memory_budget.usage_ratio": {},
+	"process.message":           {"database", "groupID", "mode", "op", "schema", "skipped", "table", "what"},
+	"row.execution_time_lag":    {"mode", "schema", "table"},
+	"row.filtered":              {"database", "mode", "schema", "table"},
+	"row.lag":                   {"groupID", "mode", "schema", "table"},
+}
+
+type kind int
+
+const (
+	histogramKind kind = iota
+	counterKind
+	gaugeKind
+)
+
+type metric struct {
+	kind       kind
+	labelNames []string
+	collector  prometheus.Collector
+	series     map[string]bool
+}
+
+type Client struct {
+	namespace          string
+	maxSeriesPerMetric int
+	registry           *prometheus.Registry
+	droppedSeries      *prometheus.CounterVec
+
+	mu      sync.Mutex
+	metrics map[string]*metric
+}
+
+func NewPrometheusClient(settings map[string]any) (base.Client, error) {
+	maxSeries, err := strconv.Atoi(fmt.Sprint(maputil.GetKeyFromMap(settings, MaxSeriesPerMetric, DefaultMaxSeriesPerMetric)))
+	if err != nil || maxSeries <= 0 {
+		return nil, fmt.Errorf("%s must be a positive integer", MaxSeriesPerMetric)
+	}
+
+	client := NewClient(fmt.Sprint(maputil.GetKeyFromMap(settings, Namespace, DefaultNamespace)), maxSeries)
+
+	address := fmt.Sprint(maputil.GetKeyFromMap(settings, Addr, DefaultAddr))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %q: %w", address, err)
+	}
+
+	path := fmt.Sprint(maputil.GetKeyFromMap(settings, Path, DefaultPath))
+	mux := http.NewServeMux()
+	mux.Handle(path, client.Handler())
+	go func() {
+		server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Prometheus metrics server stopped", slog.Any("err", err))
+		}
+	}()
+
+	slog.Info("Serving Prometheus metrics", slog.String("addr", listener.Addr().String()), slog.String("path", path))
+	return client, nil
+}
+
+// NewClient returns a client with its own registry, the caller is responsible for serving [Client.Handler].
+func NewClient(namespace string, maxSeriesPerMetric int) *Client {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	droppedSeries := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: sanitizeName(namespace),
+		Name:      "metrics_overflow_total",
+		Help:      "Number of observations that were collapsed because the metric reached its series limit.",
+	}, []string{"metric"})
+	registry.MustRegister(droppedSeries)
+
+	return &Client{
+		namespace:          sanitizeName(namespace),
+		maxSeriesPerMetric: maxSeriesPerMetric,
+		registry:           registry,
+		droppedSeries:      droppedSeries,
+		metrics:            make(map[string]*metric),
+	}
+}
+
+func (c *Client) Handler() http.Handler {
+	return promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{Registry: c.registry})
+}
+
+func sanitizeName(name string) string {
+	return invalidNameChars.ReplaceAllString(name, "_")
+}
+
+// buildLabelNames returns the declared labels for [name], or the keys of [tags] if the metric has not been declared in [metricLabelNames].
+func buildLabelNames(name string, tags map[string]string) []string {
+	var labelNames []string
+	if declaredLabelNames, ok := metricLabelNames[name]; ok {
+		labelNames = make([]string, 0, len(declaredLabelNames))
+		for _, labelName := range declaredLabelNames {
+			labelNames = append(labelNames, sanitizeName(labelName))
+		}
+	} else {
+		labelNames = make([]string, 0, len(tags))
+		for tag := range tags {
+			labelNames = append(labelNames, sanitizeName(tag))
+		}
+	}
+
+	slices.Sort(labelNames)
+	return labelNames
+}
+
+// getOrCreate returns the metric for [name], see [buildLabelNames] for how its labels are picked.
+func (c *Client) getOrCreate(name string, kind kind, tags map[string]string) (*metric, error) {
+	key := fmt.Sprintf("%d:%s", kind, name)
+	if m, ok := c.metrics[key]; ok {
+		return m, nil
+	}
+
+	labelNames := buildLabelNames(name, tags)
+	if _, ok := metricLabelNames[name]; !ok {
+		slog.Warn("Prometheus metric is not declared, its labels are fixed by its first observation", slog.String("name", name), slog.Any("labels", labelNames))
+	}
+
+	var collector prometheus.Collector
+	switch kind {
+	case histogramKind:
+		collector = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: c.namespace,
+			Name:      sanitizeName(name) + "_seconds",
+			Help:      fmt.Sprintf("Timing for %s.", name),
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, labelNames)
+	case counterKind:
+		collector = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: c.namespace,
+			Name:      sanitizeName(name) + "_total",
+			Help:      fmt.Sprintf("Count for %s.", name),
+		}, labelNames)
+	case gaugeKind:
+		collector = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: c.namespace,
+			Name:      sanitizeName(name),
+			Help:      fmt.Sprintf("Gauge for %s.", name),
+		}, labelNames)
+	}
+
+	if err := c.registry.Register(collector); err != nil {
+		return nil, err
+	}
+
+	m := &metric{kind: kind, labelNames: labelNames, collector: collector, series: make(map[string]bool)}
+	c.metrics[key] = m
+	return m, nil
+}
+
+// labelValues maps [tags] onto the metric's label names, missing tags are left blank and unknown tags are dropped.
+// Once the metric has reached [maxSeriesPerMetric], new series are collapsed into [OverflowLabelValue].
+func (c *Client) labelValues(name string, m *metric, tags map[string]string) []string {
+	sanitizedTags := make(map[string]string, len(tags))
+	for key, value := range tags {
+		sanitizedTags[sanitizeName(key)] = value
+	}
+
+	values := make([]string, len(m.labelNames))
+	for i, labelName := range m.labelNames {
+		values[i] = sanitizedTags[labelName]
+	}
+
+	seriesKey := strings.Join(values, "\x00")
+	if m.series[seriesKey] {
+		return values
+	}
+
+	if len(m.series) >= c.maxSeriesPerMetric {
+		c.droppedSeries.WithLabelValues(name).Inc()
+		for i := range values {
+			values[i] = OverflowLabelValue
+		}
+		return values
+	}
+
+	m.series[seriesKey] = true
+	return values
+}
+
+func (c *Client) observe(name string, kind kind, tags map[string]string, fn func(m *metric, values []string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, err := c.getOrCreate(name, kind, tags)
+	if err != nil {
+		slog.Warn("Failed to register Prometheus metric", slog.String("name", name), slog.Any("err", err))
+		return
+	}
+
+	fn(m, c.labelValues(name, m, tags))
+}
+
+func (c *Client) Timing(name string, value time.Duration, tags map[string]string) {
+	c.observe(name, histogramKind, tags, func(m *metric, values []string) {
+		m.collector.(*prometheus.HistogramVec).WithLabelValues(values...).Observe(value.Seconds())
+	})
+}
+
+func (c *Client) Incr(name string, tags map[string]string) {
+	c.Count(name, 1, tags)
+}
+
+func (c *Client) Count(name string, value int64, tags map[string]string) {
+	c.observe(name, counterKind, tags, func(m *metric, values []string) {
+		m.collector.(*prometheus.CounterVec).WithLabelValues(values...).Add(float64(value))
+	})
+}
+
+func (c *Client) Gauge(name string, value float64, tags map[string]string) {
+	c.observe(name, gaugeKind, tags, func(m *metric, values []string) {
+		m.collector.(*prometheus.GaugeVec).WithLabelValues(values...).Set(value)
+	})
+}
+
+// GaugeWithSample ignores [sample], Prometheus scrapes the latest value so there's nothing to sample.
+func (c *Client) GaugeWithSample(name string, value float64, tags map[string]string, _ float64) {
+	c.Gauge(name, value, tags)
+}
+
+// Flush is a no-op since metrics are pulled by the Prometheus server.
+func (c *Client) Flush() error {
+	return nil
+}
@@ -0,0 +1,186 @@
+package prometheus
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, client *Client) string {
+	server := httptest.NewServer(client.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestSanitizeName(t *testing.T) {
+	assert.Equal(t, "process_message", sanitizeName("process.message"))
+	assert.Equal(t, "row_execution_time_lag", sanitizeName("row.execution_time_lag"))
+	assert.Equal(t, "groupID", sanitizeName("groupID"))
+}
+
+func TestClient(t *testing.T) {
+	client := NewClient("transfer", 10)
+	client.Timing("process.message", 250*time.Millisecond, map[string]string{"table": "orders", "database": "shop"})
+	client.Incr("dlq.message", map[string]string{"topic": "orders"})
+	client.Count("dlq.message", 2, map[string]string{"topic": "orders"})
+	client.GaugeWithSample("custom.lag", 42, map[string]string{"table": "orders"}, 0.5)
+	// Metrics that are not declared have their labels fixed by the first call, unknown tags are dropped and missing tags are left blank.
+	client.Gauge("custom.lag", 7, map[string]string{"schema": "public"})
+	assert.NoError(t, client.Flush())
+
+	body := scrape(t, client)
+	assert.Contains(t, body, `transfer_process_message_seconds_count{database="shop",groupID="",mode="",op="",schema="",skipped="",table="orders",what=""} 1`)
+	assert.Contains(t, body, `transfer_process_message_seconds_sum{database="shop",groupID="",mode="",op="",schema="",skipped="",table="orders",what=""} 0.25`)
+	assert.Contains(t, body, `transfer_dlq_message_total{groupID="",topic="orders"} 3`)
+	assert.Contains(t, body, `transfer_custom_lag{table="orders"} 42`)
+	assert.Contains(t, body, `transfer_custom_lag{table=""} 7`)
+}
+
+func TestClient_DeclaredLabels(t *testing.T) {
+	client := NewClient("transfer", 10)
+	// The first observation only has a few of the tags, e.g. when the topic lookup fails.
+	client.Timing("process.message", time.Second, map[string]string{"mode": "replication", "groupID": "group", "what": "failed_topic_lookup"})
+	client.Timing("process.message", time.Second, map[string]string{
+		"mode":     "replication",
+		"groupID":  "group",
+		"what":     "success",
+		"database": "shop",
+		"schema":   "public",
+		"table":    "orders",
+		"op":       "c",
+		"skipped":  "filtered",
+	})
+
+	body := scrape(t, client)
+	assert.Contains(t, body, `transfer_process_message_seconds_count{database="",groupID="group",mode="replication",op="",schema="",skipped="",table="",what="failed_topic_lookup"} 1`)
+	assert.Contains(t, body, `transfer_process_message_seconds_count{database="shop",groupID="group",mode="replication",op="c",schema="public",skipped="filtered",table="orders",what="success"} 1`)
+}
+
+func TestClient_Cardinality(t *testing.T) {
+	client := NewClient("transfer", 2)
+	for _, table := range []string{"a", "b", "c", "d", "a"} {
+		client.Incr("row.filtered", map[string]string{"table": table})
+	}
+
+	body := scrape(t, client)
+	assert.Contains(t, body, `transfer_row_filtered_total{database="",mode="",schema="",table="a"} 2`)
+	assert.Contains(t, body, `transfer_row_filtered_total{database="",mode="",schema="",table="b"} 1`)
+	assert.NotContains(t, body, `table="c"`)
+	assert.Contains(t, body, `transfer_row_filtered_total{database="_overflow",mode="_overflow",schema="_overflow",table="_overflow"} 2`)
+	assert.Contains(t, body, `transfer_metrics_overflow_total{metric="row.filtered"} 2`)
+}
+
+func TestNewPrometheusClient(t *testing.T) {
+	{
+		// Invalid max series
+		_, err := NewPrometheusClient(map[string]any{MaxSeriesPerMetric: "foo"})
+		assert.ErrorContains(t, err, "maxSeriesPerMetric must be a positive integer")
+	}
+	{
+		// Valid
+		client, err := NewPrometheusClient(map[string]any{Addr: "127.0.0.1:0", Namespace: "dusty"})
+		assert.NoError(t, err)
+		assert.Equal(t, "dusty", client.(*Client).namespace)
+	}
+}
+
+// TestMetricLabelNames parses every call to the metrics client in the repo and checks that the metric and its literal tags have been declared.
+func TestMetricLabelNames(t *testing.T) {
+	// The position of the tags argument for each method on [base.Client].
+	tagsArg := map[string]int{"Timing": 2, "Incr": 1, "Count": 2, "Gauge": 2, "GaugeWithSample": 2}
+
+	emitted := make(map[string]bool)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(filepath.Join("..", "..", "..", ".."), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if name := d.Name(); name == "vendor" || name == "testdata" || (strings.HasPrefix(name, ".") && name != "." && name != "..") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+
+			index, ok := tagsArg[selector.Sel.Name]
+			if !ok || len(call.Args) <= index {
+				return true
+			}
+
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+
+			name, err := strconv.Unquote(lit.Value)
+			assert.NoError(t, err)
+			emitted[name] = true
+
+			labelNames, ok := metricLabelNames[name]
+			if !assert.True(t, ok, "%s: metric %q is not declared in metricLabelNames", fset.Position(call.Pos()), name) {
+				return true
+			}
+
+			if tags, ok := call.Args[index].(*ast.CompositeLit); ok {
+				for _, elt := range tags.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if key, ok := kv.Key.(*ast.BasicLit); ok {
+							tag, err := strconv.Unquote(key.Value)
+							assert.NoError(t, err)
+							assert.Contains(t, labelNames, tag, "%s: tag %q is not declared for metric %q", fset.Position(kv.Pos()), tag, name)
+						}
+					}
+				}
+			}
+
+			return true
+		})
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// Make sure that we actually found the call sites.
+	assert.Contains(t, emitted, "process.message")
+	for name := range metricLabelNames {
+		assert.True(t, emitted[name], "metric %q is declared in metricLabelNames but never emitted", name)
+	}
+}
@@ -7,9 +7,10 @@ import (
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics/datadog"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/prometheus"
 )
 
-var supportedExporterKinds = []constants.ExporterKind{constants.Datadog}
+var supportedExporterKinds = []constants.ExporterKind{constants.Datadog, constants.Prometheus}
 
 func exporterKindValid(kind constants.ExporterKind) bool {
 	var valid bool
@@ -25,14 +26,22 @@ func exporterKindValid(kind constants.ExporterKind) bool {
 
 func LoadExporter(cfg config.Config) base.Client {
 	kind := cfg.Telemetry.Metrics.Provider
-	ddSettings := cfg.Telemetry.Metrics.Settings
+	settings := cfg.Telemetry.Metrics.Settings
 	if !exporterKindValid(kind) {
 		slog.Info("Invalid or no exporter kind passed in, skipping...", slog.Any("exporterKind", kind))
 	}
 
 	switch kind {
 	case constants.Datadog:
-		statsClient, exportErr := datadog.NewDatadogClient(ddSettings)
+		statsClient, exportErr := datadog.NewDatadogClient(settings)
+		if exportErr != nil {
+			slog.Error("Metrics client error", slog.Any("err", exportErr), slog.Any("provider", kind))
+		} else {
+			slog.Info("Metrics client loaded", slog.Any("provider", kind))
+			return statsClient
+		}
+	case constants.Prometheus:
+		statsClient, exportErr := prometheus.NewPrometheusClient(settings)
 		if exportErr != nil {
 			slog.Error("Metrics client error", slog.Any("err", exportErr), slog.Any("provider", kind))
 		} else {
@@ -13,6 +13,7 @@ import (
 func TestExporterKindValid(t *testing.T) {
 	exporterKindToResultsMap := map[constants.ExporterKind]bool{
 		constants.Datadog:                      true,
+		constants.Prometheus:                   true,
 		constants.ExporterKind("daaaa"):        false,
 		constants.ExporterKind("daaaa231321"):  false,
 		constants.ExporterKind("honeycomb.io"): false,
@@ -39,6 +40,7 @@ func TestLoadExporter(t *testing.T) {
 					Provider constants.ExporterKind `yaml:"provider"`
 					Settings map[string]any         `yaml:"settings,omitempty"`
 				}
+				Tracing *config.TracingSettings `yaml:"tracing,omitempty"`
 			}{
 				Metrics: struct {
 					Provider constants.ExporterKind `yaml:"provider"`
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/artie-labs/transfer/lib/artie"
+)
+
+// headerCarrier adapts Kafka message headers so that the trace context from the source can be propagated.
+type headerCarrier []artie.Header
+
+func (h headerCarrier) Get(key string) string {
+	// If a header is repeated, the last one wins.
+	for i := len(h) - 1; i >= 0; i-- {
+		if h[i].Key == key {
+			return string(h[i].Value)
+		}
+	}
+
+	return ""
+}
+
+func (h headerCarrier) Set(string, string) {
+	// Headers are read-only, use [InjectIntoHeaders] instead.
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, len(h))
+	for i, header := range h {
+		keys[i] = header.Key
+	}
+
+	return keys
+}
+
+// ExtractFromHeaders returns a context that has the parent span from [headers], if there is one.
+func ExtractFromHeaders(ctx context.Context, headers []artie.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier(headers))
+}
+
+// InjectIntoHeaders returns the headers needed to propagate the span within [ctx].
+func InjectIntoHeaders(ctx context.Context) []artie.Header {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	headers := make([]artie.Header, 0, len(carrier))
+	for key, value := range carrier {
+		headers = append(headers, artie.Header{Key: key, Value: []byte(value)})
+	}
+
+	return headers
+}
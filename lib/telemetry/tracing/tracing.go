@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+const (
+	tracerName  = "github.com/artie-labs/transfer"
+	serviceName = "transfer"
+)
+
+// Init registers the global tracer provider and propagator. If tracing is not enabled, the global no-op provider is left in place.
+// The returned function flushes any buffered spans and should be called on shutdown.
+func Init(ctx context.Context, settings *config.TracingSettings, version string) (func(context.Context) error, error) {
+	if settings == nil || !settings.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closeExporter, err := buildExporter(ctx, *settings)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(settings.GetSampleRatio()))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	slog.Info("Tracing enabled", slog.String("exporter", string(settings.GetExporter())), slog.Float64("sampleRatio", settings.GetSampleRatio()))
+
+	return func(ctx context.Context) error {
+		return errors.Join(provider.Shutdown(ctx), closeExporter())
+	}, nil
+}
+
+func buildExporter(ctx context.Context, settings config.TracingSettings) (sdktrace.SpanExporter, func() error, error) {
+	switch settings.GetExporter() {
+	case config.OTLPTracingExporter:
+		var opts []otlptracehttp.Option
+		if settings.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(settings.Endpoint))
+		}
+
+		if settings.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+
+		return exporter, func() error { return nil }, nil
+	case config.FileTracingExporter:
+		file, err := os.OpenFile(settings.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open %q: %w", settings.FilePath, err)
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			return nil, nil, errors.Join(fmt.Errorf("failed to create file exporter: %w", err), file.Close())
+		}
+
+		return exporter, file.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported tracing exporter %q", settings.Exporter)
+	}
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records [err] (if any) on the span before ending it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// Run wraps [fn] in a span called [name].
+func Run(ctx context.Context, name string, fn func(ctx context.Context) error, attrs ...attribute.KeyValue) error {
+	ctx, span := Start(ctx, name, attrs...)
+	err := fn(ctx)
+	End(span, err)
+	return err
+}
+
+// RunWithResult is the same as [Run], but for functions that also return a value.
+func RunWithResult[T any](ctx context.Context, name string, fn func(ctx context.Context) (T, error), attrs ...attribute.KeyValue) (T, error) {
+	ctx, span := Start(ctx, name, attrs...)
+	result, err := fn(ctx)
+	End(span, err)
+	return result, err
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+func TestInit(t *testing.T) {
+	{
+		// Disabled
+		shutdown, err := Init(t.Context(), &config.TracingSettings{}, "dev")
+		assert.NoError(t, err)
+		assert.NoError(t, shutdown(t.Context()))
+	}
+	{
+		// File exporter
+		prevProvider := otel.GetTracerProvider()
+		defer otel.SetTracerProvider(prevProvider)
+
+		filePath := filepath.Join(t.TempDir(), "traces.jsonl")
+		shutdown, err := Init(t.Context(), &config.TracingSettings{Enabled: true, Exporter: config.FileTracingExporter, FilePath: filePath}, "dev")
+		assert.NoError(t, err)
+
+		assert.NoError(t, Run(t.Context(), "consumer.process", func(ctx context.Context) error { return nil }))
+		assert.NoError(t, shutdown(t.Context()))
+
+		data, err := os.ReadFile(filePath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"Name":"consumer.process"`)
+	}
+	{
+		// Invalid file path
+		_, err := Init(t.Context(), &config.TracingSettings{Enabled: true, Exporter: config.FileTracingExporter, FilePath: filepath.Join(t.TempDir(), "missing", "traces.jsonl")}, "dev")
+		assert.ErrorContains(t, err, "failed to open")
+	}
+}
+
+func TestRun(t *testing.T) {
+	recorder := newRecorder(t)
+	err := Run(t.Context(), "merge", func(ctx context.Context) error {
+		_, err := RunWithResult(ctx, "merge.get_table_config", func(ctx context.Context) (int, error) {
+			return 0, fmt.Errorf("table does not exist")
+		})
+		return err
+	})
+	assert.ErrorContains(t, err, "table does not exist")
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "merge.get_table_config", spans[0].Name())
+	assert.Equal(t, "merge", spans[1].Name())
+	// The child span should be nested under the parent.
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	for _, span := range spans {
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Equal(t, "table does not exist", span.Status().Description)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	recorder := newRecorder(t)
+	{
+		// No trace context
+		ctx := ExtractFromHeaders(t.Context(), []artie.Header{{Key: "foo", Value: []byte("bar")}})
+		assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+	}
+	{
+		// Round trip
+		ctx, span := Start(t.Context(), "producer")
+		headers := InjectIntoHeaders(ctx)
+		span.End()
+		assert.Len(t, headers, 1)
+		assert.Equal(t, "traceparent", headers[0].Key)
+
+		_, child := Start(ExtractFromHeaders(t.Context(), append([]artie.Header{{Key: "foo", Value: []byte("bar")}}, headers...)), "consumer.process")
+		child.End()
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 2)
+		assert.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+		assert.Equal(t, spans[0].SpanContext().SpanID(), spans[1].Parent().SpanID())
+	}
+}
@@ -52,6 +52,9 @@ type Column struct {
 	defaultValue any
 	// TODO: Instead of using a boolean, we should be setting the value at some point.
 	backfilled bool
+	// [notNull] and [destinationDefault] are read from the destination table, we need them to keep the column's constraints when changing its data type.
+	notNull            bool
+	destinationDefault *string
 }
 
 func (c Column) WithNewName(name string) Column {
@@ -97,6 +100,27 @@ func (c *Column) SetDefaultValue(value any) {
 	c.defaultValue = value
 }
 
+func (c *Column) SetNotNull(notNull bool) {
+	c.notNull = notNull
+}
+
+func (c *Column) NotNull() bool {
+	return c.notNull
+}
+
+// SetDestinationDefault sets the default value expression of the column, as reported by the destination.
+func (c *Column) SetDestinationDefault(expression string) {
+	c.destinationDefault = &expression
+}
+
+func (c *Column) DestinationDefault() (string, bool) {
+	if c.destinationDefault == nil {
+		return "", false
+	}
+
+	return *c.destinationDefault, true
+}
+
 func (c *Column) ToLowerName() {
 	c.name = strings.ToLower(c.name)
 }
@@ -98,3 +98,34 @@ func (Float32Converter) Convert(value any) (float32, error) {
 
 	return 0, fmt.Errorf("failed to convert %T to float32: unsupported type", value)
 }
+
+type Float64Converter struct{}
+
+func (Float64Converter) Convert(value any) (float64, error) {
+	switch castValue := value.(type) {
+	case float32:
+		return float64(castValue), nil
+	case float64:
+		return castValue, nil
+	case int:
+		return float64(castValue), nil
+	case int32:
+		return float64(castValue), nil
+	case int64:
+		return float64(castValue), nil
+	case json.Number:
+		parsed, err := castValue.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("failed to convert %T to float64: %w", value, err)
+		}
+		return parsed, nil
+	case string:
+		parsed, err := strconv.ParseFloat(castValue, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to convert %T to float64: %w", value, err)
+		}
+		return parsed, nil
+	}
+
+	return 0, fmt.Errorf("failed to convert %T to float64: unsupported type", value)
+}
@@ -206,3 +206,42 @@ func TestFloat32Converter_Convert(t *testing.T) {
 		assert.ErrorContains(t, err, "failed to convert bool to float32: unsupported type")
 	}
 }
+
+func TestFloat64Converter_Convert(t *testing.T) {
+	converter := Float64Converter{}
+	{
+		// Float32
+		actual, err := converter.Convert(float32(1.5))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1.5), actual)
+	}
+	{
+		// Float64
+		actual, err := converter.Convert(math.MaxFloat64)
+		assert.NoError(t, err)
+		assert.Equal(t, math.MaxFloat64, actual)
+	}
+	{
+		// Integers
+		actual, err := converter.Convert(int64(42))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(42), actual)
+	}
+	{
+		// String
+		actual, err := converter.Convert("123.55")
+		assert.NoError(t, err)
+		assert.Equal(t, 123.55, actual)
+	}
+	{
+		// json.Number
+		actual, err := converter.Convert(json.Number("1.1"))
+		assert.NoError(t, err)
+		assert.Equal(t, 1.1, actual)
+	}
+	{
+		// Irrelevant
+		_, err := converter.Convert(true)
+		assert.ErrorContains(t, err, "failed to convert bool to float64: unsupported type")
+	}
+}
@@ -1,7 +1,9 @@
 package decimal
 
 import (
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
 
 	"github.com/cockroachdb/apd/v3"
 )
@@ -43,3 +45,28 @@ func (d *Decimal) String() string {
 func (d *Decimal) Details() Details {
 	return NewDetails(d.precision, -d.value.Exponent)
 }
+
+// GobEncode is implemented so that buffered rows containing decimals can be spilled to disk.
+func (d Decimal) GobEncode() ([]byte, error) {
+	text, err := d.value.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(binary.BigEndian.AppendUint32(nil, uint32(d.precision)), text...), nil
+}
+
+func (d *Decimal) GobDecode(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("invalid gob encoded decimal: %q", data)
+	}
+
+	var value apd.Decimal
+	if err := value.UnmarshalText(data[4:]); err != nil {
+		return err
+	}
+
+	d.precision = int32(binary.BigEndian.Uint32(data[:4]))
+	d.value = &value
+	return nil
+}
@@ -30,3 +30,12 @@ func (t Time) String() string {
 func (t Time) MarshalJSON() ([]byte, error) {
 	return json.Marshal(t.String())
 }
+
+// GobEncode is implemented so that buffered rows containing [Time] values can be spilled to disk.
+func (t Time) GobEncode() ([]byte, error) {
+	return t.value.GobEncode()
+}
+
+func (t *Time) GobDecode(data []byte) error {
+	return t.value.GobDecode(data)
+}
@@ -0,0 +1,37 @@
+package values
+
+import (
+	"encoding/json"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/converters"
+	"github.com/artie-labs/transfer/lib/typing/converters/primitives"
+)
+
+// ToJSONValue converts [colVal] so that booleans and numbers keep their JSON types, nested values are embedded as-is and everything else is a string.
+func ToJSONValue(colVal any, colKind typing.KindDetails, opts converters.GetStringConverterOpts) (any, error) {
+	if colVal == nil || colVal == constants.ToastUnavailableValuePlaceholder {
+		return colVal, nil
+	}
+
+	switch colKind.Kind {
+	case typing.Boolean.Kind:
+		return primitives.BooleanConverter{}.Convert(colVal)
+	case typing.Integer.Kind:
+		return primitives.Int64Converter{}.Convert(colVal)
+	case typing.Float.Kind:
+		return primitives.Float64Converter{}.Convert(colVal)
+	}
+
+	value, err := ToStringOpts(colVal, colKind, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if (colKind.Kind == typing.Struct.Kind || colKind.Kind == typing.Array.Kind) && json.Valid([]byte(value)) {
+		return json.RawMessage(value), nil
+	}
+
+	return value, nil
+}
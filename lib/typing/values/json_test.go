@@ -0,0 +1,47 @@
+package values
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/converters"
+)
+
+func TestToJSONValue(t *testing.T) {
+	opts := converters.GetStringConverterOpts{}
+	{
+		// Nil
+		value, err := ToJSONValue(nil, typing.String, opts)
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+	}
+	{
+		// Numbers and booleans keep their types
+		value, err := ToJSONValue("5", typing.Integer, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), value)
+
+		value, err = ToJSONValue(true, typing.Boolean, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, true, value)
+
+		value, err = ToJSONValue(1.5, typing.Float, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 1.5, value)
+	}
+	{
+		// Structs are embedded
+		value, err := ToJSONValue(map[string]any{"a": "b"}, typing.Struct, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, json.RawMessage(`{"a":"b"}`), value)
+	}
+	{
+		// Everything else is a string
+		value, err := ToJSONValue("hello", typing.String, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", value)
+	}
+}
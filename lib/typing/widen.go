@@ -0,0 +1,71 @@
+package typing
+
+import "github.com/artie-labs/transfer/lib/typing/decimal"
+
+func integerKindRank(kd KindDetails) (OptionalIntegerKind, bool) {
+	if kd.OptionalIntegerKind == nil || *kd.OptionalIntegerKind == NotSpecifiedKind {
+		return NotSpecifiedKind, false
+	}
+
+	return *kd.OptionalIntegerKind, true
+}
+
+// IsWidening returns true if every value of [current] fits into [incoming] and [incoming] can hold values that [current] cannot.
+// This only compares kinds that can be widened without changing how the values are represented:
+//   - Integers: smallint -> int -> bigint, both sides need to have their integer kind specified.
+//   - Strings: varchar(n) -> varchar(m) where m > n, or varchar(n) -> text (no precision).
+//   - Decimals: numeric(p1, s1) -> numeric(p2, s2) where neither the scale nor the number of integer digits shrink, both sides need to have their precision specified.
+func IsWidening(current, incoming KindDetails) bool {
+	if current.Kind != incoming.Kind {
+		return false
+	}
+
+	switch current.Kind {
+	case Integer.Kind:
+		currentRank, ok := integerKindRank(current)
+		if !ok {
+			return false
+		}
+
+		incomingRank, ok := integerKindRank(incoming)
+		if !ok {
+			return false
+		}
+
+		return incomingRank > currentRank
+	case String.Kind:
+		if current.OptionalStringPrecision == nil {
+			// The current column is already unbounded.
+			return false
+		}
+
+		if incoming.OptionalStringPrecision == nil {
+			return true
+		}
+
+		return *incoming.OptionalStringPrecision > *current.OptionalStringPrecision
+	case EDecimal.Kind:
+		if current.ExtendedDecimalDetails == nil || incoming.ExtendedDecimalDetails == nil {
+			return false
+		}
+
+		currentDetails, incomingDetails := *current.ExtendedDecimalDetails, *incoming.ExtendedDecimalDetails
+		if currentDetails.Precision() == decimal.PrecisionNotSpecified || incomingDetails.Precision() == decimal.PrecisionNotSpecified {
+			return false
+		}
+
+		if incomingDetails.Scale() < currentDetails.Scale() {
+			return false
+		}
+
+		currentIntegerDigits := currentDetails.Precision() - currentDetails.Scale()
+		incomingIntegerDigits := incomingDetails.Precision() - incomingDetails.Scale()
+		if incomingIntegerDigits < currentIntegerDigits {
+			return false
+		}
+
+		return incomingDetails.Precision() > currentDetails.Precision()
+	default:
+		return false
+	}
+}
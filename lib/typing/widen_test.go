@@ -0,0 +1,61 @@
+package typing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+func buildDecimalKind(precision, scale int32) KindDetails {
+	return NewDecimalDetailsFromTemplate(EDecimal, decimal.NewDetails(precision, scale))
+}
+
+func TestIsWidening(t *testing.T) {
+	{
+		// Different kinds
+		assert.False(t, IsWidening(String, BuildIntegerKind(BigIntegerKind)))
+		assert.False(t, IsWidening(BuildIntegerKind(IntegerKind), buildDecimalKind(20, 0)))
+	}
+	{
+		// Integers
+		assert.True(t, IsWidening(BuildIntegerKind(IntegerKind), BuildIntegerKind(BigIntegerKind)))
+		assert.True(t, IsWidening(BuildIntegerKind(SmallIntegerKind), BuildIntegerKind(IntegerKind)))
+		assert.False(t, IsWidening(BuildIntegerKind(BigIntegerKind), BuildIntegerKind(IntegerKind)))
+		assert.False(t, IsWidening(BuildIntegerKind(IntegerKind), BuildIntegerKind(IntegerKind)))
+		// Not specified on either side
+		assert.False(t, IsWidening(BuildIntegerKind(IntegerKind), Integer))
+		assert.False(t, IsWidening(Integer, BuildIntegerKind(BigIntegerKind)))
+	}
+	{
+		// Strings
+		varchar50 := KindDetails{Kind: String.Kind, OptionalStringPrecision: ToPtr(int32(50))}
+		varchar100 := KindDetails{Kind: String.Kind, OptionalStringPrecision: ToPtr(int32(100))}
+		assert.True(t, IsWidening(varchar50, String))
+		assert.True(t, IsWidening(varchar50, varchar100))
+		assert.False(t, IsWidening(varchar100, varchar50))
+		assert.False(t, IsWidening(varchar50, varchar50))
+		assert.False(t, IsWidening(String, varchar50))
+		assert.False(t, IsWidening(String, String))
+	}
+	{
+		// Decimals
+		assert.True(t, IsWidening(buildDecimalKind(10, 2), buildDecimalKind(20, 4)))
+		assert.True(t, IsWidening(buildDecimalKind(10, 2), buildDecimalKind(12, 2)))
+		assert.True(t, IsWidening(buildDecimalKind(10, 2), buildDecimalKind(11, 3)))
+		assert.False(t, IsWidening(buildDecimalKind(10, 2), buildDecimalKind(10, 2)))
+		// Scale shrinks
+		assert.False(t, IsWidening(buildDecimalKind(10, 4), buildDecimalKind(20, 2)))
+		// Integer digits shrink
+		assert.False(t, IsWidening(buildDecimalKind(10, 2), buildDecimalKind(10, 4)))
+		// Precision is not specified
+		assert.False(t, IsWidening(buildDecimalKind(10, 2), buildDecimalKind(decimal.PrecisionNotSpecified, 2)))
+		assert.False(t, IsWidening(buildDecimalKind(10, 2), EDecimal))
+	}
+	{
+		// Kinds that cannot be widened
+		assert.False(t, IsWidening(Boolean, Boolean))
+		assert.False(t, IsWidening(TimestampNTZ, TimestampTZ))
+	}
+}
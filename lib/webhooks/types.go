@@ -18,11 +18,12 @@ const (
 	EventDedupeCompleted   EventType = "dedupe.completed"
 	EventDedupeFailed      EventType = "dedupe.failed"
 
-	EventReplicationStarted EventType = "replication.started"
-	EventReplicationError   EventType = "replication.error"
-	EventRowSkipped         EventType = "row.skipped"
-	EventDDLSeen            EventType = "ddl.seen"
-	EventDDLApplied         EventType = "ddl.applied"
+	EventReplicationStarted  EventType = "replication.started"
+	EventReplicationError    EventType = "replication.error"
+	EventRowSkipped          EventType = "row.skipped"
+	EventDDLSeen             EventType = "ddl.seen"
+	EventDDLApplied          EventType = "ddl.applied"
+	EventMessageDeadLettered EventType = "message.dead_lettered"
 
 	// Dashboard specific events
 	EventDEKGenerated EventType = "dek.generated"
@@ -47,6 +48,7 @@ var AllEventTypes = []EventType{
 	EventRowSkipped,
 	EventDDLSeen,
 	EventDDLApplied,
+	EventMessageDeadLettered,
 	EventDEKGenerated,
 
 	EventReplicationFailed,
@@ -86,11 +88,12 @@ var eventMetadataMap = map[EventType]EventMetadata{
 	EventDedupeCompleted:   {SeverityInfo, "backfill", "Deduplication completed"},
 	EventDedupeFailed:      {SeverityError, "backfill", "Deduplication failed"},
 	// Replication events
-	EventReplicationStarted: {SeverityInfo, "replication", "Replication started"},
-	EventReplicationError:   {SeverityError, "replication", "Replication error"},
-	EventRowSkipped:         {SeverityWarning, "replication", "Row skipped"},
-	EventDDLSeen:            {SeverityInfo, "replication", "DDL seen"},
-	EventDDLApplied:         {SeverityInfo, "replication", "DDL applied"},
+	EventReplicationStarted:  {SeverityInfo, "replication", "Replication started"},
+	EventReplicationError:    {SeverityError, "replication", "Replication error"},
+	EventRowSkipped:          {SeverityWarning, "replication", "Row skipped"},
+	EventDDLSeen:             {SeverityInfo, "replication", "DDL seen"},
+	EventDDLApplied:          {SeverityInfo, "replication", "DDL applied"},
+	EventMessageDeadLettered: {SeverityWarning, "replication", "Message sent to dead-letter queue"},
 	// Dashboard specific events
 	EventDEKGenerated: {SeverityInfo, "dashboard", "Data Encryption Key (DEK) generated"},
 
@@ -167,9 +170,12 @@ type EventProperties struct {
 	Database        string         `json:"database,omitempty"`
 	Topic           string         `json:"topic,omitempty"`
 	RowsWritten     int64          `json:"rows_written,omitempty"`
+	RowsSkipped     int64          `json:"rows_skipped,omitempty"`
 	DurationSeconds float64        `json:"duration_seconds,omitempty"`
 	Reason          string         `json:"reason,omitempty"`
 	PrimaryKeys     map[string]any `json:"primary_keys,omitempty"`
+	Partition       *int           `json:"partition,omitempty"`
+	Offset          *int64         `json:"offset,omitempty"`
 
 	// DDL related properties:
 	Query string `json:"query,omitempty"`
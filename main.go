@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,12 +16,17 @@ import (
 	"github.com/artie-labs/transfer/lib/cryptography"
 	"github.com/artie-labs/transfer/lib/destination"
 	"github.com/artie-labs/transfer/lib/destination/utils"
+	"github.com/artie-labs/transfer/lib/filelib"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/logger"
+	"github.com/artie-labs/transfer/lib/natslib"
 	"github.com/artie-labs/transfer/lib/system"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/lib/telemetry/tracing"
 	"github.com/artie-labs/transfer/lib/webhooks"
 	"github.com/artie-labs/transfer/models"
+	"github.com/artie-labs/transfer/processes/admin"
+	"github.com/artie-labs/transfer/processes/budget"
 	"github.com/artie-labs/transfer/processes/consumer"
 	"github.com/artie-labs/transfer/processes/pool"
 )
@@ -28,10 +34,30 @@ import (
 var version = "dev" // this will be set by the goreleaser configuration to appropriate value for the compiled binary.
 
 func main() {
+	args := os.Args[1:]
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		// Without a subcommand we replicate, which keeps `transfer --config config.yaml` working.
+		run(args)
+		return
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+
+	if err := cmd.run(context.Background(), args[1:]); err != nil {
+		logger.Fatal(fmt.Sprintf("Failed to run %q", cmd.name), slog.Any("err", err))
+	}
+}
+
+// run replicates data from the message queue into the destination until Transfer is shut down.
+func run(args []string) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	settings, err := config.LoadSettings(os.Args, true)
+	settings, err := config.LoadSettings(args, true)
 	webhookSettings := settings.Config.WebhookSettings
 	whClient, whErr := webhooks.NewClient(webhookSettings, webhooks.Transfer, version)
 	if whErr != nil {
@@ -81,6 +107,26 @@ func main() {
 	)
 
 	metricsClient := metrics.LoadExporter(settings.Config)
+	shutdownTracing, err := tracing.Init(ctx, settings.Config.Telemetry.Tracing, version)
+	if err != nil {
+		whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+			Error: fmt.Sprintf("Failed to initialize tracing: %s", err),
+		})
+		logger.Fatal("Failed to initialize tracing", slog.Any("err", err))
+	}
+
+	inMemDB := models.NewMemoryDB()
+	var adminServer *admin.Server
+	if settings.Config.AdminServer != nil {
+		adminServer = admin.NewServer(settings.Config, inMemDB, metricsClient, whClient)
+		if err = adminServer.Start(ctx, settings.Config.AdminServer.GetAddr()); err != nil {
+			whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+				Error: fmt.Sprintf("Failed to start admin server: %s", err),
+			})
+			logger.Fatal("Failed to start admin server", slog.Any("err", err))
+		}
+	}
+
 	dest, err := utils.Load(ctx, settings.Config)
 	if err != nil {
 		whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
@@ -98,32 +144,71 @@ func main() {
 		}
 	}
 
+	if adminServer != nil {
+		adminServer.SetDestination(dest)
+	}
+
 	slog.Info("Starting...", slog.String("version", version))
 	whClient.SendEvent(ctx, webhooks.EventReplicationStarted, webhooks.EventProperties{})
 
-	system.ShutdownHook(func() {
+	flushTelemetry := sync.OnceFunc(func() {
 		cleanUpHandlers()
 		if err := metricsClient.Flush(); err != nil {
 			slog.Error("Failed to flush metrics", slog.Any("err", err))
 		}
+
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			slog.Error("Failed to flush traces", slog.Any("err", err))
+		}
+	})
+
+	system.ShutdownHook(func() {
+		// With a graceful shutdown, telemetry is flushed once the consumers have finished draining so that the drain is captured.
+		if settings.Config.GracefulShutdown == nil {
+			flushTelemetry()
+		}
 	}, cancel)
 
-	inMemDB := models.NewMemoryDB()
 	kvCache := lib.NewKVCache[string]()
-	switch settings.Config.KafkaClient {
-	case config.FranzGoClient:
-		ctx, err = kafkalib.InjectFranzGoConsumerProvidersIntoContext(ctx, settings.Config.Kafka)
+	switch settings.Config.Queue {
+	case constants.Kafka:
+		switch settings.Config.KafkaClient {
+		case config.FranzGoClient:
+			ctx, err = kafkalib.InjectFranzGoConsumerProvidersIntoContext(ctx, settings.Config.Kafka)
+			if err != nil {
+				whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+					Error: fmt.Sprintf("Failed to initialize Kafka client: %s", err),
+				})
+				logger.Fatal("Failed to inject franz-go consumer providers into context", slog.Any("err", err))
+			}
+		default:
+			whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+				Error: fmt.Sprintf("Failed to initialize: Kafka client %q not supported", settings.Config.KafkaClient),
+			})
+			logger.Fatal(fmt.Sprintf("Kafka client: %q not supported", settings.Config.KafkaClient))
+		}
+	case constants.NATS:
+		ctx, err = natslib.InjectConsumersIntoContext(ctx, settings.Config.NATS)
 		if err != nil {
 			whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
-				Error: fmt.Sprintf("Failed to initialize Kafka client: %s", err),
+				Error: fmt.Sprintf("Failed to initialize NATS client: %s", err),
 			})
-			logger.Fatal("Failed to inject franz-go consumer providers into context", slog.Any("err", err))
+			logger.Fatal("Failed to inject NATS consumers into context", slog.Any("err", err))
 		}
-	default:
-		whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
-			Error: fmt.Sprintf("Failed to initialize: Kafka client %q not supported", settings.Config.KafkaClient),
-		})
-		logger.Fatal(fmt.Sprintf("Kafka client: %q not supported", settings.Config.KafkaClient))
+	case constants.File:
+		ctx, err = filelib.InjectConsumersIntoContext(ctx, settings.Config.File)
+		if err != nil {
+			whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+				Error: fmt.Sprintf("Failed to read files: %s", err),
+			})
+			logger.Fatal("Failed to inject file consumers into context", slog.Any("err", err))
+		}
+	}
+
+	if adminServer != nil {
+		adminServer.SetConsumerContext(ctx)
 	}
 
 	var wg sync.WaitGroup
@@ -131,23 +216,100 @@ func main() {
 	go func() {
 		defer wg.Done()
 		defer logger.RecoverFatal()
-		pool.StartPool(ctx, inMemDB, dest, metricsClient, whClient, settings.Config.Kafka.Topics(), time.Duration(settings.Config.FlushIntervalSeconds)*time.Second, settings.Config)
+		pool.StartPool(ctx, inMemDB, dest, metricsClient, whClient, settings.Config.Topics(), time.Duration(settings.Config.FlushIntervalSeconds)*time.Second, settings.Config)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer logger.RecoverFatal()
+		consumer.StartBufferMetrics(ctx, inMemDB, metricsClient)
+	}()
+
+	if settings.Config.MemoryBudget != nil {
+		enforcer := budget.NewEnforcer(inMemDB, dest, metricsClient, whClient, settings.Config.Topics(), settings.Config)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer logger.RecoverFatal()
+			enforcer.Start(ctx)
+		}()
+	}
+
+	if settings.Config.Queue == constants.File {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer logger.RecoverFatal()
+			if err := consumer.WaitForReplay(ctx, settings.Config, inMemDB, dest, metricsClient, whClient); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+					Error: fmt.Sprintf("Failed to replay files: %s", err),
+				})
+				logger.Fatal("Failed to replay files", slog.Any("err", err))
+			}
+
+			slog.Info("Finished replaying files, shutting down...")
+			cancel()
+		}()
+	}
+
 	wg.Add(1)
 	go func(ctx context.Context) {
 		defer wg.Done()
 		defer logger.RecoverFatal()
+		var queueConsumer *consumer.Consumer
+		var err error
 		switch settings.Config.Queue {
 		case constants.Kafka:
-			consumer.StartKafkaConsumer(ctx, settings.Config, inMemDB, dest, metricsClient, whClient, kvCache)
+			queueConsumer, err = consumer.NewKafkaConsumer(ctx, settings.Config, inMemDB, dest, metricsClient, whClient, kvCache)
+		case constants.NATS:
+			queueConsumer, err = consumer.NewNATSConsumer(ctx, settings.Config, inMemDB, dest, metricsClient, whClient, kvCache)
+		case constants.File:
+			queueConsumer, err = consumer.NewFileConsumer(ctx, settings.Config, inMemDB, dest, metricsClient, whClient, kvCache)
 		default:
 			whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
 				Error: fmt.Sprintf("Failed to initialize: message queue %q not supported", settings.Config.Queue),
 			})
 			logger.Fatal(fmt.Sprintf("Message queue: %q not supported", settings.Config.Queue))
 		}
+
+		if err != nil {
+			whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+				Error: fmt.Sprintf("Failed to start %s consumer: %s", settings.Config.Queue, err),
+			})
+			logger.Fatal("Failed to start consumer", slog.Any("err", err), slog.String("queue", string(settings.Config.Queue)))
+		}
+
+		system.ReloadHook(ctx, func() {
+			reloadTopicConfigs(ctx, settings.Config, settings.ConfigFilePath, queueConsumer)
+		})
+		queueConsumer.Start(ctx)
 	}(ctx)
 
 	wg.Wait()
+	flushTelemetry()
+}
+
+// reloadTopicConfigs re-reads the config file and applies any changes to the topic configs, other changes require a restart.
+func reloadTopicConfigs(ctx context.Context, cfg config.Config, configFilePath string, queueConsumer *consumer.Consumer) {
+	newCfg, err := config.ReadConfig(configFilePath)
+	if err != nil {
+		slog.Error("Failed to reload config", slog.Any("err", err))
+		return
+	}
+
+	if !cfg.EqualExceptTopicConfigs(*newCfg) {
+		slog.Warn("Only topic configs can be reloaded, Transfer needs to be restarted to apply the other changes")
+	}
+
+	if err = queueConsumer.Reload(ctx, newCfg.TopicConfigs()); err != nil {
+		slog.Error("Failed to reload topic configs", slog.Any("err", err))
+		return
+	}
+
+	slog.Info("Reloaded topic configs", slog.Any("topics", newCfg.Topics()))
 }
@@ -34,8 +34,13 @@ type Event struct {
 
 	// [executionTime] - The database timestamp for when the event was created.
 	executionTime time.Time
-	mode          config.Mode
-	appendOnly    bool
+	operation     string
+	// [partition] - The Kafka partition that the event was consumed from.
+	partition  int
+	mode       config.Mode
+	appendOnly bool
+	// [filtered] - The row did not match the topic's row filter, so it should not be written to the destination.
+	filtered bool
 }
 
 func (e Event) GetTableID() cdc.TableID {
@@ -68,6 +73,16 @@ func ToMemoryEvent(ctx context.Context, dest destination.Destination, event cdc.
 		return Event{}, fmt.Errorf("failed to load artie metadata: %w", err)
 	}
 
+	operation := event.Operation()
+	filtered, filteredToDelete, err := applyRowFilter(data, operation, tc, cfgMode)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to evaluate row filter: %w", err)
+	}
+
+	if filteredToDelete {
+		operation = constants.Delete
+	}
+
 	if tc.IncludeSourceMetadata {
 		metadata, err := event.GetSourceMetadata()
 		if err != nil {
@@ -87,7 +102,7 @@ func ToMemoryEvent(ctx context.Context, dest destination.Destination, event cdc.
 		}
 
 		// If this is already set, it's a no-op.
-		data[constants.OperationColumnMarker] = string(event.Operation())
+		data[constants.OperationColumnMarker] = string(operation)
 
 		// We don't need the deletion markers either.
 		delete(data, constants.DeleteColumnMarker)
@@ -150,6 +165,7 @@ func ToMemoryEvent(ctx context.Context, dest destination.Destination, event cdc.
 	sort.Strings(pks)
 	return Event{
 		executionTime: event.GetExecutionTime(),
+		operation:     string(operation),
 		mode:          cfgMode,
 		appendOnly:    tc.AppendOnly,
 		// [primaryKeys] needs to be sorted so that we have a deterministic way to identify a row in our in-memory db.
@@ -159,7 +175,8 @@ func ToMemoryEvent(ctx context.Context, dest destination.Destination, event cdc.
 		optionalSchema: optionalSchema,
 		columns:        cols,
 		data:           transformedData,
-		deleted:        event.DeletePayload(),
+		deleted:        event.DeletePayload() || filteredToDelete,
+		filtered:       filtered,
 	}, nil
 }
 
@@ -168,6 +185,11 @@ func (e Event) GetData() map[string]any {
 	return e.data
 }
 
+// Filtered - Returns true if the row did not match the topic's row filter and should be skipped.
+func (e Event) Filtered() bool {
+	return e.filtered
+}
+
 // SetData - This will set the data for the event. This is used by Reader.
 func (e *Event) SetData(key string, value any) {
 	e.data[key] = value
@@ -177,6 +199,11 @@ func (e *Event) GetExecutionTime() time.Time {
 	return e.executionTime
 }
 
+// SetPartition - This will set the Kafka partition that the event was consumed from.
+func (e *Event) SetPartition(partition int) {
+	e.partition = partition
+}
+
 // EmitExecutionTimeLag - This will check against the current time and the event execution time and emit the lag.
 func (e *Event) EmitExecutionTimeLag(metricsClient base.Client) {
 	metricsClient.GaugeWithSample(
@@ -252,6 +279,9 @@ func (e *Event) Save(cfg config.Config, inMemDB *models.DatabaseData, tc kafkali
 		}
 
 		td.SetTableData(optimization.NewTableData(cols, cfg.Mode, e.GetPrimaryKeys(), tc, e.table))
+		if cfg.Spill != nil && cfg.Spill.Enabled {
+			td.EnableSpill(*cfg.Spill)
+		}
 	} else {
 		if e.columns != nil {
 			// Iterate over this again just in case.
@@ -333,7 +363,13 @@ func (e *Event) Save(cfg config.Config, inMemDB *models.DatabaseData, tc kafkali
 		return false, "", fmt.Errorf("failed to retrieve primary key value: %w", err)
 	}
 
-	td.InsertRow(pkValueString, e.data, e.deleted)
+	metadata := optimization.RowMetadata{ExecutionTime: e.executionTime, Operation: e.operation, Partition: e.partition}
+	if err = td.InsertRowWithMetadata(pkValueString, e.data, e.deleted, metadata); err != nil {
+		return false, "", fmt.Errorf("failed to insert row: %w", err)
+	}
+
+	td.SyncMemoryUsage()
+
 	td.SetLatestTimestamp(e.executionTime)
 	flush, flushReason := td.ShouldFlush(cfg)
 	return flush, flushReason, nil
@@ -39,13 +39,24 @@ func (e *EventsTestSuite) TestSaveEvent() {
 		anotherCol:                          13.37,
 	}, nil)
 
+	mockEvent.OperationReturns("c")
 	event, err := ToMemoryEvent(e.T().Context(), e.fakeBaseline, mockEvent, map[string]any{"id": "123"}, topicConfig, config.Replication, config.SharedDestinationSettings{}, nil, nil)
 	assert.NoError(e.T(), err)
 
+	event.SetPartition(3)
 	_, _, err = event.Save(e.cfg, e.db, topicConfig, nil)
 	assert.NoError(e.T(), err)
 
 	optimization := e.db.GetOrCreateTableData(event.GetTableID(), topicConfig.Topic)
+	{
+		// The row should keep track of the event that produced it
+		rows, err := optimization.Rows()
+		assert.NoError(e.T(), err)
+		assert.Len(e.T(), rows, 1)
+		assert.Equal(e.T(), "c", rows[0].Metadata().Operation)
+		assert.Equal(e.T(), 3, rows[0].Metadata().Partition)
+		assert.Equal(e.T(), event.GetExecutionTime(), rows[0].Metadata().ExecutionTime)
+	}
 	// Check the in-memory DB columns.
 	var found int
 	for _, col := range optimization.ReadOnlyInMemoryCols().GetColumns() {
@@ -101,7 +112,9 @@ func (e *EventsTestSuite) TestEvent_SaveCasing() {
 
 	td := e.db.GetOrCreateTableData(event.GetTableID(), topicConfig.Topic)
 	var rowData map[string]any
-	for _, row := range td.Rows() {
+	rows, err := td.Rows()
+	assert.NoError(e.T(), err)
+	for _, row := range rows {
 		if id, ok := row.GetValue("id"); ok {
 			if id == "123" {
 				rowData = row.GetData()
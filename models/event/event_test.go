@@ -360,6 +360,76 @@ func (e *EventsTestSuite) TestEvent_StaticColumns() {
 	}
 }
 
+func (e *EventsTestSuite) TestEvent_RowFilter() {
+	e.fakeEvent.GetDataReturns(map[string]any{"id": 123, "tenant_id": "test", "email": "foo@example.com"}, nil)
+	{
+		// No row filter
+		evt, err := ToMemoryEvent(e.T().Context(), e.fakeBaseline, e.fakeEvent, map[string]any{"id": 123}, kafkalib.TopicConfig{}, config.Replication, config.SharedDestinationSettings{}, nil, nil)
+		assert.NoError(e.T(), err)
+		assert.False(e.T(), evt.Filtered())
+	}
+	{
+		// Row does not match
+		evt, err := ToMemoryEvent(e.T().Context(), e.fakeBaseline, e.fakeEvent, map[string]any{"id": 123}, kafkalib.TopicConfig{RowFilter: "tenant_id != 'test'"}, config.Replication, config.SharedDestinationSettings{}, nil, nil)
+		assert.NoError(e.T(), err)
+		assert.True(e.T(), evt.Filtered())
+	}
+	{
+		// The filter is evaluated before columns are hashed or excluded
+		tc := kafkalib.TopicConfig{RowFilter: "email = 'foo@example.com' AND tenant_id = 'test'", ColumnsToHash: []string{"email"}, ColumnsToExclude: []string{"tenant_id"}}
+		evt, err := ToMemoryEvent(e.T().Context(), e.fakeBaseline, e.fakeEvent, map[string]any{"id": 123}, tc, config.Replication, config.SharedDestinationSettings{}, nil, nil)
+		assert.NoError(e.T(), err)
+		assert.False(e.T(), evt.Filtered())
+		assert.NotContains(e.T(), evt.GetData(), "tenant_id")
+	}
+	{
+		// Invalid row filter
+		_, err := ToMemoryEvent(e.T().Context(), e.fakeBaseline, e.fakeEvent, map[string]any{"id": 123}, kafkalib.TopicConfig{RowFilter: "tenant_id !="}, config.Replication, config.SharedDestinationSettings{}, nil, nil)
+		assert.ErrorContains(e.T(), err, "failed to evaluate row filter: expected a column or a value, got end of expression")
+	}
+	{
+		// Deletes always pass through, even when the before image only has the primary keys
+		e.fakeEvent.OperationReturns(constants.Delete)
+		e.fakeEvent.DeletePayloadReturns(true)
+		e.fakeEvent.GetDataReturns(map[string]any{"id": 123, constants.DeleteColumnMarker: true, constants.OnlySetDeleteColumnMarker: true}, nil)
+		evt, err := ToMemoryEvent(e.T().Context(), e.fakeBaseline, e.fakeEvent, map[string]any{"id": 123}, kafkalib.TopicConfig{RowFilter: "tenant_id = 'test'"}, config.Replication, config.SharedDestinationSettings{}, nil, nil)
+		assert.NoError(e.T(), err)
+		assert.False(e.T(), evt.Filtered())
+		assert.True(e.T(), evt.deleted)
+		assert.Equal(e.T(), string(constants.Delete), evt.operation)
+	}
+	{
+		// An update that moves the row out of the filter is turned into a delete
+		e.fakeEvent.OperationReturns(constants.Update)
+		e.fakeEvent.DeletePayloadReturns(false)
+		e.fakeEvent.GetDataReturns(map[string]any{"id": 123, "tenant_id": "other", constants.DeleteColumnMarker: false, constants.OnlySetDeleteColumnMarker: false}, nil)
+		evt, err := ToMemoryEvent(e.T().Context(), e.fakeBaseline, e.fakeEvent, map[string]any{"id": 123}, kafkalib.TopicConfig{RowFilter: "tenant_id = 'test'"}, config.Replication, config.SharedDestinationSettings{}, nil, nil)
+		assert.NoError(e.T(), err)
+		assert.False(e.T(), evt.Filtered())
+		assert.True(e.T(), evt.deleted)
+		assert.Equal(e.T(), string(constants.Delete), evt.operation)
+		assert.Equal(e.T(), true, evt.GetData()[constants.DeleteColumnMarker])
+		assert.Equal(e.T(), true, evt.GetData()[constants.OnlySetDeleteColumnMarker])
+	}
+	{
+		// An update that moves the row out of the filter on an append-only topic is skipped
+		e.fakeEvent.GetDataReturns(map[string]any{"id": 123, "tenant_id": "other", constants.DeleteColumnMarker: false, constants.OnlySetDeleteColumnMarker: false}, nil)
+		evt, err := ToMemoryEvent(e.T().Context(), e.fakeBaseline, e.fakeEvent, map[string]any{"id": 123}, kafkalib.TopicConfig{RowFilter: "tenant_id = 'test'", AppendOnly: true}, config.Replication, config.SharedDestinationSettings{}, nil, nil)
+		assert.NoError(e.T(), err)
+		assert.True(e.T(), evt.Filtered())
+		assert.False(e.T(), evt.deleted)
+	}
+	{
+		// An update that still matches the filter is kept as an update
+		e.fakeEvent.GetDataReturns(map[string]any{"id": 123, "tenant_id": "test", constants.DeleteColumnMarker: false, constants.OnlySetDeleteColumnMarker: false}, nil)
+		evt, err := ToMemoryEvent(e.T().Context(), e.fakeBaseline, e.fakeEvent, map[string]any{"id": 123}, kafkalib.TopicConfig{RowFilter: "tenant_id = 'test'"}, config.Replication, config.SharedDestinationSettings{}, nil, nil)
+		assert.NoError(e.T(), err)
+		assert.False(e.T(), evt.Filtered())
+		assert.False(e.T(), evt.deleted)
+		assert.Equal(e.T(), string(constants.Update), evt.operation)
+	}
+}
+
 func (e *EventsTestSuite) TestToMemoryEventWithSoftPartitioning() {
 	partitionFrequencies := []kafkalib.PartitionFrequency{
 		kafkalib.Monthly,
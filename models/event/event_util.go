@@ -9,11 +9,13 @@ import (
 
 	"github.com/artie-labs/transfer/lib"
 	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/cryptography"
 	"github.com/artie-labs/transfer/lib/destination"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/maputil"
+	"github.com/artie-labs/transfer/lib/rowfilter"
 	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/typing/columns"
 	"github.com/artie-labs/transfer/lib/typing/converters/primitives"
@@ -231,6 +233,43 @@ func buildEventData(event cdc.Event, tc kafkalib.TopicConfig) (map[string]any, e
 	return data, nil
 }
 
+// applyRowFilter checks [data] against the topic's row filter, this needs to be called before the data is transformed.
+// It returns whether the row should be skipped, and whether it should be written as a delete instead.
+func applyRowFilter(data map[string]any, operation constants.Operation, tc kafkalib.TopicConfig, mode config.Mode) (bool, bool, error) {
+	if tc.RowFilter == "" {
+		return false, false, nil
+	}
+
+	// The before image of a delete may only have the primary keys (e.g. Postgres without REPLICA IDENTITY FULL),
+	// so we can't tell whether the row matched. Deleting a row that was never written is a no-op, so let it through.
+	if operation == constants.Delete {
+		return false, false, nil
+	}
+
+	filter, err := rowfilter.Get(tc.RowFilter)
+	if err != nil {
+		return false, false, err
+	}
+
+	if filter.Match(data) {
+		return false, false, nil
+	}
+
+	// An update may move a row that was previously written out of the filter, so it should be deleted from the destination.
+	// History mode and append-only topics never remove rows, so the update is skipped instead.
+	if operation == constants.Update && mode == config.Replication && !tc.AppendOnly {
+		data[constants.DeleteColumnMarker] = true
+		data[constants.OnlySetDeleteColumnMarker] = true
+		if _, ok := data[constants.OperationColumnMarker]; ok {
+			data[constants.OperationColumnMarker] = string(constants.Delete)
+		}
+
+		return false, true, nil
+	}
+
+	return true, false, nil
+}
+
 func buildSoftPartitionSuffix(ctx context.Context, event cdc.Event, data map[string]any, tc kafkalib.TopicConfig, tblName string, dest destination.Destination, cache *lib.KVCache[string]) (string, error) {
 	if cache == nil {
 		return "", fmt.Errorf("kv cache is nil")
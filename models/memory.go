@@ -1,10 +1,15 @@
 package models
 
 import (
+	"cmp"
+	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/optimization"
 )
 
@@ -16,6 +21,30 @@ type TableData struct {
 	tableID cdc.TableID
 	*optimization.TableData
 	lastFlushTime time.Time
+
+	db *DatabaseData
+	// memoryUsage is the in-memory size of this table that has been added to [DatabaseData]'s memory usage.
+	memoryUsage atomic.Int64
+	// filteredRows is the number of rows that did not match the topic's row filter since the last flush.
+	filteredRows atomic.Int64
+	// stats is updated by [TableData.SyncMemoryUsage] so that it can be read without holding the topic's lock.
+	stats atomic.Pointer[TableStats]
+}
+
+// TableStats is a snapshot of the table's buffer, see [TableData.Stats].
+type TableStats struct {
+	// Mode, Database and Schema are kept from the last time the table had rows, so that they can be used to tag metrics after a flush.
+	Mode     config.Mode
+	Database string
+	Schema   string
+
+	Rows                         uint
+	ApproxSize                   int
+	InMemorySize                 int
+	SpilledBytes                 int64
+	SpilledRows                  int
+	PendingMultiStepMergeFlushes int
+	LastFlushTime                time.Time
 }
 
 func (t *TableData) GetTableID() cdc.TableID {
@@ -23,8 +52,69 @@ func (t *TableData) GetTableID() cdc.TableID {
 }
 
 func (t *TableData) Wipe() {
+	if err := t.TableData.Close(); err != nil {
+		slog.Warn("Failed to clean up spill file", slog.Any("err", err), slog.String("tableID", t.tableID.String()))
+	}
+
 	t.TableData = nil
 	t.lastFlushTime = time.Now()
+	t.SyncMemoryUsage()
+}
+
+// SyncMemoryUsage updates the database's memory usage with this table's current in-memory size and refreshes [TableData.Stats].
+// This should be called whenever rows have been added or removed from the table.
+func (t *TableData) SyncMemoryUsage() {
+	size := int64(t.TableData.InMemorySize())
+	delta := size - t.memoryUsage.Swap(size)
+	if t.db != nil && delta != 0 {
+		t.db.addMemoryUsage(delta)
+	}
+
+	stats := TableStats{LastFlushTime: t.lastFlushTime}
+	if previous := t.stats.Load(); previous != nil {
+		stats.Mode, stats.Database, stats.Schema = previous.Mode, previous.Database, previous.Schema
+	}
+
+	if !t.Empty() {
+		stats.Mode = t.Mode()
+		stats.Database = t.TopicConfig().Database
+		stats.Schema = t.TopicConfig().Schema
+		stats.Rows = t.NumberOfRows()
+		stats.ApproxSize = t.ApproxSize()
+		stats.InMemorySize = t.InMemorySize()
+		stats.SpilledBytes = t.SpilledBytes()
+		stats.SpilledRows = t.SpilledRows()
+		stats.PendingMultiStepMergeFlushes = t.MultiStepMergeSettings().PendingFlushCount()
+	}
+	t.stats.Store(&stats)
+}
+
+// Stats returns the table's buffer as of the last [TableData.SyncMemoryUsage], this is safe to call without holding the topic's lock.
+func (t *TableData) Stats() TableStats {
+	if stats := t.stats.Load(); stats != nil {
+		return *stats
+	}
+
+	return TableStats{}
+}
+
+// MemoryUsage returns the in-memory size as of the last [TableData.SyncMemoryUsage], this is safe to call without holding the topic's lock.
+func (t *TableData) MemoryUsage() int64 {
+	return t.memoryUsage.Load()
+}
+
+// IncrementFilteredRows is called for every row that was skipped because it did not match the topic's row filter.
+func (t *TableData) IncrementFilteredRows() {
+	t.filteredRows.Add(1)
+}
+
+// ResetFilteredRows returns the number of rows that have been filtered since the last reset.
+func (t *TableData) ResetFilteredRows() int64 {
+	return t.filteredRows.Swap(0)
+}
+
+func (t *TableData) Topic() string {
+	return t.topic
 }
 
 // ShouldSkipFlush - this function is only used when the flush reason was time-based.
@@ -42,6 +132,10 @@ func (t *TableData) ShouldSkipFlush(cooldown time.Duration) bool {
 	return time.Since(t.lastFlushTime) < cooldown
 }
 
+func (t *TableData) LastFlushTime() time.Time {
+	return t.lastFlushTime
+}
+
 func (t *TableData) Empty() bool {
 	return t.TableData == nil
 }
@@ -53,15 +147,65 @@ func (t *TableData) SetTableData(td *optimization.TableData) {
 type DatabaseData struct {
 	tableData map[cdc.TableID]*TableData
 	sync.RWMutex
+
+	memoryUsage  atomic.Int64
+	memoryBudget int64
+	overBudget   chan struct{}
 }
 
 func NewMemoryDB() *DatabaseData {
 	tableData := make(map[cdc.TableID]*TableData)
 	return &DatabaseData{
-		tableData: tableData,
+		tableData:  tableData,
+		overBudget: make(chan struct{}, 1),
 	}
 }
 
+// SetMemoryBudget sets the number of bytes that can be buffered in memory across all the tables, [OverBudget] is notified once this is exceeded.
+func (d *DatabaseData) SetMemoryBudget(bytes int64) {
+	d.memoryBudget = bytes
+}
+
+func (d *DatabaseData) MemoryBudget() int64 {
+	return d.memoryBudget
+}
+
+func (d *DatabaseData) MemoryUsage() int64 {
+	return d.memoryUsage.Load()
+}
+
+// OverBudget receives a value when the memory usage exceeds the memory budget.
+func (d *DatabaseData) OverBudget() <-chan struct{} {
+	return d.overBudget
+}
+
+func (d *DatabaseData) addMemoryUsage(delta int64) {
+	usage := d.memoryUsage.Add(delta)
+	if delta > 0 && d.memoryBudget > 0 && usage > d.memoryBudget {
+		select {
+		case d.overBudget <- struct{}{}:
+		default:
+			// There's already a pending notification.
+		}
+	}
+}
+
+// TablesByMemoryUsage returns all the tables, sorted by the largest in-memory size first.
+func (d *DatabaseData) TablesByMemoryUsage() []*TableData {
+	d.RLock()
+	defer d.RUnlock()
+
+	out := make([]*TableData, 0, len(d.tableData))
+	for _, table := range d.tableData {
+		out = append(out, table)
+	}
+
+	slices.SortFunc(out, func(a, b *TableData) int {
+		return cmp.Compare(b.MemoryUsage(), a.MemoryUsage())
+	})
+	return out
+}
+
 func (d *DatabaseData) GetOrCreateTableData(tableID cdc.TableID, topic string) *TableData {
 	d.Lock()
 	defer d.Unlock()
@@ -70,6 +214,7 @@ func (d *DatabaseData) GetOrCreateTableData(tableID cdc.TableID, topic string) *
 		table := &TableData{
 			topic:   topic,
 			tableID: tableID,
+			db:      d,
 		}
 
 		d.tableData[tableID] = table
@@ -1,9 +1,12 @@
 package models
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/optimization"
 
 	"github.com/stretchr/testify/assert"
@@ -44,3 +47,45 @@ func TestTableData_Complete(t *testing.T) {
 		}
 	}
 }
+
+func TestDatabaseData_MemoryUsage(t *testing.T) {
+	db := NewMemoryDB()
+	db.SetMemoryBudget(100)
+
+	newTable := func(name string) *TableData {
+		td := db.GetOrCreateTableData(cdc.NewTableID("schema", name), "topic")
+		td.SetTableData(optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, name))
+		return td
+	}
+
+	small := newTable("small")
+	assert.NoError(t, small.InsertRow("1", map[string]any{"id": 1}, false))
+	small.SyncMemoryUsage()
+	assert.Equal(t, int64(small.InMemorySize()), db.MemoryUsage())
+	assert.Empty(t, db.OverBudget())
+
+	large := newTable("large")
+	assert.NoError(t, large.InsertRow("1", map[string]any{"id": 1, "payload": strings.Repeat("a", 200)}, false))
+	large.SyncMemoryUsage()
+	assert.Equal(t, int64(small.InMemorySize()+large.InMemorySize()), db.MemoryUsage())
+	assert.Len(t, db.OverBudget(), 1)
+
+	{
+		// Syncing again without any changes is a no-op
+		large.SyncMemoryUsage()
+		assert.Equal(t, int64(small.InMemorySize()+large.InMemorySize()), db.MemoryUsage())
+	}
+	{
+		// Largest table first
+		tables := db.TablesByMemoryUsage()
+		assert.Len(t, tables, 2)
+		assert.Equal(t, "large", tables[0].GetTableID().Table)
+		assert.Equal(t, "small", tables[1].GetTableID().Table)
+	}
+	{
+		// Wiping should release the memory
+		db.ClearTableConfig(large.GetTableID())
+		assert.Equal(t, int64(0), large.MemoryUsage())
+		assert.Equal(t, int64(small.InMemorySize()), db.MemoryUsage())
+	}
+}
@@ -0,0 +1,298 @@
+package admin
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/lib/webhooks"
+	"github.com/artie-labs/transfer/models"
+	"github.com/artie-labs/transfer/processes/consumer"
+)
+
+// Server serves the liveness, readiness and admin endpoints.
+// It is started before the destination and Kafka consumers are loaded so that liveness can be served during startup.
+type Server struct {
+	inMemDB               *models.DatabaseData
+	metricsClient         base.Client
+	whClient              *webhooks.Client
+	topics                []string
+	reportDBExecutionTime bool
+	bearerToken           string
+
+	mu sync.RWMutex
+	// consumerCtx has the queue consumers injected, it is nil until the consumers are created.
+	consumerCtx context.Context
+	dest        destination.Destination
+}
+
+func NewServer(cfg config.Config, inMemDB *models.DatabaseData, metricsClient base.Client, whClient *webhooks.Client) *Server {
+	server := &Server{
+		inMemDB:               inMemDB,
+		metricsClient:         metricsClient,
+		whClient:              whClient,
+		topics:                cfg.Topics(),
+		reportDBExecutionTime: cfg.Reporting.EmitDBExecutionTime,
+	}
+
+	if cfg.AdminServer != nil {
+		server.bearerToken = cfg.AdminServer.BearerToken
+	}
+
+	return server
+}
+
+func (s *Server) SetDestination(dest destination.Destination) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dest = dest
+}
+
+func (s *Server) SetConsumerContext(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consumerCtx = ctx
+}
+
+func (s *Server) state() (context.Context, destination.Destination) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.consumerCtx, s.dest
+}
+
+// currentTopics returns the topics that are being consumed, these can change when the config is reloaded.
+func (s *Server) currentTopics(consumerCtx context.Context) []string {
+	if consumerCtx != nil {
+		if topics, ok := kafkalib.GetTopicsFromContext(consumerCtx); ok {
+			return topics
+		}
+	}
+
+	return s.topics
+}
+
+// Start serves [Handler] on [addr] in the background until [ctx] is done.
+func (s *Server) Start(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %q: %w", addr, err)
+	}
+
+	server := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Admin server stopped", slog.Any("err", err))
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("Failed to shut down admin server", slog.Any("err", err))
+		}
+	}()
+
+	slog.Info("Serving admin server", slog.String("addr", listener.Addr().String()))
+	if s.bearerToken == "" {
+		if tcpAddr, ok := listener.Addr().(*net.TCPAddr); ok && !tcpAddr.IP.IsLoopback() {
+			slog.Warn("Admin server is reachable from other hosts without a bearer token, set adminServer.bearerToken to protect the mutating endpoints")
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleLiveness)
+	mux.HandleFunc("GET /readyz", s.handleReadiness)
+	mux.HandleFunc("GET /tables", s.handleTables)
+	mux.HandleFunc("POST /flush", s.requireBearerToken(s.handleFlush))
+	return mux
+}
+
+// requireBearerToken rejects requests that don't carry the configured bearer token, it's a no-op if no token is configured.
+func (s *Server) requireBearerToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.bearerToken != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.bearerToken)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+				return
+			}
+		}
+
+		next(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Warn("Failed to write admin response", slog.Any("err", err))
+	}
+}
+
+func writeError(w http.ResponseWriter, statusCode int, err error) {
+	writeJSON(w, statusCode, map[string]string{"error": err.Error()})
+}
+
+func (s *Server) handleLiveness(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+type TopicReadiness struct {
+	// JoinedGroup is true once the consumer has received its first partition assignment.
+	JoinedGroup bool    `json:"joinedGroup"`
+	Partitions  []int32 `json:"partitions"`
+}
+
+type Readiness struct {
+	Ready             bool                      `json:"ready"`
+	DestinationLoaded bool                      `json:"destinationLoaded"`
+	Topics            map[string]TopicReadiness `json:"topics"`
+}
+
+// Readiness returns whether the destination has been loaded and every topic's consumer has joined the consumer group.
+// A consumer with an empty assignment (more consumers than partitions) is still considered ready.
+func (s *Server) Readiness() Readiness {
+	consumerCtx, dest := s.state()
+	readiness := Readiness{
+		DestinationLoaded: dest != nil,
+		Topics:            make(map[string]TopicReadiness),
+	}
+
+	ready := readiness.DestinationLoaded
+	for _, topic := range s.currentTopics(consumerCtx) {
+		var topicReadiness TopicReadiness
+		if consumerCtx != nil {
+			if provider, err := kafkalib.GetConsumerFromContext(consumerCtx, topic); err == nil {
+				topicReadiness.Partitions, topicReadiness.JoinedGroup = provider.AssignedPartitions()
+			}
+		}
+
+		readiness.Topics[topic] = topicReadiness
+		ready = ready && topicReadiness.JoinedGroup
+	}
+
+	readiness.Ready = ready
+	return readiness
+}
+
+func (s *Server) handleReadiness(w http.ResponseWriter, _ *http.Request) {
+	readiness := s.Readiness()
+	statusCode := http.StatusOK
+	if !readiness.Ready {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, statusCode, readiness)
+}
+
+type TableStatus struct {
+	Topic                        string     `json:"topic"`
+	Table                        string     `json:"table"`
+	Rows                         uint       `json:"rows"`
+	ApproxSizeBytes              int        `json:"approxSizeBytes"`
+	LastFlushTime                *time.Time `json:"lastFlushTime,omitempty"`
+	PendingMultiStepMergeFlushes int        `json:"pendingMultiStepMergeFlushes"`
+}
+
+// Tables returns the state of every table that is buffered in memory.
+// This reads the snapshot from [models.TableData.Stats] instead of taking the consumer locks, so it doesn't wait for in-flight flushes.
+func (s *Server) Tables() []TableStatus {
+	consumerCtx, _ := s.state()
+	if consumerCtx == nil {
+		return []TableStatus{}
+	}
+
+	out := []TableStatus{}
+	for _, topic := range s.currentTopics(consumerCtx) {
+		for _, table := range s.inMemDB.GetTables(topic) {
+			stats := table.Stats()
+			status := TableStatus{
+				Topic:                        topic,
+				Table:                        table.GetTableID().String(),
+				Rows:                         stats.Rows,
+				ApproxSizeBytes:              stats.ApproxSize,
+				PendingMultiStepMergeFlushes: stats.PendingMultiStepMergeFlushes,
+			}
+
+			if !stats.LastFlushTime.IsZero() {
+				status.LastFlushTime = &stats.LastFlushTime
+			}
+
+			out = append(out, status)
+		}
+	}
+
+	slices.SortFunc(out, func(a, b TableStatus) int {
+		return cmp.Or(cmp.Compare(a.Topic, b.Topic), cmp.Compare(a.Table, b.Table))
+	})
+	return out
+}
+
+func (s *Server) handleTables(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.Tables())
+}
+
+// Flush flushes [topics], or every topic if [topics] is empty.
+func (s *Server) Flush(topics []string) error {
+	consumerCtx, dest := s.state()
+	if consumerCtx == nil || dest == nil {
+		return fmt.Errorf("transfer has not finished starting up")
+	}
+
+	if len(topics) == 0 {
+		topics = s.currentTopics(consumerCtx)
+	}
+
+	var errs []error
+	for _, topic := range topics {
+		slog.Info("Flushing via admin server", slog.String("topic", topic))
+		args := consumer.Args{Reason: "admin", ReportDBExecutionTime: s.reportDBExecutionTime}
+		if err := consumer.FlushSingleTopic(consumerCtx, s.inMemDB, dest, s.metricsClient, s.whClient, args, topic, true); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush topic %q: %w", topic, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Server) handleFlush(w http.ResponseWriter, r *http.Request) {
+	var topics []string
+	if topic := r.URL.Query().Get("topic"); topic != "" {
+		consumerCtx, _ := s.state()
+		if !slices.Contains(s.currentTopics(consumerCtx), topic) {
+			writeError(w, http.StatusNotFound, fmt.Errorf("topic %q is not configured", topic))
+			return
+		}
+
+		topics = []string{topic}
+	}
+
+	if err := s.Flush(topics); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "flushed"})
+}
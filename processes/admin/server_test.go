@@ -0,0 +1,181 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/models"
+)
+
+const topic = "orders"
+
+func newServer() (*Server, *models.DatabaseData) {
+	inMemDB := models.NewMemoryDB()
+	cfg := config.Config{
+		Mode: config.Replication,
+		Kafka: &kafkalib.Kafka{
+			TopicConfigs: []*kafkalib.TopicConfig{{Database: "shop", Schema: "public", Topic: topic, CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt}},
+		},
+	}
+
+	return NewServer(cfg, inMemDB, metrics.NullMetricsProvider{}, nil), inMemDB
+}
+
+func do(t *testing.T, server *Server, method, path string, out any) int {
+	req := httptest.NewRequest(method, path, nil)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if out != nil {
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), out))
+	}
+	return rec.Code
+}
+
+func TestServer_Liveness(t *testing.T) {
+	server, _ := newServer()
+	assert.Equal(t, http.StatusOK, do(t, server, http.MethodGet, "/healthz", nil))
+}
+
+func TestServer_Readiness(t *testing.T) {
+	server, _ := newServer()
+	{
+		// Nothing has been loaded
+		var readiness Readiness
+		assert.Equal(t, http.StatusServiceUnavailable, do(t, server, http.MethodGet, "/readyz", &readiness))
+		assert.False(t, readiness.Ready)
+		assert.False(t, readiness.DestinationLoaded)
+		assert.False(t, readiness.Topics[topic].JoinedGroup)
+	}
+	{
+		// Destination is loaded, but the consumer has not joined the group
+		server.SetDestination(&mocks.FakeDestination{})
+		server.SetConsumerContext(context.WithValue(t.Context(), kafkalib.BuildContextKey(topic), kafkalib.NewConsumerProviderForTest(&mocks.FakeConsumer{}, topic, "group")))
+
+		var readiness Readiness
+		assert.Equal(t, http.StatusServiceUnavailable, do(t, server, http.MethodGet, "/readyz", &readiness))
+		assert.True(t, readiness.DestinationLoaded)
+		assert.False(t, readiness.Topics[topic].JoinedGroup)
+	}
+}
+
+func TestServer_Tables(t *testing.T) {
+	server, inMemDB := newServer()
+	{
+		// Consumers have not been created yet
+		var tables []TableStatus
+		assert.Equal(t, http.StatusOK, do(t, server, http.MethodGet, "/tables", &tables))
+		assert.Empty(t, tables)
+	}
+
+	server.SetConsumerContext(context.WithValue(t.Context(), kafkalib.BuildContextKey(topic), kafkalib.NewConsumerProviderForTest(&mocks.FakeConsumer{}, topic, "group")))
+	tableID := cdc.NewTableID("public", "orders")
+	td := inMemDB.GetOrCreateTableData(tableID, topic)
+	td.SetTableData(optimization.NewTableData(columns.NewColumns(nil), config.Replication, []string{"id"}, kafkalib.TopicConfig{Topic: topic}, tableID.Table))
+	td.InsertRow("1", map[string]any{"id": 1, "name": "dusty"}, false)
+	td.SyncMemoryUsage()
+	inMemDB.GetOrCreateTableData(cdc.NewTableID("public", "customers"), topic)
+
+	var tables []TableStatus
+	assert.Equal(t, http.StatusOK, do(t, server, http.MethodGet, "/tables", &tables))
+	assert.Len(t, tables, 2)
+	assert.Equal(t, TableStatus{Topic: topic, Table: "public.customers"}, tables[0])
+	assert.Equal(t, "public.orders", tables[1].Table)
+	assert.Equal(t, uint(1), tables[1].Rows)
+	assert.Positive(t, tables[1].ApproxSizeBytes)
+	assert.Nil(t, tables[1].LastFlushTime)
+	{
+		// The consumer lock is not taken, so this doesn't wait for an in-flight flush
+		consumerCtx, _ := server.state()
+		provider, err := kafkalib.GetConsumerFromContext(consumerCtx, topic)
+		assert.NoError(t, err)
+		assert.NoError(t, provider.LockAndProcess(t.Context(), true, func() error {
+			assert.Len(t, server.Tables(), 2)
+			return nil
+		}))
+	}
+	{
+		// Wiping the table records the flush time
+		inMemDB.ClearTableConfig(tableID)
+		assert.Equal(t, http.StatusOK, do(t, server, http.MethodGet, "/tables", &tables))
+		assert.Equal(t, uint(0), tables[1].Rows)
+		assert.NotNil(t, tables[1].LastFlushTime)
+	}
+}
+
+func TestServer_Flush(t *testing.T) {
+	server, inMemDB := newServer()
+	{
+		// Unknown topic
+		assert.Equal(t, http.StatusNotFound, do(t, server, http.MethodPost, "/flush?topic=foo", nil))
+	}
+	{
+		// Not started yet
+		var resp map[string]string
+		assert.Equal(t, http.StatusInternalServerError, do(t, server, http.MethodPost, "/flush", &resp))
+		assert.Equal(t, "transfer has not finished starting up", resp["error"])
+	}
+	{
+		// Flush
+		fakeDest := &mocks.FakeDestination{}
+		fakeDest.MergeReturns(true, nil)
+		fakeConsumer := &mocks.FakeConsumer{}
+		server.SetDestination(fakeDest)
+		server.SetConsumerContext(context.WithValue(t.Context(), kafkalib.BuildContextKey(topic), kafkalib.NewConsumerProviderForTest(fakeConsumer, topic, "group")))
+
+		tableID := cdc.NewTableID("public", "orders")
+		td := inMemDB.GetOrCreateTableData(tableID, topic)
+		td.SetTableData(optimization.NewTableData(columns.NewColumns(nil), config.Replication, []string{"id"}, kafkalib.TopicConfig{Topic: topic}, tableID.Table))
+		td.InsertRow("1", map[string]any{"id": 1}, false)
+
+		assert.Equal(t, http.StatusOK, do(t, server, http.MethodPost, "/flush?topic="+topic, nil))
+		assert.Equal(t, 1, fakeDest.MergeCallCount())
+		assert.Equal(t, 1, fakeConsumer.CommitMessagesCallCount())
+		assert.True(t, td.Empty())
+	}
+}
+
+func TestServer_Flush_BearerToken(t *testing.T) {
+	server, _ := newServer()
+	server.bearerToken = "secret"
+
+	flush := func(authorization string) int {
+		req := httptest.NewRequest(http.MethodPost, "/flush", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	{
+		// Missing token
+		assert.Equal(t, http.StatusUnauthorized, flush(""))
+	}
+	{
+		// Wrong token
+		assert.Equal(t, http.StatusUnauthorized, flush("Bearer nope"))
+		assert.Equal(t, http.StatusUnauthorized, flush("secret"))
+	}
+	{
+		// Valid token, the request makes it to the handler
+		assert.Equal(t, http.StatusInternalServerError, flush("Bearer secret"))
+	}
+	{
+		// Read-only endpoints don't require the token
+		assert.Equal(t, http.StatusOK, do(t, server, http.MethodGet, "/healthz", nil))
+	}
+}
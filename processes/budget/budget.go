@@ -0,0 +1,121 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/lib/webhooks"
+	"github.com/artie-labs/transfer/models"
+	"github.com/artie-labs/transfer/processes/consumer"
+)
+
+const checkInterval = 10 * time.Second
+
+type Enforcer struct {
+	inMemDB               *models.DatabaseData
+	dest                  destination.Destination
+	metricsClient         base.Client
+	whClient              *webhooks.Client
+	topics                []string
+	settings              config.MemoryBudgetSettings
+	reportDBExecutionTime bool
+}
+
+// NewEnforcer sets the memory budget on [inMemDB], this should be called before any messages are consumed.
+func NewEnforcer(inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client, whClient *webhooks.Client, topics []string, cfg config.Config) *Enforcer {
+	settings := *cfg.MemoryBudget
+	inMemDB.SetMemoryBudget(settings.LimitBytes())
+	return &Enforcer{
+		inMemDB:               inMemDB,
+		dest:                  dest,
+		metricsClient:         metricsClient,
+		whClient:              whClient,
+		topics:                topics,
+		settings:              settings,
+		reportDBExecutionTime: cfg.Reporting.EmitDBExecutionTime,
+	}
+}
+
+// Start blocks until [ctx] is done. The budget is enforced as soon as it has been exceeded, and is also re-checked periodically in case a previous flush failed.
+func (e *Enforcer) Start(ctx context.Context) {
+	slog.Info("Starting memory budget enforcer...", slog.Int("limitMb", e.settings.LimitMb))
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.inMemDB.OverBudget():
+		}
+
+		if err := e.Enforce(ctx); err != nil {
+			slog.Error("Failed to enforce memory budget", slog.Any("err", err))
+		}
+	}
+}
+
+func (e *Enforcer) emitUsage(usage int64) {
+	e.metricsClient.Gauge("memory_budget.usage_bytes", float64(usage), nil)
+	e.metricsClient.Gauge("memory_budget.usage_ratio", float64(usage)/float64(e.settings.LimitBytes()), nil)
+}
+
+// Enforce pauses fetching for every topic and flushes the topics with the largest tables first until the usage drops below the resume threshold.
+// Fetching is resumed once this returns, even if a flush failed.
+func (e *Enforcer) Enforce(ctx context.Context) error {
+	usage := e.inMemDB.MemoryUsage()
+	e.emitUsage(usage)
+	if usage <= e.settings.LimitBytes() {
+		// The regular flush triggers may have already brought us back under budget.
+		return nil
+	}
+
+	slog.Warn("Memory budget exceeded, pausing fetching and flushing the largest tables",
+		slog.Int64("usageBytes", usage),
+		slog.Int64("limitBytes", e.settings.LimitBytes()),
+	)
+	e.metricsClient.Incr("memory_budget.exceeded", nil)
+
+	topics := e.topics
+	if currentTopics, ok := kafkalib.GetTopicsFromContext(ctx); ok {
+		topics = currentTopics
+	}
+
+	for _, topic := range topics {
+		provider, err := kafkalib.GetConsumerFromContext(ctx, topic)
+		if err != nil {
+			return fmt.Errorf("failed to get consumer from context: %w", err)
+		}
+
+		provider.PauseFetching()
+		defer provider.ResumeFetching()
+	}
+
+	flushedTopics := make(map[string]bool)
+	for _, table := range e.inMemDB.TablesByMemoryUsage() {
+		if e.inMemDB.MemoryUsage() <= e.settings.ResumeBytes() {
+			break
+		}
+
+		if flushedTopics[table.Topic()] || table.MemoryUsage() == 0 {
+			continue
+		}
+
+		flushedTopics[table.Topic()] = true
+		slog.Info("Flushing topic to free up memory", slog.String("topic", table.Topic()), slog.String("tableID", table.GetTableID().String()), slog.Int64("tableUsageBytes", table.MemoryUsage()))
+		args := consumer.Args{Reason: "memory_budget", ReportDBExecutionTime: e.reportDBExecutionTime}
+		if err := consumer.FlushSingleTopic(ctx, e.inMemDB, e.dest, e.metricsClient, e.whClient, args, table.Topic(), true); err != nil {
+			return fmt.Errorf("failed to flush topic %q: %w", table.Topic(), err)
+		}
+	}
+
+	e.emitUsage(e.inMemDB.MemoryUsage())
+	return nil
+}
@@ -0,0 +1,61 @@
+package budget
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/models"
+)
+
+func insertRows(t *testing.T, inMemDB *models.DatabaseData, topic string, rows int, payloadSize int) *models.TableData {
+	tableID := cdc.NewTableID("public", topic)
+	td := inMemDB.GetOrCreateTableData(tableID, topic)
+	td.SetTableData(optimization.NewTableData(columns.NewColumns(nil), config.Replication, []string{"id"}, kafkalib.TopicConfig{Topic: topic}, tableID.Table))
+	for i := range rows {
+		assert.NoError(t, td.InsertRow(strings.Repeat("k", i+1), map[string]any{"id": i, "payload": strings.Repeat("a", payloadSize)}, false))
+	}
+	td.SyncMemoryUsage()
+	return td
+}
+
+func TestEnforcer_Enforce(t *testing.T) {
+	inMemDB := models.NewMemoryDB()
+	fakeDest := &mocks.FakeDestination{}
+	fakeDest.MergeReturns(true, nil)
+
+	cfg := config.Config{MemoryBudget: &config.MemoryBudgetSettings{LimitMb: 1}}
+	enforcer := NewEnforcer(inMemDB, fakeDest, metrics.NullMetricsProvider{}, nil, []string{"large", "small"}, cfg)
+	assert.Equal(t, int64(1024*1024), inMemDB.MemoryBudget())
+
+	ctx := context.WithValue(t.Context(), kafkalib.BuildContextKey("large"), kafkalib.NewConsumerProviderForTest(&mocks.FakeConsumer{}, "large", "group"))
+	ctx = context.WithValue(ctx, kafkalib.BuildContextKey("small"), kafkalib.NewConsumerProviderForTest(&mocks.FakeConsumer{}, "small", "group"))
+	{
+		// Under budget, nothing should be flushed
+		small := insertRows(t, inMemDB, "small", 10, 1024)
+		assert.NoError(t, enforcer.Enforce(ctx))
+		assert.Equal(t, 0, fakeDest.MergeCallCount())
+		assert.False(t, small.Empty())
+	}
+	{
+		// Over budget, only the largest table needs to be flushed to get back under the resume threshold
+		large := insertRows(t, inMemDB, "large", 100, 20*1024)
+		assert.Greater(t, inMemDB.MemoryUsage(), inMemDB.MemoryBudget())
+		assert.Len(t, inMemDB.OverBudget(), 1)
+
+		assert.NoError(t, enforcer.Enforce(ctx))
+		assert.Equal(t, 1, fakeDest.MergeCallCount())
+		assert.True(t, large.Empty())
+		assert.False(t, inMemDB.GetOrCreateTableData(cdc.NewTableID("public", "small"), "small").Empty())
+		assert.Less(t, inMemDB.MemoryUsage(), cfg.MemoryBudget.ResumeBytes())
+	}
+}
@@ -0,0 +1,49 @@
+package consumer
+
+import (
+	"context"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/models"
+)
+
+const bufferMetricsInterval = 10 * time.Second
+
+// StartBufferMetrics blocks until [ctx] is done and periodically emits how much of each table is buffered in memory and on disk.
+func StartBufferMetrics(ctx context.Context, inMemDB *models.DatabaseData, metricsClient base.Client) {
+	ticker := time.NewTicker(bufferMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			EmitBufferMetrics(inMemDB, metricsClient)
+		}
+	}
+}
+
+// EmitBufferMetrics reads each table's [models.TableStats], so it does not need to hold the topic's lock.
+// Tables that have been flushed are still emitted so that their gauges drop back to zero.
+func EmitBufferMetrics(inMemDB *models.DatabaseData, metricsClient base.Client) {
+	for _, table := range inMemDB.TablesByMemoryUsage() {
+		stats := table.Stats()
+		if stats.Mode == "" {
+			// The table has never had any rows.
+			continue
+		}
+
+		tags := map[string]string{
+			"mode":     stats.Mode.String(),
+			"table":    table.GetTableID().Table,
+			"database": stats.Database,
+			"schema":   stats.Schema,
+		}
+
+		metricsClient.Gauge("buffer.memory_bytes", float64(stats.InMemorySize), tags)
+		metricsClient.Gauge("buffer.spilled_bytes", float64(stats.SpilledBytes), tags)
+		metricsClient.Gauge("buffer.spilled_rows", float64(stats.SpilledRows), tags)
+	}
+}
@@ -0,0 +1,65 @@
+package consumer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/models"
+)
+
+type gauge struct {
+	name  string
+	value float64
+	tags  map[string]string
+}
+
+type gaugeRecorder struct {
+	metrics.NullMetricsProvider
+	gauges []gauge
+}
+
+func (g *gaugeRecorder) Gauge(name string, value float64, tags map[string]string) {
+	g.gauges = append(g.gauges, gauge{name: name, value: value, tags: tags})
+}
+
+func TestEmitBufferMetrics(t *testing.T) {
+	inMemDB := models.NewMemoryDB()
+	tableID := cdc.NewTableID("public", "orders")
+	td := inMemDB.GetOrCreateTableData(tableID, "orders")
+	inMemDB.GetOrCreateTableData(cdc.NewTableID("public", "customers"), "customers")
+	tags := map[string]string{"mode": "replication", "table": "orders", "database": "db", "schema": "public"}
+	{
+		// Only tables that have had rows are emitted, without a flush reason
+		td.SetTableData(optimization.NewTableData(columns.NewColumns(nil), config.Replication, []string{"id"}, kafkalib.TopicConfig{Database: "db", Schema: "public"}, tableID.Table))
+		assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1}, false))
+		td.SyncMemoryUsage()
+
+		recorder := &gaugeRecorder{}
+		EmitBufferMetrics(inMemDB, recorder)
+		assert.Len(t, recorder.gauges, 3)
+		assert.Equal(t, "buffer.memory_bytes", recorder.gauges[0].name)
+		assert.Positive(t, recorder.gauges[0].value)
+		assert.Equal(t, tags, recorder.gauges[0].tags)
+		assert.Equal(t, gauge{name: "buffer.spilled_bytes", tags: tags}, recorder.gauges[1])
+		assert.Equal(t, gauge{name: "buffer.spilled_rows", tags: tags}, recorder.gauges[2])
+	}
+	{
+		// Flushed tables drop back to zero
+		inMemDB.ClearTableConfig(tableID)
+
+		recorder := &gaugeRecorder{}
+		EmitBufferMetrics(inMemDB, recorder)
+		assert.Equal(t, []gauge{
+			{name: "buffer.memory_bytes", tags: tags},
+			{name: "buffer.spilled_bytes", tags: tags},
+			{name: "buffer.spilled_rows", tags: tags},
+		}, recorder.gauges)
+	}
+}
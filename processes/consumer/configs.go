@@ -1,6 +1,7 @@
 package consumer
 
 import (
+	"context"
 	"strings"
 	"sync"
 
@@ -25,6 +26,12 @@ func (t *TcFmtMap) Add(topic string, fmt TopicConfigFormatter) {
 	t.tc[topic] = fmt
 }
 
+func (t *TcFmtMap) Remove(topic string) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.tc, topic)
+}
+
 func (t *TcFmtMap) GetTopicFmt(topic string) (TopicConfigFormatter, bool) {
 	t.RLock()
 	defer t.RUnlock()
@@ -50,12 +57,12 @@ func (t TopicConfigFormatter) ShouldSkip(op string) bool {
 // buildPKMap returns the primary key map for an event. When the Kafka message key is empty and primary keys are
 // specified via [TopicConfig.PrimaryKeysOverride] or [TopicConfig.IncludePrimaryKeys], key parsing is skipped
 // and an empty map is returned -- the PK column names come from config and values come from the event payload.
-func (t TopicConfigFormatter) buildPKMap(key []byte, reservedColumns map[string]bool) (map[string]any, error) {
+func (t TopicConfigFormatter) buildPKMap(ctx context.Context, key []byte, reservedColumns map[string]bool) (map[string]any, error) {
 	if len(key) == 0 && (len(t.tc.PrimaryKeysOverride) > 0 || len(t.tc.IncludePrimaryKeys) > 0) {
 		return map[string]any{}, nil
 	}
 
-	return t.GetPrimaryKey(key, t.tc, reservedColumns)
+	return t.GetPrimaryKey(ctx, key, t.tc, reservedColumns)
 }
 
 func NewTopicConfigFormatter(tc kafkalib.TopicConfig, format cdc.Format) TopicConfigFormatter {
@@ -0,0 +1,92 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/artie-labs/transfer/lib"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/filelib"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/lib/webhooks"
+	"github.com/artie-labs/transfer/models"
+)
+
+// replayPollInterval is how often [WaitForReplay] checks whether every record has been processed.
+const replayPollInterval = time.Second
+
+// NewFileConsumer replays records from files, the consumers must already be injected into the context with [filelib.InjectConsumersIntoContext].
+func NewFileConsumer(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client, whClient *webhooks.Client, cache *lib.KVCache[string]) (*Consumer, error) {
+	newProvider := func(_ context.Context, topicConfig kafkalib.TopicConfig) (kafkalib.QueueConsumer, error) {
+		consumer, err := filelib.NewConsumer(cfg.File, topicConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return consumer, nil
+	}
+
+	return newConsumer(ctx, cfg, newProvider, false, inMemDB, dest, metricsClient, whClient, cache)
+}
+
+// WaitForReplay blocks until every file consumer in [ctx] has processed all of its records.
+// The rows that are still buffered are then flushed, so that every offset is committed before returning.
+func WaitForReplay(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client, whClient *webhooks.Client) error {
+	registry, ok := kafkalib.GetConsumerRegistryFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("consumer registry not found in context")
+	}
+
+	ticker := time.NewTicker(replayPollInterval)
+	defer ticker.Stop()
+	for !replayed(registry) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	for _, topic := range registry.Topics() {
+		if err := FlushSingleTopic(ctx, inMemDB, dest, metricsClient, whClient, Args{Reason: "replay", ReportDBExecutionTime: cfg.Reporting.EmitDBExecutionTime}, topic, true); err != nil {
+			return fmt.Errorf("failed to flush topic %q: %w", topic, err)
+		}
+
+		for _, table := range inMemDB.GetTables(topic) {
+			if !table.Empty() {
+				return fmt.Errorf("table %q still has rows buffered after flushing", table.GetTableID().String())
+			}
+		}
+
+		// Tombstones and skipped operations are not buffered, so the records after the last buffered row may not have been committed yet.
+		queueConsumer, ok := registry.Get(topic)
+		if !ok {
+			return fmt.Errorf("consumer not found for topic %q", topic)
+		}
+
+		if err := queueConsumer.LockAndProcess(ctx, true, func() error { return queueConsumer.CommitMessage(ctx) }); err != nil {
+			return fmt.Errorf("failed to commit topic %q: %w", topic, err)
+		}
+	}
+
+	return nil
+}
+
+func replayed(registry *kafkalib.ConsumerRegistry) bool {
+	for _, topic := range registry.Topics() {
+		queueConsumer, ok := registry.Get(topic)
+		if !ok {
+			return false
+		}
+
+		fileConsumer, ok := queueConsumer.(*filelib.Consumer)
+		if !ok || !fileConsumer.Exhausted() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,122 @@
+package consumer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/filelib"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/lib/webhooks"
+	"github.com/artie-labs/transfer/models"
+)
+
+func TestFileConsumer_Replay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.ndjson")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"topic": "orders", "partition": 0, "offset": 0, "key": {"id": 1}, "value": {"payload": {"before": null, "after": {"id": 1, "name": "foo"}, "source": {"schema": "public", "table": "orders", "ts_ms": 1700000000000}, "op": "c"}}}
+{"topic": "orders", "partition": 0, "offset": 1, "key": {"id": 2}, "value": {"payload": {"before": null, "after": {"id": 2, "name": "bar"}, "source": {"schema": "public", "table": "orders", "ts_ms": 1700000000000}, "op": "c"}}}
+{"topic": "orders", "partition": 0, "offset": 2, "key": {"id": 2}, "value": null}
+`), 0o644))
+
+	orders := &kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders", CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt}
+	cfg := config.Config{
+		Mode:                 config.Replication,
+		Queue:                constants.File,
+		File:                 &filelib.File{Paths: []string{path}, TopicConfigs: []*kafkalib.TopicConfig{orders}},
+		BufferRows:           10,
+		FlushIntervalSeconds: 60,
+		FlushSizeKb:          500,
+	}
+	assert.Equal(t, []string{"orders"}, cfg.Topics())
+
+	inMemDB := models.NewMemoryDB()
+	fakeDest := &mocks.FakeDestination{}
+	fakeDest.MergeReturns(true, nil)
+
+	ctx, err := filelib.InjectConsumersIntoContext(t.Context(), cfg.File)
+	assert.NoError(t, err)
+
+	fileConsumer, err := NewFileConsumer(ctx, cfg, inMemDB, fakeDest, metrics.NullMetricsProvider{}, nil, lib.NewKVCache[string]())
+	assert.NoError(t, err)
+	assert.NoError(t, fileConsumer.startTopic(ctx, *orders))
+	t.Cleanup(func() { fileConsumer.stop(fileConsumer.topics[orders.Topic]) })
+
+	assert.NoError(t, WaitForReplay(ctx, cfg, inMemDB, fakeDest, metrics.NullMetricsProvider{}, nil))
+	assert.Equal(t, 1, fakeDest.MergeCallCount())
+	_, tableData, _ := fakeDest.MergeArgsForCall(0)
+	assert.Equal(t, uint(2), tableData.NumberOfRows())
+
+	// The tombstone is committed as well, even though it was skipped
+	queueConsumer, err := kafkalib.GetConsumerFromContext(ctx, orders.Topic)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int64{0: 3}, queueConsumer.(*filelib.Consumer).CommittedOffsets())
+	assert.True(t, inMemDB.GetTables(orders.Topic)[0].Empty())
+}
+
+func TestFileConsumer_ReplayWithRowFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.ndjson")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"topic": "orders", "partition": 0, "offset": 0, "key": {"id": 1}, "value": {"payload": {"before": null, "after": {"id": 1, "tenant_id": "acme"}, "source": {"schema": "public", "table": "orders", "ts_ms": 1700000000000}, "op": "c"}}}
+{"topic": "orders", "partition": 0, "offset": 1, "key": {"id": 2}, "value": {"payload": {"before": null, "after": {"id": 2, "tenant_id": "test"}, "source": {"schema": "public", "table": "orders", "ts_ms": 1700000000000}, "op": "c"}}}
+{"topic": "orders", "partition": 0, "offset": 2, "key": {"id": 3}, "value": {"payload": {"before": null, "after": {"id": 3, "tenant_id": "test"}, "source": {"schema": "public", "table": "orders", "ts_ms": 1700000000000}, "op": "c"}}}
+`), 0o644))
+
+	var events []webhooks.WebhooksEvent
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var event webhooks.WebhooksEvent
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&event))
+		events = append(events, event)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	whClient, err := webhooks.NewClient(&config.WebhookSettings{Enabled: true, URL: server.URL, APIKey: "test-key"}, webhooks.Transfer, "v1.0.0")
+	assert.NoError(t, err)
+
+	orders := &kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders", CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt, RowFilter: "tenant_id != 'test'"}
+	cfg := config.Config{
+		Mode:                 config.Replication,
+		Queue:                constants.File,
+		File:                 &filelib.File{Paths: []string{path}, TopicConfigs: []*kafkalib.TopicConfig{orders}},
+		BufferRows:           10,
+		FlushIntervalSeconds: 60,
+		FlushSizeKb:          500,
+	}
+
+	inMemDB := models.NewMemoryDB()
+	fakeDest := &mocks.FakeDestination{}
+	fakeDest.MergeReturns(true, nil)
+
+	ctx, err := filelib.InjectConsumersIntoContext(t.Context(), cfg.File)
+	assert.NoError(t, err)
+
+	fileConsumer, err := NewFileConsumer(ctx, cfg, inMemDB, fakeDest, metrics.NullMetricsProvider{}, whClient, lib.NewKVCache[string]())
+	assert.NoError(t, err)
+	assert.NoError(t, fileConsumer.startTopic(ctx, *orders))
+	t.Cleanup(func() { fileConsumer.stop(fileConsumer.topics[orders.Topic]) })
+
+	assert.NoError(t, WaitForReplay(ctx, cfg, inMemDB, fakeDest, metrics.NullMetricsProvider{}, whClient))
+	assert.Equal(t, 1, fakeDest.MergeCallCount())
+	_, tableData, _ := fakeDest.MergeArgsForCall(0)
+	assert.Equal(t, uint(1), tableData.NumberOfRows())
+
+	// The filtered rows are reported once per flush
+	var skipped []webhooks.WebhooksEvent
+	for _, event := range events {
+		if event.Event == string(webhooks.EventRowSkipped) {
+			skipped = append(skipped, event)
+		}
+	}
+	assert.Len(t, skipped, 1)
+	assert.Equal(t, webhooks.EventProperties{Topic: "orders", Table: "orders", Schema: "public", RowsSkipped: 2, Reason: "row_filter"}, skipped[0].Properties.EventProperties)
+	assert.Zero(t, inMemDB.GetTables(orders.Topic)[0].ResetFilteredRows())
+}
@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/artie-labs/transfer/lib/config"
@@ -15,6 +16,7 @@ import (
 	"github.com/artie-labs/transfer/lib/logger"
 	"github.com/artie-labs/transfer/lib/retry"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/lib/telemetry/tracing"
 	"github.com/artie-labs/transfer/lib/webhooks"
 	"github.com/artie-labs/transfer/models"
 )
@@ -47,7 +49,7 @@ func Flush(ctx context.Context, inMemDB *models.DatabaseData, dest destination.D
 	return nil
 }
 
-func FlushSingleTopic(ctx context.Context, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client, whClient *webhooks.Client, args Args, topic string, shouldLock bool) error {
+func FlushSingleTopic(ctx context.Context, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client, whClient *webhooks.Client, args Args, topic string, shouldLock bool) (err error) {
 	if inMemDB == nil {
 		return nil
 	}
@@ -57,6 +59,11 @@ func FlushSingleTopic(ctx context.Context, inMemDB *models.DatabaseData, dest de
 		return nil
 	}
 
+	ctx, span := tracing.Start(ctx, "consumer.flush", attribute.String("topic", topic), attribute.String("reason", args.Reason))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	consumer, err := kafkalib.GetConsumerFromContext(ctx, topic)
 	if err != nil {
 		return fmt.Errorf("failed to get consumer from context: %w", err)
@@ -75,6 +82,11 @@ func FlushSingleTopic(ctx context.Context, inMemDB *models.DatabaseData, dest de
 			}
 		}
 
+		if args.CoolDown != nil && shouldDeferFlush(dest, tables) {
+			slog.Debug("Skipping flush because the destination is waiting for more rows", slog.String("topic", topic))
+			return nil
+		}
+
 		for _, table := range tables {
 			grp.Go(func() error {
 				// ErrGroup still requires recover handling for panics :(.
@@ -102,9 +114,10 @@ func FlushSingleTopic(ctx context.Context, inMemDB *models.DatabaseData, dest de
 					"reason":   args.Reason,
 				}
 
+				tableCtx, tableSpan := tracing.Start(ctx, "consumer.flush_table", attribute.String("table", table.GetTableID().String()), attribute.String("action", action))
 				result, err := retry.WithRetriesAndResult(retryCfg, func(_ int, _ error) (flushResult, error) {
 					slog.Info("Flushing table", slog.String("tableID", table.GetTableID().String()), slog.String("reason", args.Reason))
-					r, err := flush(ctx, dest, table, whClient)
+					r, err := flush(tableCtx, dest, table, whClient)
 					if args.ReportDBExecutionTime && args.GetExecutionTime() != nil {
 						r.Duration = time.Since(*args.GetExecutionTime())
 					} else {
@@ -112,6 +125,7 @@ func FlushSingleTopic(ctx context.Context, inMemDB *models.DatabaseData, dest de
 					}
 					return r, err
 				})
+				tracing.End(tableSpan, err)
 				if err != nil {
 					tags["what"] = result.What
 					metricsClient.Timing("flush", result.Duration, tags)
@@ -121,6 +135,8 @@ func FlushSingleTopic(ctx context.Context, inMemDB *models.DatabaseData, dest de
 				// It's okay that this will get overwritten by other tables
 				// This is because MSM is only supported for a single table / topic.
 				commitOffset.Store(result.CommitOffset)
+				// Multi-step merge will have wiped the table data without clearing the table.
+				table.SyncMemoryUsage()
 				tags["what"] = result.What
 				metricsClient.Timing("flush", result.Duration, tags)
 				return nil
@@ -135,8 +151,20 @@ func FlushSingleTopic(ctx context.Context, inMemDB *models.DatabaseData, dest de
 			return fmt.Errorf("failed to flush table: %w", err)
 		}
 
+		for _, table := range tables {
+			if filteredRows := table.ResetFilteredRows(); filteredRows > 0 {
+				whClient.SendEvent(ctx, webhooks.EventRowSkipped, webhooks.EventProperties{
+					Topic:       topic,
+					Table:       table.GetTableID().Table,
+					Schema:      table.GetTableID().Schema,
+					RowsSkipped: filteredRows,
+					Reason:      "row_filter",
+				})
+			}
+		}
+
 		if commitOffset.Load() {
-			if err := consumer.CommitMessage(ctx); err != nil {
+			if err := tracing.Run(ctx, "consumer.commit", consumer.CommitMessage, attribute.String("topic", topic)); err != nil {
 				return fmt.Errorf("failed to commit message: %w", err)
 			}
 
@@ -152,6 +180,23 @@ func FlushSingleTopic(ctx context.Context, inMemDB *models.DatabaseData, dest de
 	return err
 }
 
+// shouldDeferFlush returns true if [dest] would rather keep buffering every table in this topic.
+// If any of the tables are ready, the whole topic is flushed since offsets are committed per topic.
+func shouldDeferFlush(dest destination.Destination, tables []*models.TableData) bool {
+	deferrer, ok := dest.(destination.FlushDeferrer)
+	if !ok {
+		return false
+	}
+
+	for _, table := range tables {
+		if !table.Empty() && !deferrer.ShouldDeferFlush(table.TableData, table.LastFlushTime()) {
+			return false
+		}
+	}
+
+	return true
+}
+
 type flushResult struct {
 	What         string
 	CommitOffset bool
@@ -8,6 +8,7 @@ import (
 
 	"github.com/artie-labs/transfer/lib/cdc"
 	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/optimization"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics"
@@ -155,3 +156,40 @@ func (f *FlushTestSuite) TestFlushSingleTopic_MergeNoCommit() {
 	assert.Equal(f.T(), 0, f.fakeConsumer.CommitMessagesCallCount())
 	assert.False(f.T(), td.Empty())
 }
+
+type deferringDestination struct {
+	destination.Destination
+	deferFlush bool
+}
+
+func (d deferringDestination) ShouldDeferFlush(_ *optimization.TableData, _ time.Time) bool {
+	return d.deferFlush
+}
+
+func (f *FlushTestSuite) TestFlushSingleTopic_DeferFlush() {
+	topicName := "test-topic"
+	consumer := kafkalib.NewConsumerProviderForTest(f.fakeConsumer, topicName, "test-group")
+	ctx := context.WithValue(f.T().Context(), kafkalib.BuildContextKey(topicName), consumer)
+
+	tableID := cdc.NewTableID("public", "users")
+	td := f.db.GetOrCreateTableData(tableID, topicName)
+	td.SetTableData(optimization.NewTableData(columns.NewColumns(nil), config.Replication, []string{"id"}, topicConfig, tableID.Table))
+	td.InsertRow("1", map[string]any{"id": 1, "name": "Alice"}, false)
+
+	dest := deferringDestination{Destination: f.baseline, deferFlush: true}
+	f.fakeBaseline.MergeReturns(true, nil)
+	{
+		// Time-based flushes are held back
+		cooldown := 10 * time.Second
+		assert.NoError(f.T(), FlushSingleTopic(ctx, f.db, dest, metrics.NullMetricsProvider{}, nil, Args{CoolDown: &cooldown, Reason: "time"}, topicName, false))
+		assert.Equal(f.T(), 0, f.fakeBaseline.MergeCallCount())
+		assert.False(f.T(), td.Empty())
+	}
+	{
+		// Other flushes still go through
+		assert.NoError(f.T(), FlushSingleTopic(ctx, f.db, dest, metrics.NullMetricsProvider{}, nil, Args{Reason: "shutdown"}, topicName, false))
+		assert.Equal(f.T(), 1, f.fakeBaseline.MergeCallCount())
+		assert.Equal(f.T(), 1, f.fakeConsumer.CommitMessagesCallCount())
+		assert.True(f.T(), td.Empty())
+	}
+}
@@ -129,7 +129,9 @@ func (f *FlushTestSuite) TestMemoryConcurrency() {
 	// Verify all the tables exist.
 	for idx := range tableIDs {
 		td := f.db.GetOrCreateTableData(tableIDs[idx], topicConfig.Topic)
-		assert.Len(f.T(), td.Rows(), 5)
+		rows, err := td.Rows()
+		assert.NoError(f.T(), err)
+		assert.Len(f.T(), rows, 5)
 	}
 
 	f.fakeBaseline.MergeReturns(true, nil)
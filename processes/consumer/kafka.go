@@ -2,8 +2,12 @@ package consumer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
 	"sync"
 	"time"
 
@@ -14,107 +18,438 @@ import (
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/db"
 	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/dlq"
 	"github.com/artie-labs/transfer/lib/jitter"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/logger"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/webhooks"
 	"github.com/artie-labs/transfer/models"
 )
 
-func StartKafkaConsumer(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client, whClient *webhooks.Client, cache *lib.KVCache[string]) {
+type topicConsumer struct {
+	topicConfig kafkalib.TopicConfig
+	// dlqPolicy is only read and swapped while the topic's consumer lock is held.
+	dlqPolicy *dlq.Policy
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// newQueueConsumerFunc creates the consumer for a topic, this is called when topics are added while Transfer is running.
+type newQueueConsumerFunc func(ctx context.Context, topicConfig kafkalib.TopicConfig) (kafkalib.QueueConsumer, error)
+
+// Consumer runs a consumer for each topic. Topics can be added, removed or changed while it's running with [Consumer.Reload].
+type Consumer struct {
+	cfg            config.Config
+	newConsumer    newQueueConsumerFunc
+	waitForTopics  bool
+	inMemDB        *models.DatabaseData
+	dest           destination.Destination
+	metricsClient  base.Client
+	whClient       *webhooks.Client
+	cache          *lib.KVCache[string]
+	encryptionKey  []byte
+	schemaRegistry *schemaregistry.Client
+	tcFmtMap       *TcFmtMap
+
+	// mu is held for the entirety of [Start] and [Reload] so that they don't interleave.
+	mu     sync.Mutex
+	topics map[string]*topicConsumer
+	wg     sync.WaitGroup
+}
+
+func NewKafkaConsumer(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client, whClient *webhooks.Client, cache *lib.KVCache[string]) (*Consumer, error) {
+	newProvider := func(ctx context.Context, topicConfig kafkalib.TopicConfig) (kafkalib.QueueConsumer, error) {
+		provider, err := kafkalib.NewFranzGoConsumerProvider(ctx, cfg.Kafka, topicConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return provider, nil
+	}
+
+	return newConsumer(ctx, cfg, newProvider, cfg.Kafka.WaitForTopics, inMemDB, dest, metricsClient, whClient, cache)
+}
+
+func newConsumer(ctx context.Context, cfg config.Config, newQueueConsumer newQueueConsumerFunc, waitForTopics bool, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client, whClient *webhooks.Client, cache *lib.KVCache[string]) (*Consumer, error) {
 	encryptionKey, err := cfg.SharedDestinationSettings.BuildEncryptionKey(ctx)
 	if err != nil {
-		whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
-			Error: fmt.Sprintf("Failed to build encryption key: %s", err),
-		})
-		logger.Fatal("Failed to build encryption key", slog.Any("err", err))
+		return nil, fmt.Errorf("failed to build encryption key: %w", err)
 	}
 
-	tcFmtMap := NewTcFmtMap()
-	var topics []string
-	for _, topicConfig := range cfg.Kafka.TopicConfigs {
-		tcFmtMap.Add(topicConfig.Topic, NewTopicConfigFormatter(*topicConfig, format.GetFormatParser(topicConfig.CDCFormat, topicConfig.Topic)))
-		topics = append(topics, topicConfig.Topic)
+	var registry *schemaregistry.Client
+	if cfg.Kafka != nil && cfg.Kafka.SchemaRegistry != nil {
+		registry = schemaregistry.NewClient(cfg.Kafka.SchemaRegistry.URL, cfg.Kafka.SchemaRegistry.Username, cfg.Kafka.SchemaRegistry.Password)
 	}
 
-	var wg sync.WaitGroup
-	for num, topic := range topics {
-		// It is recommended to not try to establish a connection all at the same time, which may overwhelm the Kafka cluster.
+	return &Consumer{
+		cfg:            cfg,
+		newConsumer:    newQueueConsumer,
+		waitForTopics:  waitForTopics,
+		inMemDB:        inMemDB,
+		dest:           dest,
+		metricsClient:  metricsClient,
+		whClient:       whClient,
+		cache:          cache,
+		encryptionKey:  encryptionKey,
+		schemaRegistry: registry,
+		tcFmtMap:       NewTcFmtMap(),
+		topics:         make(map[string]*topicConsumer),
+	}, nil
+}
+
+// Start starts a consumer for every topic in the config and blocks until all the consumers have stopped.
+func (k *Consumer) Start(ctx context.Context) {
+	k.mu.Lock()
+	for num, topicConfig := range k.cfg.TopicConfigs() {
+		// It is recommended to not try to establish a connection all at the same time, which may overwhelm the cluster.
 		time.Sleep(jitter.Jitter(100, 3000, num))
-		wg.Add(1)
-		go func(topic string) {
-			defer wg.Done()
-			defer logger.RecoverFatal()
-			kafkaConsumer, err := kafkalib.GetConsumerFromContext(ctx, topic)
-			if err != nil {
-				whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
-					Error: fmt.Sprintf("Failed to start Kafka consumer: %s", err),
+		if err := k.startTopic(ctx, *topicConfig); err != nil {
+			k.whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+				Error: fmt.Sprintf("Failed to start consumer: %s", err),
+				Topic: topicConfig.Topic,
+			})
+			logger.Fatal("Failed to start consumer", slog.Any("err", err), slog.String("topic", topicConfig.Topic))
+		}
+	}
+	k.mu.Unlock()
+
+	<-ctx.Done()
+	// The lock is held while waiting so that a reload cannot start another consumer after this point.
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// Cancelling [ctx] stops fetching, wait for any messages that are being processed.
+	k.wg.Wait()
+	if k.cfg.GracefulShutdown != nil {
+		k.drain(ctx, k.cfg.GracefulShutdown.GetTimeout())
+	}
+
+	for topic, state := range k.topics {
+		if state.dlqPolicy != nil {
+			if err := state.dlqPolicy.Close(); err != nil {
+				slog.Warn("Failed to close dead-letter queue", slog.Any("err", err), slog.String("topic", topic))
+			}
+		}
+	}
+
+	// Closing the clients will leave the consumer group, so that the partitions are reassigned without waiting for the session timeout.
+	if registry, ok := kafkalib.GetConsumerRegistryFromContext(ctx); ok {
+		registry.CloseAll()
+	}
+}
+
+// drain flushes every topic and commits their offsets. This is bounded by [timeout] since the process will eventually be killed by the orchestrator.
+func (k *Consumer) drain(ctx context.Context, timeout time.Duration) {
+	drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	topics := slices.Sorted(maps.Keys(k.topics))
+	slog.Info("Draining buffered rows before shutting down", slog.Any("topics", topics), slog.Duration("timeout", timeout))
+	done := make(chan error, 1)
+	go func() {
+		var errs []error
+		for _, topic := range topics {
+			args := Args{Reason: "shutdown", ReportDBExecutionTime: k.cfg.Reporting.EmitDBExecutionTime}
+			if err := FlushSingleTopic(drainCtx, k.inMemDB, k.dest, k.metricsClient, k.whClient, args, topic, true); err != nil {
+				errs = append(errs, fmt.Errorf("failed to flush topic %q: %w", topic, err))
+			}
+		}
+		done <- errors.Join(errs...)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			slog.Error("Failed to drain, the remaining rows will be replayed after restarting", slog.Any("err", err))
+			return
+		}
+		slog.Info("Finished draining")
+	case <-drainCtx.Done():
+		slog.Warn("Timed out draining, the remaining rows will be replayed after restarting", slog.Duration("timeout", timeout))
+	}
+}
+
+// startTopic requires the topic's consumer to already exist in [ctx].
+func (k *Consumer) startTopic(ctx context.Context, topicConfig kafkalib.TopicConfig) error {
+	policy, err := dlq.LoadPolicy(ctx, k.cfg.Kafka, topicConfig.DeadLetterQueue)
+	if err != nil {
+		return fmt.Errorf("failed to load dead-letter queue: %w", err)
+	}
+
+	k.tcFmtMap.Add(topicConfig.Topic, NewTopicConfigFormatter(topicConfig, format.GetFormatParser(topicConfig, k.schemaRegistry)))
+	state := &topicConsumer{topicConfig: topicConfig, dlqPolicy: policy}
+	k.topics[topicConfig.Topic] = state
+	k.run(ctx, topicConfig.Topic, state)
+	return nil
+}
+
+// run consumes [topic] in the background until [stop] is called.
+func (k *Consumer) run(ctx context.Context, topic string, state *topicConsumer) {
+	// Only fetching is cancelled, so that a message that's being processed (and flushed) when we stop or shut down can still finish.
+	fetchCtx, cancel := context.WithCancel(ctx)
+	ctx = context.WithoutCancel(ctx)
+	state.cancel = cancel
+	state.done = make(chan struct{})
+
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+		defer close(state.done)
+		defer logger.RecoverFatal()
+		kafkaConsumer, err := kafkalib.GetConsumerFromContext(ctx, topic)
+		if err != nil {
+			k.whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+				Error: fmt.Sprintf("Failed to start consumer: %s", err),
+				Topic: topic,
+			})
+			logger.Fatal("Failed to get consumer from context", slog.Any("err", err))
+		}
+
+		if k.waitForTopics {
+			if err := kafkaConsumer.WaitForTopic(fetchCtx); err != nil {
+				if fetchCtx.Err() != nil {
+					return
+				}
+
+				k.whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+					Error: fmt.Sprintf("Failed waiting for topic to exist: %s", err),
 					Topic: topic,
 				})
-				logger.Fatal("Failed to get consumer from context", slog.Any("err", err))
+				logger.Fatal("Failed waiting for topic to exist", slog.Any("err", err), slog.String("topic", topic))
 			}
+		}
+
+		var fetchRetries int
+		for {
+			err = kafkaConsumer.FetchMessageAndProcess(fetchCtx, func(msg artie.Message) error {
+				if len(msg.Value()) == 0 {
+					slog.Debug("Found a tombstone message, skipping...", artie.BuildLogFields(msg)...)
+					return nil
+				}
+
+				args := processArgs{
+					Msg:                    msg,
+					GroupID:                kafkaConsumer.GetGroupID(),
+					TopicToConfigFormatMap: k.tcFmtMap,
+					WhClient:               k.whClient,
+					EncryptionKey:          k.encryptionKey,
+					Cache:                  k.cache,
+				}
+
+				tableID, err := args.process(ctx, k.cfg, k.inMemDB, k.dest, k.metricsClient)
+				if err != nil {
+					if policy := state.dlqPolicy; policy != nil && isPoisonMessageError(err) {
+						dlqErr := sendToDeadLetterQueue(ctx, policy, msg, err, kafkaConsumer.GetGroupID(), k.metricsClient, k.whClient)
+						if dlqErr == nil {
+							return nil
+						}
+
+						err = fmt.Errorf("%w, dead-letter queue: %w", err, dlqErr)
+					}
+
+					k.whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+						Error: fmt.Sprintf("Failed to process message: %s", err),
+						Topic: msg.Topic(),
+					})
+					logger.Fatal("Failed to process message", slog.Any("err", err), slog.String("topic", msg.Topic()))
+				}
+
+				metrics.EmitIngestionLag(msg, k.metricsClient, k.cfg.Mode, kafkaConsumer.GetGroupID(), tableID.Schema, tableID.Table)
+				metrics.EmitRowLag(msg, k.metricsClient, k.cfg.Mode, kafkaConsumer.GetGroupID(), tableID.Schema, tableID.Table)
+
+				return nil
+			})
+			if err != nil {
+				if fetchCtx.Err() != nil {
+					slog.Info("Stopped consuming", slog.String("topic", topic))
+					return
+				}
 
-			if cfg.Kafka.WaitForTopics {
-				if err := kafkaConsumer.WaitForTopic(ctx); err != nil {
-					whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
-						Error: fmt.Sprintf("Failed waiting for Kafka topic to exist: %s", err),
+				_, isFetchErr := kafkalib.AsFetchMessageError(err)
+				if isFetchErr && db.IsRetryableError(err, context.DeadlineExceeded, kafkalib.ErrNoMessages) {
+					sleepDuration := jitter.Jitter(500, jitter.DefaultMaxMs, fetchRetries)
+					time.Sleep(sleepDuration)
+					fetchRetries++
+					continue
+				} else {
+					k.whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
+						Error: fmt.Sprintf("Failed to fetch and process message: %s", err),
 						Topic: topic,
 					})
-					logger.Fatal("Failed waiting for topic to exist", slog.Any("err", err), slog.String("topic", topic))
+					logger.Fatal("Failed to fetch and process message", slog.Any("err", err), slog.String("topic", topic))
 				}
 			}
+			fetchRetries = 0
+		}
+	}()
+}
 
-			var fetchRetries int
-			for {
-				err = kafkaConsumer.FetchMessageAndProcess(ctx, func(msg artie.Message) error {
-					if len(msg.Value()) == 0 {
-						slog.Debug("Found a tombstone message, skipping...", artie.BuildLogFields(msg)...)
-						return nil
-					}
+func (k *Consumer) stop(state *topicConsumer) {
+	state.cancel()
+	<-state.done
+}
 
-					args := processArgs{
-						Msg:                    msg,
-						GroupID:                kafkaConsumer.GetGroupID(),
-						TopicToConfigFormatMap: tcFmtMap,
-						WhClient:               whClient,
-						EncryptionKey:          encryptionKey,
-						Cache:                  cache,
-					}
+// Reload diffs [topicConfigs] against the topics that are currently being consumed:
+// - Added topics will have a new consumer created and started.
+// - Removed topics will be flushed before their consumer is closed.
+// - Changed topics will be flushed before the new topic config is swapped in.
+// Topics that have not changed are left untouched. [ctx] must be the same context that was passed into [Start].
+func (k *Consumer) Reload(ctx context.Context, topicConfigs []*kafkalib.TopicConfig) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
 
-					tableID, err := args.process(ctx, cfg, inMemDB, dest, metricsClient)
-					if err != nil {
-						whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
-							Error: fmt.Sprintf("Failed to process message: %s", err),
-							Topic: msg.Topic(),
-						})
-						logger.Fatal("Failed to process message", slog.Any("err", err), slog.String("topic", msg.Topic()))
-					}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("consumer is shutting down: %w", err)
+	}
 
-					metrics.EmitIngestionLag(msg, metricsClient, cfg.Mode, kafkaConsumer.GetGroupID(), tableID.Schema, tableID.Table)
-					metrics.EmitRowLag(msg, metricsClient, cfg.Mode, kafkaConsumer.GetGroupID(), tableID.Schema, tableID.Table)
+	registry, ok := kafkalib.GetConsumerRegistryFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("consumer registry not found in context")
+	}
 
-					return nil
-				})
-				if err != nil {
-					_, isFetchErr := kafkalib.AsFetchMessageError(err)
-					if isFetchErr && db.IsRetryableError(err, context.DeadlineExceeded, kafkalib.ErrNoMessages) {
-						sleepDuration := jitter.Jitter(500, jitter.DefaultMaxMs, fetchRetries)
-						time.Sleep(sleepDuration)
-						fetchRetries++
-						continue
-					} else {
-						whClient.SendEvent(ctx, webhooks.EventReplicationError, webhooks.EventProperties{
-							Error: fmt.Sprintf("Failed to fetch and process message: %s", err),
-							Topic: topic,
-						})
-						logger.Fatal("Failed to fetch and process message", slog.Any("err", err), slog.String("topic", topic))
-					}
-				}
-				fetchRetries = 0
+	desired := make(map[string]kafkalib.TopicConfig)
+	for _, topicConfig := range topicConfigs {
+		desired[topicConfig.Topic] = *topicConfig
+	}
+
+	var errs []error
+	for _, topic := range slices.Sorted(maps.Keys(k.topics)) {
+		if _, ok := desired[topic]; !ok {
+			if err := k.removeTopic(ctx, registry, topic); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove topic %q: %w", topic, err))
+			}
+		}
+	}
+
+	for _, topic := range slices.Sorted(maps.Keys(desired)) {
+		topicConfig := desired[topic]
+		state, ok := k.topics[topic]
+		if !ok {
+			if err := k.addTopic(ctx, registry, topicConfig); err != nil {
+				errs = append(errs, fmt.Errorf("failed to add topic %q: %w", topic, err))
 			}
-		}(topic)
+		} else if !reflect.DeepEqual(state.topicConfig, topicConfig) {
+			if err := k.updateTopic(ctx, state, topicConfig); err != nil {
+				errs = append(errs, fmt.Errorf("failed to update topic %q: %w", topic, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (k *Consumer) addTopic(ctx context.Context, registry *kafkalib.ConsumerRegistry, topicConfig kafkalib.TopicConfig) error {
+	slog.Info("Adding topic", slog.String("topic", topicConfig.Topic))
+	provider, err := k.newConsumer(ctx, topicConfig)
+	if err != nil {
+		return err
 	}
 
-	wg.Wait()
+	registry.Add(provider)
+	if err = k.startTopic(ctx, topicConfig); err != nil {
+		if closeErr := registry.Remove(topicConfig.Topic); closeErr != nil {
+			slog.Warn("Failed to close consumer", slog.Any("err", closeErr), slog.String("topic", topicConfig.Topic))
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (k *Consumer) removeTopic(ctx context.Context, registry *kafkalib.ConsumerRegistry, topic string) error {
+	slog.Info("Removing topic", slog.String("topic", topic))
+	state := k.topics[topic]
+	k.stop(state)
+
+	args := Args{Reason: "reload", ReportDBExecutionTime: k.cfg.Reporting.EmitDBExecutionTime}
+	if err := FlushSingleTopic(ctx, k.inMemDB, k.dest, k.metricsClient, k.whClient, args, topic, true); err != nil {
+		// Resume consuming so the buffered rows are not dropped, the removal will be retried on the next reload.
+		// If we're shutting down, the rows are left for [Consumer.Start] to drain instead.
+		if ctx.Err() == nil {
+			k.run(ctx, topic, state)
+		}
+		return fmt.Errorf("failed to flush: %w", err)
+	}
+
+	delete(k.topics, topic)
+	k.tcFmtMap.Remove(topic)
+	if state.dlqPolicy != nil {
+		if err := state.dlqPolicy.Close(); err != nil {
+			slog.Warn("Failed to close dead-letter queue", slog.Any("err", err), slog.String("topic", topic))
+		}
+	}
+
+	return registry.Remove(topic)
+}
+
+func (k *Consumer) updateTopic(ctx context.Context, state *topicConsumer, topicConfig kafkalib.TopicConfig) error {
+	slog.Info("Updating topic config", slog.String("topic", topicConfig.Topic))
+	provider, err := kafkalib.GetConsumerFromContext(ctx, topicConfig.Topic)
+	if err != nil {
+		return err
+	}
+
+	policy, err := dlq.LoadPolicy(ctx, k.cfg.Kafka, topicConfig.DeadLetterQueue)
+	if err != nil {
+		return fmt.Errorf("failed to load dead-letter queue: %w", err)
+	}
+
+	prevPolicy := state.dlqPolicy
+	// The lock is held across the flush and the swap so that no messages are processed with the old topic config after the flush.
+	err = provider.LockAndProcess(ctx, true, func() error {
+		args := Args{Reason: "reload", ReportDBExecutionTime: k.cfg.Reporting.EmitDBExecutionTime}
+		if err := FlushSingleTopic(ctx, k.inMemDB, k.dest, k.metricsClient, k.whClient, args, topicConfig.Topic, false); err != nil {
+			return fmt.Errorf("failed to flush: %w", err)
+		}
+
+		for _, table := range k.inMemDB.GetTables(topicConfig.Topic) {
+			if !table.Empty() {
+				// Multi-step merge will hold onto the table data until the final flush.
+				slog.Warn("Table still has buffered data, the new topic config will apply once it has been flushed", slog.String("tableID", table.GetTableID().String()))
+			}
+		}
+
+		k.tcFmtMap.Add(topicConfig.Topic, NewTopicConfigFormatter(topicConfig, format.GetFormatParser(topicConfig, k.schemaRegistry)))
+		state.topicConfig = topicConfig
+		state.dlqPolicy = policy
+		return nil
+	})
+	if err != nil {
+		if policy != nil {
+			if closeErr := policy.Close(); closeErr != nil {
+				slog.Warn("Failed to close dead-letter queue", slog.Any("err", closeErr), slog.String("topic", topicConfig.Topic))
+			}
+		}
+		return err
+	}
+
+	if prevPolicy != nil {
+		if err := prevPolicy.Close(); err != nil {
+			slog.Warn("Failed to close dead-letter queue", slog.Any("err", err), slog.String("topic", topicConfig.Topic))
+		}
+	}
+
+	return nil
+}
+
+func sendToDeadLetterQueue(ctx context.Context, policy *dlq.Policy, msg artie.Message, processErr error, groupID string, metricsClient base.Client, whClient *webhooks.Client) error {
+	if err := policy.Handle(ctx, msg, processErr); err != nil {
+		return err
+	}
+
+	metricsClient.Incr("dlq.message", map[string]string{
+		"groupID": groupID,
+		"topic":   msg.Topic(),
+	})
+
+	whClient.SendEvent(ctx, webhooks.EventMessageDeadLettered, webhooks.EventProperties{
+		Error:     fmt.Sprintf("Failed to process message: %s", processErr),
+		Topic:     msg.Topic(),
+		Partition: typing.ToPtr(msg.Partition()),
+		Offset:    typing.ToPtr(msg.Offset()),
+	})
+	return nil
 }
@@ -0,0 +1,188 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib"
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/models"
+)
+
+func newBlockingFakeConsumer() *mocks.FakeConsumer {
+	fakeConsumer := &mocks.FakeConsumer{}
+	fakeConsumer.FetchMessageStub = func(ctx context.Context) (artie.Message, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return fakeConsumer
+}
+
+func TestKafkaConsumer_Reload(t *testing.T) {
+	orders := &kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders", CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt}
+	customers := &kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "customers", CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt}
+	cfg := config.Config{Mode: config.Replication, Kafka: &kafkalib.Kafka{TopicConfigs: []*kafkalib.TopicConfig{orders, customers}}}
+
+	inMemDB := models.NewMemoryDB()
+	fakeDest := &mocks.FakeDestination{}
+	fakeDest.MergeReturns(true, nil)
+
+	registry := kafkalib.NewConsumerRegistry()
+	ctx := kafkalib.InjectConsumerRegistryIntoContext(t.Context(), registry)
+	fakeConsumers := make(map[string]*mocks.FakeConsumer)
+	for _, tc := range cfg.Kafka.TopicConfigs {
+		fakeConsumers[tc.Topic] = newBlockingFakeConsumer()
+		registry.Add(kafkalib.NewConsumerProviderForTest(fakeConsumers[tc.Topic], tc.Topic, "group"))
+	}
+
+	kafkaConsumer, err := NewKafkaConsumer(ctx, cfg, inMemDB, fakeDest, metrics.NullMetricsProvider{}, nil, lib.NewKVCache[string]())
+	assert.NoError(t, err)
+	for _, tc := range cfg.Kafka.TopicConfigs {
+		assert.NoError(t, kafkaConsumer.startTopic(ctx, *tc))
+	}
+
+	for _, tc := range cfg.Kafka.TopicConfigs {
+		tableID := cdc.NewTableID("public", tc.Topic)
+		td := inMemDB.GetOrCreateTableData(tableID, tc.Topic)
+		td.SetTableData(optimization.NewTableData(columns.NewColumns(nil), config.Replication, []string{"id"}, *tc, tableID.Table))
+		assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1}, false))
+	}
+	{
+		// Nothing has changed
+		assert.NoError(t, kafkaConsumer.Reload(ctx, cfg.Kafka.TopicConfigs))
+		assert.Equal(t, 0, fakeDest.MergeCallCount())
+		assert.Equal(t, []string{"customers", "orders"}, registry.Topics())
+	}
+	{
+		// Change orders and remove customers
+		changedOrders := *orders
+		changedOrders.SoftDelete = true
+		assert.NoError(t, kafkaConsumer.Reload(ctx, []*kafkalib.TopicConfig{&changedOrders}))
+
+		// Both topics should have been flushed
+		assert.Equal(t, 2, fakeDest.MergeCallCount())
+		assert.Equal(t, 1, fakeConsumers["orders"].CommitMessagesCallCount())
+		assert.Equal(t, 1, fakeConsumers["customers"].CommitMessagesCallCount())
+
+		// Orders should have the new topic config, and its consumer should not have been touched
+		tcFmt, ok := kafkaConsumer.tcFmtMap.GetTopicFmt("orders")
+		assert.True(t, ok)
+		assert.True(t, tcFmt.tc.SoftDelete)
+		assert.Equal(t, 0, fakeConsumers["orders"].CloseCallCount())
+
+		// Customers should have been closed and removed
+		_, ok = kafkaConsumer.tcFmtMap.GetTopicFmt("customers")
+		assert.False(t, ok)
+		assert.Equal(t, 1, fakeConsumers["customers"].CloseCallCount())
+		assert.Equal(t, []string{"orders"}, registry.Topics())
+		assert.NotContains(t, kafkaConsumer.topics, "customers")
+	}
+}
+
+func TestKafkaConsumer_RemoveTopic_ShuttingDown(t *testing.T) {
+	orders := &kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders", CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt}
+	cfg := config.Config{Mode: config.Replication, Kafka: &kafkalib.Kafka{TopicConfigs: []*kafkalib.TopicConfig{orders}}}
+
+	inMemDB := models.NewMemoryDB()
+	fakeDest := &mocks.FakeDestination{}
+	fakeDest.MergeReturns(true, nil)
+
+	fakeConsumer := newBlockingFakeConsumer()
+	fakeConsumer.CommitMessagesReturns(fmt.Errorf("commit failed"))
+	registry := kafkalib.NewConsumerRegistry()
+	registry.Add(kafkalib.NewConsumerProviderForTest(fakeConsumer, orders.Topic, "group"))
+	ctx, cancel := context.WithCancel(kafkalib.InjectConsumerRegistryIntoContext(t.Context(), registry))
+
+	kafkaConsumer, err := NewKafkaConsumer(ctx, cfg, inMemDB, fakeDest, metrics.NullMetricsProvider{}, nil, lib.NewKVCache[string]())
+	assert.NoError(t, err)
+	assert.NoError(t, kafkaConsumer.startTopic(ctx, *orders))
+
+	tableID := cdc.NewTableID("public", orders.Topic)
+	td := inMemDB.GetOrCreateTableData(tableID, orders.Topic)
+	td.SetTableData(optimization.NewTableData(columns.NewColumns(nil), config.Replication, []string{"id"}, *orders, tableID.Table))
+	assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1}, false))
+
+	cancel()
+	// The flush fails, but the consumer should not be restarted since we're shutting down.
+	assert.ErrorContains(t, kafkaConsumer.removeTopic(ctx, registry, orders.Topic), "failed to flush")
+	assert.Contains(t, kafkaConsumer.topics, orders.Topic)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		kafkaConsumer.wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the consumer was restarted after shutting down")
+	}
+
+	// Reloading after shutting down is a no-op.
+	assert.ErrorContains(t, kafkaConsumer.Reload(ctx, nil), "consumer is shutting down")
+}
+
+func TestKafkaConsumer_Start_GracefulShutdown(t *testing.T) {
+	orders := &kafkalib.TopicConfig{Database: "shop", Schema: "public", Topic: "orders", CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt}
+	newConsumer := func(gracefulShutdown *config.GracefulShutdownSettings) (*Consumer, *mocks.FakeDestination, *mocks.FakeConsumer, context.Context, context.CancelFunc) {
+		cfg := config.Config{Mode: config.Replication, Kafka: &kafkalib.Kafka{TopicConfigs: []*kafkalib.TopicConfig{orders}}, GracefulShutdown: gracefulShutdown}
+		inMemDB := models.NewMemoryDB()
+		fakeDest := &mocks.FakeDestination{}
+		fakeDest.MergeReturns(true, nil)
+		fakeConsumer := newBlockingFakeConsumer()
+
+		registry := kafkalib.NewConsumerRegistry()
+		registry.Add(kafkalib.NewConsumerProviderForTest(fakeConsumer, orders.Topic, "group"))
+		ctx, cancel := context.WithCancel(kafkalib.InjectConsumerRegistryIntoContext(t.Context(), registry))
+
+		tableID := cdc.NewTableID("public", orders.Topic)
+		td := inMemDB.GetOrCreateTableData(tableID, orders.Topic)
+		td.SetTableData(optimization.NewTableData(columns.NewColumns(nil), config.Replication, []string{"id"}, *orders, tableID.Table))
+		assert.NoError(t, td.InsertRow("1", map[string]any{"id": 1}, false))
+
+		kafkaConsumer, err := NewKafkaConsumer(ctx, cfg, inMemDB, fakeDest, metrics.NullMetricsProvider{}, nil, lib.NewKVCache[string]())
+		assert.NoError(t, err)
+		return kafkaConsumer, fakeDest, fakeConsumer, ctx, cancel
+	}
+
+	run := func(kafkaConsumer *Consumer, ctx context.Context, cancel context.CancelFunc) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			kafkaConsumer.Start(ctx)
+		}()
+
+		cancel()
+		<-done
+	}
+	{
+		// Without a graceful shutdown, the buffered rows are dropped
+		kafkaConsumer, fakeDest, fakeConsumer, ctx, cancel := newConsumer(nil)
+		run(kafkaConsumer, ctx, cancel)
+		assert.Equal(t, 0, fakeDest.MergeCallCount())
+		assert.Equal(t, 0, fakeConsumer.CommitMessagesCallCount())
+		assert.Equal(t, 1, fakeConsumer.CloseCallCount())
+	}
+	{
+		// With a graceful shutdown, the buffered rows are flushed and committed before the consumer is closed
+		kafkaConsumer, fakeDest, fakeConsumer, ctx, cancel := newConsumer(&config.GracefulShutdownSettings{TimeoutSeconds: 5})
+		run(kafkaConsumer, ctx, cancel)
+		assert.Equal(t, 1, fakeDest.MergeCallCount())
+		assert.Equal(t, 1, fakeConsumer.CommitMessagesCallCount())
+		assert.Equal(t, 1, fakeConsumer.CloseCallCount())
+		assert.True(t, kafkaConsumer.inMemDB.GetOrCreateTableData(cdc.NewTableID("public", orders.Topic), orders.Topic).Empty())
+	}
+}
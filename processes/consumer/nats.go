@@ -0,0 +1,28 @@
+package consumer
+
+import (
+	"context"
+
+	"github.com/artie-labs/transfer/lib"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/natslib"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/lib/webhooks"
+	"github.com/artie-labs/transfer/models"
+)
+
+// NewNATSConsumer consumes from NATS JetStream, the consumers must already be injected into the context with [natslib.InjectConsumersIntoContext].
+func NewNATSConsumer(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client, whClient *webhooks.Client, cache *lib.KVCache[string]) (*Consumer, error) {
+	newProvider := func(ctx context.Context, topicConfig kafkalib.TopicConfig) (kafkalib.QueueConsumer, error) {
+		consumer, err := natslib.NewConsumer(ctx, cfg.NATS, topicConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return consumer, nil
+	}
+
+	return newConsumer(ctx, cfg, newProvider, false, inMemDB, dest, metricsClient, whClient, cache)
+}
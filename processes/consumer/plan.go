@@ -0,0 +1,77 @@
+package consumer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/artie-labs/transfer/clients/shared"
+	"github.com/artie-labs/transfer/lib"
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/cdc/format"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+	"github.com/artie-labs/transfer/models"
+)
+
+// BuildPlans buffers [msgs] the same way [processArgs.process] does and returns the statements that flushing each table would run.
+// Messages are buffered into their own in-memory database that is never flushed, so nothing is written to the destination.
+func BuildPlans(ctx context.Context, cfg config.Config, dest destination.SQLDestination, msgs []artie.Message) ([]shared.TablePlan, error) {
+	encryptionKey, err := cfg.SharedDestinationSettings.BuildEncryptionKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build encryption key: %w", err)
+	}
+
+	var registry *schemaregistry.Client
+	if cfg.Kafka != nil && cfg.Kafka.SchemaRegistry != nil {
+		registry = schemaregistry.NewClient(cfg.Kafka.SchemaRegistry.URL, cfg.Kafka.SchemaRegistry.Username, cfg.Kafka.SchemaRegistry.Password)
+	}
+
+	tcFmtMap := NewTcFmtMap()
+	for _, topicConfig := range cfg.TopicConfigs() {
+		tcFmtMap.Add(topicConfig.Topic, NewTopicConfigFormatter(*topicConfig, format.GetFormatParser(*topicConfig, registry)))
+	}
+
+	inMemDB := models.NewMemoryDB()
+	reservedColumns := destination.BuildReservedColumnNames(dest)
+	cache := lib.NewKVCache[string]()
+	for _, msg := range msgs {
+		topicConfig, ok := tcFmtMap.GetTopicFmt(msg.Topic())
+		if !ok {
+			return nil, fmt.Errorf("no topic config found for topic %q", msg.Topic())
+		}
+
+		parsed, err := topicConfig.toEvent(ctx, cfg, dest, msg, reservedColumns, encryptionKey, cache)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process message at offset %d for topic %q: %w", msg.Offset(), msg.Topic(), err)
+		}
+
+		if parsed.skipped || parsed.event.Filtered() {
+			continue
+		}
+
+		if _, _, err = parsed.event.Save(cfg, inMemDB, topicConfig.tc, reservedColumns); err != nil {
+			return nil, fmt.Errorf("event at offset %d for topic %q failed to save: %w", msg.Offset(), msg.Topic(), err)
+		}
+	}
+
+	var plans []shared.TablePlan
+	for tableID, tableData := range inMemDB.TableData() {
+		if tableData.Empty() {
+			continue
+		}
+
+		plan, err := shared.BuildPlan(ctx, dest, tableData.TableData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build plan for table %q: %w", tableID.Table, err)
+		}
+		plans = append(plans, plan)
+	}
+
+	slices.SortFunc(plans, func(a, b shared.TablePlan) int {
+		return cmp.Compare(a.TableID.FullyQualifiedName(), b.TableID.FullyQualifiedName())
+	})
+	return plans, nil
+}
@@ -0,0 +1,66 @@
+package consumer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	postgresDialect "github.com/artie-labs/transfer/clients/postgres/dialect"
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks"
+	"github.com/artie-labs/transfer/lib/sql"
+)
+
+func buildPlanMessage(topic string, offset int64, key, value string) artie.Message {
+	return artie.NewFranzGoMessage(kgo.Record{Topic: topic, Offset: offset, Key: []byte(key), Value: []byte(value), Timestamp: time.Now()}, offset+1)
+}
+
+func TestBuildPlans(t *testing.T) {
+	cfg := config.Config{
+		Mode: config.Replication,
+		Kafka: &kafkalib.Kafka{
+			TopicConfigs: []*kafkalib.TopicConfig{
+				{Database: "shop", Schema: "public", Topic: "orders", CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt},
+				{Database: "shop", Schema: "public", Topic: "customers", CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt, SkippedOperations: "c"},
+			},
+		},
+	}
+
+	fakeDest := &mocks.FakeSQLDestination{}
+	fakeDest.DialectReturns(postgresDialect.PostgresDialect{})
+	fakeDest.GetTableConfigReturns(types.NewDestinationTableConfig(nil, false), nil)
+	fakeDest.IdentifierForCalls(func(pair kafkalib.DatabaseAndSchemaPair, table string) sql.TableIdentifier {
+		return postgresDialect.NewTableIdentifier(pair.Schema, table)
+	})
+
+	msgs := []artie.Message{
+		buildPlanMessage("orders", 0, `{"id": 1}`, `{"payload": {"before": null, "after": {"id": 1, "name": "foo"}, "source": {"schema": "public", "table": "orders", "ts_ms": 1700000000000}, "op": "c"}}`),
+		buildPlanMessage("orders", 1, `{"id": 2}`, `{"payload": {"before": null, "after": {"id": 2, "name": "bar"}, "source": {"schema": "public", "table": "orders", "ts_ms": 1700000000000}, "op": "c"}}`),
+		// Skipped operations should not create a table
+		buildPlanMessage("customers", 0, `{"id": 1}`, `{"payload": {"before": null, "after": {"id": 1}, "source": {"schema": "public", "table": "customers", "ts_ms": 1700000000000}, "op": "c"}}`),
+	}
+
+	plans, err := BuildPlans(t.Context(), cfg, fakeDest, msgs)
+	assert.NoError(t, err)
+	assert.Len(t, plans, 1)
+	assert.Equal(t, `"public"."orders"`, plans[0].TableID.FullyQualifiedName())
+	assert.Equal(t, uint(2), plans[0].Rows)
+	assert.True(t, plans[0].CreateTable)
+	assert.Len(t, plans[0].DDL, 1)
+	assert.Len(t, plans[0].Merge, 1)
+
+	// Nothing should have been executed
+	assert.Zero(t, fakeDest.ExecContextCallCount())
+	assert.Zero(t, fakeDest.BeginCallCount())
+	assert.Zero(t, fakeDest.MergeCallCount())
+
+	// Messages for topics that are not in the config
+	_, err = BuildPlans(t.Context(), cfg, fakeDest, []artie.Message{buildPlanMessage("unknown", 0, `{"id": 1}`, `{}`)})
+	assert.ErrorContains(t, err, `no topic config found for topic "unknown"`)
+}
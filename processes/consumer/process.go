@@ -2,20 +2,102 @@ package consumer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/artie-labs/transfer/lib"
 	"github.com/artie-labs/transfer/lib/artie"
 	"github.com/artie-labs/transfer/lib/cdc"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/optimization"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/lib/telemetry/tracing"
 	"github.com/artie-labs/transfer/lib/webhooks"
 	"github.com/artie-labs/transfer/models"
 	"github.com/artie-labs/transfer/models/event"
 )
 
+// poisonMessageError is returned when the message itself could not be processed (e.g. the key or value cannot be parsed).
+// Unlike flush errors, it is safe to route these messages to a dead-letter queue and continue.
+type poisonMessageError struct {
+	err error
+}
+
+func newPoisonMessageError(err error) error {
+	return poisonMessageError{err: err}
+}
+
+func (p poisonMessageError) Error() string {
+	return p.err.Error()
+}
+
+func (p poisonMessageError) Unwrap() error {
+	return p.err
+}
+
+func isPoisonMessageError(err error) bool {
+	var poisonErr poisonMessageError
+	return errors.As(err, &poisonErr)
+}
+
+// retryableErrors are caused by the environment rather than the message (e.g. the schema registry is down or the disk is full).
+// Retrying the message later may succeed, so these are never treated as poison.
+var retryableErrors = []error{cdc.ErrSchemaRegistry, optimization.ErrSpill}
+
+// asPoisonMessageError wraps [err] in a [poisonMessageError] unless it was caused by one of [retryableErrors].
+func asPoisonMessageError(err error) error {
+	for _, retryableErr := range retryableErrors {
+		if errors.Is(err, retryableErr) {
+			return err
+		}
+	}
+
+	return newPoisonMessageError(err)
+}
+
+// parsedMessage is returned by [TopicConfigFormatter.toEvent].
+type parsedMessage struct {
+	event event.Event
+	// operation is set as soon as the value has been parsed, even if converting it to an event fails.
+	operation string
+	// skipped is true if the topic is configured to skip [operation].
+	skipped bool
+	// what is the step that failed, it is only set when an error is returned.
+	what string
+}
+
+// toEvent parses [msg] and converts it into an [event.Event]. This is shared by [processArgs.process] and [BuildPlans] so that a plan buffers messages the same way the consumer does.
+func (t TopicConfigFormatter) toEvent(ctx context.Context, cfg config.Config, dest destination.Destination, msg artie.Message, reservedColumns map[string]bool, encryptionKey []byte, cache *lib.KVCache[string]) (parsedMessage, error) {
+	pkMap, err := t.buildPKMap(ctx, msg.Key(), reservedColumns)
+	if err != nil {
+		return parsedMessage{what: "marshall_pk_err"}, fmt.Errorf("cannot unmarshal key %q: %w", string(msg.Key()), err)
+	}
+
+	_event, err := t.GetEventFromBytes(ctx, msg.Value())
+	if err != nil {
+		return parsedMessage{what: "marshal_value_err"}, fmt.Errorf("cannot unmarshal event: %w", err)
+	}
+
+	if fallback, ok := _event.(cdc.ExecutionTimeFallback); ok && !msg.PublishTime().IsZero() {
+		fallback.SetExecutionTimeFallback(msg.PublishTime())
+	}
+
+	parsed := parsedMessage{operation: string(_event.Operation())}
+	parsed.event, err = event.ToMemoryEvent(ctx, dest, _event, pkMap, t.tc, cfg.Mode, cfg.SharedDestinationSettings, encryptionKey, cache)
+	if err != nil {
+		parsed.what = "to_mem_event_err"
+		return parsed, fmt.Errorf("cannot convert to memory event: %w", err)
+	}
+
+	parsed.event.SetPartition(msg.Partition())
+	parsed.skipped = t.ShouldSkip(parsed.operation)
+	return parsed, nil
+}
+
 type processArgs struct {
 	Msg                    artie.Message
 	GroupID                string
@@ -25,11 +107,21 @@ type processArgs struct {
 	Cache                  *lib.KVCache[string]
 }
 
-func (p processArgs) process(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client) (cdc.TableID, error) {
+func (p processArgs) process(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client) (_ cdc.TableID, err error) {
 	if p.TopicToConfigFormatMap == nil {
 		return cdc.TableID{}, fmt.Errorf("failed to process, topicConfig is nil")
 	}
 
+	// If the source propagated a trace context through the Kafka headers, continue that trace.
+	ctx, span := tracing.Start(tracing.ExtractFromHeaders(ctx, p.Msg.Headers()), "consumer.process",
+		attribute.String("topic", p.Msg.Topic()),
+		attribute.Int("partition", p.Msg.Partition()),
+		attribute.Int64("offset", p.Msg.Offset()),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	reservedColumns := destination.BuildReservedColumnNames(dest)
 	tags := map[string]string{
 		"mode":    cfg.Mode.String(),
@@ -51,44 +143,53 @@ func (p processArgs) process(ctx context.Context, cfg config.Config, inMemDB *mo
 
 	tags["database"] = topicConfig.tc.Database
 	tags["schema"] = topicConfig.tc.Schema
-	pkMap, err := topicConfig.buildPKMap(p.Msg.Key(), reservedColumns)
-	if err != nil {
-		tags["what"] = "marshall_pk_err"
-		return cdc.TableID{}, fmt.Errorf("cannot unmarshal key %q: %w", string(p.Msg.Key()), err)
+	parsed, err := topicConfig.toEvent(ctx, cfg, dest, p.Msg, reservedColumns, p.EncryptionKey, p.Cache)
+	if parsed.operation != "" {
+		tags["op"] = parsed.operation
 	}
-
-	_event, err := topicConfig.GetEventFromBytes(p.Msg.Value())
 	if err != nil {
-		tags["what"] = "marshal_value_err"
-		return cdc.TableID{}, fmt.Errorf("cannot unmarshal event: %w", err)
-	}
-
-	tags["op"] = string(_event.Operation())
-	evt, err := event.ToMemoryEvent(ctx, dest, _event, pkMap, topicConfig.tc, cfg.Mode, cfg.SharedDestinationSettings, p.EncryptionKey, p.Cache)
-	if err != nil {
-		tags["what"] = "to_mem_event_err"
-		return cdc.TableID{}, fmt.Errorf("cannot convert to memory event: %w", err)
+		tags["what"] = parsed.what
+		return cdc.TableID{}, asPoisonMessageError(err)
 	}
 
+	evt := parsed.event
 	// Table name is only available after event has been cast
 	tags["table"] = evt.GetTable()
-	if topicConfig.ShouldSkip(string(_event.Operation())) {
+	span.SetAttributes(attribute.String("table", evt.GetTable()))
+	if parsed.skipped {
 		// Check to see if we should skip first
 		// This way, we can emit a specific tag to be more clear
 		tags["skipped"] = "yes"
 		return evt.GetTableID(), nil
 	}
 
+	if evt.Filtered() {
+		tags["skipped"] = "filtered"
+		metricsClient.Incr("row.filtered", map[string]string{
+			"mode":     cfg.Mode.String(),
+			"database": topicConfig.tc.Database,
+			"schema":   topicConfig.tc.Schema,
+			"table":    evt.GetTable(),
+		})
+		// The webhook is sent once per flush with the number of rows that were filtered, see [FlushSingleTopic].
+		inMemDB.GetOrCreateTableData(evt.GetTableID(), topicConfig.tc.Topic).IncrementFilteredRows()
+		return evt.GetTableID(), nil
+	}
+
 	if cfg.Reporting.EmitExecutionTime {
 		evt.EmitExecutionTimeLag(metricsClient)
 	}
 
+	_, saveSpan := tracing.Start(ctx, "event.save")
 	shouldFlush, flushReason, err := evt.Save(cfg, inMemDB, topicConfig.tc, reservedColumns)
+	tracing.End(saveSpan, err)
 	if err != nil {
 		tags["what"] = "save_fail"
-		return cdc.TableID{}, fmt.Errorf("event failed to save: %w", err)
+		return cdc.TableID{}, asPoisonMessageError(fmt.Errorf("event failed to save: %w", err))
 	}
 
+	emitSpillStats(metricsClient, inMemDB.GetOrCreateTableData(evt.GetTableID(), topicConfig.tc.Topic), cfg.Mode)
+
 	if shouldFlush {
 		executionTime := evt.GetExecutionTime()
 		err = FlushSingleTopic(ctx, inMemDB, dest, metricsClient, p.WhClient, Args{Reason: flushReason, ReportDBExecutionTime: cfg.Reporting.EmitDBExecutionTime, EventExecutionTime: &executionTime}, topicConfig.tc.Topic, false)
@@ -100,3 +201,22 @@ func (p processArgs) process(ctx context.Context, cfg config.Config, inMemDB *mo
 
 	return evt.GetTableID(), nil
 }
+
+// emitSpillStats emits counters for the rows that were moved to disk while saving the event, see [config.SpillSettings].
+func emitSpillStats(metricsClient base.Client, table *models.TableData, mode config.Mode) {
+	stats := table.ResetSpillStats()
+	if stats.Rows == 0 && stats.Files == 0 {
+		return
+	}
+
+	tags := map[string]string{
+		"mode":     mode.String(),
+		"database": table.TopicConfig().Database,
+		"schema":   table.TopicConfig().Schema,
+		"table":    table.GetTableID().Table,
+	}
+
+	metricsClient.Count("buffer.spill.files", int64(stats.Files), tags)
+	metricsClient.Count("buffer.spill.rows", int64(stats.Rows), tags)
+	metricsClient.Count("buffer.spill.bytes", stats.Bytes, tags)
+}
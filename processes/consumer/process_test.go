@@ -2,6 +2,10 @@ package consumer
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -10,11 +14,15 @@ import (
 
 	"github.com/artie-labs/transfer/lib/artie"
 	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/cdc/confluent"
+	"github.com/artie-labs/transfer/lib/cdc/flattened"
 	"github.com/artie-labs/transfer/lib/cdc/mongo"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/mocks"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics"
 	"github.com/artie-labs/transfer/models"
 )
@@ -56,6 +64,7 @@ func TestProcessMessageFailures(t *testing.T) {
 	args.TopicToConfigFormatMap = NewTcFmtMap()
 	tableName, err = args.process(ctx, cfg, memDB, &mocks.FakeDestination{}, metrics.NullMetricsProvider{})
 	assert.ErrorContains(t, err, "failed to get topic", err.Error())
+	assert.False(t, isPoisonMessageError(err))
 	assert.Equal(t, 0, len(memDB.TableData()))
 	assert.Empty(t, tableName)
 
@@ -84,6 +93,7 @@ func TestProcessMessageFailures(t *testing.T) {
 
 	tableName, err = args.process(ctx, cfg, memDB, &mocks.FakeDestination{}, metrics.NullMetricsProvider{})
 	assert.ErrorContains(t, err, `cannot unmarshal key "": format:  is not supported`)
+	assert.True(t, isPoisonMessageError(err))
 	assert.Equal(t, 0, len(memDB.TableData()))
 	assert.Empty(t, tableName)
 
@@ -177,10 +187,12 @@ func TestProcessMessageFailures(t *testing.T) {
 
 	td := memoryDB.GetOrCreateTableData(tableID, msg.Topic())
 	// Check that there are corresponding row(s) in the memory DB
-	assert.Len(t, td.Rows(), 1)
+	rows, err := td.Rows()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
 
 	var rowData map[string]any
-	for _, row := range td.Rows() {
+	for _, row := range rows {
 		if id, ok := row.GetValue("_id"); ok {
 			if id == "1004" {
 				rowData = row.GetData()
@@ -217,7 +229,7 @@ func TestBuildPKMap(t *testing.T) {
 			PrimaryKeysOverride: []string{"_id"},
 		}, &mgo)
 
-		pkMap, err := tcf.buildPKMap(nil, nil)
+		pkMap, err := tcf.buildPKMap(t.Context(), nil, nil)
 		assert.NoError(t, err)
 		assert.Empty(t, pkMap)
 	}
@@ -228,7 +240,7 @@ func TestBuildPKMap(t *testing.T) {
 			IncludePrimaryKeys: []string{"_id"},
 		}, &mgo)
 
-		pkMap, err := tcf.buildPKMap(nil, nil)
+		pkMap, err := tcf.buildPKMap(t.Context(), nil, nil)
 		assert.NoError(t, err)
 		assert.Empty(t, pkMap)
 	}
@@ -238,7 +250,7 @@ func TestBuildPKMap(t *testing.T) {
 			CDCKeyFormat: "org.apache.kafka.connect.storage.StringConverter",
 		}, &mgo)
 
-		_, err := tcf.buildPKMap(nil, nil)
+		_, err := tcf.buildPKMap(t.Context(), nil, nil)
 		assert.ErrorContains(t, err, "key is nil")
 	}
 	{
@@ -248,7 +260,7 @@ func TestBuildPKMap(t *testing.T) {
 			PrimaryKeysOverride: []string{"_id"},
 		}, &mgo)
 
-		pkMap, err := tcf.buildPKMap([]byte("Struct{id=1001}"), nil)
+		pkMap, err := tcf.buildPKMap(t.Context(), []byte("Struct{id=1001}"), nil)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, pkMap)
 	}
@@ -346,9 +358,11 @@ func TestProcessMessageNilKeyWithPrimaryKeyOverride(t *testing.T) {
 	assert.Equal(t, tableID, actualTableID)
 
 	td := memDB.GetOrCreateTableData(tableID, "foo")
-	assert.Len(t, td.Rows(), 1)
+	rows, err := td.Rows()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
 
-	for _, row := range td.Rows() {
+	for _, row := range rows {
 		value, ok := row.GetValue("_id")
 		assert.True(t, ok)
 		assert.Equal(t, int64(1004), value)
@@ -497,3 +511,106 @@ func TestProcessMessageSkip(t *testing.T) {
 		assert.Equal(t, 0, int(td.NumberOfRows()))
 	}
 }
+
+func TestProcessMessageSpillFailure(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Config{
+		FlushIntervalSeconds: 10,
+		BufferRows:           10,
+		FlushSizeKb:          900,
+		// A threshold of zero moves every row to disk as soon as it is inserted.
+		Spill: &config.SpillSettings{Enabled: true, Directory: dir},
+	}
+
+	var mgo mongo.Debezium
+	tc := kafkalib.TopicConfig{
+		Database:            testDB,
+		TableName:           table,
+		Schema:              schema,
+		Topic:               "foo",
+		CDCKeyFormat:        "org.apache.kafka.connect.storage.StringConverter",
+		PrimaryKeysOverride: []string{"_id"},
+	}
+
+	tcFmtMap := NewTcFmtMap()
+	tcFmtMap.Add("foo", NewTopicConfigFormatter(tc, &mgo))
+
+	val := `{"payload": {"after": "{\"_id\": {\"$numberLong\": \"1004\"},\"first_name\": \"Anne\"}", "source": {"ts_ms": 1668753321000, "db": "inventory", "collection": "customers"}, "op": "r", "ts_ms": 1668753329387}}`
+	memDB := models.NewMemoryDB()
+	args := processArgs{
+		Msg:                    artie.NewFranzGoMessage(kgo.Record{Topic: "foo", Value: []byte(val)}, 0),
+		GroupID:                "foo",
+		TopicToConfigFormatMap: tcFmtMap,
+	}
+
+	_, err := args.process(t.Context(), cfg, memDB, &mocks.FakeDestination{}, metrics.NullMetricsProvider{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, memDB.GetOrCreateTableData(tableID, "foo").SpilledRows())
+
+	// Truncate the spill file so that the row can no longer be read back when the same primary key comes in again.
+	spillFiles, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, spillFiles, 1)
+	assert.NoError(t, os.Truncate(filepath.Join(dir, spillFiles[0].Name()), 0))
+
+	_, err = args.process(t.Context(), cfg, memDB, &mocks.FakeDestination{}, metrics.NullMetricsProvider{})
+	assert.ErrorIs(t, err, optimization.ErrSpill)
+	assert.ErrorContains(t, err, "failed to read spilled row")
+	// This is not an issue with the message, so it should not be sent to the dead-letter queue.
+	assert.False(t, isPoisonMessageError(err))
+}
+
+func TestProcessMessageSchemaRegistryUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	tcFmtMap := NewTcFmtMap()
+	tcFmtMap.Add("foo", NewTopicConfigFormatter(kafkalib.TopicConfig{
+		Database:     testDB,
+		TableName:    table,
+		Schema:       schema,
+		Topic:        "foo",
+		CDCKeyFormat: kafkalib.AvroKeyFmt,
+	}, confluent.NewAvro(schemaregistry.NewClient(server.URL, "", ""))))
+
+	args := processArgs{
+		// Wire format: magic byte, schema ID 1 and then the payload.
+		Msg:                    artie.NewFranzGoMessage(kgo.Record{Topic: "foo", Key: []byte{0, 0, 0, 0, 1, 0}, Value: []byte{0, 0, 0, 0, 2, 0}}, 0),
+		GroupID:                "foo",
+		TopicToConfigFormatMap: tcFmtMap,
+	}
+
+	_, err := args.process(t.Context(), config.Config{}, models.NewMemoryDB(), &mocks.FakeDestination{}, metrics.NullMetricsProvider{})
+	assert.ErrorIs(t, err, cdc.ErrSchemaRegistry)
+	// The message may be fine, so it should not be sent to the dead-letter queue.
+	assert.False(t, isPoisonMessageError(err))
+}
+
+func TestProcessMessageFlattenedWithoutSourceTimestamp(t *testing.T) {
+	tc := kafkalib.TopicConfig{
+		Database:     testDB,
+		TableName:    table,
+		Schema:       schema,
+		Topic:        "foo",
+		CDCFormat:    constants.DBZRelationalFlattenedFormat,
+		CDCKeyFormat: "org.apache.kafka.connect.storage.StringConverter",
+	}
+
+	tcFmtMap := NewTcFmtMap()
+	tcFmtMap.Add("foo", NewTopicConfigFormatter(tc, flattened.NewFormat(tc.GetFlattenedSettings())))
+
+	publishTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	memDB := models.NewMemoryDB()
+	args := processArgs{
+		Msg:                    artie.NewFranzGoMessage(kgo.Record{Topic: "foo", Key: []byte("Struct{id=1}"), Value: []byte(`{"id": 1, "name": "dusty"}`), Timestamp: publishTime}, 0),
+		GroupID:                "foo",
+		TopicToConfigFormatMap: tcFmtMap,
+	}
+
+	_, err := args.process(t.Context(), config.Config{FlushIntervalSeconds: 10, BufferRows: 10, FlushSizeKb: 900}, memDB, &mocks.FakeDestination{}, metrics.NullMetricsProvider{})
+	assert.NoError(t, err)
+	// The message's timestamp is used instead of the Unix epoch.
+	assert.Equal(t, publishTime, memDB.GetOrCreateTableData(tableID, "foo").GetLatestTimestamp().UTC())
+}
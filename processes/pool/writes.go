@@ -7,6 +7,7 @@ import (
 
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
 	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/webhooks"
@@ -17,8 +18,20 @@ import (
 func StartPool(ctx context.Context, inMemDB *models.DatabaseData, dest destination.Destination, metricsClient base.Client, whClient *webhooks.Client, topics []string, td time.Duration, cfg config.Config) {
 	slog.Info("Starting pool timer...")
 	ticker := time.NewTicker(td)
-	for range ticker.C {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
 		slog.Info("Flushing via pool...")
+		// Topics can be added or removed when the config is reloaded, so the consumers are the source of truth.
+		if currentTopics, ok := kafkalib.GetTopicsFromContext(ctx); ok {
+			topics = currentTopics
+		}
+
 		if err := consumer.Flush(ctx, inMemDB, dest, metricsClient, whClient, topics, consumer.Args{Reason: "time", CoolDown: typing.ToPtr(td), ReportDBExecutionTime: cfg.Reporting.EmitDBExecutionTime}); err != nil {
 			slog.Error("Failed to flush via pool", slog.Any("err", err))
 		}